@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// loadConfigFile reads the options of a configuration file, keyed by the names of their flags.
+func loadConfigFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the configuration file: %w", err)
+	}
+	options := map[string]any{}
+	if err := yaml.Unmarshal(data, &options); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return options, nil
+}
+
+// applyConfigFile sets the flags to the options of a configuration file, except the flags in
+// commandLine, which were set on the command line and take precedence.
+func applyConfigFile(flags *pflag.FlagSet, options map[string]any, commandLine map[string]bool) error {
+	for name, value := range options {
+		flag := flags.Lookup(name)
+		if flag == nil || name == "config" {
+			return fmt.Errorf("unknown option %q in the configuration file", name)
+		}
+		if commandLine[name] {
+			continue
+		}
+		if err := setFlag(flags, flag, value); err != nil {
+			return fmt.Errorf("invalid option %q in the configuration file: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setFlag sets a flag to a value decoded from YAML: the lists replace the values of the slice flags
+// and the maps the values of the map flags.
+func setFlag(flags *pflag.FlagSet, flag *pflag.Flag, value any) error {
+	switch value := value.(type) {
+	case nil:
+		return fmt.Errorf("no value")
+	case []any:
+		slice, ok := flag.Value.(pflag.SliceValue)
+		if !ok {
+			return fmt.Errorf("expected a single value, got a list")
+		}
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			s, err := scalarString(item)
+			if err != nil {
+				return err
+			}
+			values = append(values, s)
+		}
+		return slice.Replace(values)
+	case map[string]any:
+		if flag.Value.Type() != "stringToString" {
+			return fmt.Errorf("expected a single value, got a map")
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		// The first value replaces the default map, the next ones are added to it.
+		for _, key := range keys {
+			s, err := scalarString(value[key])
+			if err != nil {
+				return err
+			}
+			if err := flags.Set(flag.Name, key+"="+s); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		s, err := scalarString(value)
+		if err != nil {
+			return err
+		}
+		return flags.Set(flag.Name, s)
+	}
+}
+
+// scalarString formats a scalar decoded from YAML as a flag value, the numbers without exponent.
+func scalarString(value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("expected a string, a number or a boolean, got %T", value)
+	}
+}
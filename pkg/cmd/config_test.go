@@ -0,0 +1,237 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestApplyConfigFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		commandLine []string
+		expected    func(o *RunOptions)
+		wantErr     bool
+	}{
+		{
+			name: "scalars, lists and maps",
+			config: `port: 9090
+read-only: true
+impact-threshold: 1000000
+tool-timeout: 30s
+toolsets: [core, diagnostics]
+feature-gates:
+  A: "true"
+  B: false
+`,
+			expected: func(o *RunOptions) {
+				o.Port = "9090"
+				o.ReadOnly = true
+				o.ImpactThreshold = 1000000
+				o.ToolTimeout = 30 * time.Second
+				o.Toolsets = []string{"core", "diagnostics"}
+				o.FeatureGates = map[string]string{"A": "true", "B": "false"}
+			},
+		},
+		{
+			name:        "command line takes precedence",
+			config:      "port: 9090\ntoolsets: [core]\n",
+			commandLine: []string{"--port=7070"},
+			expected: func(o *RunOptions) {
+				o.Port = "7070"
+				o.Toolsets = []string{"core"}
+			},
+		},
+		{name: "unknown option", config: "listen: 9090\n", wantErr: true},
+		{name: "config option", config: "config: other.yaml\n", wantErr: true},
+		{name: "list of a single value", config: "port: [9090]\n", wantErr: true},
+		{name: "invalid value", config: "read-only: maybe\n", wantErr: true},
+		{name: "nested list", config: "toolsets: [[core]]\n", wantErr: true},
+		{name: "not a map", config: "- port\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(file, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			o := NewRunOptions(genericiooptions.NewTestIOStreamsDiscard())
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			o.AddFlags(flags)
+			if err := flags.Parse(tt.commandLine); err != nil {
+				t.Fatal(err)
+			}
+			commandLine := map[string]bool{}
+			flags.Visit(func(flag *pflag.Flag) {
+				commandLine[flag.Name] = true
+			})
+
+			err := func() error {
+				options, err := loadConfigFile(file)
+				if err != nil {
+					return err
+				}
+				return applyConfigFile(flags, options, commandLine)
+			}()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			expected := NewRunOptions(genericiooptions.NewTestIOStreamsDiscard())
+			expectedFlags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			expected.AddFlags(expectedFlags)
+			tt.expected(expected)
+			if !reflect.DeepEqual(o, expected) {
+				t.Errorf("expected %+v, got %+v", expected, o)
+			}
+		})
+	}
+}
+
+func TestReloadConfigFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		commandLine []string
+		expected    func(o *RunOptions)
+		wantErr     bool
+	}{
+		{
+			name:   "reloadable options of the file",
+			config: "clusters: /etc/k-mcp/clusters.yaml\ntoolsets: [core]\nread-only: true\n",
+			expected: func(o *RunOptions) {
+				o.ClustersFile = "/etc/k-mcp/clusters.yaml"
+				o.Toolsets = []string{"core"}
+				o.ReadOnly = true
+			},
+		},
+		{
+			name:        "command line takes precedence",
+			config:      "clusters: /etc/k-mcp/clusters.yaml\nsummary-columns: /etc/k-mcp/columns.yaml\nlog-level: debug\n",
+			commandLine: []string{"--clusters=/srv/clusters.yaml", "--log-level=warn"},
+			expected: func(o *RunOptions) {
+				o.ClustersFile = "/srv/clusters.yaml"
+				o.SummaryColumnsFile = "/etc/k-mcp/columns.yaml"
+				o.LogLevel = "warn"
+			},
+		},
+		{name: "invalid reloadable option", config: "impact-threshold: -1\n", wantErr: true},
+		{name: "invalid log level", config: "log-level: verbose\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(file, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			o := NewRunOptions(genericiooptions.NewTestIOStreamsDiscard())
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			o.AddFlags(flags)
+			if err := flags.Parse(tt.commandLine); err != nil {
+				t.Fatal(err)
+			}
+			o.ConfigFile = file
+			o.flags = flags
+			o.commandLine = map[string]bool{}
+			flags.Visit(func(flag *pflag.Flag) {
+				o.commandLine[flag.Name] = true
+			})
+
+			fresh, err := o.reloadConfigFile()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			expected := NewRunOptions(genericiooptions.NewTestIOStreamsDiscard())
+			expected.AddFlags(pflag.NewFlagSet("test", pflag.ContinueOnError))
+			tt.expected(expected)
+			if fresh.ClustersFile != expected.ClustersFile || fresh.SummaryColumnsFile != expected.SummaryColumnsFile ||
+				fresh.LogLevel != expected.LogLevel || fresh.ReadOnly != expected.ReadOnly || !reflect.DeepEqual(fresh.Toolsets, expected.Toolsets) {
+				t.Errorf("expected %+v, got %+v", expected, fresh)
+			}
+		})
+	}
+}
+
+func TestRestartOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		commandLine []string
+		expected    []string
+	}{
+		{name: "unchanged", config: "port: \"8080\"\naudience: k-mcp\n"},
+		{name: "reloadable options", config: "toolsets: [core]\nlog-level: debug\nclusters: /etc/k-mcp/clusters.yaml\n"},
+		{
+			name:     "options requiring a restart",
+			config:   "port: 9090\naudience: other\ninsecure: true\nissuer: [https://issuer.example.com]\ntoolsets: [core]\n",
+			expected: []string{"audience", "insecure", "issuer", "port"},
+		},
+		{name: "set on the command line", config: "port: 9090\n", commandLine: []string{"--port=7070"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewRunOptions(genericiooptions.NewTestIOStreamsDiscard())
+			o.flags = pflag.NewFlagSet("test", pflag.ContinueOnError)
+			o.AddFlags(o.flags)
+			if err := o.flags.Parse(tt.commandLine); err != nil {
+				t.Fatal(err)
+			}
+			o.commandLine = map[string]bool{}
+			o.flags.Visit(func(flag *pflag.Flag) {
+				o.commandLine[flag.Name] = true
+			})
+
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(file, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			options, err := loadConfigFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			flags := pflag.NewFlagSet("config", pflag.ContinueOnError)
+			NewRunOptions(o.IOStreams).AddFlags(flags)
+			if err := applyConfigFile(flags, options, o.commandLine); err != nil {
+				t.Fatal(err)
+			}
+
+			if changed := o.restartOptions(flags); !reflect.DeepEqual(changed, tt.expected) {
+				t.Errorf("expected the options %v to require a restart, got %v", tt.expected, changed)
+			}
+		})
+	}
+}
@@ -19,15 +19,26 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
+	"net/url"
 	"os"
+	"path"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ardaguclu/k-mcp/pkg/mcp"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/rest"
 )
 
 var (
@@ -44,19 +55,100 @@ var (
 )
 
 const (
-	DefaultPort     = "8080"
-	DefaultAudience = "k-mcp"
+	DefaultPort               = "8080"
+	DefaultAudience           = "k-mcp"
+	DefaultElicitationTimeout = 5 * time.Minute
+	DefaultToolTimeout        = 15 * time.Minute
 )
 
+const (
+	// LogFormatText writes the logs as key=value pairs.
+	LogFormatText = "text"
+	// LogFormatJSON writes the logs as JSON objects, one per line.
+	LogFormatJSON = "json"
+)
+
+// FeatureFailureInjection enables the hidden flags injecting latency and errors
+// into the requests sent to the API servers, for resilience testing on staging.
+const FeatureFailureInjection = "FailureInjection"
+
+// defaultFeatureGates are the known feature gates with their default values.
+var defaultFeatureGates = map[string]bool{
+	FeatureFailureInjection: false,
+}
+
 // RunOptions provides information required to run
 // MCP Server
 type RunOptions struct {
 	Port                    string
 	LogLevel                string
+	LogFormat               string
 	Audience                string
 	TLSInsecure             bool
 	TLSCertificateAuthority string
 	TLSServerName           string
+	ConfigFile              string
+	Transport               string
+	InCluster               bool
+	Kubeconfig              string
+	KubeconfigContext       string
+	APIServerTLSFile        string
+	ClustersFile            string
+	ClusterRouting          map[string]string
+	SummaryColumnsFile      string
+	SavedQueriesFile        string
+	ConformanceProfilesFile string
+	CRDToolsFile            string
+	PreferencesFile         string
+	AuditNamespace          string
+	AuditLogFile            string
+	AuditWebhookURL         string
+	RedactEnvPatterns       []string
+	ResourceURL             string
+	TokenExchangeURL        string
+	TokenExchangeClientID   string
+	TokenExchangeSecret     string
+	TokenReview             bool
+	MemoryLimit             string
+	MaxRequestBodySize      string
+	MaxHeaderSize           string
+	MaxResultSize           string
+	ReadHeaderTimeout       time.Duration
+	ReadTimeout             time.Duration
+	WriteTimeout            time.Duration
+	IdleTimeout             time.Duration
+	ImpactThreshold         int
+	JWKSURL                 string
+	Issuers                 []string
+	SigningAlgorithms       []string
+	ProductionNamespaces    []string
+	UserAgent               string
+	QPS                     float32
+	Burst                   int
+	DiscoveryCacheDir       string
+	DiscoveryCacheTTL       time.Duration
+	DisableDiscoveryCache   bool
+	Headers                 map[string]string
+	ElicitationTimeout      time.Duration
+	ToolTimeout             time.Duration
+	ProbeAPIServers         []string
+	Toolsets                []string
+	ReadOnly                bool
+	Headless                bool
+	ProbeInterval           time.Duration
+	FeatureGates            map[string]string
+	InjectLatency           time.Duration
+	InjectErrorRate         float64
+	OTLPEndpoint            string
+	OTLPHeaders             map[string]string
+	TraceSamplingRatio      float64
+
+	featureGates map[string]bool
+	logLevel     slog.LevelVar
+	// commandLine is the set of the flags set on the command line, which the configuration file does not override.
+	commandLine map[string]bool
+	// flags are the flags of the options, whose values the reloaded configuration file is compared to.
+	flags *pflag.FlagSet
 
 	Server        *mcp.Server
 	DynamicConfig *mcp.DynamicConfig
@@ -67,9 +159,25 @@ type RunOptions struct {
 // NewRunOptions provides an instance of RunOptions with default values
 func NewRunOptions(streams genericiooptions.IOStreams) *RunOptions {
 	return &RunOptions{
-		IOStreams: streams,
-		Port:      DefaultPort,
-		Audience:  DefaultAudience,
+		IOStreams:            streams,
+		Port:                 DefaultPort,
+		Transport:            mcp.TransportHTTP,
+		Audience:             DefaultAudience,
+		LogFormat:            LogFormatText,
+		ElicitationTimeout:   DefaultElicitationTimeout,
+		ToolTimeout:          DefaultToolTimeout,
+		ImpactThreshold:      mcp.DefaultImpactThreshold,
+		ProductionNamespaces: mcp.DefaultProductionNamespaces,
+		RedactEnvPatterns:    mcp.DefaultRedactEnvPatterns,
+		MaxRequestBodySize:   resource.NewQuantity(mcp.DefaultHTTPLimits.MaxRequestBodySize, resource.BinarySI).String(),
+		MaxHeaderSize:        resource.NewQuantity(int64(mcp.DefaultHTTPLimits.MaxHeaderBytes), resource.BinarySI).String(),
+		MaxResultSize:        resource.NewQuantity(mcp.DefaultMaxResultSize, resource.BinarySI).String(),
+		ReadHeaderTimeout:    mcp.DefaultHTTPLimits.ReadHeaderTimeout,
+		ReadTimeout:          mcp.DefaultHTTPLimits.ReadTimeout,
+		WriteTimeout:         mcp.DefaultHTTPLimits.WriteTimeout,
+		IdleTimeout:          mcp.DefaultHTTPLimits.IdleTimeout,
+		DiscoveryCacheTTL:    mcp.DefaultDiscoveryCacheTTL,
+		TraceSamplingRatio:   1,
 	}
 }
 
@@ -97,44 +205,229 @@ func NewCmdRun(streams genericiooptions.IOStreams) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&o.Port, "port", o.Port, "Start a streamable HTTP on the specified port. Default is 8080")
-	cmd.Flags().StringVar(&o.LogLevel, "log-level", "info", "Log level (debug, info, warn, error)")
-	cmd.Flags().StringVar(&o.Audience, "audience", o.Audience, "JWT token audience for validation. Default is k-mcp")
-	cmd.Flags().BoolVar(&o.TLSInsecure, "insecure", false, "Skip TLS certificate verification when connecting to Kubernetes API server")
-	cmd.Flags().StringVar(&o.TLSCertificateAuthority, "certificate-authority", "", "Path to a cert authority file for the certificate authority in TLS")
-	cmd.Flags().StringVar(&o.TLSServerName, "tls-server-name", o.TLSServerName, "The name of the server to use for TLS")
+	o.AddFlags(cmd.Flags())
 
 	return cmd
 }
 
+// AddFlags binds the options to the flags.
+func (o *RunOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.ConfigFile, "config", o.ConfigFile, "Path to a YAML configuration file setting the options by the names of their flags (e.g. port: 8080, toolsets: [core]). The flags set on the command line take precedence. The file is watched, and its changes are applied like on SIGHUP")
+	flags.StringVar(&o.Port, "port", o.Port, "Start a streamable HTTP on the specified port. Default is 8080")
+	flags.StringVar(&o.Transport, "transport", o.Transport, "Transport of the MCP server: http serves the callers authenticated with their tokens on --port at /mcp, sse serves the deprecated HTTP with SSE transport at /sse in addition, for the clients that have not migrated to streamable HTTP, stdio serves a single client over stdin and stdout (e.g. a desktop client or an editor running k-mcp) with the credentials of the current kubeconfig context, or of --kubeconfig, --context or --in-cluster. Logs are written to stderr with stdio")
+	flags.StringVar(&o.LogLevel, "log-level", "info", "Log level (debug, info, warn, error)")
+	flags.StringVar(&o.LogFormat, "log-format", o.LogFormat, "Log format: text writes key=value pairs, json writes JSON objects, one per line, for log aggregation systems")
+	flags.StringVar(&o.Audience, "audience", o.Audience, "JWT token audience for validation. Default is k-mcp")
+	flags.StringVar(&o.JWKSURL, "jwks-url", o.JWKSURL, "URL of the JSON Web Key Set verifying the signatures of the tokens (e.g. the /openid/v1/jwks endpoint of the service account issuer). Default does not verify signatures")
+	flags.StringVar(&o.ResourceURL, "resource-url", o.ResourceURL, "Public URL of the MCP endpoint (e.g. https://k-mcp.example.com/mcp), advertised in the OAuth protected resource metadata. Default is derived from the requests")
+	flags.StringSliceVar(&o.Issuers, "issuer", o.Issuers, "Trusted issuer (iss claim) of the tokens, repeatable. Without --jwks-url, the signatures are verified with the keys of the issuers, resolved with OpenID Connect discovery. Default accepts any issuer")
+	flags.StringSliceVar(&o.SigningAlgorithms, "signing-algorithm", o.SigningAlgorithms, "Accepted signing algorithm (alg header) of the tokens, repeatable, e.g. RS256 or ES256. Tokens signed with none, a symmetric algorithm or another algorithm are refused, even when their signatures are not verified. Default accepts the RSA, ECDSA and EdDSA algorithms")
+	flags.BoolVar(&o.TLSInsecure, "insecure", false, "Skip TLS certificate verification when connecting to Kubernetes API server")
+	flags.StringVar(&o.TLSCertificateAuthority, "certificate-authority", "", "Path to a cert authority file for the certificate authority in TLS")
+	flags.StringVar(&o.TLSServerName, "tls-server-name", o.TLSServerName, "The name of the server to use for TLS")
+	flags.StringVar(&o.Kubeconfig, "kubeconfig", o.Kubeconfig, "Manage the cluster of a kubeconfig context with the credentials of its user, e.g. from an exec plugin such as aws eks get-token, gke-gcloud-auth-plugin or kubelogin, instead of sending the tokens of the callers to the API servers of their audience. The namespace of the context is the default namespace. Requires --issuer, --jwks-url or --token-review with the http and sse transports")
+	flags.StringVar(&o.KubeconfigContext, "context", o.KubeconfigContext, "The kubeconfig context of --kubeconfig. Default is the current context")
+	flags.StringVar(&o.APIServerTLSFile, "api-server-tls", o.APIServerTLSFile, "Path to a YAML file listing the TLS settings (certificateAuthority, tlsServerName, insecureSkipVerify) of the API servers of some hosts, overriding --certificate-authority, --tls-server-name and --insecure for them")
+	flags.StringVar(&o.ClustersFile, "clusters", o.ClustersFile, "Path to a YAML file listing the named clusters (name, server, certificateAuthority, tlsServerName, insecureSkipVerify, auth), which the token audiences and the cluster input of the tools can refer to by name. auth is token to send the token of the caller, or exchange to send the credential of --token-exchange-url, and description tells the models what the cluster is for. Read again on reload")
+	flags.StringToStringVar(&o.ClusterRouting, "cluster-routing", o.ClusterRouting, fmt.Sprintf("Routing policies of the tools (e.g. --cluster-routing=resource_list=healthiest), selecting the cluster of their calls without cluster argument when the token grants several clusters and the session has no default cluster: %s asks the user, %s uses the cluster of the token audience, %s the reachable cluster answering the fastest, for the tools not changing the clusters, and %s every cluster concurrently with the result or the error of each, for resource_list and resource_get. The result tells which cluster answered. Default is %s. Can be repeated", mcp.ClusterRoutingAsk, mcp.ClusterRoutingPrimary, mcp.ClusterRoutingHealthiest, mcp.ClusterRoutingAll, mcp.ClusterRoutingAsk))
+	flags.BoolVar(&o.InCluster, "in-cluster", o.InCluster, "Manage the cluster k-mcp runs in with its service account token and CA, instead of sending the tokens of the callers to the API servers of their audience. The namespace of the pod is the default namespace. Requires --issuer, --jwks-url or --token-review with the http and sse transports")
+	flags.DurationVar(&o.ElicitationTimeout, "elicitation-timeout", o.ElicitationTimeout, "Maximum time to wait for the user to answer a prompt (e.g. namespace selection, apply confirmation). Zero means no timeout")
+	flags.DurationVar(&o.ToolTimeout, "tool-timeout", o.ToolTimeout, "Maximum duration of a tool call, including prompts and waits, after which its requests to the API server are cancelled. Zero means no timeout")
+	flags.StringSliceVar(&o.ProbeAPIServers, "probe-api-server", o.ProbeAPIServers, "URL of an API server probed for reachability at startup. k-mcp is not ready (/readyz) while it can not be reached. Can be repeated")
+	flags.DurationVar(&o.ProbeInterval, "probe-interval", o.ProbeInterval, "Interval of the reachability probes of the API servers after startup. Zero means they are only probed at startup and when first used")
+	flags.StringSliceVar(&o.Toolsets, "toolsets", o.Toolsets, fmt.Sprintf("Comma separated toolsets whose tools are enabled, one of: %s. Default is every toolset", strings.Join(mcp.ToolsetNames(), ", ")))
+	flags.BoolVar(&o.ReadOnly, "read-only", o.ReadOnly, "Disable the tools changing the state of the clusters (resource_apply, take_ownership, pod_evict)")
+	flags.BoolVar(&o.Headless, "headless", o.Headless, "Never prompt the user: use the default namespace when none is given and apply changes without confirmation. Clients not supporting prompts get these defaults anyway, except that changes are refused")
+	flags.IntVar(&o.ImpactThreshold, "impact-threshold", o.ImpactThreshold, "Impact score of an apply (objects, restarted workloads, PodDisruptionBudget risks, production namespaces) from which the user must confirm it by typing the cluster name. Zero disables it")
+	flags.StringSliceVar(&o.ProductionNamespaces, "production-namespaces", o.ProductionNamespaces, "Patterns of the production namespaces (e.g. prod-*), whose changes raise the impact score of applies")
+	flags.StringVar(&o.SummaryColumnsFile, "summary-columns", o.SummaryColumnsFile, "Path to a YAML file defining additional summary columns (JSONPath) per kind")
+	flags.StringVar(&o.UserAgent, "user-agent", o.UserAgent, "UserAgent template of the requests sent to the API servers. Can refer to {{.Version}}, {{.GitCommit}}, {{.OS}} and {{.Arch}}. Default is k-mcp")
+	flags.Float32Var(&o.QPS, "kube-api-qps", o.QPS, "Maximum rate of the requests sent to an API server with a token, to bound the load on busy API servers. 0 means the default of client-go (5)")
+	flags.IntVar(&o.Burst, "kube-api-burst", o.Burst, "Number of requests sent to an API server with a token above --kube-api-qps in bursts. 0 means the default of client-go (10)")
+	flags.StringVar(&o.DiscoveryCacheDir, "discovery-cache-dir", o.DiscoveryCacheDir, "Directory caching the discovery of the API servers. Default is ~/k-mcp-discovery-cache")
+	flags.DurationVar(&o.DiscoveryCacheTTL, "discovery-cache-ttl", o.DiscoveryCacheTTL, "How long the cached discovery of the API servers is used before being fetched again")
+	flags.BoolVar(&o.DisableDiscoveryCache, "disable-discovery-cache", o.DisableDiscoveryCache, "Keep the discovery of the API servers in memory only instead of caching it on disk, e.g. on read-only filesystems")
+	flags.StringToStringVar(&o.Headers, "header", o.Headers, "Extra HTTP headers added to the requests sent to the API servers (e.g. --header=X-Client-Id=k-mcp). Values can reference secrets as ${env:NAME}, ${file:path}, ${k8s:namespace/name/key} or ${vault:path#key}. Can be repeated")
+	flags.StringVar(&o.SavedQueriesFile, "saved-queries", o.SavedQueriesFile, "Path to a YAML file defining named queries available to every session through the run_query tool")
+	flags.StringVar(&o.ConformanceProfilesFile, "conformance-profiles", o.ConformanceProfilesFile, "Path to a YAML file defining additional profiles of the conformance_check tool, whose rules require fields (JSONPath) of the workloads to be set")
+	flags.StringVar(&o.CRDToolsFile, "crd-tools", o.CRDToolsFile, "Path to a YAML file listing the CustomResourceDefinitions with dedicated list and get tools, described from their schemas")
+	flags.StringVar(&o.MemoryLimit, "memory-limit", o.MemoryLimit, "Memory limit (e.g. 512Mi) from 85% of which large list and get calls are refused and caches are evicted, until the heap falls under 70%. Default is GOMEMLIMIT, else the memory limit of the container. 0 disables it")
+	flags.BoolVar(&o.TokenReview, "token-review", o.TokenReview, "Validate the tokens with the TokenReview API of the API server of their audience, in addition to the local checks. The tokens must be allowed to create TokenReviews")
+	flags.StringVar(&o.TokenExchangeURL, "token-exchange-url", o.TokenExchangeURL, "URL of a security token service exchanging the validated tokens for access tokens of the API servers (OAuth 2.0 Token Exchange, RFC 8693), for clusters not trusting the issuer of the tokens. Default sends the tokens as is")
+	flags.StringVar(&o.TokenExchangeClientID, "token-exchange-client-id", o.TokenExchangeClientID, "Client ID authenticating k-mcp to the token exchange service")
+	flags.StringVar(&o.TokenExchangeSecret, "token-exchange-client-secret", o.TokenExchangeSecret, "Client secret authenticating k-mcp to the token exchange service. Can reference a secret as ${env:NAME}, ${file:path}, ${k8s:namespace/name/key} or ${vault:path#key}")
+	flags.StringVar(&o.AuditNamespace, "audit-namespace", o.AuditNamespace, "Namespace recording the calls of the mutating tools in the clusters they change, as Events and rotated ConfigMaps, with the token of the call. Default does not record them")
+	flags.StringVar(&o.AuditLogFile, "audit-log-file", o.AuditLogFile, "Path to the file the calls of the mutating tools are appended to as JSON lines, with their subject, objects, cluster and outcome")
+	flags.StringVar(&o.AuditWebhookURL, "audit-webhook-url", o.AuditWebhookURL, "URL the calls of the mutating tools are posted to as JSON objects, with their subject, objects, cluster and outcome")
+	flags.StringVar(&o.MaxRequestBodySize, "max-request-body-size", o.MaxRequestBodySize, "Maximum size of the bodies of the MCP requests (e.g. 10Mi), larger requests are refused. 0 disables the limit")
+	flags.StringVar(&o.MaxResultSize, "max-result-size", o.MaxResultSize, "Maximum size of the resources returned by resource_list (e.g. 1Mi). Larger lists are stripped of their managed fields, last applied configurations and status details, then cut with a notice. 0 disables the limit")
+	flags.StringVar(&o.MaxHeaderSize, "max-header-size", o.MaxHeaderSize, "Maximum size of the headers of the HTTP requests (e.g. 64Ki). 0 means the default of the Go HTTP server (1Mi)")
+	flags.DurationVar(&o.ReadHeaderTimeout, "read-header-timeout", o.ReadHeaderTimeout, "Maximum duration to read the headers of an HTTP request. Zero means no timeout")
+	flags.DurationVar(&o.ReadTimeout, "read-timeout", o.ReadTimeout, "Maximum duration to read an HTTP request, including its body. Zero means no timeout")
+	flags.DurationVar(&o.WriteTimeout, "write-timeout", o.WriteTimeout, "Maximum duration to write an HTTP response. The responses of the tool calls and the event streams of the sessions are written until they end, so it should be longer than --tool-timeout. Zero means no timeout")
+	flags.DurationVar(&o.IdleTimeout, "idle-timeout", o.IdleTimeout, "Maximum duration a keep-alive connection waits for the next HTTP request. Zero means the read timeout")
+	flags.StringArrayVar(&o.RedactEnvPatterns, "redact-env-pattern", o.RedactEnvPatterns, "Regular expression matching the names or values of the environment variables redacted from the tool results and the debug logs, in addition to the data of the Secrets and the last applied configurations. Can be repeated")
+	flags.StringVar(&o.PreferencesFile, "preferences-file", o.PreferencesFile, "Path to the JSON file keeping the preferences of the users (set_preferences) across restarts. Default keeps them in memory only")
+	flags.StringVar(&o.OTLPEndpoint, "otlp-endpoint", o.OTLPEndpoint, "URL of the OpenTelemetry collector (e.g. http://localhost:4318) the spans of the tool calls and of their requests to the API servers are exported to with OTLP over HTTP. The OTEL_EXPORTER_OTLP_* environment variables configure the exporter further. Default disables tracing")
+	flags.StringToStringVar(&o.OTLPHeaders, "otlp-header", o.OTLPHeaders, "HTTP headers added to the export requests of the spans (e.g. --otlp-header=Authorization=Bearer...). Can be repeated")
+	flags.Float64Var(&o.TraceSamplingRatio, "trace-sampling-ratio", o.TraceSamplingRatio, "Fraction of the traces sampled (0-1), unless the client propagates a sampled trace in the _meta of its requests")
+	flags.StringToStringVar(&o.FeatureGates, "feature-gates", o.FeatureGates, "A set of key=value pairs enabling or disabling features. Options are: FailureInjection=true|false (ALPHA - default=false)")
+	flags.DurationVar(&o.InjectLatency, "inject-latency", o.InjectLatency, "Latency added to every request sent to the API servers. Requires the FailureInjection feature gate")
+	flags.Float64Var(&o.InjectErrorRate, "inject-error-rate", o.InjectErrorRate, "Fraction of the requests sent to the API servers failing with 503 Service Unavailable (0-1). Requires the FailureInjection feature gate")
+	//nolint:errcheck
+	flags.MarkHidden("inject-latency")
+	//nolint:errcheck
+	flags.MarkHidden("inject-error-rate")
+}
+
 // Complete sets all information required to run the MCP server
 func (o *RunOptions) Complete(cmd *cobra.Command) error {
+	if o.ConfigFile != "" {
+		o.flags = cmd.Flags()
+		o.commandLine = map[string]bool{}
+		cmd.Flags().Visit(func(flag *pflag.Flag) {
+			o.commandLine[flag.Name] = true
+		})
+		options, err := loadConfigFile(o.ConfigFile)
+		if err != nil {
+			return err
+		}
+		if err := applyConfigFile(cmd.Flags(), options, o.commandLine); err != nil {
+			return err
+		}
+	}
+
 	_, err := strconv.Atoi(o.Port)
 	if err != nil {
 		return fmt.Errorf("invalid port number %s err: %w", o.Port, err)
 	}
 
-	var level slog.Level
-	switch strings.ToLower(o.LogLevel) {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
-	}
+	o.logLevel.Set(parseLogLevel(o.LogLevel))
 
-	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	})
-	logger := slog.New(handler)
+	// With stdio, stdout carries the MCP messages.
+	logOut := o.Out
+	if o.Transport == mcp.TransportStdio {
+		logOut = o.ErrOut
+	}
+	handler, err := newLogHandler(logOut, o.LogFormat, &o.logLevel)
+	if err != nil {
+		return err
+	}
+	logger := slog.New(mcp.NewRequestIDHandler(handler))
 	slog.SetDefault(logger)
 
+	o.featureGates = make(map[string]bool, len(defaultFeatureGates))
+	for feature, enabled := range defaultFeatureGates {
+		o.featureGates[feature] = enabled
+	}
+	for feature, value := range o.FeatureGates {
+		if _, ok := defaultFeatureGates[feature]; !ok {
+			return fmt.Errorf("unknown feature gate %s", feature)
+		}
+		o.featureGates[feature], err = strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %s of feature gate %s: %w", value, feature, err)
+		}
+	}
+
 	o.Server = mcp.NewServer(o.Port, o.Audience)
+	o.Server.ElicitationTimeout = o.ElicitationTimeout
+	o.Server.ToolTimeout = o.ToolTimeout
+	o.Server.ProbeAPIServers = o.ProbeAPIServers
+	o.Server.ProbeInterval = o.ProbeInterval
+	o.Server.Headless = o.Headless
+	o.Server.ClusterRouting = o.ClusterRouting
+	o.Server.PreferencesFile = o.PreferencesFile
+	o.Server.AuditNamespace = o.AuditNamespace
+	o.Server.AuditLogFile = o.AuditLogFile
+	o.Server.AuditWebhookURL = o.AuditWebhookURL
+	o.Server.RedactEnvPatterns = o.RedactEnvPatterns
+	o.Server.ResourceURL = o.ResourceURL
+	o.Server.Tracing = mcp.Tracing{
+		Endpoint:      o.OTLPEndpoint,
+		Headers:       o.OTLPHeaders,
+		SamplingRatio: o.TraceSamplingRatio,
+	}
+	if o.TokenExchangeURL != "" {
+		secret, err := mcp.NewSecretResolvers().Resolve(context.Background(), o.TokenExchangeSecret)
+		if err != nil {
+			return fmt.Errorf("invalid token exchange client secret: %w", err)
+		}
+		o.Server.CredentialExchanger = mcp.NewTokenExchanger(o.TokenExchangeURL, o.TokenExchangeClientID, secret)
+	}
+	o.Server.TokenReview = o.TokenReview
+	o.Server.MemoryLimit = mcp.DefaultMemoryLimit()
+	if o.MemoryLimit != "" {
+		limit, err := resource.ParseQuantity(o.MemoryLimit)
+		if err != nil || limit.Sign() < 0 {
+			return fmt.Errorf("invalid memory limit %q", o.MemoryLimit)
+		}
+		o.Server.MemoryLimit = uint64(limit.Value())
+	}
+	if o.Server.MemoryLimit > 0 {
+		slog.Info("Refusing large list and get calls under memory pressure", "limit_bytes", o.Server.MemoryLimit)
+	}
+	maxRequestBodySize, err := resource.ParseQuantity(o.MaxRequestBodySize)
+	if err != nil || maxRequestBodySize.Sign() < 0 {
+		return fmt.Errorf("invalid maximum request body size %q", o.MaxRequestBodySize)
+	}
+	maxHeaderSize, err := resource.ParseQuantity(o.MaxHeaderSize)
+	if err != nil || maxHeaderSize.Sign() < 0 || maxHeaderSize.Value() > math.MaxInt32 {
+		return fmt.Errorf("invalid maximum header size %q", o.MaxHeaderSize)
+	}
+	maxResultSize, err := resource.ParseQuantity(o.MaxResultSize)
+	if err != nil || maxResultSize.Sign() < 0 || maxResultSize.Value() > math.MaxInt32 {
+		return fmt.Errorf("invalid maximum result size %q", o.MaxResultSize)
+	}
+	o.Server.MaxResultSize = int(maxResultSize.Value())
+	o.Server.HTTPLimits = mcp.HTTPLimits{
+		MaxRequestBodySize: maxRequestBodySize.Value(),
+		MaxHeaderBytes:     int(maxHeaderSize.Value()),
+		ReadHeaderTimeout:  o.ReadHeaderTimeout,
+		ReadTimeout:        o.ReadTimeout,
+		WriteTimeout:       o.WriteTimeout,
+		IdleTimeout:        o.IdleTimeout,
+	}
+	if o.WriteTimeout > 0 && (o.ToolTimeout == 0 || o.WriteTimeout < o.ToolTimeout) {
+		slog.Warn("The write timeout is shorter than the tool timeout, long tool calls and event streams are cut", "write_timeout", o.WriteTimeout, "tool_timeout", o.ToolTimeout)
+	}
+	o.Server.Transport = o.Transport
+	o.Server.JWKSURL = o.JWKSURL
+	o.Server.Issuers = o.Issuers
+	o.Server.SigningAlgorithms = o.SigningAlgorithms
+	if o.Headless && !o.ReadOnly {
+		slog.Warn("Running in headless mode, changes are applied without confirmation")
+	}
+
+	reloadable, err := o.reloadableConfig()
+	if err != nil {
+		return err
+	}
+	o.Server.Clusters = reloadable.Clusters
+	o.Server.Toolsets = reloadable.Toolsets
+	o.Server.ReadOnly = reloadable.ReadOnly
+	o.Server.SummaryColumns = reloadable.SummaryColumns
+	o.Server.ConformanceProfiles = reloadable.ConformanceProfiles
+	o.Server.ImpactThreshold = reloadable.ImpactThreshold
+	o.Server.ProductionNamespaces = reloadable.ProductionNamespaces
+	o.Server.Reload = o.reload
+	if o.ConfigFile != "" {
+		o.Server.WatchedFiles = []string{o.ConfigFile}
+	}
+
+	if o.SavedQueriesFile != "" {
+		o.Server.SavedQueries, err = mcp.LoadSavedQueries(o.SavedQueriesFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.CRDToolsFile != "" {
+		o.Server.CRDTools, err = mcp.LoadCRDTools(o.CRDToolsFile)
+		if err != nil {
+			return err
+		}
+	}
 
 	if o.TLSCertificateAuthority != "" {
 		_, err = os.ReadFile(o.TLSCertificateAuthority)
@@ -148,19 +441,468 @@ func (o *RunOptions) Complete(cmd *cobra.Command) error {
 	}
 
 	o.DynamicConfig = mcp.NewDynamicConfig(o.TLSCertificateAuthority, o.TLSInsecure, o.TLSServerName)
+	o.DynamicConfig.APIServerTLS = reloadable.APIServerTLS
+	o.DynamicConfig.QPS = o.QPS
+	o.DynamicConfig.Burst = o.Burst
+	o.DynamicConfig.DiscoveryCacheDir = o.DiscoveryCacheDir
+	o.DynamicConfig.DiscoveryCacheTTL = o.DiscoveryCacheTTL
+	o.DynamicConfig.DisableDiscoveryCache = o.DisableDiscoveryCache
+	if o.InCluster {
+		o.DynamicConfig.Cluster, err = rest.InClusterConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load the in-cluster configuration: %w", err)
+		}
+		o.Server.DefaultNamespace, err = mcp.InClusterNamespace()
+		if err != nil {
+			return err
+		}
+		o.Server.ProbeAPIServers = append(o.Server.ProbeAPIServers, o.DynamicConfig.Cluster.Host)
+	}
+	if o.kubeconfigMode() {
+		o.DynamicConfig.Cluster, o.Server.DefaultNamespace, err = mcp.LoadKubeconfig(o.Kubeconfig, o.KubeconfigContext)
+		if err != nil {
+			return err
+		}
+		if o.DynamicConfig.Cluster.ExecProvider != nil {
+			slog.Info("Using an exec credential plugin", "command", o.DynamicConfig.Cluster.ExecProvider.Command)
+		}
+		o.Server.ProbeAPIServers = append(o.Server.ProbeAPIServers, o.DynamicConfig.Cluster.Host)
+	}
+	// Header values may hold credentials of the gateways in front of the API servers,
+	// which can be referenced from secret sources instead of being passed in plain text.
+	o.DynamicConfig.Headers, err = mcp.NewSecretResolvers().ResolveMap(context.Background(), o.Headers)
+	if err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+	if o.UserAgent != "" {
+		o.DynamicConfig.UserAgent, err = mcp.RenderUserAgent(o.UserAgent)
+		if err != nil {
+			return err
+		}
+	}
+	if o.featureGates[FeatureFailureInjection] {
+		o.DynamicConfig.FailureInjection = &mcp.FailureInjection{
+			Latency:   o.InjectLatency,
+			ErrorRate: o.InjectErrorRate,
+		}
+		if o.DynamicConfig.FailureInjection.Enabled() {
+			slog.Warn("Injecting failures into the requests sent to the API servers. This must not be used in production.",
+				"latency", o.InjectLatency, "errorRate", o.InjectErrorRate)
+		}
+	}
+
+	return nil
+}
+
+// newLogHandler returns the handler writing the logs of the level in the log format.
+func newLogHandler(w io.Writer, logFormat string, level slog.Leveler) (slog.Handler, error) {
+	options := &slog.HandlerOptions{Level: level}
+	switch logFormat {
+	case LogFormatText:
+		return slog.NewTextHandler(w, options), nil
+	case LogFormatJSON:
+		return slog.NewJSONHandler(w, options), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %s, must be one of: %s, %s", logFormat, LogFormatText, LogFormatJSON)
+	}
+}
+
+// logLevels are the valid log levels.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// parseLogLevel returns the slog level of a log level flag, info when unknown.
+func parseLogLevel(logLevel string) slog.Level {
+	switch strings.ToLower(logLevel) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// reloadableConfig loads the configuration applied again on reload, reading its files again.
+func (o *RunOptions) reloadableConfig() (*mcp.ReloadableConfig, error) {
+	config := &mcp.ReloadableConfig{
+		Toolsets:             o.Toolsets,
+		ReadOnly:             o.ReadOnly,
+		ImpactThreshold:      o.ImpactThreshold,
+		ProductionNamespaces: o.ProductionNamespaces,
+	}
+	var err error
+	if o.SummaryColumnsFile != "" {
+		config.SummaryColumns, err = mcp.LoadSummaryColumns(o.SummaryColumnsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if o.ConformanceProfilesFile != "" {
+		config.ConformanceProfiles, err = mcp.LoadConformanceProfiles(o.ConformanceProfilesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if o.APIServerTLSFile != "" {
+		config.APIServerTLS, err = mcp.LoadAPIServerTLS(o.APIServerTLSFile)
+		if err != nil {
+			return nil, err
+		}
+		for host, tls := range config.APIServerTLS {
+			if tls.InsecureSkipVerify {
+				slog.Warn("Using insecure TLS client config for an API server. This is not recommended for production.", "host", host)
+			}
+		}
+	}
+	if o.ClustersFile != "" {
+		config.Clusters, err = mcp.LoadClusters(o.ClustersFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range config.Clusters {
+			if cluster.InsecureSkipVerify {
+				slog.Warn("Using insecure TLS client config for a cluster. This is not recommended for production.", "cluster", cluster.Name)
+			}
+		}
+		config.APIServerTLS, err = mcp.MergeClusterTLS(config.APIServerTLS, config.Clusters)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// reload is called on SIGHUP to apply the log level and the reloadable configuration again,
+// with the summary columns, the conformance profiles, the TLS settings of the API servers and the
+// cluster registry read again from their files.
+func (o *RunOptions) reload() (*mcp.ReloadableConfig, error) {
+	options := o
+	if o.ConfigFile != "" {
+		var err error
+		options, err = o.reloadConfigFile()
+		if err != nil {
+			return nil, err
+		}
+	}
+	config, err := options.reloadableConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := mcp.ValidateToolsets(config.Toolsets); err != nil {
+		return nil, err
+	}
+	if err := validateClusterAuth(config.Clusters, o.TokenExchangeURL); err != nil {
+		return nil, err
+	}
+	if o.TokenReview {
+		for host, tls := range config.APIServerTLS {
+			if tls.InsecureSkipVerify {
+				return nil, fmt.Errorf("token review can not be used with insecure TLS connections, set for API server %s", host)
+			}
+		}
+	}
+	if options != o {
+		o.setReloadable(options, func(string) bool {
+			return true
+		})
+	}
+	o.logLevel.Set(parseLogLevel(o.LogLevel))
+	return config, nil
+}
+
+// validateClusterAuth refuses the clusters exchanging the tokens without token exchange.
+func validateClusterAuth(clusters []mcp.Cluster, tokenExchangeURL string) error {
+	if tokenExchangeURL != "" {
+		return nil
+	}
+	for _, cluster := range clusters {
+		if cluster.Auth == mcp.ClusterAuthExchange {
+			return fmt.Errorf("cluster %s exchanges the tokens, it requires --token-exchange-url", cluster.Name)
+		}
+	}
+	return nil
+}
+
+// reloadConfigFile returns the options of the configuration file read again, the flags set on the
+// command line keeping their values. Only the reloadable options are applied, the others require a
+// restart.
+func (o *RunOptions) reloadConfigFile() (*RunOptions, error) {
+	fresh := NewRunOptions(o.IOStreams)
+	flags := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	fresh.AddFlags(flags)
+	options, err := loadConfigFile(o.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyConfigFile(flags, options, o.commandLine); err != nil {
+		return nil, err
+	}
+	fresh.setReloadable(o, func(flag string) bool {
+		return o.commandLine[flag]
+	})
+	if err := fresh.validateReloadable(); err != nil {
+		return nil, err
+	}
+	if changed := o.restartOptions(flags); len(changed) > 0 {
+		slog.Warn("Options of the configuration file changed without being applied, they require a restart", "options", changed)
+	}
+	return fresh, nil
+}
+
+// reloadableFlags are the flags of the options applied again on reload, by setReloadable.
+var reloadableFlags = []string{"log-level", "toolsets", "read-only", "impact-threshold", "production-namespaces", "summary-columns", "conformance-profiles", "api-server-tls", "clusters"}
+
+// restartOptions returns the options of the reloaded configuration file whose values differ from the
+// running ones but are not reloadable.
+func (o *RunOptions) restartOptions(flags *pflag.FlagSet) []string {
+	if o.flags == nil {
+		return nil
+	}
+	var changed []string
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "config" || o.commandLine[flag.Name] || slices.Contains(reloadableFlags, flag.Name) {
+			return
+		}
+		if running := o.flags.Lookup(flag.Name); running != nil && running.Value.String() != flag.Value.String() {
+			changed = append(changed, flag.Name)
+		}
+	})
+	return changed
+}
+
+// setReloadable sets the reloadable options named by the flags selected by set to the ones of from.
+func (o *RunOptions) setReloadable(from *RunOptions, set func(flag string) bool) {
+	if set("log-level") {
+		o.LogLevel = from.LogLevel
+	}
+	if set("toolsets") {
+		o.Toolsets = from.Toolsets
+	}
+	if set("read-only") {
+		o.ReadOnly = from.ReadOnly
+	}
+	if set("impact-threshold") {
+		o.ImpactThreshold = from.ImpactThreshold
+	}
+	if set("production-namespaces") {
+		o.ProductionNamespaces = from.ProductionNamespaces
+	}
+	if set("summary-columns") {
+		o.SummaryColumnsFile = from.SummaryColumnsFile
+	}
+	if set("conformance-profiles") {
+		o.ConformanceProfilesFile = from.ConformanceProfilesFile
+	}
+	if set("api-server-tls") {
+		o.APIServerTLSFile = from.APIServerTLSFile
+	}
+	if set("clusters") {
+		o.ClustersFile = from.ClustersFile
+	}
+}
 
+// validateReloadable validates the reloadable options not validated by the reloaded configuration.
+func (o *RunOptions) validateReloadable() error {
+	if !slices.Contains(logLevels, strings.ToLower(o.LogLevel)) {
+		return fmt.Errorf("invalid log level %s, must be one of: %s", o.LogLevel, strings.Join(logLevels, ", "))
+	}
+	if o.ImpactThreshold < 0 {
+		return fmt.Errorf("impact threshold must not be negative")
+	}
+	for _, pattern := range o.ProductionNamespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid production namespace pattern %q: %w", pattern, err)
+		}
+	}
 	return nil
 }
 
+// kubeconfigMode tells whether the cluster is the one of a kubeconfig context: the context of
+// --kubeconfig, or the one of the default kubeconfig with stdio unless running in a cluster.
+func (o *RunOptions) kubeconfigMode() bool {
+	return o.Kubeconfig != "" || (o.Transport == mcp.TransportStdio && !o.InCluster)
+}
+
+// verifiesTokens tells whether the tokens of the callers are verified before being trusted.
+func (o *RunOptions) verifiesTokens() bool {
+	return len(o.Issuers) > 0 || o.JWKSURL != "" || o.TokenReview
+}
+
 // Validate ensures that all required arguments and flag values are provided
 func (o *RunOptions) Validate() error {
-	validLevels := []string{"debug", "info", "warn", "error"}
-	for _, valid := range validLevels {
-		if strings.ToLower(o.LogLevel) == valid {
-			return nil
+	if o.ElicitationTimeout < 0 {
+		return fmt.Errorf("elicitation timeout must not be negative")
+	}
+
+	if o.ToolTimeout < 0 {
+		return fmt.Errorf("tool timeout must not be negative")
+	}
+
+	if o.QPS < 0 || o.Burst < 0 {
+		return fmt.Errorf("kube API QPS and burst must not be negative")
+	}
+
+	if o.DiscoveryCacheTTL <= 0 {
+		return fmt.Errorf("discovery cache TTL must be positive")
+	}
+	if o.DisableDiscoveryCache && o.DiscoveryCacheDir != "" {
+		return fmt.Errorf("--discovery-cache-dir can not be used with --disable-discovery-cache")
+	}
+
+	if err := o.validateReloadable(); err != nil {
+		return err
+	}
+
+	if o.ReadHeaderTimeout < 0 || o.ReadTimeout < 0 || o.WriteTimeout < 0 || o.IdleTimeout < 0 {
+		return fmt.Errorf("HTTP server timeouts must not be negative")
+	}
+
+	// The API server reviewing a token is named by the token, only the TLS settings tell whether it can be trusted.
+	if o.TokenReview && o.TLSInsecure {
+		return fmt.Errorf("token review can not be used with insecure TLS connections")
+	}
+	if o.TokenReview && o.DynamicConfig != nil {
+		for host, tls := range o.DynamicConfig.APIServerTLS {
+			if tls.InsecureSkipVerify {
+				return fmt.Errorf("token review can not be used with insecure TLS connections, set for API server %s", host)
+			}
 		}
 	}
-	return fmt.Errorf("invalid log level %s, must be one of: %s", o.LogLevel, strings.Join(validLevels, ", "))
+
+	// The CA and the credentials of the in-cluster mode are the ones mounted in the pod.
+	if o.InCluster && (o.TLSCertificateAuthority != "" || o.TLSInsecure || o.TLSServerName != "" || o.APIServerTLSFile != "") {
+		return fmt.Errorf("in-cluster mode can not be used with --certificate-authority, --insecure, --tls-server-name or --api-server-tls")
+	}
+	// The cluster of the in-cluster and kubeconfig modes is the only one.
+	if o.ClustersFile != "" && (o.InCluster || o.kubeconfigMode()) {
+		return fmt.Errorf("--clusters can not be used with --in-cluster, --kubeconfig or --transport=stdio")
+	}
+	if o.Server != nil {
+		if err := validateClusterAuth(o.Server.Clusters, o.TokenExchangeURL); err != nil {
+			return err
+		}
+	}
+	if o.InCluster && o.TokenExchangeURL != "" {
+		return fmt.Errorf("in-cluster mode can not be used with token exchange")
+	}
+	if o.Transport != mcp.TransportHTTP && o.Transport != mcp.TransportSSE && o.Transport != mcp.TransportStdio {
+		return fmt.Errorf("invalid transport %q, must be %s, %s or %s", o.Transport, mcp.TransportHTTP, mcp.TransportSSE, mcp.TransportStdio)
+	}
+	// The stdio client is the local user, calling the cluster with the credentials of k-mcp.
+	if o.Transport == mcp.TransportStdio && (len(o.Issuers) > 0 || o.JWKSURL != "" || o.TokenReview || o.TokenExchangeURL != "") {
+		return fmt.Errorf("the stdio transport does not authenticate its client, it can not be used with --issuer, --jwks-url, --token-review or --token-exchange-url")
+	}
+	// The in-cluster mode calls the cluster with the service account of the pod, not with the token of the caller,
+	// so an unverified token forged with the MCP server audience would act with its permissions.
+	if o.InCluster && o.Transport != mcp.TransportStdio && !o.verifiesTokens() {
+		return fmt.Errorf("in-cluster mode with the %s transport requires --issuer, --jwks-url or --token-review to verify the tokens of the callers", o.Transport)
+	}
+	// The TLS settings and the credentials of the kubeconfig mode are the ones of the context.
+	if o.kubeconfigMode() && (o.InCluster || o.TLSCertificateAuthority != "" || o.TLSInsecure || o.TLSServerName != "" || o.APIServerTLSFile != "" || o.TokenExchangeURL != "") {
+		return fmt.Errorf("kubeconfig mode can not be used with --in-cluster, --certificate-authority, --insecure, --tls-server-name, --api-server-tls or --token-exchange-url")
+	}
+	// Like the in-cluster mode, the kubeconfig mode calls the cluster with its own credentials whatever the token.
+	if o.kubeconfigMode() && o.Transport != mcp.TransportStdio && !o.verifiesTokens() {
+		return fmt.Errorf("kubeconfig mode with the %s transport requires --issuer, --jwks-url or --token-review to verify the tokens of the callers", o.Transport)
+	}
+	if o.KubeconfigContext != "" && !o.kubeconfigMode() {
+		return fmt.Errorf("--context requires --kubeconfig or --transport=stdio")
+	}
+
+	if o.TokenExchangeURL != "" {
+		if u, err := url.Parse(o.TokenExchangeURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid token exchange URL %q, it must be an https URL", o.TokenExchangeURL)
+		}
+		// The API servers of the exchanged credentials may not trust the issuer of the tokens to review.
+		if o.TokenReview {
+			return fmt.Errorf("token review can not be used with token exchange")
+		}
+	}
+	if o.TokenExchangeSecret != "" && o.TokenExchangeClientID == "" {
+		return fmt.Errorf("token exchange client secret requires a client ID")
+	}
+
+	if o.AuditNamespace != "" {
+		if errs := validation.IsDNS1123Label(o.AuditNamespace); len(errs) > 0 {
+			return fmt.Errorf("invalid audit namespace %q: %v", o.AuditNamespace, errs)
+		}
+	}
+	if o.ResourceURL != "" {
+		if u, err := url.Parse(o.ResourceURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("invalid resource URL %q", o.ResourceURL)
+		}
+	}
+	if o.OTLPEndpoint != "" {
+		if u, err := url.Parse(o.OTLPEndpoint); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid OTLP endpoint %q", o.OTLPEndpoint)
+		}
+	}
+	if o.TraceSamplingRatio < 0 || o.TraceSamplingRatio > 1 {
+		return fmt.Errorf("trace sampling ratio must be between 0 and 1")
+	}
+	if o.AuditWebhookURL != "" {
+		if u, err := url.Parse(o.AuditWebhookURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid audit webhook URL %q", o.AuditWebhookURL)
+		}
+	}
+
+	for _, pattern := range o.RedactEnvPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+	}
+
+	if err := mcp.ValidateToolsets(o.Toolsets); err != nil {
+		return err
+	}
+
+	if err := mcp.ValidateClusterRouting(o.ClusterRouting); err != nil {
+		return err
+	}
+
+	if o.ProbeInterval < 0 {
+		return fmt.Errorf("probe interval must not be negative")
+	}
+	for _, apiServer := range o.ProbeAPIServers {
+		if u, err := url.Parse(apiServer); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid API server URL %q to probe", apiServer)
+		}
+	}
+
+	if o.JWKSURL != "" {
+		if u, err := url.Parse(o.JWKSURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid JWKS URL %q", o.JWKSURL)
+		}
+	}
+	if o.JWKSURL == "" {
+		// Without JWKS URL, the keys of the issuers are discovered from their URL.
+		for _, issuer := range o.Issuers {
+			if u, err := url.Parse(issuer); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return fmt.Errorf("invalid issuer URL %q", issuer)
+			}
+		}
+	}
+
+	if err := mcp.ValidateSigningAlgorithms(o.SigningAlgorithms); err != nil {
+		return err
+	}
+
+	if err := mcp.ValidateHeaders(o.Headers); err != nil {
+		return err
+	}
+
+	if (o.InjectLatency != 0 || o.InjectErrorRate != 0) && !o.featureGates[FeatureFailureInjection] {
+		return fmt.Errorf("injecting failures requires the %s feature gate", FeatureFailureInjection)
+	}
+	if o.DynamicConfig.FailureInjection != nil {
+		if err := o.DynamicConfig.FailureInjection.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Run runs the MCP Server
@@ -171,4 +913,4 @@ func (o *RunOptions) Run() error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ardaguclu/k-mcp/pkg/mcp"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestNewLogHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		logFormat string
+		check     func(t *testing.T, out string)
+		wantErr   bool
+	}{
+		{
+			name:      "text",
+			logFormat: LogFormatText,
+			check: func(t *testing.T, out string) {
+				if !strings.Contains(out, `msg="tool called" tool=resource_list`) {
+					t.Errorf("expected a key=value line, got %q", out)
+				}
+			},
+		},
+		{
+			name:      "json",
+			logFormat: LogFormatJSON,
+			check: func(t *testing.T, out string) {
+				var record map[string]any
+				if err := json.Unmarshal([]byte(out), &record); err != nil {
+					t.Fatalf("expected a JSON line, got %q: %v", out, err)
+				}
+				if record["msg"] != "tool called" || record["tool"] != "resource_list" || record["level"] != "INFO" {
+					t.Errorf("unexpected record %v", record)
+				}
+			},
+		},
+		{
+			name:      "unknown",
+			logFormat: "logfmt",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			handler, err := newLogHandler(&out, tt.logFormat, slog.LevelInfo)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			logger := slog.New(handler)
+			logger.Debug("hidden")
+			logger.Info("tool called", "tool", "resource_list")
+			tt.check(t, strings.TrimSpace(out.String()))
+		})
+	}
+}
+
+func TestValidateTokenVerification(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(o *RunOptions)
+		wantErr string
+	}{
+		{
+			name: "in-cluster over http without verification",
+			modify: func(o *RunOptions) {
+				o.InCluster = true
+			},
+			wantErr: "in-cluster mode with the http transport requires --issuer, --jwks-url or --token-review",
+		},
+		{
+			name: "in-cluster over sse without verification",
+			modify: func(o *RunOptions) {
+				o.InCluster = true
+				o.Transport = mcp.TransportSSE
+			},
+			wantErr: "in-cluster mode with the sse transport requires --issuer, --jwks-url or --token-review",
+		},
+		{
+			name: "in-cluster with an issuer",
+			modify: func(o *RunOptions) {
+				o.InCluster = true
+				o.Issuers = []string{"https://kubernetes.default.svc.cluster.local"}
+			},
+		},
+		{
+			name: "in-cluster with a JWKS URL",
+			modify: func(o *RunOptions) {
+				o.InCluster = true
+				o.JWKSURL = "https://kubernetes.default.svc.cluster.local/openid/v1/jwks"
+			},
+		},
+		{
+			name: "in-cluster with token review",
+			modify: func(o *RunOptions) {
+				o.InCluster = true
+				o.TokenReview = true
+			},
+		},
+		{
+			name: "in-cluster over stdio",
+			modify: func(o *RunOptions) {
+				o.InCluster = true
+				o.Transport = mcp.TransportStdio
+			},
+		},
+		{
+			name: "kubeconfig over http without verification",
+			modify: func(o *RunOptions) {
+				o.Kubeconfig = "/home/user/.kube/config"
+			},
+			wantErr: "kubeconfig mode with the http transport requires --issuer, --jwks-url or --token-review",
+		},
+		{
+			name: "kubeconfig over sse with token review",
+			modify: func(o *RunOptions) {
+				o.Kubeconfig = "/home/user/.kube/config"
+				o.Transport = mcp.TransportSSE
+				o.TokenReview = true
+			},
+		},
+		{
+			name: "kubeconfig over stdio",
+			modify: func(o *RunOptions) {
+				o.Kubeconfig = "/home/user/.kube/config"
+				o.Transport = mcp.TransportStdio
+			},
+		},
+		{
+			name:   "audience of the tokens over http",
+			modify: func(o *RunOptions) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewRunOptions(genericiooptions.NewTestIOStreamsDiscard())
+			o.DynamicConfig = &mcp.DynamicConfig{}
+			o.LogLevel = "info"
+			tt.modify(o)
+			err := o.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
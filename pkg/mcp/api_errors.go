@@ -0,0 +1,191 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// errorMetaKey is the key of the structured error in the metadata of the failed tool calls.
+const errorMetaKey = "k-mcp/error"
+
+// apiErrorHints are the Kubernetes API error reasons returned as structured tool errors, with how
+// the caller can recover from them.
+var apiErrorHints = map[metav1.StatusReason]string{
+	metav1.StatusReasonNotFound:        "The object or resource does not exist: check its name and namespace, or list the resources to find it.",
+	metav1.StatusReasonForbidden:       "The token is not allowed to do this: do not retry, ask the user for the missing permissions.",
+	metav1.StatusReasonUnauthorized:    "The token is not accepted by the cluster: ask the user for a valid token.",
+	metav1.StatusReasonConflict:        "The object was modified in the meantime: get it again and retry with its latest version.",
+	metav1.StatusReasonAlreadyExists:   "The object already exists: get it, or apply it to update it.",
+	metav1.StatusReasonTimeout:         "The call timed out: retry later or narrow it with a namespace, label selector or limit.",
+	metav1.StatusReasonServerTimeout:   "The API server timed out: retry later or narrow the call with a namespace, label selector or limit.",
+	metav1.StatusReasonTooManyRequests: "The API server is overloaded: retry later.",
+}
+
+// fieldManagerConflictHint is the hint of the conflicts of server-side apply with other field managers.
+const fieldManagerConflictHint = "Fields of the object are managed by other field managers, e.g. controllers: apply again with force to take them over, or leave them out of the manifest."
+
+// APIError is the structured error of the tool calls failed by the Kubernetes API, in the
+// k-mcp/error metadata and under the error field of the structured content of their result.
+type APIError struct {
+	// Code is the reason of the error, e.g. NotFound or Forbidden.
+	Code              string `json:"code"`
+	Message           string `json:"message"`
+	Hint              string `json:"hint,omitempty"`
+	Group             string `json:"group,omitempty"`
+	Version           string `json:"version,omitempty"`
+	Resource          string `json:"resource,omitempty"`
+	Name              string `json:"name,omitempty"`
+	Cluster           string `json:"cluster,omitempty"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds,omitempty"`
+}
+
+// toolCall collects what the tool handler ran into, for the error of its result.
+type toolCall struct {
+	resource *schema.GroupVersionResource
+	err      error
+}
+
+type toolCallKey struct{}
+
+func toolCallFrom(ctx context.Context) *toolCall {
+	call, _ := ctx.Value(toolCallKey{}).(*toolCall)
+	return call
+}
+
+// recordResource records the resource the tool call is about.
+func recordResource(ctx context.Context, gvr schema.GroupVersionResource) {
+	if call := toolCallFrom(ctx); call != nil {
+		call.resource = &gvr
+	}
+}
+
+// addTool adds the tool to the server, recording the error returned by its handler so that
+// apiErrorMiddleware can return the Kubernetes API errors as structured errors.
+func addTool[In, Out any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	mcp.AddTool(server, tool, func(ctx context.Context, request *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		result, output, err := handler(ctx, request, input)
+		if call := toolCallFrom(ctx); call != nil && err != nil {
+			call.err = err
+		}
+		return result, output, err
+	})
+}
+
+// newAPIError returns the structured error of a Kubernetes API error, false when the error is not
+// one the caller can recover from.
+func newAPIError(err error, resource *schema.GroupVersionResource) (APIError, bool) {
+	var apiErr APIError
+	switch reason := apierrors.ReasonForError(err); {
+	case apiErrorHints[reason] != "":
+		apiErr.Code, apiErr.Hint = string(reason), apiErrorHints[reason]
+	case errors.Is(err, context.DeadlineExceeded):
+		apiErr.Code, apiErr.Hint = string(metav1.StatusReasonTimeout), apiErrorHints[metav1.StatusReasonTimeout]
+	default:
+		return APIError{}, false
+	}
+	apiErr.Message = err.Error()
+
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		if details := status.Status().Details; details != nil {
+			// The kind of the details of the errors is the resource they are about.
+			apiErr.Group, apiErr.Resource, apiErr.Name = details.Group, details.Kind, details.Name
+			for _, cause := range details.Causes {
+				if cause.Type == metav1.CauseTypeFieldManagerConflict {
+					apiErr.Hint = fieldManagerConflictHint
+					break
+				}
+			}
+		}
+		if status.Status().Reason == metav1.StatusReasonServerTimeout {
+			// The name of the details of the server timeouts is the operation that timed out.
+			apiErr.Name = ""
+		}
+	}
+	if resource != nil && (apiErr.Resource == "" || (apiErr.Resource == resource.Resource && apiErr.Group == resource.Group)) {
+		apiErr.Group, apiErr.Version, apiErr.Resource = resource.Group, resource.Version, resource.Resource
+	}
+	if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+		apiErr.RetryAfterSeconds = seconds
+	}
+	return apiErr, true
+}
+
+// apiErrorMiddleware returns the tool calls failed by a Kubernetes API error with the structured
+// error in the k-mcp/error metadata of their result, with the resource and the cluster it was
+// returned for, so that the LLM can tell how to recover from it.
+func apiErrorMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != methodCallTool {
+			return next(ctx, method, req)
+		}
+
+		call := &toolCall{}
+		result, err := next(context.WithValue(ctx, toolCallKey{}, call), method, req)
+		toolResult, ok := result.(*mcp.CallToolResult)
+		if err != nil || !ok || !toolResult.IsError || call.err == nil {
+			return result, err
+		}
+		apiErr, ok := newAPIError(call.err, call.resource)
+		if !ok {
+			return result, err
+		}
+		if extra := req.GetExtra(); extra != nil && extra.TokenInfo != nil {
+			apiErr.Cluster = clusterName(extra.TokenInfo)
+		}
+
+		if toolResult.Meta == nil {
+			toolResult.Meta = mcp.Meta{}
+		}
+		toolResult.Meta[errorMetaKey] = apiErr
+		// The clients which drop the metadata of the results still get the structured error.
+		toolResult.StructuredContent = map[string]any{"error": apiErr}
+		toolResult.Content = []mcp.Content{&mcp.TextContent{Text: apiErr.text()}}
+		return toolResult, nil
+	}
+}
+
+// text returns the error as told to the LLM: its reason, the resource and the cluster it was
+// returned for, its message and its hint.
+func (e APIError) text() string {
+	var about []string
+	if e.Resource != "" {
+		resource := strings.Join(slices.DeleteFunc([]string{e.Resource, e.Version, e.Group}, func(part string) bool { return part == "" }), ".")
+		if e.Name != "" {
+			resource += fmt.Sprintf(" %q", e.Name)
+		}
+		about = append(about, "resource "+resource)
+	}
+	if e.Cluster != "" {
+		about = append(about, "cluster "+e.Cluster)
+	}
+	text := e.Code
+	if len(about) > 0 {
+		text += " (" + strings.Join(about, ", ") + ")"
+	}
+	return text + ": " + e.Message + ". " + e.Hint
+}
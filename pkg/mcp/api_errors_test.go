@@ -0,0 +1,197 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestAPIErrorMiddleware(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	tests := []struct {
+		name     string
+		err      error
+		resource *schema.GroupVersionResource
+		expected *APIError
+		hint     string
+		// text is the start of the text of the result.
+		text string
+	}{
+		{
+			name:     "not found",
+			err:      fmt.Errorf("failed to get resource: %w", apierrors.NewNotFound(deployments.GroupResource(), "web")),
+			resource: &deployments,
+			expected: &APIError{Code: "NotFound", Group: "apps", Version: "v1", Resource: "deployments", Name: "web"},
+			text:     `NotFound (resource deployments.v1.apps "web"): failed to get resource: deployments.apps "web" not found. `,
+		},
+		{
+			name:     "forbidden without found resource",
+			err:      apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", errors.New("no RBAC policy matched")),
+			expected: &APIError{Code: "Forbidden", Resource: "nodes"},
+			text:     "Forbidden (resource nodes): ",
+		},
+		{
+			name:     "conflict",
+			err:      fmt.Errorf("failed to apply resource: %w", apierrors.NewConflict(deployments.GroupResource(), "web", errors.New("the object has been modified"))),
+			resource: &deployments,
+			expected: &APIError{Code: "Conflict", Group: "apps", Version: "v1", Resource: "deployments", Name: "web"},
+		},
+		{
+			name: "field manager conflict",
+			err: fmt.Errorf("failed to apply Deployment/web: %w", apierrors.NewApplyConflict([]metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "kube-controller-manager"`, Field: ".spec.replicas"},
+			}, "Apply failed with 1 conflict")),
+			resource: &deployments,
+			expected: &APIError{Code: "Conflict", Group: "apps", Version: "v1", Resource: "deployments"},
+			hint:     fieldManagerConflictHint,
+		},
+		{
+			name:     "server timeout",
+			err:      apierrors.NewServerTimeout(deployments.GroupResource(), "list", 5),
+			resource: &deployments,
+			expected: &APIError{Code: "ServerTimeout", Group: "apps", Version: "v1", Resource: "deployments", RetryAfterSeconds: 5},
+		},
+		{
+			name:     "deadline exceeded",
+			err:      fmt.Errorf("failed to list resources: %w", context.DeadlineExceeded),
+			resource: &deployments,
+			expected: &APIError{Code: "Timeout", Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name: "other error",
+			err:  errors.New("failed to load dynamic client"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+			server.AddReceivingMiddleware(apiErrorMiddleware)
+			addTool(server, &mcp.Tool{Name: "fail"}, func(ctx context.Context, request *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+				if tt.resource != nil {
+					recordResource(ctx, *tt.resource)
+				}
+				return nil, nil, tt.err
+			})
+
+			client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			serverSession, err := server.Connect(ctx, serverTransport, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer serverSession.Close()
+			clientSession, err := client.Connect(ctx, clientTransport, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer clientSession.Close()
+
+			result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "fail"})
+			if err != nil {
+				t.Fatalf("expected a tool error result, got %v", err)
+			}
+			if !result.IsError {
+				t.Fatalf("expected the result to be an error")
+			}
+
+			meta, ok := result.Meta[errorMetaKey]
+			if tt.expected == nil {
+				if ok {
+					t.Errorf("expected no structured error, got %v", meta)
+				}
+				return
+			}
+			data, err := json.Marshal(meta)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var apiErr APIError
+			if err := json.Unmarshal(data, &apiErr); err != nil {
+				t.Fatalf("expected a structured error, got %s", data)
+			}
+			if apiErr.Message != tt.err.Error() || apiErr.Hint == "" {
+				t.Errorf("expected the message %q with a hint, got %+v", tt.err.Error(), apiErr)
+			}
+			if tt.hint != "" && apiErr.Hint != tt.hint {
+				t.Errorf("expected the hint %q, got %q", tt.hint, apiErr.Hint)
+			}
+			if text := result.Content[0].(*mcp.TextContent).Text; tt.text != "" && !strings.HasPrefix(text, tt.text) {
+				t.Errorf("expected the text to start with %q, got %q", tt.text, text)
+			}
+			structured, err := json.Marshal(result.StructuredContent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := fmt.Sprintf(`{"error":%s}`, data); string(structured) != expected {
+				t.Errorf("expected the structured content %s, got %s", expected, structured)
+			}
+
+			apiErr.Message, apiErr.Hint = "", ""
+			if apiErr != *tt.expected {
+				t.Errorf("expected %+v, got %+v", *tt.expected, apiErr)
+			}
+		})
+	}
+}
+
+func TestAPIErrorText(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiErr   APIError
+		expected string
+	}{
+		{
+			name:     "resource and cluster",
+			apiErr:   APIError{Code: "NotFound", Message: "not found", Hint: "Check its name.", Group: "apps", Version: "v1", Resource: "deployments", Name: "web", Cluster: "api.example.com:6443"},
+			expected: `NotFound (resource deployments.v1.apps "web", cluster api.example.com:6443): not found. Check its name.`,
+		},
+		{
+			name:     "core resource",
+			apiErr:   APIError{Code: "Forbidden", Message: "forbidden", Hint: "Ask the user.", Version: "v1", Resource: "pods"},
+			expected: "Forbidden (resource pods.v1): forbidden. Ask the user.",
+		},
+		{
+			name:     "cluster only",
+			apiErr:   APIError{Code: "Unauthorized", Message: "unauthorized", Hint: "Ask the user.", Cluster: "api.example.com:6443"},
+			expected: "Unauthorized (cluster api.example.com:6443): unauthorized. Ask the user.",
+		},
+		{
+			name:     "neither",
+			apiErr:   APIError{Code: "Timeout", Message: "context deadline exceeded", Hint: "Retry later."},
+			expected: "Timeout: context deadline exceeded. Retry later.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if text := tt.apiErr.text(); text != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, text)
+			}
+		})
+	}
+}
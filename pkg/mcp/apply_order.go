@@ -0,0 +1,168 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// Phases of a multi-document apply. Resources are applied phase by phase,
+// keeping the order of the documents within a phase.
+const (
+	// applyPhaseFoundation holds the Namespaces and CustomResourceDefinitions other resources depend on.
+	applyPhaseFoundation = iota
+	applyPhaseResources
+	// applyPhaseWebhooks holds the admission webhooks, policies and aggregated APIs, which are applied
+	// last so that they do not intercept the creation of the resources serving them.
+	applyPhaseWebhooks
+)
+
+var webhookKinds = map[schema.GroupKind]bool{
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:     true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingAdmissionPolicy"}:        true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingAdmissionPolicyBinding"}: true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingAdmissionPolicy"}:          true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingAdmissionPolicyBinding"}:   true,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                             true,
+}
+
+func isCRD(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition"
+}
+
+func isNamespace(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "" && gvk.Kind == "Namespace"
+}
+
+// applyPhase returns the phase the object is applied in.
+func applyPhase(obj *unstructured.Unstructured) int {
+	switch {
+	case isNamespace(obj) || isCRD(obj):
+		return applyPhaseFoundation
+	case webhookKinds[obj.GroupVersionKind().GroupKind()]:
+		return applyPhaseWebhooks
+	default:
+		return applyPhaseResources
+	}
+}
+
+// sortForApply orders the objects by their apply phase, keeping the document order within a phase.
+func sortForApply(objs []*unstructured.Unstructured) {
+	sort.SliceStable(objs, func(i, j int) bool {
+		return applyPhase(objs[i]) < applyPhase(objs[j])
+	})
+}
+
+// bundleDependency returns the CustomResourceDefinition or the Namespace of the bundle the object
+// depends on, or an empty string. Such objects can neither be discovered nor validated with a
+// dry-run before their dependency is applied.
+func bundleDependency(obj *unstructured.Unstructured, bundle []*unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	for _, other := range bundle {
+		switch {
+		case isCRD(other):
+			group, _, _ := unstructured.NestedString(other.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(other.Object, "spec", "names", "kind")
+			if group == gvk.Group && kind == gvk.Kind {
+				return fmt.Sprintf("customresourcedefinition/%s", other.GetName())
+			}
+		case isNamespace(other):
+			if obj.GetNamespace() != "" && obj.GetNamespace() == other.GetName() {
+				return fmt.Sprintf("namespace/%s", other.GetName())
+			}
+		}
+	}
+	return ""
+}
+
+// bundleKey returns the name the resources of the bundle depending on the object refer to it by,
+// as bundleDependency returns it.
+func bundleKey(obj *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s", strings.ToLower(obj.GetKind()), obj.GetName())
+}
+
+// overrideNamespace places the namespaced objects of the bundle in the namespace. The objects of
+// another namespace are moved only when forced, otherwise they are all reported. Objects the scope
+// of which can not be found yet are placed by applyTarget when they are applied.
+func overrideNamespace(ctx context.Context, bundle []*unstructured.Unstructured, namespace string, force bool, discoveryClient discovery.CachedDiscoveryInterface, session *mcp.ServerSession) error {
+	var mismatches []string
+	for _, obj := range bundle {
+		switch current := obj.GetNamespace(); {
+		case current == namespace:
+		case current != "" && !force:
+			mismatches = append(mismatches, fmt.Sprintf("%s/%s is in namespace %q", obj.GetKind(), obj.GetName(), current))
+		case current != "" || isNamespaceScoped(ctx, obj, bundle, discoveryClient, session):
+			obj.SetNamespace(namespace)
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%s, not %q: remove their namespace or set forceNamespace to move them", strings.Join(mismatches, ", "), namespace)
+	}
+	return nil
+}
+
+// isNamespaceScoped returns whether the object is namespaced, as its API resource or the
+// CustomResourceDefinition of the bundle defining it tells.
+func isNamespaceScoped(ctx context.Context, obj *unstructured.Unstructured, bundle []*unstructured.Unstructured, discoveryClient discovery.CachedDiscoveryInterface, session *mcp.ServerSession) bool {
+	if isNamespace(obj) || isCRD(obj) {
+		return false
+	}
+	gvk := obj.GroupVersionKind()
+	for _, other := range bundle {
+		group, _, _ := unstructured.NestedString(other.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(other.Object, "spec", "names", "kind")
+		if isCRD(other) && group == gvk.Group && kind == gvk.Kind {
+			scope, _, _ := unstructured.NestedString(other.Object, "spec", "scope")
+			return scope == "Namespaced"
+		}
+	}
+	_, isNamespaced, err := FindResource(ctx, strings.ToLower(gvk.Kind), discoveryClient, session)
+	return err == nil && isNamespaced
+}
+
+// applyTarget finds the API resource of the object and returns the client to apply it with.
+// Namespaced objects without a namespace are placed in the default namespace of the session,
+// or in the default namespace when the session has none.
+func applyTarget(ctx context.Context, resource *unstructured.Unstructured, dynamicClient dynamic.Interface, discoveryClient discovery.CachedDiscoveryInterface, session *mcp.ServerSession, defaultNamespace string) (dynamic.ResourceInterface, bool, error) {
+	gvr, isNamespaced, err := FindResource(ctx, strings.ToLower(resource.GetKind()), discoveryClient, session)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to find resource: %w", err)
+	}
+
+	if !isNamespaced {
+		return dynamicClient.Resource(gvr), false, nil
+	}
+	if resource.GetNamespace() == "" {
+		if defaultNamespace == "" {
+			defaultNamespace = "default"
+		}
+		resource.SetNamespace(defaultNamespace)
+	}
+	return dynamicClient.Resource(gvr).Namespace(resource.GetNamespace()), true, nil
+}
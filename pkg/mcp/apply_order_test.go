@@ -0,0 +1,186 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func bundleObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func crdObject(name, group, kind string) *unstructured.Unstructured {
+	crd := bundleObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", name)
+	crd.Object["spec"] = map[string]interface{}{
+		"group": group,
+		"names": map[string]interface{}{"kind": kind},
+	}
+	return crd
+}
+
+func TestSortForApply(t *testing.T) {
+	bundle := []*unstructured.Unstructured{
+		bundleObject("admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", "", "operator-webhook"),
+		bundleObject("apps/v1", "Deployment", "operator", "operator"),
+		bundleObject("example.com/v1", "Database", "operator", "orders"),
+		crdObject("databases.example.com", "example.com", "Database"),
+		bundleObject("v1", "Service", "operator", "operator-webhook"),
+		bundleObject("v1", "Namespace", "", "operator"),
+	}
+	sortForApply(bundle)
+
+	var order []string
+	for _, obj := range bundle {
+		order = append(order, obj.GetKind()+"/"+obj.GetName())
+	}
+	expected := []string{
+		"CustomResourceDefinition/databases.example.com",
+		"Namespace/operator",
+		"Deployment/operator",
+		"Database/orders",
+		"Service/operator-webhook",
+		"ValidatingWebhookConfiguration/operator-webhook",
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+}
+
+func TestBundleDependency(t *testing.T) {
+	bundle := []*unstructured.Unstructured{
+		bundleObject("v1", "Namespace", "", "operator"),
+		crdObject("databases.example.com", "example.com", "Database"),
+	}
+
+	tests := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		expected string
+	}{
+		{name: "custom resource of the bundle", obj: bundleObject("example.com/v1", "Database", "", "orders"), expected: "customresourcedefinition/databases.example.com"},
+		{name: "object in a namespace of the bundle", obj: bundleObject("apps/v1", "Deployment", "operator", "operator"), expected: "namespace/operator"},
+		{name: "object in an existing namespace", obj: bundleObject("apps/v1", "Deployment", "default", "web")},
+		{name: "custom resource of another group", obj: bundleObject("other.com/v1", "Database", "default", "orders")},
+		{name: "namespace", obj: bundle[0]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if dependency := bundleDependency(tt.obj, bundle); dependency != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, dependency)
+			}
+		})
+	}
+
+	// The resources depending on the objects of the bundle are skipped by their key when they are
+	// not applied.
+	keys := []string{bundleKey(bundle[0]), bundleKey(bundle[1])}
+	if expected := []string{"namespace/operator", "customresourcedefinition/databases.example.com"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected keys %v, got %v", expected, keys)
+	}
+}
+
+func TestOverrideNamespace(t *testing.T) {
+	resources := []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true}},
+		},
+		{
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []metav1.APIResource{{Name: "clusterroles", SingularName: "clusterrole", Kind: "ClusterRole"}},
+		},
+	}
+	discoveryClient := cmdtesting.NewFakeCachedDiscoveryClient()
+	discoveryClient.PreferredResources = resources
+	discoveryClient.Resources = resources
+
+	namespacedCRD := crdObject("databases.example.com", "example.com", "Database")
+	namespacedCRD.Object["spec"].(map[string]interface{})["scope"] = "Namespaced"
+
+	tests := []struct {
+		name          string
+		bundle        []*unstructured.Unstructured
+		force         bool
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "namespaced objects without namespace",
+			bundle: []*unstructured.Unstructured{
+				bundleObject("v1", "Namespace", "", "shop"),
+				bundleObject("apps/v1", "Deployment", "", "web"),
+				bundleObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "reader"),
+				namespacedCRD,
+				bundleObject("example.com/v1", "Database", "", "orders"),
+			},
+			expected: []string{"", "shop", "", "", "shop"},
+		},
+		{
+			name: "objects of another namespace",
+			bundle: []*unstructured.Unstructured{
+				bundleObject("apps/v1", "Deployment", "shop", "web"),
+				bundleObject("apps/v1", "Deployment", "default", "api"),
+				bundleObject("apps/v1", "Deployment", "other", "worker"),
+			},
+			expectedError: `Deployment/api is in namespace "default", Deployment/worker is in namespace "other", not "shop"`,
+		},
+		{
+			name: "objects of another namespace forced",
+			bundle: []*unstructured.Unstructured{
+				bundleObject("apps/v1", "Deployment", "default", "api"),
+			},
+			force:    true,
+			expected: []string{"shop"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := overrideNamespace(context.Background(), tt.bundle, "shop", tt.force, discoveryClient, nil)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var namespaces []string
+			for _, obj := range tt.bundle {
+				namespaces = append(namespaces, obj.GetNamespace())
+			}
+			if !reflect.DeepEqual(namespaces, tt.expected) {
+				t.Errorf("expected namespaces %v, got %v", tt.expected, namespaces)
+			}
+		})
+	}
+}
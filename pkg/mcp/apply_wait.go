@@ -0,0 +1,256 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// defaultApplyWaitTimeout is the default time to wait for the applied objects to become ready.
+	defaultApplyWaitTimeout = 5 * time.Minute
+	// watchResumeDelay is the delay before resuming a watch closed by the API server.
+	watchResumeDelay = 100 * time.Millisecond
+)
+
+const (
+	ReadinessReady      = "Ready"
+	ReadinessInProgress = "InProgress"
+	ReadinessFailed     = "Failed"
+	ReadinessTimeout    = "Timeout"
+)
+
+// ApplyStatus is the readiness of an applied object.
+type ApplyStatus struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// waitForReady waits until the object is ready, has failed or the context is done.
+// The object is the result of the apply, its resourceVersion is where the watch starts.
+// Watches are closed by the API server after a few minutes, so the watch is resumed from the
+// last seen resourceVersion, kept current by bookmarks, until the context is done.
+func waitForReady(ctx context.Context, resource dynamic.ResourceInterface, obj *unstructured.Unstructured) ApplyStatus {
+	status := ApplyStatus{
+		Kind:      obj.GetKind(),
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+	}
+
+	state, message := objectReadiness(obj)
+	resourceVersion := obj.GetResourceVersion()
+	for state == ReadinessInProgress {
+		var err error
+		state, message, resourceVersion, err = watchReadiness(ctx, resource, obj.GetName(), resourceVersion, message)
+		switch {
+		case ctx.Err() != nil:
+			status.Status = ReadinessTimeout
+			status.Message = message
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				status.Message = fmt.Sprintf("wait cancelled: %s", message)
+			}
+			return status
+		case apierrors.IsGone(err) || apierrors.IsResourceExpired(err):
+			// The resourceVersion is too old to resume from, start over from the current object.
+			current, err := resource.Get(ctx, obj.GetName(), v1.GetOptions{})
+			if err != nil {
+				status.Status, status.Message = ReadinessInProgress, fmt.Sprintf("failed to get the object: %v", err)
+				return status
+			}
+			state, message = objectReadiness(current)
+			resourceVersion = current.GetResourceVersion()
+		case err != nil:
+			status.Status, status.Message = ReadinessInProgress, err.Error()
+			return status
+		case state == ReadinessInProgress:
+			// Avoid busy looping when watches keep being closed right away.
+			select {
+			case <-ctx.Done():
+			case <-time.After(watchResumeDelay):
+			}
+		}
+	}
+
+	status.Status, status.Message = state, message
+	return status
+}
+
+// watchReadiness watches the object from the resourceVersion until its readiness is decided or the
+// watch ends. It returns the readiness and the last seen resourceVersion to resume the watch from.
+func watchReadiness(ctx context.Context, resource dynamic.ResourceInterface, name, resourceVersion, message string) (string, string, string, error) {
+	w, err := resource.Watch(ctx, v1.ListOptions{
+		FieldSelector:       fields.OneTermEqualSelector("metadata.name", name).String(),
+		ResourceVersion:     resourceVersion,
+		AllowWatchBookmarks: true,
+	})
+	if err != nil {
+		return ReadinessInProgress, message, resourceVersion, fmt.Errorf("failed to watch: %w", err)
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ReadinessInProgress, message, resourceVersion, ctx.Err()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return ReadinessInProgress, message, resourceVersion, nil
+			}
+			switch event.Type {
+			case watch.Deleted:
+				return ReadinessFailed, "object was deleted", resourceVersion, nil
+			case watch.Error:
+				return ReadinessInProgress, message, resourceVersion, apierrors.FromObject(event.Object)
+			}
+			current, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			resourceVersion = current.GetResourceVersion()
+			if event.Type == watch.Bookmark {
+				continue
+			}
+			var state string
+			state, message = objectReadiness(current)
+			if state != ReadinessInProgress {
+				return state, message, resourceVersion, nil
+			}
+		}
+	}
+}
+
+// objectReadiness returns whether the object is ready, still in progress or has failed,
+// with a message explaining why. Kinds without known readiness criteria are considered
+// ready once their Ready or Available condition is true, or right away without conditions.
+func objectReadiness(obj *unstructured.Unstructured) (string, string) {
+	gvk := obj.GroupVersionKind()
+	switch {
+	case gvk.Group == "apps" && gvk.Kind == "Deployment":
+		var deployment appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment); err != nil {
+			return ReadinessInProgress, err.Error()
+		}
+		return workloadReadiness(deploymentHealth(&deployment))
+	case gvk.Group == "apps" && gvk.Kind == "StatefulSet":
+		var statefulSet appsv1.StatefulSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &statefulSet); err != nil {
+			return ReadinessInProgress, err.Error()
+		}
+		return workloadReadiness(statefulSetHealth(&statefulSet))
+	case gvk.Group == "apps" && gvk.Kind == "DaemonSet":
+		var daemonSet appsv1.DaemonSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &daemonSet); err != nil {
+			return ReadinessInProgress, err.Error()
+		}
+		return workloadReadiness(daemonSetHealth(&daemonSet))
+	case gvk.Group == "" && gvk.Kind == "Pod":
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+			return ReadinessInProgress, err.Error()
+		}
+		return podReadiness(&pod)
+	case gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition":
+		return conditionReadiness(obj, "Established")
+	case gvk.Group == "batch" && gvk.Kind == "Job":
+		for _, cond := range extractConditions(obj) {
+			if cond.Status != string(corev1.ConditionTrue) {
+				continue
+			}
+			switch cond.Type {
+			case "Complete":
+				return ReadinessReady, "job completed"
+			case "Failed":
+				return ReadinessFailed, fmt.Sprintf("job failed (%s): %s", cond.Reason, cond.Message)
+			}
+		}
+		return ReadinessInProgress, "job is running"
+	}
+
+	observedGeneration, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if found && observedGeneration < obj.GetGeneration() {
+		return ReadinessInProgress, "the latest generation is not observed yet"
+	}
+	for _, cond := range extractConditions(obj) {
+		if cond.Type == "Ready" || cond.Type == "Available" {
+			return conditionReadiness(obj, cond.Type)
+		}
+	}
+	return ReadinessReady, "no readiness conditions"
+}
+
+func workloadReadiness(health WorkloadHealth) (string, string) {
+	message := strings.Join(health.Reasons, "; ")
+	switch health.Verdict {
+	case WorkloadHealthy:
+		if message == "" {
+			message = fmt.Sprintf("%d/%d replicas available", health.AvailableReplicas, health.DesiredReplicas)
+		}
+		return ReadinessReady, message
+	case WorkloadFailed:
+		return ReadinessFailed, message
+	default:
+		return ReadinessInProgress, message
+	}
+}
+
+func podReadiness(pod *corev1.Pod) (string, string) {
+	switch {
+	case pod.Status.Phase == corev1.PodSucceeded:
+		return ReadinessReady, "pod completed"
+	case isPodReady(pod):
+		return ReadinessReady, "pod is running and ready"
+	}
+	message := fmt.Sprintf("pod is %s", pod.Status.Phase)
+	if problems := buildPodDiagnosis(pod, nil).Problems; len(problems) > 0 {
+		message = strings.Join(problems, "; ")
+	}
+	if pod.Status.Phase == corev1.PodFailed {
+		return ReadinessFailed, message
+	}
+	return ReadinessInProgress, message
+}
+
+// conditionReadiness returns whether the condition of the given type is true.
+func conditionReadiness(obj *unstructured.Unstructured, conditionType string) (string, string) {
+	for _, cond := range extractConditions(obj) {
+		if cond.Type != conditionType {
+			continue
+		}
+		if cond.Status == string(corev1.ConditionTrue) {
+			return ReadinessReady, fmt.Sprintf("%s condition is true", conditionType)
+		}
+		return ReadinessInProgress, fmt.Sprintf("%s condition is %s (%s): %s", conditionType, cond.Status, cond.Reason, cond.Message)
+	}
+	return ReadinessInProgress, fmt.Sprintf("waiting for the %s condition", conditionType)
+}
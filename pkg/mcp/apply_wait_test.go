@@ -0,0 +1,209 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func deploymentObject(generation, observedGeneration, availableReplicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":       "web",
+			"namespace":  "default",
+			"generation": generation,
+		},
+		"spec": map[string]interface{}{"replicas": int64(2)},
+		"status": map[string]interface{}{
+			"observedGeneration": observedGeneration,
+			"replicas":           availableReplicas,
+			"updatedReplicas":    availableReplicas,
+			"readyReplicas":      availableReplicas,
+			"availableReplicas":  availableReplicas,
+		},
+	}}
+}
+
+func TestObjectReadiness(t *testing.T) {
+	withConditions := func(apiVersion, kind string, conditions ...map[string]interface{}) *unstructured.Unstructured {
+		raw := make([]interface{}, 0, len(conditions))
+		for _, cond := range conditions {
+			raw = append(raw, cond)
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": "obj"},
+			"status":     map[string]interface{}{"conditions": raw},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		expected string
+	}{
+		{name: "rolled out deployment", obj: deploymentObject(1, 1, 2), expected: ReadinessReady},
+		{name: "deployment rolling out", obj: deploymentObject(2, 1, 2), expected: ReadinessInProgress},
+		{name: "deployment without available replicas", obj: deploymentObject(1, 1, 0), expected: ReadinessInProgress},
+		{
+			name: "deployment past its progress deadline",
+			obj: withConditions("apps/v1", "Deployment",
+				map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"}),
+			expected: ReadinessFailed,
+		},
+		{
+			name:     "running pod",
+			obj:      withConditions("v1", "Pod", map[string]interface{}{"type": "Ready", "status": "True"}),
+			expected: ReadinessReady,
+		},
+		{
+			name:     "pending pod",
+			obj:      withConditions("v1", "Pod", map[string]interface{}{"type": "Ready", "status": "False"}),
+			expected: ReadinessInProgress,
+		},
+		{
+			name:     "established CRD",
+			obj:      withConditions("apiextensions.k8s.io/v1", "CustomResourceDefinition", map[string]interface{}{"type": "Established", "status": "True"}),
+			expected: ReadinessReady,
+		},
+		{
+			name:     "CRD with conflicting names",
+			obj:      withConditions("apiextensions.k8s.io/v1", "CustomResourceDefinition", map[string]interface{}{"type": "NamesAccepted", "status": "False"}),
+			expected: ReadinessInProgress,
+		},
+		{
+			name:     "failed job",
+			obj:      withConditions("batch/v1", "Job", map[string]interface{}{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded"}),
+			expected: ReadinessFailed,
+		},
+		{
+			name:     "custom resource not ready",
+			obj:      withConditions("example.com/v1", "Database", map[string]interface{}{"type": "Ready", "status": "False"}),
+			expected: ReadinessInProgress,
+		},
+		{
+			name:     "configmap",
+			obj:      &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}},
+			expected: ReadinessReady,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if state, message := objectReadiness(tt.obj); state != tt.expected {
+				t.Errorf("expected %s, got %s (%s)", tt.expected, state, message)
+			}
+		})
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	applied := deploymentObject(1, 0, 0)
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), applied)
+	resource := dynamicClient.Resource(deploymentsGVR).Namespace("default")
+
+	go func() {
+		// Update the status once the watch is established.
+		for !hasWatchAction(dynamicClient) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		//nolint:errcheck
+		resource.UpdateStatus(context.Background(), deploymentObject(1, 1, 2), v1.UpdateOptions{})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if status := waitForReady(ctx, resource, applied); status.Status != ReadinessReady {
+		t.Errorf("expected the deployment to become ready, got %+v", status)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if status := waitForReady(ctx, resource, deploymentObject(2, 1, 2)); status.Status != ReadinessTimeout {
+		t.Errorf("expected the wait to time out, got %+v", status)
+	}
+}
+
+func hasWatchAction(dynamicClient *dynamicfake.FakeDynamicClient) bool {
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "watch" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWaitForReadyResumesWatch(t *testing.T) {
+	applied := deploymentObject(1, 0, 0)
+	applied.SetResourceVersion("1")
+	current := deploymentObject(1, 0, 0)
+	current.SetResourceVersion("20")
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), current)
+
+	// The first watch delivers a bookmark and is closed by the server, the resumed watch fails
+	// because its resourceVersion expired, and the watch started over delivers the ready object.
+	bookmark := deploymentObject(1, 0, 0)
+	bookmark.SetResourceVersion("10")
+	ready := deploymentObject(1, 1, 2)
+	ready.SetResourceVersion("21")
+	var resourceVersions []string
+	dynamicClient.PrependWatchReactor("deployments", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		options := action.(clienttesting.WatchActionImpl).ListOptions
+		if !options.AllowWatchBookmarks {
+			t.Errorf("expected bookmarks to be requested")
+		}
+		resourceVersions = append(resourceVersions, options.ResourceVersion)
+
+		w := watch.NewFake()
+		go func() {
+			switch options.ResourceVersion {
+			case "1":
+				w.Action(watch.Bookmark, bookmark)
+				w.Stop()
+			case "10":
+				w.Error(&apierrors.NewResourceExpired("too old resource version").ErrStatus)
+			case "20":
+				w.Modify(ready)
+			}
+		}()
+		return true, w, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status := waitForReady(ctx, dynamicClient.Resource(deploymentsGVR).Namespace("default"), applied)
+	if status.Status != ReadinessReady {
+		t.Errorf("expected the deployment to become ready, got %+v", status)
+	}
+	if expected := []string{"1", "10", "20"}; !reflect.DeepEqual(resourceVersions, expected) {
+		t.Errorf("expected watches from resource versions %v, got %v", expected, resourceVersions)
+	}
+}
@@ -0,0 +1,524 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// auditConfigMapName is the ConfigMap receiving the audit entries, archived once full.
+	auditConfigMapName = "k-mcp-audit"
+	// auditEntriesPerConfigMap is the number of entries of a ConfigMap before it is archived.
+	auditEntriesPerConfigMap = 100
+	// auditArchivedConfigMaps is the number of archived ConfigMaps, the oldest being overwritten.
+	auditArchivedConfigMaps = 10
+	// auditRotationAnnotation counts the archivals of the ConfigMap receiving the entries.
+	auditRotationAnnotation = "k-mcp.io/audit-rotation"
+	// maxAuditMessageLength bounds the message of the entries.
+	maxAuditMessageLength = 1024
+	// auditWriteTimeout bounds the writes of an entry.
+	auditWriteTimeout = 10 * time.Second
+	// auditWriteAttempts bounds the writes of an entry conflicting with other replicas.
+	auditWriteAttempts = 3
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// Audit outcomes.
+const (
+	AuditSucceeded = "Succeeded"
+	AuditFailed    = "Failed"
+	// AuditCancelled is the outcome of the changes declined by the user.
+	AuditCancelled = "Cancelled"
+	// AuditStarted is the outcome of the calls run as background operations, whose outcome is
+	// recorded in a second entry once they complete.
+	AuditStarted = "Started"
+)
+
+// AuditEntry records a call of a mutating tool.
+type AuditEntry struct {
+	Time         time.Time `json:"time"`
+	Tool         string    `json:"tool"`
+	Subject      string    `json:"subject,omitempty"`
+	APIServerURL string    `json:"apiServerUrl,omitempty"`
+	Outcome      string    `json:"outcome"`
+	Message      string    `json:"message,omitempty"`
+	// OperationID is the ID of the background operation run by the call.
+	OperationID string `json:"operationId,omitempty"`
+	// Objects are the objects targeted by the call.
+	Objects []AuditObject `json:"objects,omitempty"`
+}
+
+// AuditObject is an object targeted by a call of a mutating tool.
+type AuditObject struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// ManifestHash is the SHA-256 of the JSON encoding of the applied manifest.
+	ManifestHash string `json:"manifestHash,omitempty"`
+}
+
+// auditWriter writes the audit entries to a sink.
+type auditWriter interface {
+	write(ctx context.Context, tokenInfo *auth.TokenInfo, entry AuditEntry) error
+}
+
+// auditLog records the calls of the mutating tools to the configured sinks, independently of the
+// debug logging: who called which tool on which objects of which cluster, when and with which outcome.
+type auditLog struct {
+	writers []auditWriter
+	// discovery resolves the resources of the objects, replaced in tests.
+	discovery func(tokenInfo *auth.TokenInfo) (discovery.CachedDiscoveryInterface, error)
+	// defaultNamespace is the namespace of the namespaced objects applied without one.
+	defaultNamespace func(request *mcp.CallToolRequest) string
+}
+
+// newAuditLog returns the audit log writing to the file, the webhook and the cluster namespace
+// configured on the server, which does not record anything when none is.
+func (s *Server) newAuditLog(dynamicConfig *DynamicConfig) (*auditLog, error) {
+	a := &auditLog{
+		discovery: func(tokenInfo *auth.TokenInfo) (discovery.CachedDiscoveryInterface, error) {
+			_, discoveryClient, err := dynamicConfig.LoadRestConfigForTokenInfo(tokenInfo)
+			return discoveryClient, err
+		},
+		defaultNamespace: s.defaultNamespace,
+	}
+	if s.AuditLogFile != "" {
+		file, err := newAuditFile(s.AuditLogFile)
+		if err != nil {
+			return nil, err
+		}
+		a.writers = append(a.writers, file)
+	}
+	if s.AuditWebhookURL != "" {
+		a.writers = append(a.writers, newAuditWebhook(s.AuditWebhookURL))
+	}
+	if s.AuditNamespace != "" {
+		a.writers = append(a.writers, newAuditSink(dynamicConfig, s.AuditNamespace))
+	}
+	return a, nil
+}
+
+// close releases the sinks holding resources.
+func (a *auditLog) close() {
+	for _, writer := range a.writers {
+		if closer, ok := writer.(io.Closer); ok {
+			//nolint:errcheck
+			closer.Close()
+		}
+	}
+}
+
+// middleware records the calls of the mutating tools once done, timed out calls included. The calls
+// run as background operations are recorded as started, then once their operation completes.
+func (a *auditLog) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			request, ok := req.(*mcp.CallToolRequest)
+			if method != methodCallTool || !ok {
+				return result, err
+			}
+
+			callResult, _ := result.(*mcp.CallToolResult)
+			var structured any
+			if callResult != nil {
+				structured = callResult.StructuredContent
+			}
+			a.record(ctx, request, auditEntry(request, callResult, structured, err))
+			return result, err
+		}
+	}
+}
+
+// recordOperation records the completion of a background operation started by a mutating tool call,
+// with the outcome of the call it ran.
+func (a *auditLog) recordOperation(ctx context.Context, request *mcp.CallToolRequest, result *mcp.CallToolResult, structured any, err error, completed Operation) {
+	entry := auditEntry(request, result, structured, err)
+	entry.OperationID = completed.ID
+	switch completed.Status {
+	case OperationCancelled:
+		entry.Outcome = AuditCancelled
+	case OperationFailed:
+		entry.Outcome = AuditFailed
+	}
+	a.record(ctx, request, entry)
+}
+
+// auditEntry returns the entry of a call of a tool from its result, its structured result or its error.
+func auditEntry(request *mcp.CallToolRequest, result *mcp.CallToolResult, structured any, err error) AuditEntry {
+	entry := AuditEntry{
+		Time:    time.Now().UTC(),
+		Tool:    request.Params.Name,
+		Outcome: AuditSucceeded,
+	}
+	switch {
+	case err != nil:
+		entry.Outcome, entry.Message = AuditFailed, err.Error()
+	case result != nil:
+		cancellationReason, operationID := resultFields(structured)
+		switch {
+		case result.IsError:
+			entry.Outcome = AuditFailed
+		case cancellationReason != "":
+			entry.Outcome = AuditCancelled
+		case operationID != "":
+			entry.Outcome, entry.OperationID = AuditStarted, operationID
+		}
+		for _, content := range result.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				entry.Message = text.Text
+				break
+			}
+		}
+	}
+	if len(entry.Message) > maxAuditMessageLength {
+		cut := maxAuditMessageLength
+		for cut > 0 && !utf8.RuneStart(entry.Message[cut]) {
+			cut--
+		}
+		entry.Message = entry.Message[:cut] + "..."
+	}
+	return entry
+}
+
+// record writes the entry of a call of a mutating tool to the sinks.
+func (a *auditLog) record(ctx context.Context, request *mcp.CallToolRequest, entry AuditEntry) {
+	if len(a.writers) == 0 || !slices.Contains(mutatingTools, request.Params.Name) {
+		return
+	}
+	if request.Extra == nil || request.Extra.TokenInfo == nil {
+		return
+	}
+	entry.Subject = requestSubject(request)
+	entry.APIServerURL = requestAPIServerURL(request)
+
+	// Record the call even when the client cancelled it.
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+	entry.Objects = a.objects(writeCtx, request)
+	for _, writer := range a.writers {
+		if err := writer.write(writeCtx, request.Extra.TokenInfo, entry); err != nil {
+			slog.WarnContext(ctx, "Failed to record the audit entry", "tool", entry.Tool, "sink", fmt.Sprintf("%T", writer), "err", err)
+		}
+	}
+}
+
+// resultFields returns the reason the user declined the change of the call and the ID of the
+// background operation it started, as given in its structured result.
+func resultFields(structured any) (string, string) {
+	if structured == nil {
+		return "", ""
+	}
+	data, err := json.Marshal(structured)
+	if err != nil {
+		return "", ""
+	}
+	var content struct {
+		CancellationReason string `json:"cancellationReason"`
+		OperationID        string `json:"operationId"`
+	}
+	if json.Unmarshal(data, &content) != nil {
+		return "", ""
+	}
+	return content.CancellationReason, content.OperationID
+}
+
+// objects returns the objects targeted by the call, as given in its arguments. The resources that
+// can not be resolved are left empty rather than failing the record.
+func (a *auditLog) objects(ctx context.Context, request *mcp.CallToolRequest) []AuditObject {
+	var discoveryClient discovery.CachedDiscoveryInterface
+	resolve := func(resource string) (schema.GroupVersionResource, bool, bool) {
+		if discoveryClient == nil {
+			var err error
+			if discoveryClient, err = a.discovery(request.Extra.TokenInfo); err != nil {
+				return schema.GroupVersionResource{}, false, false
+			}
+		}
+		// Never ask the user to choose between partial matches when recording.
+		gvr, namespaced, err := FindResource(ctx, resource, discoveryClient, nil)
+		return gvr, namespaced, err == nil
+	}
+
+	switch request.Params.Name {
+	case "resource_apply":
+		var input ResourceCreateOrUpdateInput
+		if err := json.Unmarshal(request.Params.Arguments, &input); err != nil {
+			return nil
+		}
+		manifests, err := decodeManifests(input.ResourceYAML)
+		if err != nil {
+			return nil
+		}
+		var objects []AuditObject
+		for _, manifest := range manifests {
+			gvk := manifest.GroupVersionKind()
+			object := AuditObject{
+				Group:     gvk.Group,
+				Version:   gvk.Version,
+				Kind:      gvk.Kind,
+				Name:      manifest.GetName(),
+				Namespace: manifest.GetNamespace(),
+			}
+			if data, err := json.Marshal(manifest.Object); err == nil {
+				object.ManifestHash = fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+			}
+			// Resolved like the applies do.
+			if gvr, namespaced, ok := resolve(strings.ToLower(gvk.Kind)); ok {
+				object.Resource = gvr.Resource
+				if !namespaced {
+					object.Namespace = ""
+				} else if object.Namespace == "" {
+					object.Namespace = a.defaultNamespace(request)
+					if object.Namespace == "" {
+						object.Namespace = "default"
+					}
+				}
+			}
+			objects = append(objects, object)
+		}
+		return objects
+	case "take_ownership":
+		var input TakeOwnershipInput
+		if err := json.Unmarshal(request.Params.Arguments, &input); err != nil {
+			return nil
+		}
+		object := AuditObject{Resource: input.Resource, Name: input.Name, Namespace: input.Namespace}
+		if gvr, _, ok := resolve(input.Resource); ok {
+			object.Group, object.Version, object.Resource = gvr.Group, gvr.Version, gvr.Resource
+		}
+		return []AuditObject{object}
+	case "pod_evict":
+		var input PodEvictInput
+		if err := json.Unmarshal(request.Params.Arguments, &input); err != nil {
+			return nil
+		}
+		namespace := input.Namespace
+		if namespace == "" {
+			namespace = a.defaultNamespace(request)
+			if namespace == "" {
+				namespace = "default"
+			}
+		}
+		return []AuditObject{{Version: podsGVR.Version, Kind: "Pod", Resource: podsGVR.Resource, Name: input.Name, Namespace: namespace}}
+	}
+	return nil
+}
+
+// auditSink records the calls of the mutating tools in a namespace of the cluster they change, as
+// Events for a quick look and as entries of ConfigMaps for retention, so that deployments without
+// external logging keep a queryable record of the actions of the agents. The entries are written
+// with the token of the call.
+type auditSink struct {
+	namespace string
+	// client is replaced in tests.
+	client func(tokenInfo *auth.TokenInfo) (dynamic.Interface, error)
+
+	// mu serializes the writes, so that the replica only conflicts with other replicas.
+	mu sync.Mutex
+}
+
+func newAuditSink(dynamicConfig *DynamicConfig, namespace string) *auditSink {
+	return &auditSink{
+		namespace: namespace,
+		client: func(tokenInfo *auth.TokenInfo) (dynamic.Interface, error) {
+			client, _, err := dynamicConfig.LoadRestConfigForTokenInfo(tokenInfo)
+			return client, err
+		},
+	}
+}
+
+// write adds the entry to the ConfigMap, then emits an Event about it.
+func (a *auditSink) write(ctx context.Context, tokenInfo *auth.TokenInfo, entry AuditEntry) error {
+	client, err := a.client(tokenInfo)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var configMap *unstructured.Unstructured
+	for attempt := 1; ; attempt++ {
+		configMap, err = a.appendEntry(ctx, client, entry)
+		if err == nil || !apierrors.IsConflict(err) || attempt == auditWriteAttempts {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the audit entry: %w", err)
+	}
+
+	if _, err := client.Resource(eventsGVR).Namespace(a.namespace).Create(ctx, auditEvent(configMap, entry), v1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to emit the audit event: %w", err)
+	}
+	return nil
+}
+
+// appendEntry adds the entry to the ConfigMap receiving the entries, after archiving it when full.
+func (a *auditSink) appendEntry(ctx context.Context, client dynamic.Interface, entry AuditEntry) (*unstructured.Unstructured, error) {
+	configMaps := client.Resource(configMapsGVR).Namespace(a.namespace)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	configMap, err := configMaps.Get(ctx, auditConfigMapName, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap = newAuditConfigMap(auditConfigMapName, a.namespace, 0, nil)
+		configMap.Object["data"] = map[string]any{auditEntryKey(entry, nil): string(data)}
+		return configMaps.Create(ctx, configMap, v1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, _, _ := unstructured.NestedStringMap(configMap.Object, "data")
+	rotation, _ := strconv.Atoi(configMap.GetAnnotations()[auditRotationAnnotation])
+	if len(entries) >= auditEntriesPerConfigMap {
+		archiveName := fmt.Sprintf("%s-%d", auditConfigMapName, rotation%auditArchivedConfigMaps)
+		if err := a.archive(ctx, client, archiveName, rotation, entries); err != nil {
+			return nil, err
+		}
+		rotation++
+		entries = nil
+	}
+
+	key := auditEntryKey(entry, entries)
+	if entries == nil {
+		entries = map[string]string{}
+	}
+	entries[key] = string(data)
+	if err := unstructured.SetNestedStringMap(configMap.Object, entries, "data"); err != nil {
+		return nil, err
+	}
+	annotations := configMap.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[auditRotationAnnotation] = strconv.Itoa(rotation)
+	configMap.SetAnnotations(annotations)
+	return configMaps.Update(ctx, configMap, v1.UpdateOptions{})
+}
+
+// archive writes the entries of a full ConfigMap to an archived ConfigMap, overwriting its older entries.
+func (a *auditSink) archive(ctx context.Context, client dynamic.Interface, name string, rotation int, entries map[string]string) error {
+	configMaps := client.Resource(configMapsGVR).Namespace(a.namespace)
+	archived := newAuditConfigMap(name, a.namespace, rotation, entries)
+
+	existing, err := configMaps.Get(ctx, name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, archived, v1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	archived.SetResourceVersion(existing.GetResourceVersion())
+	_, err = configMaps.Update(ctx, archived, v1.UpdateOptions{})
+	return err
+}
+
+func newAuditConfigMap(name, namespace string, rotation int, entries map[string]string) *unstructured.Unstructured {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetName(name)
+	configMap.SetNamespace(namespace)
+	configMap.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "k-mcp", "app.kubernetes.io/component": "audit"})
+	configMap.SetAnnotations(map[string]string{auditRotationAnnotation: strconv.Itoa(rotation)})
+	if entries != nil {
+		//nolint:errcheck
+		unstructured.SetNestedStringMap(configMap.Object, entries, "data")
+	}
+	return configMap
+}
+
+// auditEntryKey returns the key of the entry in the ConfigMap, sorting the entries by time.
+func auditEntryKey(entry AuditEntry, entries map[string]string) string {
+	prefix := entry.Time.Format("20060102T150405.000000000Z") + "." + entry.Tool
+	key := prefix
+	for i := 1; ; i++ {
+		if _, ok := entries[key]; !ok {
+			return key
+		}
+		key = fmt.Sprintf("%s.%d", prefix, i)
+	}
+}
+
+// auditEvent returns the Event about the entry, regarding the ConfigMap recording it.
+func auditEvent(configMap *unstructured.Unstructured, entry AuditEntry) *unstructured.Unstructured {
+	eventType := "Normal"
+	if entry.Outcome == AuditFailed {
+		eventType = "Warning"
+	}
+	subject := entry.Subject
+	if subject == "" {
+		subject = "unknown subject"
+	}
+	message := fmt.Sprintf("%s called %s: %s", subject, entry.Tool, entry.Message)
+	timestamp := entry.Time.Format(time.RFC3339)
+
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Event",
+		"metadata": map[string]any{
+			// Named like the Events of the client-go event recorders.
+			"name":      fmt.Sprintf("%s.%x", configMap.GetName(), entry.Time.UnixNano()),
+			"namespace": configMap.GetNamespace(),
+		},
+		"involvedObject": map[string]any{
+			"apiVersion":      "v1",
+			"kind":            "ConfigMap",
+			"name":            configMap.GetName(),
+			"namespace":       configMap.GetNamespace(),
+			"uid":             string(configMap.GetUID()),
+			"resourceVersion": configMap.GetResourceVersion(),
+		},
+		"reason":         "Tool" + entry.Outcome,
+		"message":        message,
+		"type":           eventType,
+		"source":         map[string]any{"component": "k-mcp"},
+		"firstTimestamp": timestamp,
+		"lastTimestamp":  timestamp,
+		"count":          int64(1),
+	}}
+}
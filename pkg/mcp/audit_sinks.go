@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+// maxAuditWebhookResponseSize bounds the response bodies read from the audit webhook.
+const maxAuditWebhookResponseSize = 4 << 10
+
+// auditFile appends the audit entries to a file, one JSON object per line.
+type auditFile struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newAuditFile(path string) (*auditFile, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the audit log file: %w", err)
+	}
+	return &auditFile{file: file}, nil
+}
+
+func (f *auditFile) write(_ context.Context, _ *auth.TokenInfo, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = f.file.Write(append(data, '\n'))
+	return err
+}
+
+func (f *auditFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// auditWebhook posts the audit entries to a URL as JSON objects.
+type auditWebhook struct {
+	url    string
+	client *http.Client
+}
+
+func newAuditWebhook(url string) *auditWebhook {
+	return &auditWebhook{url: url, client: http.DefaultClient}
+}
+
+func (w *auditWebhook) write(ctx context.Context, _ *auth.TokenInfo, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post the audit entry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxAuditWebhookResponseSize))
+		return fmt.Errorf("audit webhook returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func newTestAuditSink(namespace string) (*auditSink, *dynamicfake.FakeDynamicClient) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configMapsGVR: "ConfigMapList",
+		eventsGVR:     "EventList",
+	})
+	sink := &auditSink{
+		namespace: namespace,
+		client: func(*auth.TokenInfo) (dynamic.Interface, error) {
+			return dynamicClient, nil
+		},
+	}
+	return sink, dynamicClient
+}
+
+func newTestAuditLog(writers ...auditWriter) *auditLog {
+	resources := []*v1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []v1.APIResource{{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true}},
+		},
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{{Name: "namespaces", SingularName: "namespace", Kind: "Namespace"}},
+		},
+	}
+	discoveryClient := cmdtesting.NewFakeCachedDiscoveryClient()
+	discoveryClient.PreferredResources = resources
+	discoveryClient.Resources = resources
+	return &auditLog{
+		writers: writers,
+		discovery: func(*auth.TokenInfo) (discovery.CachedDiscoveryInterface, error) {
+			return discoveryClient, nil
+		},
+		defaultNamespace: func(*mcp.CallToolRequest) string { return "shop" },
+	}
+}
+
+func auditTestRequest(tool, arguments string) *mcp.CallToolRequest {
+	return &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: tool, Arguments: json.RawMessage(arguments)},
+		Extra: &mcp.RequestExtra{TokenInfo: &auth.TokenInfo{Extra: map[string]any{
+			"audience": "https://cluster.example.com",
+			"subject":  "system:serviceaccount:agents:bot",
+		}}},
+	}
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	applyArguments := `{"resourceYAML": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: shop\n  namespace: ignored\n"}`
+	deploymentHash := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"}}`)))
+	namespaceHash := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"shop","namespace":"ignored"}}`)))
+
+	tests := []struct {
+		name      string
+		namespace string
+		tool      string
+		arguments string
+		result    *mcp.CallToolResult
+		err       error
+		expected  *AuditEntry
+	}{
+		{
+			name:      "mutating tool",
+			namespace: "k-mcp-audit",
+			tool:      "resource_apply",
+			arguments: applyArguments,
+			result:    &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Applied deployment/web"}}},
+			expected: &AuditEntry{Tool: "resource_apply", Outcome: AuditSucceeded, Message: "Applied deployment/web", Objects: []AuditObject{
+				{Group: "apps", Version: "v1", Resource: "deployments", Kind: "Deployment", Name: "web", Namespace: "shop", ManifestHash: deploymentHash},
+				{Version: "v1", Resource: "namespaces", Kind: "Namespace", Name: "shop", ManifestHash: namespaceHash},
+			}},
+		},
+		{
+			name:      "cancelled call",
+			namespace: "k-mcp-audit",
+			tool:      "resource_apply",
+			arguments: `{"resourceYAML": "apiVersion: v1\nkind: Widget\nmetadata:\n  name: w\n  namespace: shop\n"}`,
+			result: &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "Apply cancelled"}},
+				StructuredContent: &ResourceApplyResult{CancellationReason: "declined"},
+			},
+			expected: &AuditEntry{Tool: "resource_apply", Outcome: AuditCancelled, Message: "Apply cancelled", Objects: []AuditObject{
+				{Version: "v1", Kind: "Widget", Name: "w", Namespace: "shop", ManifestHash: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(`{"apiVersion":"v1","kind":"Widget","metadata":{"name":"w","namespace":"shop"}}`)))},
+			}},
+		},
+		{
+			name:      "async call",
+			namespace: "k-mcp-audit",
+			tool:      "resource_apply",
+			arguments: `{"resourceYAML": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: shop\n", "async": true}`,
+			result: &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "Started operation op-1"}},
+				StructuredContent: &ResourceApplyResult{OperationID: "op-1"},
+			},
+			expected: &AuditEntry{Tool: "resource_apply", Outcome: AuditStarted, Message: "Started operation op-1", OperationID: "op-1", Objects: []AuditObject{
+				{Version: "v1", Resource: "namespaces", Kind: "Namespace", Name: "shop", ManifestHash: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"shop"}}`)))},
+			}},
+		},
+		{
+			name:      "failed call",
+			namespace: "k-mcp-audit",
+			tool:      "take_ownership",
+			arguments: `{"resource": "deployment", "name": "web", "namespace": "shop", "fieldPaths": ["spec.replicas"]}`,
+			result:    &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "forbidden"}}},
+			expected: &AuditEntry{Tool: "take_ownership", Outcome: AuditFailed, Message: "forbidden", Objects: []AuditObject{
+				{Group: "apps", Version: "v1", Resource: "deployments", Name: "web", Namespace: "shop"},
+			}},
+		},
+		{
+			name:      "pod eviction",
+			namespace: "k-mcp-audit",
+			tool:      "pod_evict",
+			arguments: `{"name": "web-1", "namespace": "shop"}`,
+			result:    &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Evicted pod shop/web-1"}}},
+			expected: &AuditEntry{Tool: "pod_evict", Outcome: AuditSucceeded, Message: "Evicted pod shop/web-1", Objects: []AuditObject{
+				{Version: "v1", Kind: "Pod", Resource: "pods", Name: "web-1", Namespace: "shop"},
+			}},
+		},
+		{
+			name:      "protocol error",
+			namespace: "k-mcp-audit",
+			tool:      "resource_apply",
+			arguments: `{}`,
+			err:       fmt.Errorf("invalid params"),
+			expected:  &AuditEntry{Tool: "resource_apply", Outcome: AuditFailed, Message: "invalid params"},
+		},
+		{
+			name:      "read-only tool",
+			namespace: "k-mcp-audit",
+			tool:      "resource_get",
+			result:    &mcp.CallToolResult{},
+		},
+		{
+			name:   "disabled",
+			tool:   "resource_apply",
+			result: &mcp.CallToolResult{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, dynamicClient := newTestAuditSink(tt.namespace)
+			audit := newTestAuditLog()
+			if tt.namespace != "" {
+				audit = newTestAuditLog(sink)
+			}
+			handler := audit.middleware()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return tt.result, nil
+			})
+			if _, err := handler(context.Background(), methodCallTool, auditTestRequest(tt.tool, tt.arguments)); err != tt.err {
+				t.Fatalf("expected the error of the call, got %v", err)
+			}
+
+			configMaps, err := dynamicClient.Resource(configMapsGVR).Namespace(tt.namespace).List(context.Background(), v1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.expected == nil {
+				if len(configMaps.Items) != 0 {
+					t.Errorf("expected no audit entry, got %d ConfigMaps", len(configMaps.Items))
+				}
+				return
+			}
+			entries := auditTestEntries(t, dynamicClient, tt.namespace, auditConfigMapName)
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 entry, got %d", len(entries))
+			}
+			entry := entries[0]
+			if entry.Tool != tt.expected.Tool || entry.Outcome != tt.expected.Outcome || entry.Message != tt.expected.Message || entry.OperationID != tt.expected.OperationID || !reflect.DeepEqual(entry.Objects, tt.expected.Objects) {
+				t.Errorf("expected entry %+v, got %+v", *tt.expected, entry)
+			}
+			if entry.Subject != "system:serviceaccount:agents:bot" || entry.APIServerURL != "https://cluster.example.com" {
+				t.Errorf("expected the subject and API server of the call, got %+v", entry)
+			}
+
+			events, err := dynamicClient.Resource(eventsGVR).Namespace(tt.namespace).List(context.Background(), v1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events.Items) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(events.Items))
+			}
+			message, _, _ := unstructured.NestedString(events.Items[0].Object, "message")
+			if !strings.Contains(message, "system:serviceaccount:agents:bot called "+tt.tool) {
+				t.Errorf("unexpected event message %q", message)
+			}
+		})
+	}
+}
+
+func TestAuditRecordOperation(t *testing.T) {
+	arguments := `{"resourceYAML": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: shop\n", "async": true}`
+	applied := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Applied namespace/shop"}}}
+
+	tests := []struct {
+		name       string
+		result     *mcp.CallToolResult
+		structured any
+		err        error
+		status     string
+		expected   string
+	}{
+		{name: "succeeded", result: applied, structured: &ResourceApplyResult{}, status: OperationSucceeded, expected: AuditSucceeded},
+		{name: "declined", result: applied, structured: &ResourceApplyResult{CancellationReason: "declined"}, status: OperationSucceeded, expected: AuditCancelled},
+		{name: "timed out", err: context.DeadlineExceeded, status: OperationFailed, expected: AuditFailed},
+		{name: "cancelled", err: context.Canceled, status: OperationCancelled, expected: AuditCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, dynamicClient := newTestAuditSink("k-mcp-audit")
+			audit := newTestAuditLog(sink)
+			audit.recordOperation(context.Background(), auditTestRequest("resource_apply", arguments), tt.result, tt.structured, tt.err, Operation{ID: "op-1", Status: tt.status})
+
+			entries := auditTestEntries(t, dynamicClient, "k-mcp-audit", auditConfigMapName)
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 entry, got %d", len(entries))
+			}
+			if entries[0].Outcome != tt.expected || entries[0].OperationID != "op-1" {
+				t.Errorf("expected outcome %s of operation op-1, got %+v", tt.expected, entries[0])
+			}
+		})
+	}
+}
+
+func TestAuditRotation(t *testing.T) {
+	sink, dynamicClient := newTestAuditSink("k-mcp-audit")
+	request := auditTestRequest("resource_apply", "")
+	for i := 0; i < auditEntriesPerConfigMap*(auditArchivedConfigMaps+1)+1; i++ {
+		entry := AuditEntry{Time: time.Unix(int64(i), 0).UTC(), Tool: "resource_apply", Outcome: AuditSucceeded, Message: fmt.Sprintf("apply %d", i)}
+		if err := sink.write(context.Background(), request.Extra.TokenInfo, entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The archives wrapped around, the first one now has the entries of the last rotation.
+	if got := len(auditTestEntries(t, dynamicClient, "k-mcp-audit", auditConfigMapName)); got != 1 {
+		t.Errorf("expected 1 entry after the rotation, got %d", got)
+	}
+	archived := auditTestEntries(t, dynamicClient, "k-mcp-audit", auditConfigMapName+"-0")
+	if len(archived) != auditEntriesPerConfigMap {
+		t.Fatalf("expected %d archived entries, got %d", auditEntriesPerConfigMap, len(archived))
+	}
+	if first := fmt.Sprintf("apply %d", auditEntriesPerConfigMap*auditArchivedConfigMaps); archived[0].Message != first {
+		t.Errorf("expected the overwritten archive to start with %q, got %q", first, archived[0].Message)
+	}
+	configMaps, err := dynamicClient.Resource(configMapsGVR).Namespace("k-mcp-audit").List(context.Background(), v1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configMaps.Items) != auditArchivedConfigMaps+1 {
+		t.Errorf("expected %d ConfigMaps, got %d", auditArchivedConfigMaps+1, len(configMaps.Items))
+	}
+}
+
+// auditTestEntries returns the entries of a ConfigMap, sorted by key.
+func auditTestEntries(t *testing.T, dynamicClient dynamic.Interface, namespace, name string) []AuditEntry {
+	t.Helper()
+	configMap, err := dynamicClient.Resource(configMapsGVR).Namespace(namespace).Get(context.Background(), name, v1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _, _ := unstructured.NestedStringMap(configMap.Object, "data")
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	entries := make([]AuditEntry, 0, len(keys))
+	for _, key := range keys {
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(data[key]), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditExternalSinks(t *testing.T) {
+	entry := AuditEntry{
+		Time:         time.Unix(0, 0).UTC(),
+		Tool:         "resource_apply",
+		Subject:      "system:serviceaccount:agents:bot",
+		APIServerURL: "https://cluster.example.com",
+		Outcome:      AuditSucceeded,
+		Objects:      []AuditObject{{Group: "apps", Version: "v1", Resource: "deployments", Kind: "Deployment", Name: "web", Namespace: "shop"}},
+	}
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		for range 2 {
+			file, err := newAuditFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := file.write(context.Background(), nil, entry); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := file.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// The entries of the previous runs are kept.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 lines, got %q", data)
+		}
+		var got AuditEntry
+		if err := json.Unmarshal([]byte(lines[1]), &got); err != nil || !reflect.DeepEqual(got, entry) {
+			t.Errorf("expected entry %+v, got %+v (%v)", entry, got, err)
+		}
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		var received []AuditEntry
+		status := http.StatusOK
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var got AuditEntry
+			if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&got) != nil {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			received = append(received, got)
+			w.WriteHeader(status)
+		}))
+		defer server.Close()
+
+		webhook := newAuditWebhook(server.URL)
+		if err := webhook.write(context.Background(), nil, entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(received) != 1 || !reflect.DeepEqual(received[0], entry) {
+			t.Errorf("expected entry %+v to be posted, got %+v", entry, received)
+		}
+
+		status = http.StatusServiceUnavailable
+		if err := webhook.write(context.Background(), nil, entry); err == nil {
+			t.Errorf("expected the rejected entry to fail")
+		}
+	})
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// ResourceListGroup holds the resources of a type of a listed category.
+type ResourceListGroup struct {
+	// Resource is the resource type, as accepted by the resource argument of the tools.
+	Resource  string                   `json:"resource"`
+	Resources []map[string]interface{} `json:"resources"`
+	// Truncated tells how the resources were truncated to the maximum result size, when they were.
+	Truncated *ListTruncation `json:"truncated,omitempty"`
+	// Error is why the resources of the type could not be listed, e.g. forbidden.
+	Error string `json:"error,omitempty"`
+}
+
+// categoryMembers returns the resources of the category named by a resource argument, like all,
+// unless a resource type has that name.
+func categoryMembers(resource string, discoveryClient discovery.CachedDiscoveryInterface) ([]resourceMatch, bool, error) {
+	index, err := resourceIndexFor(discoveryClient)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(index.lookup(resource)) > 0 {
+		return nil, false, nil
+	}
+	members := index.byCategory[strings.ToLower(resource)]
+	return members, len(members) > 0, nil
+}
+
+// listCategory lists the resources of every type of a category, as kubectl get does, grouped per type.
+// The types that can not be listed are reported in their group rather than failing the call.
+func (s *Server) listCategory(ctx context.Context, request *mcp.CallToolRequest, dynamicConfig *DynamicConfig, dynamicClient dynamic.Interface, members []resourceMatch, input ResourceListInput) (*mcp.CallToolResult, *ResourceListResult, error) {
+	if input.Limit > 0 || input.Continue != "" {
+		return nil, nil, fmt.Errorf("limit and continue can not be used with the category %q, list its resource types one by one to paginate", input.Resource)
+	}
+
+	result := &ResourceListResult{
+		Resources:    []map[string]interface{}{},
+		APIServerURL: requestAPIServerURL(request),
+		Cluster:      requestCluster(request),
+	}
+	var found, failed []string
+	truncated := false
+	size := 0
+	for _, member := range members {
+		if input.Namespace != "" && !member.namespaced {
+			continue
+		}
+		group := ResourceListGroup{Resource: resourceArgument(member.gvr)}
+		objects, _, err := s.listObjects(ctx, request, dynamicConfig, dynamicClient, member.gvr, input)
+		if err != nil {
+			group.Error = err.Error()
+			group.Resources = []map[string]interface{}{}
+			result.Groups = append(result.Groups, group)
+			failed = append(failed, group.Resource)
+			continue
+		}
+		// The types share the maximum result size in their order.
+		if s.MaxResultSize > 0 && len(objects) > 0 {
+			objects, group.Truncated = truncateObjects(objects, max(s.MaxResultSize-size, 1))
+			size += objectsSize(objects)
+			truncated = truncated || group.Truncated != nil
+		}
+		group.Resources = objects
+		result.Groups = append(result.Groups, group)
+		if len(objects) > 0 {
+			found = append(found, fmt.Sprintf("%d %s", len(objects), group.Resource))
+		}
+	}
+
+	message := fmt.Sprintf("Listed the resources of category '%s'", input.Resource)
+	if input.LabelSelector != "" {
+		message += fmt.Sprintf(" with label selector '%s'", input.LabelSelector)
+	}
+	if input.Namespace != "" {
+		message += fmt.Sprintf(" in namespace '%s'", input.Namespace)
+	}
+	message += clusterSuffix(request)
+	if len(found) == 0 {
+		message += ": none found"
+	} else {
+		message += ": " + strings.Join(found, ", ")
+	}
+	if len(failed) > 0 {
+		message += fmt.Sprintf(". Failed to list %s, see the errors of their groups", strings.Join(failed, ", "))
+		notifyClient(ctx, request.Session, "warning", fmt.Sprintf("Skipped %s of category %s, which could not be listed", strings.Join(failed, ", "), input.Resource))
+	}
+	if truncated {
+		message += ". The result exceeded the maximum size and was truncated, list the resource types one by one with a limit to get every resource"
+		notifyClient(ctx, request.Session, "warning", fmt.Sprintf("The %s list exceeded the maximum result size and was truncated", input.Resource))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: message}},
+	}, result, nil
+}
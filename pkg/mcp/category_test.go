@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestListCategory(t *testing.T) {
+	discoveryClient := cmdtesting.NewFakeCachedDiscoveryClient()
+	discoveryClient.PreferredResources = []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true, Categories: []string{"all"}},
+				{Name: "services", Kind: "Service", Namespaced: true, Categories: []string{"all"}},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []v1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Categories: []string{"all"}},
+			},
+		},
+	}
+
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1", "kind": "Pod", "metadata": map[string]interface{}{"name": "web-0", "namespace": "shop"},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "pods"}:                       "PodList",
+		{Version: "v1", Resource: "services"}:                   "ServiceList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+	}, pod)
+	dynamicClient.PrependReactor("list", "services", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "services"}, "", nil)
+	})
+
+	if _, ok, err := categoryMembers("configmaps", discoveryClient); err != nil || ok {
+		t.Fatalf("expected a resource type not to be a category, got %v %v", ok, err)
+	}
+	members, ok, err := categoryMembers("all", discoveryClient)
+	if err != nil || !ok || len(members) != 3 {
+		t.Fatalf("expected the 3 resources of category all, got %+v %v", members, err)
+	}
+
+	s := NewServer("8080", "k-mcp")
+	request := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "resource_list"}}
+	if _, _, err := s.listCategory(context.TODO(), request, nil, dynamicClient, members, ResourceListInput{Resource: "all", Limit: 10}); err == nil {
+		t.Errorf("expected the pagination of a category to be refused")
+	}
+
+	toolResult, result, err := s.listCategory(context.TODO(), request, nil, dynamicClient, members, ResourceListInput{Resource: "all", Namespace: "shop", OutputMode: OutputModeSummary})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Groups) != 3 {
+		t.Fatalf("expected 3 groups, got %+v", result.Groups)
+	}
+	for _, group := range result.Groups {
+		switch group.Resource {
+		case "pods":
+			if len(group.Resources) != 1 || group.Resources[0]["name"] != "web-0" {
+				t.Errorf("expected the pod, got %+v", group.Resources)
+			}
+		case "services":
+			if group.Error == "" {
+				t.Errorf("expected the error of the forbidden services")
+			}
+		case "deployments.v1.apps":
+			if len(group.Resources) != 0 || group.Error != "" {
+				t.Errorf("expected no deployment, got %+v", group)
+			}
+		default:
+			t.Errorf("unexpected group %s", group.Resource)
+		}
+	}
+	if text := toolResult.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "1 pods") || !strings.Contains(text, "Failed to list services") {
+		t.Errorf("expected the counts and the failures in the message, got %q", text)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// maxClientCacheSize bounds the number of cached clients, the least recently used being evicted first.
+const maxClientCacheSize = 256
+
+// cachedClients are the clients of an API server for a bearer token.
+type cachedClients struct {
+	key             [sha256.Size]byte
+	dynamicClient   *dynamic.DynamicClient
+	discoveryClient discovery.CachedDiscoveryInterface
+	// expiration is when the token expires. Zero means the clients do not expire.
+	expiration time.Time
+}
+
+// clientCache keeps the clients of the API servers across the tool calls of a token, sparing the
+// building of the clients and the reading of the discovery from disk on every call.
+type clientCache struct {
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	lru     *list.List
+}
+
+func newClientCache() *clientCache {
+	return &clientCache{
+		entries: make(map[[sha256.Size]byte]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func clientCacheKey(bearerToken, apiServerUrl string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(apiServerUrl + "\x00" + bearerToken))
+}
+
+// get returns the clients of the key unless they expired.
+func (c *clientCache) get(key [sha256.Size]byte) (*cachedClients, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	clients := element.Value.(*cachedClients)
+	if !clients.expiration.IsZero() && !time.Now().Before(clients.expiration) {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return clients, true
+}
+
+// add caches the clients, evicting the least recently used ones when the cache is full.
+func (c *clientCache) add(clients *cachedClients) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[clients.key]; ok {
+		element.Value = clients
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[clients.key] = c.lru.PushFront(clients)
+	for c.lru.Len() > maxClientCacheSize {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedClients).key)
+	}
+}
+
+// purge drops every cached client, e.g. when the TLS settings of the API servers change.
+func (c *clientCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[[sha256.Size]byte]*list.Element)
+	c.lru.Init()
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+func TestClientCache(t *testing.T) {
+	c := newClientCache()
+	for i := range maxClientCacheSize + 1 {
+		c.add(&cachedClients{key: clientCacheKey(fmt.Sprintf("token-%d", i), "https://cluster.example.com")})
+		if i == maxClientCacheSize-1 {
+			// The first clients are used again once the cache is full, the second ones are the least recently used.
+			if _, ok := c.get(clientCacheKey("token-0", "https://cluster.example.com")); !ok {
+				t.Fatalf("expected the clients to be cached")
+			}
+		}
+	}
+	if _, ok := c.get(clientCacheKey("token-1", "https://cluster.example.com")); ok {
+		t.Errorf("expected the least recently used clients to be evicted")
+	}
+	if _, ok := c.get(clientCacheKey("token-0", "https://cluster.example.com")); !ok {
+		t.Errorf("expected the recently used clients to be kept")
+	}
+	if _, ok := c.get(clientCacheKey("token-0", "https://other.example.com")); ok {
+		t.Errorf("expected the clients of another API server not to be shared")
+	}
+
+	expired := clientCacheKey("expired", "https://cluster.example.com")
+	c.add(&cachedClients{key: expired, expiration: time.Now().Add(-time.Second)})
+	if _, ok := c.get(expired); ok {
+		t.Errorf("expected the clients of an expired token not to be returned")
+	}
+
+	c.purge()
+	if _, ok := c.get(clientCacheKey("token-0", "https://cluster.example.com")); ok {
+		t.Errorf("expected the purge to drop every client")
+	}
+}
+
+func TestLoadRestConfigForTokenInfoCached(t *testing.T) {
+	d := NewDynamicConfig("", false, "")
+	d.DisableDiscoveryCache = true
+	tokenInfo := func(token string) *auth.TokenInfo {
+		return &auth.TokenInfo{
+			Expiration: time.Now().Add(time.Hour),
+			Extra:      map[string]any{"audience": "https://cluster.example.com", "bearer_token": token},
+		}
+	}
+
+	first, _, err := d.LoadRestConfigForTokenInfo(tokenInfo("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := d.LoadRestConfigForTokenInfo(tokenInfo("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("expected the clients of the token to be reused")
+	}
+	other, _, err := d.LoadRestConfigForTokenInfo(tokenInfo("other-token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == first {
+		t.Errorf("expected the clients of another token not to be shared")
+	}
+
+	d.setAPIServerTLS(map[string]APIServerTLS{"cluster.example.com": {InsecureSkipVerify: true}})
+	reloaded, _, err := d.LoadRestConfigForTokenInfo(tokenInfo("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded == first {
+		t.Errorf("expected the clients to be built again when the TLS settings change")
+	}
+}
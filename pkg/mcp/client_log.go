@@ -0,0 +1,40 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// clientLogger is the logger of the log notifications sent to the clients.
+const clientLogger = "k-mcp"
+
+// notifyClient sends an event the user should know about, like a fallback to a default or a
+// truncated result, to the client of the session as a log notification, since the user never sees
+// the logs of k-mcp. Nothing is sent until the client sets its logging level with logging/setLevel,
+// nor below that level.
+func notifyClient(ctx context.Context, session *mcp.ServerSession, level mcp.LoggingLevel, message string) {
+	if session == nil {
+		return
+	}
+	if err := session.Log(ctx, &mcp.LoggingMessageParams{Level: level, Logger: clientLogger, Data: message}); err != nil {
+		slog.DebugContext(ctx, "Failed to send log notification", "session_id", session.ID(), "err", err)
+	}
+}
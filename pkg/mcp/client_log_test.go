@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestNotifyClient(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "notify"}, func(ctx context.Context, request *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		notifyClient(ctx, request.Session, "info", "informational")
+		notifyClient(ctx, request.Session, "warning", "truncated")
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	messages := make(chan *mcp.LoggingMessageParams, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			messages <- req.Params
+		},
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	// Nothing is sent until the client sets its level.
+	if _, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "notify"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := clientSession.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "warning"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "notify"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case message := <-messages:
+		if message.Level != "warning" || message.Logger != clientLogger || message.Data != "truncated" {
+			t.Errorf("unexpected log notification %+v", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a log notification")
+	}
+	select {
+	case message := <-messages:
+		t.Errorf("expected a single log notification, got %+v", message)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
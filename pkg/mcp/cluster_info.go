@@ -0,0 +1,206 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/utils/ptr"
+
+	"github.com/ardaguclu/k-mcp/pkg/version"
+)
+
+const (
+	clientGoModule = "k8s.io/client-go"
+	// maxMinorVersionSkew is the largest supported difference between the minor versions
+	// of client-go and the API server. Larger skews are reported as warnings.
+	maxMinorVersionSkew = 1
+	// methodInitialized is the notification sent by clients once the session is initialized.
+	methodInitialized = "notifications/initialized"
+)
+
+// clientKubernetesVersion returns the Kubernetes minor version matching the compiled client-go
+// (client-go v0.X.Y is released with Kubernetes 1.X), or zero when it can not be determined.
+func clientKubernetesVersion() (string, int) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", 0
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != clientGoModule {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		parts := strings.Split(strings.TrimPrefix(dep.Version, "v"), ".")
+		if len(parts) < 2 || parts[0] != "0" {
+			return dep.Version, 0
+		}
+		minor, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return dep.Version, 0
+		}
+		return dep.Version, minor
+	}
+	return "", 0
+}
+
+// parseMinorVersion parses the minor version reported by the API server,
+// which may carry a suffix on managed offerings (e.g. "31+").
+func parseMinorVersion(minor string) int {
+	minor = strings.TrimRight(minor, "+")
+	value, err := strconv.Atoi(minor)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// versionSkewWarning returns a warning when the compiled client-go and the API server
+// are more than maxMinorVersionSkew minor versions apart.
+func versionSkewWarning(clientMinor, serverMajor, serverMinor int) string {
+	if clientMinor == 0 || serverMinor == 0 || serverMajor != 1 {
+		return ""
+	}
+	skew := serverMinor - clientMinor
+	if skew >= -maxMinorVersionSkew && skew <= maxMinorVersionSkew {
+		return ""
+	}
+	direction := "newer"
+	if skew < 0 {
+		direction = "older"
+		skew = -skew
+	}
+	return fmt.Sprintf("API server version 1.%d is %d minor versions %s than the client version 1.%d of k-mcp, some APIs may behave differently",
+		serverMinor, skew, direction, clientMinor)
+}
+
+type ClusterInfoInput struct{}
+
+type ClusterInfoResult struct {
+	APIServerURL  string `json:"apiServerUrl"`
+	Cluster       string `json:"cluster"`
+	ServerVersion string `json:"serverVersion"`
+	Platform      string `json:"platform,omitempty"`
+	ClientVersion string `json:"clientVersion,omitempty"`
+	KMCPVersion   string `json:"kMcpVersion"`
+	Warning       string `json:"warning,omitempty"`
+	// Reachability is the result of the last reachability probe of the API server.
+	Reachability *Reachability `json:"reachability,omitempty"`
+}
+
+func (s *Server) addClusterInfoTool(server *mcp.Server, dynamicConfig *DynamicConfig, prober *reachabilityProber) {
+	addTool(server, &mcp.Tool{
+		Name: "cluster_info",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Show the cluster information",
+		},
+		Description: "Show the API server URL, the name used in k8s:// resource URIs and the version of the cluster, the Kubernetes client version of the server, and a warning when they are too far apart",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ClusterInfoInput) (*mcp.CallToolResult, *ClusterInfoResult, error) {
+		_, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		apiServerURL := request.Extra.TokenInfo.Extra["audience"].(string)
+		serverVersion, err := discoveryClient.ServerVersion()
+		if err != nil {
+			// Tell connectivity problems, like an untrusted CA or an unknown host, from API errors.
+			if reason := reachabilityError(prober.check(ctx, apiServerURL)); reason != "" {
+				return nil, nil, fmt.Errorf("failed to get server version, %s", reason)
+			}
+			return nil, nil, fmt.Errorf("failed to get server version: %w", err)
+		}
+
+		clientVersion, clientMinor := clientKubernetesVersion()
+		serverMajor, _ := strconv.Atoi(serverVersion.Major)
+		result := &ClusterInfoResult{
+			APIServerURL:  apiServerURL,
+			Cluster:       clusterName(request.Extra.TokenInfo),
+			ServerVersion: serverVersion.GitVersion,
+			Platform:      serverVersion.Platform,
+			ClientVersion: clientVersion,
+			KMCPVersion:   version.Get().Version,
+			Warning:       versionSkewWarning(clientMinor, serverMajor, parseMinorVersion(serverVersion.Minor)),
+			Reachability:  prober.get(apiServerURL),
+		}
+
+		message := fmt.Sprintf("Connected to %s running Kubernetes %s", result.APIServerURL, result.ServerVersion)
+		if result.Warning != "" {
+			message += fmt.Sprintf(". Warning: %s", result.Warning)
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, result, nil
+	})
+}
+
+// versionSkewMiddleware checks the version of the API server when a client finishes
+// initializing its session, and warns the client about an unsupported version skew.
+func versionSkewMiddleware(dynamicConfig *DynamicConfig) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if method != methodInitialized || err != nil {
+				return result, err
+			}
+			extra := req.GetExtra()
+			session, ok := req.GetSession().(*mcp.ServerSession)
+			if extra == nil || extra.TokenInfo == nil || !ok {
+				return result, err
+			}
+
+			// Don't hold the notification while the API server is contacted.
+			go func() {
+				_, discoveryClient, err := dynamicConfig.LoadRestConfigForTokenInfo(extra.TokenInfo)
+				if err != nil {
+					return
+				}
+				serverVersion, err := discoveryClient.ServerVersion()
+				if err != nil {
+					slog.WarnContext(ctx, "Failed to check the API server version", "session_id", session.ID(), "err", err)
+					return
+				}
+				_, clientMinor := clientKubernetesVersion()
+				serverMajor, _ := strconv.Atoi(serverVersion.Major)
+				warning := versionSkewWarning(clientMinor, serverMajor, parseMinorVersion(serverVersion.Minor))
+				if warning == "" {
+					return
+				}
+				slog.WarnContext(ctx, "Version skew detected", "session_id", session.ID(), "warning", warning)
+				notifyClient(context.Background(), session, "warning", warning)
+			}()
+			return result, err
+		}
+	}
+}
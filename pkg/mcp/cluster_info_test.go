@@ -0,0 +1,61 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import "testing"
+
+func TestVersionSkewWarning(t *testing.T) {
+	tests := []struct {
+		name        string
+		clientMinor int
+		serverMajor int
+		serverMinor int
+		expected    string
+	}{
+		{name: "same version", clientMinor: 34, serverMajor: 1, serverMinor: 34},
+		{name: "one minor older", clientMinor: 34, serverMajor: 1, serverMinor: 33},
+		{name: "one minor newer", clientMinor: 34, serverMajor: 1, serverMinor: 35},
+		{
+			name:        "two minors older",
+			clientMinor: 34, serverMajor: 1, serverMinor: 32,
+			expected: "API server version 1.32 is 2 minor versions older than the client version 1.34 of k-mcp, some APIs may behave differently",
+		},
+		{
+			name:        "three minors newer",
+			clientMinor: 34, serverMajor: 1, serverMinor: 37,
+			expected: "API server version 1.37 is 3 minor versions newer than the client version 1.34 of k-mcp, some APIs may behave differently",
+		},
+		{name: "unknown client version", clientMinor: 0, serverMajor: 1, serverMinor: 20},
+		{name: "unknown server version", clientMinor: 34, serverMajor: 1, serverMinor: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if warning := versionSkewWarning(tt.clientMinor, tt.serverMajor, tt.serverMinor); warning != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, warning)
+			}
+		})
+	}
+}
+
+func TestParseMinorVersion(t *testing.T) {
+	for minor, expected := range map[string]int{"31": 31, "31+": 31, "": 0, "x": 0} {
+		if value := parseMinorVersion(minor); value != expected {
+			t.Errorf("minor %q: expected %d, got %d", minor, expected, value)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Routing policies of the tool calls without cluster argument, when the token grants several clusters
+// and the session has no default cluster.
+const (
+	// ClusterRoutingAsk asks the user for the cluster, which becomes the default cluster of the
+	// session. It is the policy of the tools without one.
+	ClusterRoutingAsk = "ask"
+	// ClusterRoutingPrimary runs the calls against the cluster of the audience of the token.
+	ClusterRoutingPrimary = "primary"
+	// ClusterRoutingHealthiest runs the calls against the reachable cluster whose API server answered
+	// its last reachability probe the fastest, the primary cluster first on ties.
+	ClusterRoutingHealthiest = "healthiest"
+	// ClusterRoutingAll runs the calls against every granted cluster, returning the result of each
+	// cluster. Only the fanOutTools can be routed to every cluster.
+	ClusterRoutingAll = "all"
+)
+
+// clusterRoutingPolicies are the valid routing policies.
+var clusterRoutingPolicies = []string{ClusterRoutingAsk, ClusterRoutingPrimary, ClusterRoutingHealthiest, ClusterRoutingAll}
+
+// fanOutTools are the read-only tools whose calls can run against every granted cluster.
+var fanOutTools = []string{"resource_list", "resource_get"}
+
+// maxFanOutConcurrency bounds the clusters a fanned out call runs against at a time.
+const maxFanOutConcurrency = 4
+
+// ClusterResult is the result of a call run against every granted cluster in one of them.
+type ClusterResult struct {
+	Cluster      string `json:"cluster"`
+	APIServerURL string `json:"apiServerUrl"`
+	// Result is the structured result of the call in the cluster, as returned by a call against it.
+	Result any `json:"result,omitempty"`
+	// Error is why the call failed in the cluster, the other clusters answering anyway.
+	Error string `json:"error,omitempty"`
+}
+
+// ValidateClusterRouting validates the routing policies of the tools.
+func ValidateClusterRouting(routing map[string]string) error {
+	for _, tool := range slices.Sorted(maps.Keys(routing)) {
+		policy := routing[tool]
+		if !slices.Contains(clusterRoutingPolicies, policy) {
+			return fmt.Errorf("invalid cluster routing policy %q of tool %s, must be one of: %s", policy, tool, strings.Join(clusterRoutingPolicies, ", "))
+		}
+		if !knownTool(tool) {
+			return fmt.Errorf("unknown tool %q in the cluster routing policies", tool)
+		}
+		// A change must not land on whichever cluster happens to answer first.
+		if policy == ClusterRoutingHealthiest && slices.Contains(mutatingTools, tool) {
+			return fmt.Errorf("tool %s changes the state of the clusters, it can not be routed to the healthiest cluster", tool)
+		}
+		if policy == ClusterRoutingAll && !slices.Contains(fanOutTools, tool) {
+			return fmt.Errorf("tool %s can not be routed to every cluster, only %s can", tool, strings.Join(fanOutTools, " and "))
+		}
+	}
+	return nil
+}
+
+// knownTool tells whether a tool belongs to a toolset.
+func knownTool(name string) bool {
+	for _, tools := range toolsets {
+		if slices.Contains(tools, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeCluster returns the API server the routing policy of the tool selects among the clusters
+// granted by the token, with the policy. The policy is returned alone when the call runs against
+// every cluster. Empty means the tool has no routing policy, and the user is asked for the cluster.
+func (a *clusterAccess) routeCluster(ctx context.Context, tool string, tokenInfo *auth.TokenInfo, granted []string) (string, string) {
+	policy := a.routing[tool]
+	primary, _ := tokenInfo.Extra["audience"].(string)
+	if !slices.Contains(granted, primary) {
+		primary = granted[0]
+	}
+	switch policy {
+	case ClusterRoutingPrimary:
+		return primary, policy
+	case ClusterRoutingHealthiest:
+		return a.healthiest(ctx, primary, granted), policy
+	case ClusterRoutingAll:
+		return "", policy
+	}
+	return "", ""
+}
+
+// healthiest returns the reachable API server which answered its last reachability probe the fastest,
+// probing the ones not probed yet. The primary API server is returned when none is reachable, so that
+// the call fails with the reason it can not be reached.
+func (a *clusterAccess) healthiest(ctx context.Context, primary string, granted []string) string {
+	if a.prober == nil {
+		return primary
+	}
+	candidates := append([]string{primary}, slices.DeleteFunc(slices.Clone(granted), func(apiServerURL string) bool {
+		return apiServerURL == primary
+	})...)
+
+	results := make([]*Reachability, len(candidates))
+	var wg sync.WaitGroup
+	for i, apiServerURL := range candidates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = a.prober.latest(ctx, apiServerURL)
+		}()
+	}
+	wg.Wait()
+
+	var best *Reachability
+	for _, result := range results {
+		if result.Reachable && (best == nil || result.LatencyMilliseconds < best.LatencyMilliseconds) {
+			best = result
+		}
+	}
+	if best == nil {
+		return primary
+	}
+	return best.APIServerURL
+}
+
+// routedResult tells which cluster the routing policy selected in the result of a tool call.
+func (a *clusterAccess) routedResult(result mcp.Result, policy, apiServerURL string) {
+	toolResult, ok := result.(*mcp.CallToolResult)
+	if !ok || toolResult == nil {
+		return
+	}
+	toolResult.Content = append(toolResult.Content, &mcp.TextContent{
+		Text: fmt.Sprintf("The call ran against cluster %s, selected by the %s cluster routing policy of the tool. Pass the cluster argument to run it against another cluster", a.displayName(apiServerURL), policy),
+	})
+}
+
+// fanOut runs a tool call against every granted cluster, at most maxFanOutConcurrency at a time. The
+// clusters failing are reported with their error next to the results of the others, the call only
+// failing when every cluster fails.
+func (a *clusterAccess) fanOut(ctx context.Context, next mcp.MethodHandler, method string, request *mcp.CallToolRequest, granted []string) (mcp.Result, error) {
+	results := make([]ClusterResult, len(granted))
+	texts := make([]string, len(granted))
+	limit := make(chan struct{}, maxFanOutConcurrency)
+	var wg sync.WaitGroup
+	for i, apiServerURL := range granted {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			results[i], texts[i] = a.callCluster(ctx, next, method, request, apiServerURL)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	content := make([]mcp.Content, 0, len(results))
+	for i, result := range results {
+		if result.Error != "" {
+			failed++
+			content = append(content, &mcp.TextContent{Text: fmt.Sprintf("Cluster %s failed: %s", result.Cluster, result.Error)})
+			continue
+		}
+		content = append(content, &mcp.TextContent{Text: fmt.Sprintf("Cluster %s: %s", result.Cluster, texts[i])})
+	}
+
+	// The fields of the results without fan-out are required by the output schemas of the tools.
+	structured := map[string]any{"clusters": results}
+	switch request.Params.Name {
+	case "resource_list":
+		structured["resources"] = []any{}
+	case "resource_get":
+		structured["resource"] = map[string]any{}
+	}
+	return &mcp.CallToolResult{
+		Content:           content,
+		StructuredContent: structured,
+		IsError:           failed == len(results),
+	}, nil
+}
+
+// callCluster runs a tool call of a fan out against a cluster, returning its result and its text.
+func (a *clusterAccess) callCluster(ctx context.Context, next mcp.MethodHandler, method string, request *mcp.CallToolRequest, apiServerURL string) (ClusterResult, string) {
+	result := ClusterResult{Cluster: a.displayName(apiServerURL), APIServerURL: apiServerURL}
+	tokenInfo, err := a.tokenInfoFor(ctx, request.Extra.TokenInfo, apiServerURL)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to authenticate: %v", err)
+		return result, ""
+	}
+
+	// The calls run concurrently, each with its own request.
+	params := *request.Params
+	extra := *request.Extra
+	extra.TokenInfo = tokenInfo
+	clusterRequest := *request
+	clusterRequest.Params = &params
+	clusterRequest.Extra = &extra
+	called, err := next(ctx, method, &clusterRequest)
+	if err != nil {
+		result.Error = err.Error()
+		return result, ""
+	}
+	toolResult, ok := called.(*mcp.CallToolResult)
+	if !ok {
+		result.Error = "unexpected result"
+		return result, ""
+	}
+	var texts []string
+	for _, content := range toolResult.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	if toolResult.IsError {
+		result.Error = strings.Join(texts, "\n")
+		return result, ""
+	}
+	result.Result = toolResult.StructuredContent
+	return result, strings.Join(texts, "\n")
+}
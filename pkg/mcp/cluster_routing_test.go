@@ -0,0 +1,270 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestValidateClusterRouting(t *testing.T) {
+	tests := []struct {
+		name    string
+		routing map[string]string
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "valid", routing: map[string]string{"resource_list": ClusterRoutingHealthiest, "resource_apply": ClusterRoutingPrimary, "pod_diagnose": ClusterRoutingAsk}},
+		{name: "unknown policy", routing: map[string]string{"resource_list": "fastest"}, wantErr: `invalid cluster routing policy "fastest" of tool resource_list`},
+		{name: "unknown tool", routing: map[string]string{"resource_lsit": ClusterRoutingPrimary}, wantErr: `unknown tool "resource_lsit"`},
+		{name: "mutating tool routed by health", routing: map[string]string{"resource_apply": ClusterRoutingHealthiest}, wantErr: "tool resource_apply changes the state of the clusters"},
+		{name: "read tools routed to every cluster", routing: map[string]string{"resource_list": ClusterRoutingAll, "resource_get": ClusterRoutingAll}},
+		{name: "other tool routed to every cluster", routing: map[string]string{"resource_apply": ClusterRoutingAll}, wantErr: "tool resource_apply can not be routed to every cluster, only resource_list and resource_get can"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateClusterRouting(tt.routing)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRouteCluster(t *testing.T) {
+	const (
+		staging = "https://staging.example.com"
+		prodEU  = "https://prod-eu.example.com"
+		prodUS  = "https://prod-us.example.com"
+	)
+
+	tests := []struct {
+		name     string
+		policy   string
+		audience string
+		probed   map[string]*Reachability
+		// unreachable are the API servers whose probe fails, the others answer it.
+		unreachable []string
+		expected    string
+	}{
+		{name: "no policy", audience: staging},
+		{name: "asked", policy: ClusterRoutingAsk, audience: staging},
+		{name: "every cluster", policy: ClusterRoutingAll, audience: staging},
+		{name: "primary", policy: ClusterRoutingPrimary, audience: prodEU, expected: prodEU},
+		{name: "primary not granted", policy: ClusterRoutingPrimary, audience: "https://dev.example.com", expected: staging},
+		{
+			name:     "healthiest",
+			policy:   ClusterRoutingHealthiest,
+			audience: staging,
+			probed: map[string]*Reachability{
+				staging: {APIServerURL: staging, Reachable: true, LatencyMilliseconds: 30},
+				prodEU:  {APIServerURL: prodEU, Reachable: true, LatencyMilliseconds: 10},
+				prodUS:  {APIServerURL: prodUS, Error: "connection refused"},
+			},
+			expected: prodEU,
+		},
+		{
+			name:     "primary first on ties",
+			policy:   ClusterRoutingHealthiest,
+			audience: prodUS,
+			probed: map[string]*Reachability{
+				staging: {APIServerURL: staging, Reachable: true, LatencyMilliseconds: 10},
+				prodEU:  {APIServerURL: prodEU, Reachable: true, LatencyMilliseconds: 10},
+				prodUS:  {APIServerURL: prodUS, Reachable: true, LatencyMilliseconds: 10},
+			},
+			expected: prodUS,
+		},
+		{
+			name:        "clusters not probed yet",
+			policy:      ClusterRoutingHealthiest,
+			audience:    staging,
+			unreachable: []string{staging, prodUS},
+			expected:    prodEU,
+		},
+		{
+			name:     "none reachable",
+			policy:   ClusterRoutingHealthiest,
+			audience: prodEU,
+			probed: map[string]*Reachability{
+				staging: {APIServerURL: staging, Error: "connection refused"},
+				prodEU:  {APIServerURL: prodEU, Error: "connection refused"},
+				prodUS:  {APIServerURL: prodUS, Error: "connection refused"},
+			},
+			expected: prodEU,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prober := newReachabilityProber(nil, nil)
+			prober.probe = func(ctx context.Context, apiServerURL string) error {
+				for _, unreachable := range tt.unreachable {
+					if apiServerURL == unreachable {
+						return fmt.Errorf("connection refused")
+					}
+				}
+				return nil
+			}
+			for apiServerURL, result := range tt.probed {
+				prober.results[apiServerURL] = result
+			}
+			access := &clusterAccess{routing: map[string]string{"resource_list": tt.policy}, prober: prober}
+			tokenInfo := &auth.TokenInfo{Extra: map[string]any{"audience": tt.audience}}
+
+			apiServerURL, policy := access.routeCluster(context.Background(), "resource_list", tokenInfo, []string{staging, prodEU, prodUS})
+			if apiServerURL != tt.expected {
+				t.Errorf("expected cluster %q, got %q", tt.expected, apiServerURL)
+			}
+			if apiServerURL != "" && policy != tt.policy {
+				t.Errorf("expected policy %q, got %q", tt.policy, policy)
+			}
+			if result := prober.get(prodEU); tt.probed == nil && tt.policy == ClusterRoutingHealthiest && (result == nil || !result.Reachable) {
+				t.Errorf("expected the clusters not probed yet to be probed, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestRoutedResult(t *testing.T) {
+	access := &clusterAccess{clusters: []Cluster{{Name: "prod-eu", Server: "https://prod-eu.example.com"}}}
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Found 3 pods"}}}
+	access.routedResult(result, ClusterRoutingHealthiest, "https://prod-eu.example.com")
+	if len(result.Content) != 2 || !strings.Contains(result.Content[1].(*mcp.TextContent).Text, "cluster prod-eu, selected by the healthiest cluster routing policy") {
+		t.Errorf("expected the routed cluster to be reported, got %v", result.Content)
+	}
+}
+
+func TestClusterFanOut(t *testing.T) {
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	access := &clusterAccess{
+		sessions: newSessionContexts(),
+		routing:  map[string]string{"resource_list": ClusterRoutingAll},
+	}
+	server.AddReceivingMiddleware(access.middleware())
+	var inFlight, maxInFlight atomic.Int32
+	type input struct {
+		Namespace string `json:"namespace,omitempty"`
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "resource_list"}, func(ctx context.Context, request *mcp.CallToolRequest, in input) (*mcp.CallToolResult, ResourceListResult, error) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		// Keep the call in flight long enough for the others to overlap it.
+		time.Sleep(10 * time.Millisecond)
+
+		cluster := clusterName(request.Extra.TokenInfo)
+		if in.Namespace == "forbidden" || cluster == "prod-us.example.com" {
+			return nil, ResourceListResult{}, fmt.Errorf("pods is forbidden in cluster %s", cluster)
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Found 1 resources"}}},
+			ResourceListResult{Resources: []map[string]any{{"metadata": map[string]any{"name": cluster}}}, Cluster: cluster}, nil
+	})
+
+	var granted []string
+	for _, name := range []string{"staging", "prod-eu", "prod-us", "dev-1", "dev-2", "dev-3"} {
+		granted = append(granted, "https://"+name+".example.com")
+	}
+	tokenInfo := &auth.TokenInfo{
+		Expiration: time.Now().Add(time.Hour),
+		Extra: map[string]any{
+			"audience": granted[0],
+			"clusters": granted,
+			"token":    "inbound",
+		},
+	}
+	transport := &tokenInfoTransport{Transport: serverTransport, tokenInfo: func() *auth.TokenInfo { return tokenInfo }}
+	if _, err := server.Connect(ctx, transport, nil); err != nil {
+		t.Fatal(err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "resource_list", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("expected the failure of a cluster not to fail the call, got %v", result.Content)
+	}
+	if max := maxInFlight.Load(); max > maxFanOutConcurrency {
+		t.Errorf("expected at most %d clusters called at a time, got %d", maxFanOutConcurrency, max)
+	}
+
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var structured ResourceListResult
+	if err := json.Unmarshal(data, &structured); err != nil {
+		t.Fatal(err)
+	}
+	if len(structured.Clusters) != len(granted) {
+		t.Fatalf("expected the results of %d clusters, got %+v", len(granted), structured.Clusters)
+	}
+	for i, clusterResult := range structured.Clusters {
+		if clusterResult.APIServerURL != granted[i] {
+			t.Errorf("expected the results in the order of the token, got %s at %d", clusterResult.APIServerURL, i)
+		}
+		if clusterResult.Cluster == "prod-us.example.com" {
+			if !strings.Contains(clusterResult.Error, "pods is forbidden") {
+				t.Errorf("expected the error of cluster prod-us, got %+v", clusterResult)
+			}
+			continue
+		}
+		resources, _ := clusterResult.Result.(map[string]any)["resources"].([]any)
+		if clusterResult.Error != "" || len(resources) != 1 {
+			t.Errorf("expected the resources of cluster %s, got %+v", clusterResult.Cluster, clusterResult)
+		}
+	}
+	if text := result.Content[2].(*mcp.TextContent).Text; !strings.Contains(text, "Cluster prod-us.example.com failed: pods is forbidden") {
+		t.Errorf("expected the failure of prod-us to be told, got %q", text)
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "resource_list", Arguments: map[string]any{"namespace": "forbidden"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Errorf("expected the call to fail when every cluster fails, got %v", result.Content)
+	}
+}
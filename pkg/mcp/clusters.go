@@ -0,0 +1,581 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// Authentication modes of the clusters of the registry.
+const (
+	// ClusterAuthToken sends the token of the caller to the API server of the cluster.
+	ClusterAuthToken = "token"
+	// ClusterAuthExchange sends the credential the token of the caller is exchanged for.
+	ClusterAuthExchange = "exchange"
+)
+
+// clusterArgument is the argument added to every tool, selecting the cluster of the call.
+const clusterArgument = "cluster"
+
+// Cluster is a named cluster of the registry, which the tokens and the tool calls can target by
+// name instead of by the URL of its API server.
+type Cluster struct {
+	// Name is the name of the cluster, e.g. prod-eu.
+	Name string `json:"name"`
+	// Server is the https URL of the API server of the cluster.
+	Server string `json:"server"`
+	// CertificateAuthority is the path of the CA certificates verifying the API server.
+	CertificateAuthority string `json:"certificateAuthority,omitempty"`
+	// TLSServerName is the name of the API server verified instead of its host.
+	TLSServerName string `json:"tlsServerName,omitempty"`
+	// InsecureSkipVerify skips the verification of the certificates of the API server.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// Auth is how the callers authenticate to the API server, ClusterAuthToken or ClusterAuthExchange.
+	// Empty means their tokens are exchanged when a credential exchanger is configured.
+	Auth string `json:"auth,omitempty"`
+	// Description tells the models what the cluster is for, e.g. production in Europe.
+	Description string `json:"description,omitempty"`
+}
+
+// LoadClusters reads the cluster registry from the given YAML file.
+func LoadClusters(path string) ([]Cluster, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clusters from %s: %w", path, err)
+	}
+
+	var clusters []Cluster
+	if err := yaml.UnmarshalStrict(data, &clusters); err != nil {
+		return nil, fmt.Errorf("failed to parse clusters from %s: %w", path, err)
+	}
+
+	names := make(map[string]bool, len(clusters))
+	hosts := make(map[string]string, len(clusters))
+	for _, cluster := range clusters {
+		if errs := validation.IsDNS1123Label(cluster.Name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid cluster name %q: %s", cluster.Name, strings.Join(errs, ", "))
+		}
+		if names[cluster.Name] {
+			return nil, fmt.Errorf("cluster %s is defined more than once", cluster.Name)
+		}
+		names[cluster.Name] = true
+		if u, err := url.Parse(cluster.Server); err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("invalid server %q of cluster %s, it must be an https URL", cluster.Server, cluster.Name)
+		}
+		host, err := apiServerHost(cluster.Server)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server %q of cluster %s: %w", cluster.Server, cluster.Name, err)
+		}
+		if other, ok := hosts[host]; ok {
+			return nil, fmt.Errorf("clusters %s and %s have the same server %s", other, cluster.Name, host)
+		}
+		hosts[host] = cluster.Name
+		if cluster.Auth != "" && cluster.Auth != ClusterAuthToken && cluster.Auth != ClusterAuthExchange {
+			return nil, fmt.Errorf("invalid auth %q of cluster %s, must be %s or %s", cluster.Auth, cluster.Name, ClusterAuthToken, ClusterAuthExchange)
+		}
+		if cluster.InsecureSkipVerify && (cluster.CertificateAuthority != "" || cluster.TLSServerName != "") {
+			return nil, fmt.Errorf("cluster %s can not skip the verification and set a CA or server name", cluster.Name)
+		}
+		if cluster.CertificateAuthority != "" {
+			if _, err := os.ReadFile(cluster.CertificateAuthority); err != nil {
+				return nil, fmt.Errorf("failed to read CA certificate of cluster %s: %w", cluster.Name, err)
+			}
+		}
+	}
+	return clusters, nil
+}
+
+// MergeClusterTLS returns the TLS settings of the API servers with the ones of the clusters of the
+// registry added, which must not be set in both.
+func MergeClusterTLS(apiServerTLS map[string]APIServerTLS, clusters []Cluster) (map[string]APIServerTLS, error) {
+	merged := maps.Clone(apiServerTLS)
+	for _, cluster := range clusters {
+		if cluster.CertificateAuthority == "" && cluster.TLSServerName == "" && !cluster.InsecureSkipVerify {
+			continue
+		}
+		host, err := apiServerHost(cluster.Server)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server %q of cluster %s: %w", cluster.Server, cluster.Name, err)
+		}
+		if _, ok := merged[host]; ok {
+			return nil, fmt.Errorf("TLS settings of API server %s are set by both the API server TLS settings and cluster %s", host, cluster.Name)
+		}
+		if merged == nil {
+			merged = map[string]APIServerTLS{}
+		}
+		merged[host] = APIServerTLS{
+			Host:                 cluster.Server,
+			CertificateAuthority: cluster.CertificateAuthority,
+			TLSServerName:        cluster.TLSServerName,
+			InsecureSkipVerify:   cluster.InsecureSkipVerify,
+		}
+	}
+	return merged, nil
+}
+
+// clusterAccess authenticates the callers to the clusters granted by their tokens: the API servers
+// and the clusters of the registry named by their audience.
+type clusterAccess struct {
+	// mu guards the clusters, replaced when the registry is reloaded.
+	mu       sync.RWMutex
+	clusters []Cluster
+	// reviewer validates the tokens with the API servers, nil means they are not reviewed.
+	reviewer *tokenReviewer
+	// credentials exchanges the tokens for credentials, nil means the tokens are sent as is.
+	credentials *credentialCache
+	// sessions keeps the default clusters of the sessions, nil means the cluster is never asked.
+	sessions *sessionContexts
+	// clusterless are the tools not calling a single cluster, which take no cluster argument and
+	// never ask for one.
+	clusterless map[string]bool
+	// routing are the cluster routing policies of the tools.
+	routing map[string]string
+	// prober ranks the clusters routed by health, nil means the primary cluster is used.
+	prober *reachabilityProber
+}
+
+// clusterlessTool marks a tool as not calling a single cluster when registering it, so that it takes
+// no cluster argument and the cluster of its calls is never asked.
+func (s *Server) clusterlessTool(tool *mcp.Tool) *mcp.Tool {
+	// The tools registered again while serving are already marked, the map is then only read.
+	if !s.clusterlessTools[tool.Name] {
+		s.clusterlessTools[tool.Name] = true
+	}
+	return tool
+}
+
+// registry returns the clusters of the registry, none without cluster access.
+func (a *clusterAccess) registry() []Cluster {
+	if a == nil {
+		return nil
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.clusters
+}
+
+// setRegistry replaces the clusters of the registry, read again on reload.
+func (a *clusterAccess) setRegistry(clusters []Cluster) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.clusters = clusters
+}
+
+// lookup returns the cluster of the registry with the API server.
+func (a *clusterAccess) lookup(apiServerURL string) (Cluster, bool) {
+	host, err := apiServerHost(apiServerURL)
+	if err != nil {
+		return Cluster{}, false
+	}
+	for _, cluster := range a.registry() {
+		if serverHost, err := apiServerHost(cluster.Server); err == nil && serverHost == host {
+			return cluster, true
+		}
+	}
+	return Cluster{}, false
+}
+
+// granted returns the API servers of the audiences of a token, in their order, the names of the
+// clusters of the registry being resolved to their server. The audiences which are neither, e.g. the
+// default audience of the API servers, are left out, unless no audience names a cluster.
+func (a *clusterAccess) granted(audiences []string) []string {
+	var apiServerURLs []string
+	seen := map[string]bool{}
+	for _, audience := range audiences {
+		apiServerURL := ""
+		for _, cluster := range a.registry() {
+			if cluster.Name == audience {
+				apiServerURL = cluster.Server
+				break
+			}
+		}
+		if u, err := url.Parse(audience); apiServerURL == "" && err == nil && u.Scheme != "" && u.Host != "" {
+			apiServerURL = audience
+		}
+		if apiServerURL == "" {
+			continue
+		}
+		host, err := apiServerHost(apiServerURL)
+		if err != nil || seen[host] {
+			continue
+		}
+		seen[host] = true
+		apiServerURLs = append(apiServerURLs, apiServerURL)
+	}
+	if len(apiServerURLs) == 0 && len(audiences) > 0 {
+		return audiences[:1]
+	}
+	return apiServerURLs
+}
+
+// displayName returns the name of the cluster of the API server in the registry, or its host.
+func (a *clusterAccess) displayName(apiServerURL string) string {
+	if cluster, ok := a.lookup(apiServerURL); ok {
+		return cluster.Name
+	}
+	return clusterHost(apiServerURL)
+}
+
+// resolve returns the granted API server selected by a cluster argument: the name of a cluster of
+// the registry, or the URL or the host of an API server.
+func (a *clusterAccess) resolve(selected string, granted []string) (string, error) {
+	selectedHost, _ := apiServerHost(selected)
+	for _, apiServerURL := range granted {
+		if selected == apiServerURL {
+			return apiServerURL, nil
+		}
+		if host, err := apiServerHost(apiServerURL); err == nil && host == selectedHost {
+			return apiServerURL, nil
+		}
+		if cluster, ok := a.lookup(apiServerURL); ok && cluster.Name == selected {
+			return apiServerURL, nil
+		}
+	}
+	names := make([]string, 0, len(granted))
+	for _, apiServerURL := range granted {
+		names = append(names, a.displayName(apiServerURL))
+	}
+	return "", fmt.Errorf("cluster %q is not granted by the token, must be one of: %s", selected, strings.Join(names, ", "))
+}
+
+// authenticate returns the user of the token reviewed by the API server, empty when the tokens are
+// not reviewed, and the bearer token sent to it: the token itself or the credential it is exchanged
+// for, depending on the authentication mode of its cluster.
+func (a *clusterAccess) authenticate(ctx context.Context, apiServerURL, token string, expiration time.Time) (string, string, error) {
+	var username string
+	if a.reviewer != nil {
+		var err error
+		username, err = a.reviewer.verify(ctx, apiServerURL, token, expiration)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+		}
+	}
+
+	cluster, _ := a.lookup(apiServerURL)
+	if a.credentials == nil || cluster.Auth == ClusterAuthToken {
+		return username, token, nil
+	}
+	credential, err := a.credentials.exchange(ctx, apiServerURL, token, expiration)
+	if err != nil {
+		return "", "", err
+	}
+	return username, credential.Token, nil
+}
+
+// tokenInfoFor returns the token info of the calls to one of the API servers granted by the token,
+// the caller being authenticated to it.
+func (a *clusterAccess) tokenInfoFor(ctx context.Context, tokenInfo *auth.TokenInfo, apiServerURL string) (*auth.TokenInfo, error) {
+	if current, _ := tokenInfo.Extra["audience"].(string); current == apiServerURL {
+		return tokenInfo, nil
+	}
+	token, _ := tokenInfo.Extra["token"].(string)
+	username, bearerToken, err := a.authenticate(ctx, apiServerURL, token, tokenInfo.Expiration)
+	if err != nil {
+		return nil, err
+	}
+	selected := *tokenInfo
+	selected.Extra = maps.Clone(tokenInfo.Extra)
+	selected.Extra["audience"] = apiServerURL
+	selected.Extra["bearer_token"] = bearerToken
+	selected.Extra["cluster"] = a.displayName(apiServerURL)
+	if username != "" {
+		selected.Extra["subject"] = username
+	}
+	return &selected, nil
+}
+
+// tokenInfoForCluster returns the token info of the calls to a cluster granted by the token, named as
+// in the object URIs: the name of a cluster of the registry or the host of its API server.
+func (a *clusterAccess) tokenInfoForCluster(ctx context.Context, tokenInfo *auth.TokenInfo, cluster string) (*auth.TokenInfo, error) {
+	if a == nil {
+		a = &clusterAccess{}
+	}
+	apiServerURL, err := a.resolve(cluster, tokenClusters(tokenInfo))
+	if err != nil {
+		return nil, err
+	}
+	return a.tokenInfoFor(ctx, tokenInfo, apiServerURL)
+}
+
+// tokenClusterNames returns the names of the clusters granted by the token, as in the object URIs.
+func (a *clusterAccess) tokenClusterNames(tokenInfo *auth.TokenInfo) []string {
+	if a == nil {
+		a = &clusterAccess{}
+	}
+	apiServerURLs := tokenClusters(tokenInfo)
+	names := make([]string, 0, len(apiServerURLs))
+	for _, apiServerURL := range apiServerURLs {
+		names = append(names, a.displayName(apiServerURL))
+	}
+	return names
+}
+
+// withClusterInput returns copies of the tools with the cluster argument added to their input schema.
+// The clusters of the registry, and the other clusters granted by the token listing the tools, are
+// offered as an enum so that the models choose valid clusters instead of inventing URLs.
+func (a *clusterAccess) withClusterInput(tools []*mcp.Tool, tokenInfo *auth.TokenInfo) []*mcp.Tool {
+	input := &jsonschema.Schema{
+		Type:        "string",
+		Description: "The cluster of the call: the name of a cluster or the URL or host of its API server (optional defaults to the cluster of the session, asked when the token grants several clusters)",
+	}
+	if registry := a.registry(); len(registry) > 0 {
+		var names, descriptions []string
+		for _, cluster := range registry {
+			names = append(names, cluster.Name)
+			if cluster.Description != "" {
+				descriptions = append(descriptions, fmt.Sprintf("%s: %s", cluster.Name, cluster.Description))
+			}
+		}
+		if tokenInfo != nil {
+			for _, apiServerURL := range tokenClusters(tokenInfo) {
+				if name := a.displayName(apiServerURL); !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+		}
+		for _, name := range names {
+			input.Enum = append(input.Enum, name)
+		}
+		input.Description = "The name of the cluster of the call (optional defaults to the cluster of the session, asked when the token grants several clusters)"
+		if len(descriptions) > 0 {
+			input.Description += ". The clusters are " + strings.Join(descriptions, "; ")
+		}
+	}
+
+	withCluster := make([]*mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if tool.InputSchema == nil || a.clusterless[tool.Name] {
+			withCluster = append(withCluster, tool)
+			continue
+		}
+		copied := *tool
+		schema := *tool.InputSchema
+		schema.Properties = maps.Clone(schema.Properties)
+		if schema.Properties == nil {
+			schema.Properties = map[string]*jsonschema.Schema{}
+		}
+		schema.Properties[clusterArgument] = input
+		copied.InputSchema = &schema
+		withCluster = append(withCluster, &copied)
+	}
+	return withCluster
+}
+
+// takeClusterArgument returns the cluster argument of a tool call and its arguments without it, which
+// the input schemas of the tools do not allow.
+func takeClusterArgument(arguments json.RawMessage) (string, json.RawMessage, bool) {
+	if !bytes.Contains(arguments, []byte(`"`+clusterArgument+`"`)) {
+		return "", arguments, false
+	}
+	var decoded map[string]json.RawMessage
+	if err := json.Unmarshal(arguments, &decoded); err != nil {
+		return "", arguments, false
+	}
+	value, ok := decoded[clusterArgument]
+	if !ok {
+		return "", arguments, false
+	}
+	var cluster string
+	if err := json.Unmarshal(value, &cluster); err != nil {
+		return "", arguments, false
+	}
+	delete(decoded, clusterArgument)
+	stripped, err := json.Marshal(decoded)
+	if err != nil {
+		return "", arguments, false
+	}
+	return cluster, stripped, true
+}
+
+// tokenClusters returns the API servers granted by the token, the one of its audience when the token
+// info does not list them.
+func tokenClusters(tokenInfo *auth.TokenInfo) []string {
+	if clusters, ok := tokenInfo.Extra["clusters"].([]string); ok && len(clusters) > 0 {
+		return clusters
+	}
+	if apiServerURL, ok := tokenInfo.Extra["audience"].(string); ok && apiServerURL != "" {
+		return []string{apiServerURL}
+	}
+	return nil
+}
+
+// elicitationClusterField is the field of the elicitation choosing the cluster of a tool call.
+const elicitationClusterField = "cluster"
+
+// clusterChoice asks the user to choose the cluster of a tool call, offered as an enum so that the
+// clients render a picker. The clients without elicitation get the default cluster.
+func clusterChoice(tool string, options []string, defaultOption string) *mcp.ElicitParams {
+	enum := make([]any, 0, len(options))
+	for _, option := range options {
+		enum = append(enum, option)
+	}
+	defaultValue, _ := json.Marshal(defaultOption)
+	return &mcp.ElicitParams{
+		Message: fmt.Sprintf("The token grants several clusters. Which cluster should %s run against? "+
+			"The next calls of the session run against it too, until it is changed with set_context.", tool),
+		RequestedSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				elicitationClusterField: {
+					Type:        "string",
+					Description: "The cluster to use",
+					Enum:        enum,
+					Default:     defaultValue,
+				},
+			},
+			Required: []string{elicitationClusterField},
+		},
+	}
+}
+
+// elicitCluster asks the user for the cluster of a tool call among the clusters granted by the token.
+func (a *clusterAccess) elicitCluster(ctx context.Context, session *mcp.ServerSession, tool string, granted []string, defaultURL string) (string, error) {
+	options := make([]string, 0, len(granted))
+	for _, apiServerURL := range granted {
+		options = append(options, a.displayName(apiServerURL))
+	}
+	elicitResult, err := session.Elicit(ctx, clusterChoice(tool, options, a.displayName(defaultURL)))
+	if err != nil {
+		return "", fmt.Errorf("failed to elicit cluster: %w", err)
+	}
+	if elicitResult.Action != "accept" {
+		return "", fmt.Errorf("user cancelled cluster selection")
+	}
+	selected, _ := elicitResult.Content[elicitationClusterField].(string)
+	return a.resolve(selected, granted)
+}
+
+// selectCluster returns the API server of a tool call without cluster argument: the default cluster
+// of the session, or when the token grants several clusters the one selected by the routing policy
+// of the tool, returned with the API server, or else the one the user chooses. Empty means the call
+// runs against the first cluster of the token, or against every cluster with ClusterRoutingAll.
+func (a *clusterAccess) selectCluster(ctx context.Context, request *mcp.CallToolRequest, granted []string) (string, string, error) {
+	if a.sessions == nil {
+		return "", "", nil
+	}
+	if apiServerURL := a.sessions.cluster(request.Session); apiServerURL != "" {
+		// The session may be continued with a token granting other clusters.
+		if slices.Contains(granted, apiServerURL) {
+			return apiServerURL, "", nil
+		}
+	}
+	if len(granted) < 2 || a.clusterless[request.Params.Name] {
+		return "", "", nil
+	}
+	if apiServerURL, policy := a.routeCluster(ctx, request.Params.Name, request.Extra.TokenInfo, granted); policy != "" {
+		return apiServerURL, policy, nil
+	}
+	if request.Session == nil {
+		return "", "", nil
+	}
+
+	defaultURL, _ := request.Extra.TokenInfo.Extra["audience"].(string)
+	apiServerURL, err := a.elicitCluster(ctx, request.Session, request.Params.Name, granted, defaultURL)
+	if err != nil {
+		return "", "", err
+	}
+	a.sessions.setCluster(request.Session, apiServerURL, a.displayName(apiServerURL))
+	return apiServerURL, "", nil
+}
+
+// middleware adds the cluster argument to the tools calling a cluster, and runs their calls against
+// the cluster they select, or else the default cluster of the session, asking the user for it when
+// the token grants several clusters, so that changes are not silently applied to the first one. The
+// caller is authenticated to the selected cluster.
+func (a *clusterAccess) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			request, ok := req.(*mcp.CallToolRequest)
+			if method != methodCallTool || !ok {
+				result, err := next(ctx, method, req)
+				if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+					var tokenInfo *auth.TokenInfo
+					if extra := req.GetExtra(); extra != nil {
+						tokenInfo = extra.TokenInfo
+					}
+					list.Tools = a.withClusterInput(list.Tools, tokenInfo)
+				}
+				return result, err
+			}
+
+			var selected string
+			if !a.clusterless[request.Params.Name] {
+				var arguments json.RawMessage
+				if selected, arguments, ok = takeClusterArgument(request.Params.Arguments); ok {
+					request.Params.Arguments = arguments
+				}
+			}
+			if request.Extra == nil || request.Extra.TokenInfo == nil {
+				return next(ctx, method, req)
+			}
+
+			tokenInfo := request.Extra.TokenInfo
+			granted := tokenClusters(tokenInfo)
+			var apiServerURL, policy string
+			var err error
+			if selected != "" {
+				apiServerURL, err = a.resolve(selected, granted)
+			} else {
+				apiServerURL, policy, err = a.selectCluster(ctx, request, granted)
+			}
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+					IsError: true,
+				}, nil
+			}
+			if policy == ClusterRoutingAll {
+				return a.fanOut(ctx, next, method, request, granted)
+			}
+			if apiServerURL == "" {
+				return next(ctx, method, req)
+			}
+
+			selectedInfo, err := a.tokenInfoFor(ctx, tokenInfo, apiServerURL)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to authenticate to cluster %s: %v", a.displayName(apiServerURL), err)}},
+					IsError: true,
+				}, nil
+			}
+			extra := *request.Extra
+			extra.TokenInfo = selectedInfo
+			request.Extra = &extra
+			result, err := next(ctx, method, req)
+			if policy != "" && err == nil {
+				a.routedResult(result, policy, apiServerURL)
+			}
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestLoadClusters(t *testing.T) {
+	dir := t.TempDir()
+	ca := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(ca, []byte("ca"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name: "clusters",
+			content: "- name: prod-eu\n  server: https://prod-eu.example.com:6443\n  certificateAuthority: " + ca + "\n  auth: exchange\n" +
+				"- name: staging\n  server: https://staging.example.com\n  auth: token\n",
+			expected: []string{"prod-eu", "staging"},
+		},
+		{name: "invalid name", content: "- name: Prod_EU\n  server: https://prod-eu.example.com\n", wantErr: true},
+		{name: "duplicate name", content: "- name: prod\n  server: https://a.example.com\n- name: prod\n  server: https://b.example.com\n", wantErr: true},
+		{name: "duplicate server", content: "- name: a\n  server: https://a.example.com\n- name: b\n  server: https://a.example.com:443\n", wantErr: true},
+		{name: "http server", content: "- name: a\n  server: http://a.example.com\n", wantErr: true},
+		{name: "unknown auth", content: "- name: a\n  server: https://a.example.com\n  auth: basic\n", wantErr: true},
+		{name: "insecure with CA", content: "- name: a\n  server: https://a.example.com\n  insecureSkipVerify: true\n  certificateAuthority: " + ca + "\n", wantErr: true},
+		{name: "unknown field", content: "- name: a\n  server: https://a.example.com\n  token: abc\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "clusters.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			clusters, err := LoadClusters(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			var names []string
+			for _, cluster := range clusters {
+				names = append(names, cluster.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("expected clusters %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestMergeClusterTLS(t *testing.T) {
+	clusters := []Cluster{
+		{Name: "prod-eu", Server: "https://prod-eu.example.com:6443", TLSServerName: "kubernetes"},
+		{Name: "staging", Server: "https://staging.example.com"},
+	}
+	merged, err := MergeClusterTLS(map[string]APIServerTLS{"other.example.com:443": {InsecureSkipVerify: true}}, clusters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 2 || merged["prod-eu.example.com:6443"].TLSServerName != "kubernetes" {
+		t.Errorf("expected the TLS settings of prod-eu to be added, got %v", merged)
+	}
+
+	_, err = MergeClusterTLS(map[string]APIServerTLS{"prod-eu.example.com:6443": {InsecureSkipVerify: true}}, clusters)
+	if err == nil {
+		t.Errorf("expected the TLS settings set twice to be refused")
+	}
+}
+
+func TestClusterAccessGranted(t *testing.T) {
+	access := &clusterAccess{clusters: []Cluster{
+		{Name: "prod-eu", Server: "https://prod-eu.example.com:6443"},
+		{Name: "staging", Server: "https://staging.example.com"},
+	}}
+
+	if granted := access.granted([]string{"default", "10.0.0.1:6443"}); !reflect.DeepEqual(granted, []string{"default"}) {
+		t.Errorf("expected the first audience without cluster, got %v", granted)
+	}
+
+	granted := access.granted([]string{"default", "prod-eu", "https://10.0.0.1:6443", "https://prod-eu.example.com:6443"})
+	expected := []string{"https://prod-eu.example.com:6443", "https://10.0.0.1:6443"}
+	if !reflect.DeepEqual(granted, expected) {
+		t.Fatalf("expected %v, got %v", expected, granted)
+	}
+
+	tests := []struct {
+		selected string
+		expected string
+		wantErr  bool
+	}{
+		{selected: "prod-eu", expected: "https://prod-eu.example.com:6443"},
+		{selected: "https://10.0.0.1:6443", expected: "https://10.0.0.1:6443"},
+		{selected: "prod-eu.example.com:6443", expected: "https://prod-eu.example.com:6443"},
+		{selected: "staging", wantErr: true},
+		{selected: "https://other.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selected, func(t *testing.T) {
+			apiServerURL, err := access.resolve(tt.selected, granted)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if apiServerURL != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, apiServerURL)
+			}
+		})
+	}
+}
+
+func TestClusterlessTool(t *testing.T) {
+	s := NewServer("8080", "k-mcp")
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	s.addSetContextTool(server)
+	s.addPreferencesTools(server)
+	s.addOperationTools(server)
+	s.addListClustersTool(server, nil, nil, nil)
+
+	expected := map[string]bool{"set_context": true, "get_preferences": true, "set_preferences": true, "operation_status": true, "operation_cancel": true, "list_clusters": true}
+	if !reflect.DeepEqual(s.clusterlessTools, expected) {
+		t.Errorf("expected the tools registered as clusterless %v, got %v", expected, s.clusterlessTools)
+	}
+}
+
+func TestClusterInputEnum(t *testing.T) {
+	tools := []*mcp.Tool{
+		{Name: "resource_get", InputSchema: &jsonschema.Schema{Type: "object"}},
+		{Name: "list_clusters", InputSchema: &jsonschema.Schema{Type: "object"}},
+	}
+	tokenInfo := &auth.TokenInfo{Extra: map[string]any{"clusters": []string{"https://prod-eu.example.com", "https://10.0.0.1:6443"}}}
+
+	tests := []struct {
+		name        string
+		clusters    []Cluster
+		expected    []any
+		description string
+	}{
+		{name: "no registry", description: "the URL or host of its API server"},
+		{
+			name: "registry",
+			clusters: []Cluster{
+				{Name: "prod-eu", Server: "https://prod-eu.example.com", Description: "production in Europe"},
+				{Name: "staging", Server: "https://staging.example.com"},
+			},
+			expected:    []any{"prod-eu", "staging", "10.0.0.1:6443"},
+			description: "The clusters are prod-eu: production in Europe",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access := &clusterAccess{clusterless: map[string]bool{"list_clusters": true}}
+			// The input follows the registry read again on reload.
+			access.setRegistry(tt.clusters)
+			withCluster := access.withClusterInput(tools, tokenInfo)
+			input := withCluster[0].InputSchema.Properties[clusterArgument]
+			if input == nil || !reflect.DeepEqual(input.Enum, tt.expected) || !strings.Contains(input.Description, tt.description) {
+				t.Errorf("expected the clusters %v described with %q, got %+v", tt.expected, tt.description, input)
+			}
+			if withCluster[1].InputSchema.Properties[clusterArgument] != nil || tools[0].InputSchema.Properties != nil {
+				t.Errorf("expected the cluster input to be added to copies of the tools calling a cluster only")
+			}
+		})
+	}
+}
+
+func TestClusterMiddleware(t *testing.T) {
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	access := &clusterAccess{
+		clusters: []Cluster{{Name: "prod-eu", Server: "https://prod-eu.example.com", Auth: ClusterAuthToken}},
+		sessions: newSessionContexts(),
+	}
+	server.AddReceivingMiddleware(access.middleware())
+	type whoami struct {
+		Cluster string `json:"cluster"`
+		Token   string `json:"token"`
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "whoami"}, func(ctx context.Context, request *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, whoami, error) {
+		token, _ := request.Extra.TokenInfo.Extra["bearer_token"].(string)
+		return nil, whoami{Cluster: clusterName(request.Extra.TokenInfo), Token: token}, nil
+	})
+	tokenInfo := &auth.TokenInfo{
+		Expiration: time.Now().Add(time.Hour),
+		Extra: map[string]any{
+			"audience":     "https://staging.example.com",
+			"bearer_token": "exchanged",
+			"subject":      "alice",
+			"clusters":     []string{"https://staging.example.com", "https://prod-eu.example.com"},
+			"token":        "inbound",
+		},
+	}
+	transport := &tokenInfoTransport{Transport: serverTransport, tokenInfo: func() *auth.TokenInfo { return tokenInfo }}
+	if _, err := server.Connect(ctx, transport, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var elicited []any
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		ElicitationHandler: func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			elicited = req.Params.RequestedSchema.Properties[elicitationClusterField].Enum
+			return &mcp.ElicitResult{Action: "accept", Content: map[string]any{elicitationClusterField: "prod-eu"}}, nil
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema := tools.Tools[0].InputSchema; schema == nil || schema.Properties[clusterArgument] == nil {
+		t.Errorf("expected the cluster input, got %v", tools.Tools[0].InputSchema)
+	}
+
+	// The calls of the tests run in order in the same session.
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		expected  whoami
+		elicited  bool
+		wantErr   string
+	}{
+		{name: "cluster asked", arguments: map[string]any{}, expected: whoami{Cluster: "prod-eu.example.com", Token: "inbound"}, elicited: true},
+		{name: "cluster of the session", arguments: map[string]any{}, expected: whoami{Cluster: "prod-eu.example.com", Token: "inbound"}},
+		{name: "cluster by host", arguments: map[string]any{"cluster": "staging.example.com"}, expected: whoami{Cluster: "staging.example.com", Token: "exchanged"}},
+		{name: "cluster not granted", arguments: map[string]any{"cluster": "dev"}, wantErr: `cluster "dev" is not granted by the token, must be one of: staging.example.com, prod-eu`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elicited = nil
+			result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "whoami", Arguments: tt.arguments})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" {
+				if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, tt.wantErr) {
+					t.Errorf("expected the error %q, got %v", tt.wantErr, result.Content)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("unexpected error %v", result.Content[0].(*mcp.TextContent).Text)
+			}
+			if expected := []any{"staging.example.com", "prod-eu"}; tt.elicited != (elicited != nil) || (tt.elicited && !reflect.DeepEqual(elicited, expected)) {
+				t.Errorf("expected the cluster asked %v among %v, got %v", tt.elicited, expected, elicited)
+			}
+			content := result.StructuredContent.(map[string]any)
+			got := whoami{Cluster: content["cluster"].(string), Token: content["token"].(string)}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+	if tokenInfo.Extra["audience"] != "https://staging.example.com" {
+		t.Errorf("expected the token info of the connection to be left unchanged, got %v", tokenInfo.Extra)
+	}
+}
+
+func TestClusterAccessTokenInfoForCluster(t *testing.T) {
+	access := &clusterAccess{clusters: []Cluster{{Name: "prod-eu", Server: "https://prod-eu.example.com:6443", Auth: ClusterAuthToken}}}
+	tokenInfo := &auth.TokenInfo{
+		Expiration: time.Now().Add(time.Hour),
+		Extra: map[string]any{
+			"audience":     "https://staging.example.com",
+			"bearer_token": "exchanged",
+			"clusters":     []string{"https://staging.example.com", "https://prod-eu.example.com:6443"},
+			"token":        "inbound",
+		},
+	}
+
+	if names := access.tokenClusterNames(tokenInfo); !reflect.DeepEqual(names, []string{"staging.example.com", "prod-eu"}) {
+		t.Errorf("expected the names of the granted clusters, got %v", names)
+	}
+
+	tests := []struct {
+		cluster  string
+		expected string
+		wantErr  bool
+	}{
+		{cluster: "staging.example.com", expected: "https://staging.example.com"},
+		{cluster: "prod-eu", expected: "https://prod-eu.example.com:6443"},
+		{cluster: "prod-eu.example.com:6443", expected: "https://prod-eu.example.com:6443"},
+		{cluster: "dev.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			clusterInfo, err := access.tokenInfoForCluster(context.Background(), tokenInfo, tt.cluster)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && clusterInfo.Extra["audience"] != tt.expected {
+				t.Errorf("expected the token info of %s, got %v", tt.expected, clusterInfo.Extra)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// ColumnDefinition describes a single summary column, similar to
+// additionalPrinterColumns in CustomResourceDefinitions.
+type ColumnDefinition struct {
+	Name     string `json:"name"`
+	JSONPath string `json:"jsonPath"`
+}
+
+// KindColumns binds a set of summary columns to a group and kind.
+// Version is optional and matches every version when omitted.
+type KindColumns struct {
+	Group   string             `json:"group"`
+	Version string             `json:"version,omitempty"`
+	Kind    string             `json:"kind"`
+	Columns []ColumnDefinition `json:"columns"`
+}
+
+// SummaryColumns holds the operator configured summary columns.
+type SummaryColumns []KindColumns
+
+// LoadSummaryColumns reads the summary columns configuration from the given YAML file.
+func LoadSummaryColumns(path string) (SummaryColumns, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary columns from %s: %w", path, err)
+	}
+
+	var columns SummaryColumns
+	if err := yaml.UnmarshalStrict(data, &columns); err != nil {
+		return nil, fmt.Errorf("failed to parse summary columns from %s: %w", path, err)
+	}
+
+	for _, kc := range columns {
+		if kc.Kind == "" {
+			return nil, fmt.Errorf("summary columns entry for group %q is missing kind", kc.Group)
+		}
+		for _, col := range kc.Columns {
+			if col.Name == "" {
+				return nil, fmt.Errorf("summary column for %s is missing name", kc.Kind)
+			}
+			if _, err := parseJSONPath(col.Name, col.JSONPath); err != nil {
+				return nil, fmt.Errorf("invalid jsonPath for column %s of %s: %w", col.Name, kc.Kind, err)
+			}
+		}
+	}
+
+	return columns, nil
+}
+
+// ColumnsFor returns the configured columns matching the given GroupVersionKind.
+func (c SummaryColumns) ColumnsFor(gvk schema.GroupVersionKind) []ColumnDefinition {
+	var columns []ColumnDefinition
+	for _, kc := range c {
+		if kc.Group != gvk.Group || !strings.EqualFold(kc.Kind, gvk.Kind) {
+			continue
+		}
+		if kc.Version != "" && kc.Version != gvk.Version {
+			continue
+		}
+		columns = append(columns, kc.Columns...)
+	}
+	return columns
+}
+
+// shapeObjects shapes the listed objects for the output mode. Objects are returned
+// unchanged in full mode and summarized with their configured columns in summary mode.
+func shapeObjects(items []unstructured.Unstructured, outputMode string, summaryColumns SummaryColumns) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(items))
+	now := time.Now()
+	for i := range items {
+		if outputMode == OutputModeSummary {
+			result = append(result, summarizeObject(&items[i], summaryColumns.ColumnsFor(items[i].GroupVersionKind()), now))
+			continue
+		}
+		result = append(result, items[i].Object)
+	}
+	return result
+}
+
+// summarizeObject returns a compact record of the object containing its identity, its age, the
+// key status fields of its kind and the values of the given columns, which take precedence.
+func summarizeObject(obj *unstructured.Unstructured, columns []ColumnDefinition, now time.Time) map[string]interface{} {
+	summary := statusSummary(obj)
+	if created := obj.GetCreationTimestamp(); !created.IsZero() {
+		summary["age"] = duration.HumanDuration(now.Sub(created.Time))
+	}
+	for key, value := range columnRecord(obj, columns) {
+		summary[key] = value
+	}
+	return summary
+}
+
+// columnRecord returns a record of the object containing its identity and the values of the
+// given columns.
+func columnRecord(obj *unstructured.Unstructured, columns []ColumnDefinition) map[string]interface{} {
+	record := map[string]interface{}{
+		"name": obj.GetName(),
+		"kind": obj.GetKind(),
+	}
+	if obj.GetNamespace() != "" {
+		record["namespace"] = obj.GetNamespace()
+	}
+
+	for _, col := range columns {
+		value, err := evaluateJSONPath(obj.Object, col.JSONPath)
+		if err != nil {
+			value = "<error>"
+		}
+		record[col.Name] = value
+	}
+
+	return record
+}
+
+// parseJSONPath parses a kubectl style JSONPath expression. Braces are optional,
+// so both `.status.phase` and `{.status.phase}` are accepted.
+func parseJSONPath(name, path string) (*jsonpath.JSONPath, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("empty jsonPath")
+	}
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+
+	jp := jsonpath.New(name).AllowMissingKeys(true)
+	if err := jp.Parse(path); err != nil {
+		return nil, err
+	}
+	return jp, nil
+}
+
+// evaluateJSONPath evaluates the JSONPath expression against the object and
+// returns the printed result.
+func evaluateJSONPath(obj map[string]interface{}, path string) (string, error) {
+	jp, err := parseJSONPath("column", path)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, obj); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// fieldPath is a JSONPath expression of the fields of the objects to return.
+type fieldPath struct {
+	expression string
+	path       *jsonpath.JSONPath
+}
+
+// parseFields parses the JSONPath expressions of the fields of the objects to return.
+func parseFields(fields []string) ([]fieldPath, error) {
+	paths := make([]fieldPath, 0, len(fields))
+	for _, field := range fields {
+		path, err := parseJSONPath(field, field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %q: %w", field, err)
+		}
+		paths = append(paths, fieldPath{expression: field, path: path})
+	}
+	return paths, nil
+}
+
+// projectObjects returns the identity of the objects and the values of their fields.
+func projectObjects(items []unstructured.Unstructured, fields []fieldPath) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(items))
+	for i := range items {
+		result = append(result, projectObject(&items[i], fields))
+	}
+	return result
+}
+
+// projectObject returns the identity of the object and the values of its fields, by their
+// expression. The expressions matching several values, e.g. with [*], return the list of the
+// values, and the ones matching none are left out.
+func projectObject(obj *unstructured.Unstructured, fields []fieldPath) map[string]interface{} {
+	projection := map[string]interface{}{
+		"name": obj.GetName(),
+		"kind": obj.GetKind(),
+	}
+	if obj.GetNamespace() != "" {
+		projection["namespace"] = obj.GetNamespace()
+	}
+
+	for _, field := range fields {
+		results, err := field.path.FindResults(obj.Object)
+		if err != nil {
+			projection[field.expression] = "<error>"
+			continue
+		}
+		var values []interface{}
+		for _, result := range results {
+			for _, value := range result {
+				values = append(values, value.Interface())
+			}
+		}
+		switch len(values) {
+		case 0:
+		case 1:
+			projection[field.expression] = values[0]
+		default:
+			projection[field.expression] = values
+		}
+	}
+	return projection
+}
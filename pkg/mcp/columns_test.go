@@ -0,0 +1,230 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestSummaryColumnsFor(t *testing.T) {
+	columns := SummaryColumns{
+		{
+			Group:   "cert-manager.io",
+			Kind:    "Certificate",
+			Columns: []ColumnDefinition{{Name: "ready", JSONPath: `.status.conditions[?(@.type=="Ready")].status`}},
+		},
+		{
+			Group:   "example.com",
+			Version: "v1beta1",
+			Kind:    "Widget",
+			Columns: []ColumnDefinition{{Name: "phase", JSONPath: ".status.phase"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		gvk      schema.GroupVersionKind
+		expected []string
+	}{
+		{
+			name:     "any version matches",
+			gvk:      schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"},
+			expected: []string{"ready"},
+		},
+		{
+			name:     "kind is case insensitive",
+			gvk:      schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "certificate"},
+			expected: []string{"ready"},
+		},
+		{
+			name:     "pinned version matches",
+			gvk:      schema.GroupVersionKind{Group: "example.com", Version: "v1beta1", Kind: "Widget"},
+			expected: []string{"phase"},
+		},
+		{
+			name: "pinned version does not match",
+			gvk:  schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"},
+		},
+		{
+			name: "group does not match",
+			gvk:  schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Certificate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, col := range columns.ColumnsFor(tt.gvk) {
+				names = append(names, col.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("expected columns %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestSummarizeObject(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name":              "foo",
+			"namespace":         "bar",
+			"creationTimestamp": "2025-03-01T10:00:00Z",
+		},
+		"status": map[string]interface{}{
+			"phase": "Running",
+		},
+	}}
+
+	now := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+	summary := summarizeObject(obj, []ColumnDefinition{
+		{Name: "phase", JSONPath: ".status.phase"},
+		{Name: "missing", JSONPath: "{.status.missing}"},
+	}, now)
+
+	expected := map[string]interface{}{
+		"name":      "foo",
+		"namespace": "bar",
+		"kind":      "Widget",
+		"age":       "2d",
+		"status":    "Running",
+		"phase":     "Running",
+		"missing":   "",
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("expected summary %v, got %v", expected, summary)
+	}
+}
+
+func TestProjectObject(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      "web",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "nginx:1.27"},
+				map[string]interface{}{"name": "sidecar", "image": "envoy:1.31"},
+			},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			},
+		},
+	}}
+
+	fields, err := parseFields([]string{".spec.containers[*].image", "{.status.conditions}", `.status.conditions[?(@.type=="Ready")].status`, ".status.missing"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"name":                      "web",
+		"namespace":                 "default",
+		"kind":                      "Pod",
+		".spec.containers[*].image": []interface{}{"nginx:1.27", "envoy:1.31"},
+		"{.status.conditions}": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		},
+		`.status.conditions[?(@.type=="Ready")].status`: "True",
+	}
+	if projection := projectObject(obj, fields); !reflect.DeepEqual(projection, expected) {
+		t.Errorf("expected projection %v, got %v", expected, projection)
+	}
+
+	if _, err := parseFields([]string{".spec.containers[*"}); err == nil {
+		t.Errorf("expected an invalid field to fail")
+	}
+}
+
+func TestLoadSummaryColumns(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError bool
+	}{
+		{
+			name: "valid",
+			content: `
+- group: example.com
+  kind: Widget
+  columns:
+  - name: phase
+    jsonPath: .status.phase
+`,
+		},
+		{
+			name: "missing kind",
+			content: `
+- group: example.com
+  columns:
+  - name: phase
+    jsonPath: .status.phase
+`,
+			expectedError: true,
+		},
+		{
+			name: "invalid jsonPath",
+			content: `
+- group: example.com
+  kind: Widget
+  columns:
+  - name: phase
+    jsonPath: .status[
+`,
+			expectedError: true,
+		},
+		{
+			name: "unknown field",
+			content: `
+- group: example.com
+  kind: Widget
+  colums: []
+`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "columns.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadSummaryColumns(path)
+			if tt.expectedError && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectedError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
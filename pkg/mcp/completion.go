@@ -0,0 +1,221 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// maxCompletionValues is the maximum number of values of a completion allowed by the MCP specification.
+const maxCompletionValues = 100
+
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// promptNameResources are the resources of the objects named by the name argument of the prompts.
+var promptNameResources = map[string]schema.GroupVersionResource{
+	"diagnose_failing_pod": podsGVR,
+	"plan_safe_rollout":    deploymentsGVR,
+}
+
+// completionHandler completes the arguments of the prompts and the variables of the object resource template.
+// The resource, namespace and name arguments are completed from the discovery data and the objects of the
+// cluster of the token, or of the cluster already filled in the object URI, the values already filled in
+// narrowing down the names.
+func (s *Server) completionHandler(dynamicConfig *DynamicConfig) func(context.Context, *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	return func(ctx context.Context, request *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+		argument := request.Params.Argument
+		var resolved map[string]string
+		if request.Params.Context != nil {
+			resolved = request.Params.Context.Arguments
+		}
+		if request.Extra == nil || request.Extra.TokenInfo == nil {
+			return nil, fmt.Errorf("completion requires a token")
+		}
+
+		tokenInfo := request.Extra.TokenInfo
+		ref := request.Params.Ref
+		objectURIRef := ref != nil && ref.Type == "ref/resource" && ref.URI == objectURITemplate
+		if objectURIRef && argument.Name == "cluster" {
+			return &mcp.CompleteResult{Completion: completionValues(s.clusters.tokenClusterNames(tokenInfo), argument.Value)}, nil
+		}
+		if objectURIRef && resolved["cluster"] != "" {
+			var err error
+			tokenInfo, err = s.clusters.tokenInfoForCluster(ctx, tokenInfo, resolved["cluster"])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfigForTokenInfo(tokenInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		var candidates []string
+		switch {
+		case objectURIRef:
+			candidates, err = objectURICandidates(ctx, argument.Name, resolved, dynamicClient, discoveryClient)
+		case argument.Name == "resource":
+			candidates, err = resourceCandidates(discoveryClient)
+		case argument.Name == "namespace":
+			candidates, err = objectNames(ctx, dynamicClient, namespacesGVR, "")
+		case argument.Name == "name" && ref != nil && ref.Type == "ref/prompt":
+			gvr, ok := promptNameResources[ref.Name]
+			if ok && resolved["namespace"] != "" {
+				candidates, err = objectNames(ctx, dynamicClient, gvr, resolved["namespace"])
+			}
+		case argument.Name == "name" && resolved["resource"] != "":
+			var gvr schema.GroupVersionResource
+			gvr, _, err = FindResource(ctx, resolved["resource"], discoveryClient, nil)
+			if err == nil {
+				candidates, err = objectNames(ctx, dynamicClient, gvr, resolved["namespace"])
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &mcp.CompleteResult{Completion: completionValues(candidates, argument.Value)}, nil
+	}
+}
+
+// objectURICandidates returns the values of a variable of the object resource template but the cluster.
+func objectURICandidates(ctx context.Context, variable string, resolved map[string]string, dynamicClient dynamic.Interface, discoveryClient discovery.CachedDiscoveryInterface) ([]string, error) {
+	group := resolved["group"]
+	if group == objectURICoreGroup {
+		group = ""
+	}
+	gv := schema.GroupVersion{Group: group, Version: resolved["version"]}
+
+	switch variable {
+	case "group", "version":
+		groups, err := discoveryClient.ServerGroups()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get server groups: %w", err)
+		}
+		var candidates []string
+		for _, g := range groups.Groups {
+			switch {
+			case variable == "group" && g.Name == "":
+				candidates = append(candidates, objectURICoreGroup)
+			case variable == "group":
+				candidates = append(candidates, g.Name)
+			case g.Name == group:
+				for _, version := range g.Versions {
+					candidates = append(candidates, version.Version)
+				}
+			}
+		}
+		return candidates, nil
+	case "resource":
+		if gv.Version == "" {
+			return resourceCandidates(discoveryClient)
+		}
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(gv.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get resources of %s: %w", gv, err)
+		}
+		var candidates []string
+		for _, resource := range resources.APIResources {
+			if !strings.Contains(resource.Name, "/") && !isRestrictedResource(gv.WithResource(resource.Name)) {
+				candidates = append(candidates, resource.Name)
+			}
+		}
+		return candidates, nil
+	case "namespace":
+		namespaces, err := objectNames(ctx, dynamicClient, namespacesGVR, "")
+		if err != nil {
+			return nil, err
+		}
+		return append(namespaces, objectURIClusterScoped), nil
+	case "name":
+		gvr := gv.WithResource(resolved["resource"])
+		if gvr.Version == "" || gvr.Resource == "" || isRestrictedResource(gvr) {
+			return nil, nil
+		}
+		namespace := resolved["namespace"]
+		if namespace == objectURIClusterScoped {
+			namespace = ""
+		}
+		return objectNames(ctx, dynamicClient, gvr, namespace)
+	}
+	return nil, nil
+}
+
+// resourceCandidates returns the names of the preferred resources of the cluster, without the restricted ones.
+func resourceCandidates(discoveryClient discovery.CachedDiscoveryInterface) ([]string, error) {
+	resources, _, err := serverPreferredResources(discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, resourceList := range resources {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			if !isRestrictedResource(gv.WithResource(resource.Name)) {
+				candidates = append(candidates, resource.Name)
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// objectNames returns the names of the objects of a resource in a namespace, or in all namespaces if empty.
+func objectNames(ctx context.Context, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, namespace string) ([]string, error) {
+	list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return names, nil
+}
+
+// completionValues returns the sorted, unique candidates starting with the typed prefix,
+// truncated to the maximum number of values.
+func completionValues(candidates []string, prefix string) mcp.CompletionResultDetails {
+	values := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			values = append(values, candidate)
+		}
+	}
+	slices.Sort(values)
+	values = slices.Compact(values)
+
+	details := mcp.CompletionResultDetails{Values: values, Total: len(values)}
+	if len(values) > maxCompletionValues {
+		details.Values = values[:maxCompletionValues]
+		details.HasMore = true
+	}
+	return details
+}
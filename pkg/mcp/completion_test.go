@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestCompletionValues(t *testing.T) {
+	details := completionValues([]string{"pods", "podtemplates", "services", "pods", "poddisruptionbudgets"}, "pod")
+	expected := []string{"poddisruptionbudgets", "pods", "podtemplates"}
+	if !reflect.DeepEqual(details.Values, expected) || details.Total != 3 || details.HasMore {
+		t.Errorf("expected %v, got %+v", expected, details)
+	}
+
+	var candidates []string
+	for i := range maxCompletionValues + 20 {
+		candidates = append(candidates, fmt.Sprintf("pod-%03d", i))
+	}
+	details = completionValues(candidates, "")
+	if len(details.Values) != maxCompletionValues || details.Total != maxCompletionValues+20 || !details.HasMore {
+		t.Errorf("expected %d truncated values, got %d values, total %d, hasMore %t", maxCompletionValues, len(details.Values), details.Total, details.HasMore)
+	}
+}
+
+func TestResourceCandidates(t *testing.T) {
+	dc := cmdtesting.NewFakeCachedDiscoveryClient()
+	dc.PreferredResources = []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{{Name: "pods"}, {Name: "secrets"}, {Name: "services"}},
+		},
+		{
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []v1.APIResource{{Name: "roles"}},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []v1.APIResource{{Name: "deployments"}},
+		},
+	}
+
+	candidates, err := resourceCandidates(dc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"pods", "services", "deployments"}
+	if !reflect.DeepEqual(candidates, expected) {
+		t.Errorf("expected %v, got %v", expected, candidates)
+	}
+}
+
+func TestObjectURICandidates(t *testing.T) {
+	dc := cmdtesting.NewFakeCachedDiscoveryClient()
+	dc.Groups = []*v1.APIGroup{
+		{Name: "", Versions: []v1.GroupVersionForDiscovery{{Version: "v1"}}},
+		{Name: "apps", Versions: []v1.GroupVersionForDiscovery{{Version: "v1"}}},
+		{Name: "autoscaling", Versions: []v1.GroupVersionForDiscovery{{Version: "v1"}, {Version: "v2"}}},
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		bundleObject("v1", "Namespace", "", "default"),
+		bundleObject("v1", "Namespace", "", "shop"),
+		bundleObject("v1", "Pod", "shop", "checkout"),
+		bundleObject("v1", "Pod", "default", "web"),
+	)
+
+	tests := []struct {
+		variable string
+		resolved map[string]string
+		expected []string
+	}{
+		{variable: "group", expected: []string{"core", "apps", "autoscaling"}},
+		{variable: "version", resolved: map[string]string{"group": "autoscaling"}, expected: []string{"v1", "v2"}},
+		{variable: "version", resolved: map[string]string{"group": "core"}, expected: []string{"v1"}},
+		{variable: "namespace", expected: []string{"default", "shop", "-"}},
+		{variable: "name", resolved: map[string]string{"group": "core", "version": "v1", "resource": "pods", "namespace": "shop"}, expected: []string{"checkout"}},
+		{variable: "name", resolved: map[string]string{"group": "core", "version": "v1", "resource": "secrets", "namespace": "shop"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.variable, func(t *testing.T) {
+			candidates, err := objectURICandidates(context.TODO(), tt.variable, tt.resolved, dynamicClient, dc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(candidates, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, candidates)
+			}
+		})
+	}
+}
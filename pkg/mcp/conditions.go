@@ -0,0 +1,179 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+type ResourceConditionsInput struct {
+	Resource      string `json:"resource,required" jsonschema:"The Kubernetes resource type (e.g. pods deployments.v1.apps certificates.cert-manager.io)"`
+	Name          string `json:"name,omitempty" jsonschema:"The name of the resource. When omitted, all resources matching the namespace and label selector are returned"`
+	Namespace     string `json:"namespace,omitempty" jsonschema:"The namespace of the resources (optional defaults to all namespaces when name is omitted)"`
+	LabelSelector string `json:"labelSelector,omitempty" jsonschema:"Label selector to filter resources when name is omitted (e.g. app=myapp)"`
+}
+
+type ResourceConditionsResult struct {
+	Objects []ObjectConditions `json:"objects"`
+}
+
+// ObjectConditions holds the normalized status conditions of a single object.
+type ObjectConditions struct {
+	Kind       string             `json:"kind"`
+	Name       string             `json:"name"`
+	Namespace  string             `json:"namespace,omitempty"`
+	Conditions []ConditionSummary `json:"conditions"`
+}
+
+func (s *Server) addResourceConditionsTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "resource_conditions",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Summarize the status conditions of Kubernetes resources",
+		},
+		Description: "Extract the .status.conditions of a resource, or of every resource matching a namespace and label selector, as a compact table instead of the full objects. Works with any kind including custom resources",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ResourceConditionsInput) (*mcp.CallToolResult, *ResourceConditionsResult, error) {
+		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		gvr, isNamespaced, err := FindResource(ctx, input.Resource, discoveryClient, request.Session)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find resource: %w", err)
+		}
+
+		var items []unstructured.Unstructured
+		if input.Name != "" {
+			if isNamespaced && input.Namespace == "" {
+				input.Namespace, err = s.sessionNamespace(ctx, request, input.Resource)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+
+			var obj *unstructured.Unstructured
+			if isNamespaced {
+				obj, err = dynamicClient.Resource(gvr).Namespace(input.Namespace).Get(ctx, input.Name, v1.GetOptions{})
+			} else {
+				obj, err = dynamicClient.Resource(gvr).Get(ctx, input.Name, v1.GetOptions{})
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get resource: %w", err)
+			}
+			items = append(items, *obj)
+		} else {
+			listOptions := v1.ListOptions{LabelSelector: input.LabelSelector}
+			var list *unstructured.UnstructuredList
+			if isNamespaced && input.Namespace != "" {
+				list, err = dynamicClient.Resource(gvr).Namespace(input.Namespace).List(ctx, listOptions)
+			} else {
+				list, err = dynamicClient.Resource(gvr).List(ctx, listOptions)
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list resources: %w", err)
+			}
+			items = list.Items
+		}
+
+		objects := make([]ObjectConditions, 0, len(items))
+		for _, item := range items {
+			oc := ObjectConditions{
+				Kind:       item.GetKind(),
+				Name:       item.GetName(),
+				Namespace:  item.GetNamespace(),
+				Conditions: extractConditions(&item),
+			}
+			objects = append(objects, oc)
+		}
+
+		message := fmt.Sprintf("Found conditions of %d %s resources", len(objects), input.Resource)
+		if len(objects) > 0 {
+			message = textSummary(message, conditionsTable(objects).String())
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, &ResourceConditionsResult{Objects: objects}, nil
+	})
+}
+
+// extractConditions normalizes the .status.conditions of an unstructured object.
+// Conditions without a type are skipped.
+func extractConditions(obj *unstructured.Unstructured) []ConditionSummary {
+	conditions := []ConditionSummary{}
+
+	rawConditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, raw := range rawConditions {
+		cond, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType := stringField(cond, "type")
+		if conditionType == "" {
+			continue
+		}
+		conditions = append(conditions, ConditionSummary{
+			Type:               conditionType,
+			Status:             stringField(cond, "status"),
+			Reason:             stringField(cond, "reason"),
+			Message:            stringField(cond, "message"),
+			LastTransitionTime: stringField(cond, "lastTransitionTime"),
+		})
+	}
+
+	return conditions
+}
+
+func stringField(m map[string]interface{}, field string) string {
+	value, ok := m[field]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// formatObjectConditions renders the conditions of an object as a single table row.
+// conditionsTable renders one row per condition of the objects, and a row without condition
+// for the objects having none.
+func conditionsTable(objects []ObjectConditions) *textTable {
+	table := newTextTable("KIND", "NAMESPACE", "NAME", "TYPE", "STATUS", "REASON")
+	for _, oc := range objects {
+		if len(oc.Conditions) == 0 {
+			table.addRow(oc.Kind, oc.Namespace, oc.Name)
+			continue
+		}
+		for _, cond := range oc.Conditions {
+			table.addRow(oc.Kind, oc.Namespace, oc.Name, cond.Type, cond.Status, cond.Reason)
+		}
+	}
+	return table
+}
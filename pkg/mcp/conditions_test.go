@@ -0,0 +1,78 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExtractConditions(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Certificate",
+		"metadata": map[string]interface{}{
+			"name":      "tls",
+			"namespace": "web",
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":               "Ready",
+					"status":             "False",
+					"reason":             "Pending",
+					"message":            "Issuing certificate",
+					"lastTransitionTime": "2025-01-01T00:00:00Z",
+				},
+				map[string]interface{}{
+					"status": "True",
+				},
+				"invalid",
+			},
+		},
+	}}
+
+	conditions := extractConditions(obj)
+	expected := []ConditionSummary{
+		{
+			Type:               "Ready",
+			Status:             "False",
+			Reason:             "Pending",
+			Message:            "Issuing certificate",
+			LastTransitionTime: "2025-01-01T00:00:00Z",
+		},
+	}
+	if !reflect.DeepEqual(conditions, expected) {
+		t.Errorf("expected conditions %+v, got %+v", expected, conditions)
+	}
+
+	table := conditionsTable([]ObjectConditions{
+		{Kind: "Certificate", Name: "tls", Namespace: "web", Conditions: conditions},
+		{Kind: "Certificate", Name: "api", Namespace: "web"},
+	}).String()
+	expectedTable := `KIND         NAMESPACE  NAME  TYPE    STATUS  REASON
+Certificate  web        api   <none>  <none>  <none>
+Certificate  web        tls   Ready   False   Pending`
+	if table != expectedTable {
+		t.Errorf("unexpected table:\n%s", table)
+	}
+
+	if conditions := extractConditions(&unstructured.Unstructured{Object: map[string]interface{}{}}); len(conditions) != 0 {
+		t.Errorf("expected no conditions, got %+v", conditions)
+	}
+}
@@ -0,0 +1,557 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	ProfileRestricted = "restricted"
+	ProfileNSA        = "nsa"
+)
+
+type ConformanceCheckInput struct {
+	Resource     string   `json:"resource,omitempty" jsonschema:"The workload type of a live workload (e.g. deployments statefulsets pods cronjobs)"`
+	Name         string   `json:"name,omitempty" jsonschema:"The name of the live workload"`
+	Namespace    string   `json:"namespace,omitempty" jsonschema:"The namespace of the live workload"`
+	ResourceYAML string   `json:"resourceYAML,omitempty" jsonschema:"Workload manifest(s) in YAML format to check instead of a live workload. Can contain multiple resources separated by ---"`
+	Profiles     []string `json:"profiles,omitempty" jsonschema:"The profiles to check against: restricted (Pod Security Standards), nsa (NSA/CISA hardening guidance) or the profiles configured by the operator (optional defaults to restricted)"`
+}
+
+type ConformanceCheckResult struct {
+	Workloads []WorkloadConformance `json:"workloads"`
+	Passed    bool                  `json:"passed"`
+}
+
+// WorkloadConformance are the findings of the checks of a workload.
+type WorkloadConformance struct {
+	Kind      string               `json:"kind"`
+	Name      string               `json:"name"`
+	Namespace string               `json:"namespace,omitempty"`
+	Passed    bool                 `json:"passed"`
+	Findings  []ConformanceFinding `json:"findings"`
+}
+
+// ConformanceFinding is the result of a rule of a profile, for a container when the rule applies to containers.
+type ConformanceFinding struct {
+	Profile   string `json:"profile"`
+	Rule      string `json:"rule"`
+	Container string `json:"container,omitempty"`
+	Passed    bool   `json:"passed"`
+	Message   string `json:"message"`
+	// Patch is a strategic merge patch of the workload fixing the failure, in YAML.
+	Patch string `json:"patch,omitempty"`
+}
+
+// ConformanceProfile is an operator configured profile, whose rules check fields of the workloads.
+type ConformanceProfile struct {
+	Name  string            `json:"name"`
+	Rules []ConformanceRule `json:"rules"`
+}
+
+// ConformanceRule requires the value of a field of the workloads to be set, and to be one of the
+// given values if any.
+type ConformanceRule struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	JSONPath    string   `json:"jsonPath"`
+	Values      []string `json:"values,omitempty"`
+	// Patch is the suggested patch of the workloads failing the rule, in YAML.
+	Patch string `json:"patch,omitempty"`
+}
+
+// LoadConformanceProfiles reads the operator configured conformance profiles from the given YAML file.
+func LoadConformanceProfiles(path string) ([]ConformanceProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conformance profiles from %s: %w", path, err)
+	}
+
+	var profiles []ConformanceProfile
+	if err := yaml.UnmarshalStrict(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse conformance profiles from %s: %w", path, err)
+	}
+
+	seen := map[string]bool{}
+	for _, profile := range profiles {
+		if profile.Name == "" {
+			return nil, fmt.Errorf("conformance profile is missing name")
+		}
+		if _, ok := builtinProfiles[profile.Name]; ok || seen[profile.Name] {
+			return nil, fmt.Errorf("conformance profile %s is defined more than once", profile.Name)
+		}
+		seen[profile.Name] = true
+		if len(profile.Rules) == 0 {
+			return nil, fmt.Errorf("conformance profile %s has no rules", profile.Name)
+		}
+		for _, rule := range profile.Rules {
+			if rule.Name == "" {
+				return nil, fmt.Errorf("rule of conformance profile %s is missing name", profile.Name)
+			}
+			if _, err := parseJSONPath(rule.Name, rule.JSONPath); err != nil {
+				return nil, fmt.Errorf("invalid jsonPath for rule %s of conformance profile %s: %w", rule.Name, profile.Name, err)
+			}
+			if rule.Patch != "" {
+				var patch map[string]interface{}
+				if err := yaml.Unmarshal([]byte(rule.Patch), &patch); err != nil {
+					return nil, fmt.Errorf("invalid patch for rule %s of conformance profile %s: %w", rule.Name, profile.Name, err)
+				}
+			}
+		}
+	}
+	return profiles, nil
+}
+
+// podRule is a built-in rule checking the pod spec of the workloads.
+type podRule struct {
+	name        string
+	description string
+	check       func(spec *corev1.PodSpec) []podViolation
+}
+
+// podViolation is a failure of a pod rule, with the patch of the pod spec fixing it when there is one.
+type podViolation struct {
+	container string
+	message   string
+	patch     map[string]interface{}
+}
+
+var (
+	hostNamespacesRule = podRule{
+		name:        "host-namespaces",
+		description: "Pods do not share the host network, PID or IPC namespaces",
+		check: func(spec *corev1.PodSpec) []podViolation {
+			patch := map[string]interface{}{}
+			for field, shared := range map[string]bool{"hostNetwork": spec.HostNetwork, "hostPID": spec.HostPID, "hostIPC": spec.HostIPC} {
+				if shared {
+					patch[field] = false
+				}
+			}
+			if len(patch) == 0 {
+				return nil
+			}
+			return []podViolation{{message: "the pod shares host namespaces", patch: patch}}
+		},
+	}
+	privilegedRule = podRule{
+		name:        "privileged",
+		description: "Containers are not privileged",
+		check: containerCheck(func(c *corev1.Container) (string, map[string]interface{}) {
+			if c.SecurityContext == nil || !ptr.Deref(c.SecurityContext.Privileged, false) {
+				return "", nil
+			}
+			return "the container is privileged", securityContextPatch("privileged", false)
+		}),
+	}
+	capabilitiesRule = podRule{
+		name:        "capabilities",
+		description: "Containers drop ALL capabilities and add at most NET_BIND_SERVICE",
+		check: containerCheck(func(c *corev1.Container) (string, map[string]interface{}) {
+			var capabilities *corev1.Capabilities
+			if c.SecurityContext != nil {
+				capabilities = c.SecurityContext.Capabilities
+			}
+			if capabilities == nil || !slices.Contains(capabilities.Drop, "ALL") {
+				return "the container does not drop ALL capabilities", securityContextPatch("capabilities", map[string]interface{}{"drop": []interface{}{"ALL"}})
+			}
+			for _, capability := range capabilities.Add {
+				if capability != "NET_BIND_SERVICE" {
+					return fmt.Sprintf("the container adds the %s capability", capability), nil
+				}
+			}
+			return "", nil
+		}),
+	}
+	hostPathRule = podRule{
+		name:        "host-path-volumes",
+		description: "Pods do not mount hostPath volumes",
+		check: func(spec *corev1.PodSpec) []podViolation {
+			var violations []podViolation
+			for _, volume := range spec.Volumes {
+				if volume.HostPath != nil {
+					violations = append(violations, podViolation{message: fmt.Sprintf("volume %s mounts host path %s", volume.Name, volume.HostPath.Path)})
+				}
+			}
+			return violations
+		},
+	}
+	hostPortsRule = podRule{
+		name:        "host-ports",
+		description: "Containers do not use host ports",
+		check: containerCheck(func(c *corev1.Container) (string, map[string]interface{}) {
+			for _, port := range c.Ports {
+				if port.HostPort != 0 {
+					return fmt.Sprintf("the container uses host port %d", port.HostPort), nil
+				}
+			}
+			return "", nil
+		}),
+	}
+	privilegeEscalationRule = podRule{
+		name:        "privilege-escalation",
+		description: "Containers set allowPrivilegeEscalation to false",
+		check: containerCheck(func(c *corev1.Container) (string, map[string]interface{}) {
+			if c.SecurityContext != nil && c.SecurityContext.AllowPrivilegeEscalation != nil && !*c.SecurityContext.AllowPrivilegeEscalation {
+				return "", nil
+			}
+			return "the container allows privilege escalation", securityContextPatch("allowPrivilegeEscalation", false)
+		}),
+	}
+	runAsNonRootRule = podRule{
+		name:        "run-as-non-root",
+		description: "Containers run as a non root user",
+		check: func(spec *corev1.PodSpec) []podViolation {
+			podNonRoot := spec.SecurityContext != nil && ptr.Deref(spec.SecurityContext.RunAsNonRoot, false)
+			podRoot := spec.SecurityContext != nil && ptr.Deref(spec.SecurityContext.RunAsUser, -1) == 0
+			return containerCheck(func(c *corev1.Container) (string, map[string]interface{}) {
+				nonRoot, root := podNonRoot, podRoot
+				if c.SecurityContext != nil {
+					if c.SecurityContext.RunAsNonRoot != nil {
+						nonRoot = *c.SecurityContext.RunAsNonRoot
+					}
+					if c.SecurityContext.RunAsUser != nil {
+						root = *c.SecurityContext.RunAsUser == 0
+					}
+				}
+				if root {
+					return "the container runs as user 0", securityContextPatch("runAsUser", int64(65532))
+				}
+				if !nonRoot {
+					return "the container does not set runAsNonRoot", securityContextPatch("runAsNonRoot", true)
+				}
+				return "", nil
+			})(spec)
+		},
+	}
+	seccompRule = podRule{
+		name:        "seccomp-profile",
+		description: "Containers use the RuntimeDefault or a Localhost seccomp profile",
+		check: func(spec *corev1.PodSpec) []podViolation {
+			allowed := func(profile *corev1.SeccompProfile) bool {
+				return profile != nil && (profile.Type == corev1.SeccompProfileTypeRuntimeDefault || profile.Type == corev1.SeccompProfileTypeLocalhost)
+			}
+			podAllowed := spec.SecurityContext != nil && allowed(spec.SecurityContext.SeccompProfile)
+			return containerCheck(func(c *corev1.Container) (string, map[string]interface{}) {
+				if c.SecurityContext != nil && c.SecurityContext.SeccompProfile != nil {
+					if allowed(c.SecurityContext.SeccompProfile) {
+						return "", nil
+					}
+				} else if podAllowed {
+					return "", nil
+				}
+				return "the container does not use the RuntimeDefault or a Localhost seccomp profile",
+					securityContextPatch("seccompProfile", map[string]interface{}{"type": string(corev1.SeccompProfileTypeRuntimeDefault)})
+			})(spec)
+		},
+	}
+	readOnlyRootFilesystemRule = podRule{
+		name:        "read-only-root-filesystem",
+		description: "Containers mount their root filesystem as read-only",
+		check: containerCheck(func(c *corev1.Container) (string, map[string]interface{}) {
+			if c.SecurityContext != nil && ptr.Deref(c.SecurityContext.ReadOnlyRootFilesystem, false) {
+				return "", nil
+			}
+			return "the root filesystem of the container is writable", securityContextPatch("readOnlyRootFilesystem", true)
+		}),
+	}
+	resourceLimitsRule = podRule{
+		name:        "resource-limits",
+		description: "Containers set CPU and memory limits",
+		check: containerCheck(func(c *corev1.Container) (string, map[string]interface{}) {
+			var missing []string
+			for _, resource := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				if _, ok := c.Resources.Limits[resource]; !ok {
+					missing = append(missing, string(resource))
+				}
+			}
+			if len(missing) == 0 {
+				return "", nil
+			}
+			// The values depend on the workload, the patch only shows where they go.
+			limits := map[string]interface{}{}
+			for _, resource := range missing {
+				limits[resource] = "<limit>"
+			}
+			return fmt.Sprintf("the container does not set %s limits", strings.Join(missing, " and ")),
+				map[string]interface{}{"resources": map[string]interface{}{"limits": limits}}
+		}),
+	}
+	serviceAccountTokenRule = podRule{
+		name:        "service-account-token",
+		description: "Pods do not mount a service account token",
+		check: func(spec *corev1.PodSpec) []podViolation {
+			if spec.AutomountServiceAccountToken != nil && !*spec.AutomountServiceAccountToken {
+				return nil
+			}
+			return []podViolation{{
+				message: "the service account token is mounted, disable it if the workload does not call the API server",
+				patch:   map[string]interface{}{"automountServiceAccountToken": false},
+			}}
+		},
+	}
+)
+
+// builtinProfiles are the profiles available without configuration: the restricted Pod Security
+// Standard, and the pod hardening of the NSA/CISA Kubernetes Hardening Guidance.
+var builtinProfiles = map[string][]podRule{
+	ProfileRestricted: {hostNamespacesRule, privilegedRule, capabilitiesRule, hostPathRule, hostPortsRule,
+		privilegeEscalationRule, runAsNonRootRule, seccompRule},
+	ProfileNSA: {runAsNonRootRule, readOnlyRootFilesystemRule, privilegedRule, privilegeEscalationRule,
+		hostNamespacesRule, capabilitiesRule, resourceLimitsRule, serviceAccountTokenRule},
+}
+
+// containerCheck checks every container and init container of the pod with the given function, which
+// returns why the container fails and the patch of the container fixing it.
+func containerCheck(check func(c *corev1.Container) (string, map[string]interface{})) func(spec *corev1.PodSpec) []podViolation {
+	return func(spec *corev1.PodSpec) []podViolation {
+		var violations []podViolation
+		for _, group := range []struct {
+			field      string
+			containers []corev1.Container
+		}{{"initContainers", spec.InitContainers}, {"containers", spec.Containers}} {
+			for i := range group.containers {
+				message, patch := check(&group.containers[i])
+				if message == "" {
+					continue
+				}
+				violation := podViolation{container: group.containers[i].Name, message: message}
+				if patch != nil {
+					patch["name"] = group.containers[i].Name
+					violation.patch = map[string]interface{}{group.field: []interface{}{patch}}
+				}
+				violations = append(violations, violation)
+			}
+		}
+		return violations
+	}
+}
+
+func securityContextPatch(field string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"securityContext": map[string]interface{}{field: value}}
+}
+
+// podSpecPath returns the path of the pod spec of the workloads of the kind.
+func podSpecPath(kind string) ([]string, bool) {
+	switch kind {
+	case "Pod":
+		return []string{"spec"}, true
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		return []string{"spec", "template", "spec"}, true
+	case "CronJob":
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}, true
+	}
+	return nil, false
+}
+
+// checkConformance checks a workload against the profiles.
+func checkConformance(obj *unstructured.Unstructured, profiles []string, custom []ConformanceProfile) (WorkloadConformance, error) {
+	result := WorkloadConformance{Kind: obj.GetKind(), Name: obj.GetName(), Namespace: obj.GetNamespace(), Passed: true, Findings: []ConformanceFinding{}}
+	path, ok := podSpecPath(obj.GetKind())
+	if !ok {
+		return result, fmt.Errorf("%s/%s is not a workload with a pod template", obj.GetKind(), obj.GetName())
+	}
+	specObj, _, err := unstructured.NestedMap(obj.Object, path...)
+	if err != nil {
+		return result, fmt.Errorf("invalid pod spec of %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	var spec corev1.PodSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(specObj, &spec); err != nil {
+		return result, fmt.Errorf("invalid pod spec of %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	for _, profile := range profiles {
+		if rules, ok := builtinProfiles[profile]; ok {
+			for _, rule := range rules {
+				violations := rule.check(&spec)
+				if len(violations) == 0 {
+					result.Findings = append(result.Findings, ConformanceFinding{Profile: profile, Rule: rule.name, Passed: true, Message: rule.description})
+					continue
+				}
+				for _, violation := range violations {
+					finding := ConformanceFinding{Profile: profile, Rule: rule.name, Container: violation.container, Message: violation.message}
+					if violation.patch != nil {
+						finding.Patch, err = workloadPatch(path, violation.patch)
+						if err != nil {
+							return result, err
+						}
+					}
+					result.Findings = append(result.Findings, finding)
+				}
+			}
+			continue
+		}
+
+		index := slices.IndexFunc(custom, func(p ConformanceProfile) bool { return p.Name == profile })
+		if index < 0 {
+			return result, fmt.Errorf("unknown conformance profile %q, must be one of: %s", profile, strings.Join(conformanceProfileNames(custom), ", "))
+		}
+		for _, rule := range custom[index].Rules {
+			result.Findings = append(result.Findings, checkRule(obj, profile, rule))
+		}
+	}
+
+	for _, finding := range result.Findings {
+		result.Passed = result.Passed && finding.Passed
+	}
+	return result, nil
+}
+
+// checkRule checks a workload against a rule of a configured profile.
+func checkRule(obj *unstructured.Unstructured, profile string, rule ConformanceRule) ConformanceFinding {
+	finding := ConformanceFinding{Profile: profile, Rule: rule.Name, Passed: true, Message: rule.Description}
+	value, err := evaluateJSONPath(obj.Object, rule.JSONPath)
+	switch {
+	case err != nil || value == "":
+		finding.Passed = false
+		finding.Message = fmt.Sprintf("%s is not set", rule.JSONPath)
+	case len(rule.Values) > 0 && !slices.Contains(rule.Values, value):
+		finding.Passed = false
+		finding.Message = fmt.Sprintf("%s is %q, must be one of: %s", rule.JSONPath, value, strings.Join(rule.Values, ", "))
+	}
+	if finding.Message == "" {
+		finding.Message = fmt.Sprintf("%s is set", rule.JSONPath)
+	}
+	if !finding.Passed {
+		finding.Patch = rule.Patch
+	}
+	return finding
+}
+
+// workloadPatch nests the patch of a pod spec at the path of the pod spec of the workload.
+func workloadPatch(path []string, patch map[string]interface{}) (string, error) {
+	for i := len(path) - 1; i >= 0; i-- {
+		patch = map[string]interface{}{path[i]: patch}
+	}
+	data, err := yaml.Marshal(patch)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode patch: %w", err)
+	}
+	return string(data), nil
+}
+
+func conformanceProfileNames(custom []ConformanceProfile) []string {
+	names := []string{ProfileNSA, ProfileRestricted}
+	for _, profile := range custom {
+		names = append(names, profile.Name)
+	}
+	return names
+}
+
+func (s *Server) addConformanceCheckTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "conformance_check",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Check workloads against best-practice profiles",
+		},
+		Description: "Check a live workload or workload manifests against profiles: restricted (Pod Security Standards), nsa (NSA/CISA hardening guidance) or the profiles configured by the operator. " +
+			"Returns the passed and failed rules, with a strategic merge patch fixing the failures when possible",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ConformanceCheckInput) (*mcp.CallToolResult, *ConformanceCheckResult, error) {
+		profiles := input.Profiles
+		if len(profiles) == 0 {
+			profiles = []string{ProfileRestricted}
+		}
+		slices.Sort(profiles)
+		profiles = slices.Compact(profiles)
+
+		var objects []*unstructured.Unstructured
+		switch {
+		case input.ResourceYAML != "" && input.Resource != "":
+			return nil, nil, fmt.Errorf("either a live workload or a manifest can be checked, not both")
+		case input.ResourceYAML != "":
+			var err error
+			objects, err = decodeManifests(input.ResourceYAML)
+			if err != nil {
+				return nil, nil, err
+			}
+		case input.Resource != "" && input.Name != "":
+			dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+			}
+			gvr, isNamespaced, err := FindResource(ctx, input.Resource, discoveryClient, request.Session)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to find resource: %w", err)
+			}
+			if isNamespaced && input.Namespace == "" {
+				input.Namespace, err = s.sessionNamespace(ctx, request, input.Resource)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			obj, err := dynamicClient.Resource(gvr).Namespace(input.Namespace).Get(ctx, input.Name, v1.GetOptions{})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get resource: %w", err)
+			}
+			objects = append(objects, obj)
+		default:
+			return nil, nil, fmt.Errorf("a live workload (resource and name) or a manifest is required")
+		}
+
+		result := &ConformanceCheckResult{Workloads: []WorkloadConformance{}, Passed: true}
+		workloads := newTextTable("KIND", "NAMESPACE", "NAME", "FAILED", "CHECKS")
+		failures := newTextTable("KIND", "NAME", "PROFILE", "RULE", "CONTAINER", "MESSAGE")
+		for _, obj := range objects {
+			workload, err := checkConformance(obj, profiles, s.reloadable().ConformanceProfiles)
+			if err != nil {
+				return nil, nil, err
+			}
+			result.Workloads = append(result.Workloads, workload)
+			result.Passed = result.Passed && workload.Passed
+
+			failed := 0
+			for _, finding := range workload.Findings {
+				if finding.Passed {
+					continue
+				}
+				failed++
+				failures.addRow(workload.Kind, workload.Name, finding.Profile, finding.Rule, finding.Container, finding.Message)
+			}
+			workloads.addRow(workload.Kind, workload.Namespace, workload.Name, strconv.Itoa(failed), strconv.Itoa(len(workload.Findings)))
+		}
+
+		message := textSummary(fmt.Sprintf("Checked %d workload(s) against %s:", len(objects), strings.Join(profiles, ", ")), workloads.String())
+		if !result.Passed {
+			message = textSummary(message, "Failed checks:\n"+failures.String())
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, result, nil
+	})
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const hardenedDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+  labels:
+    team: payments
+spec:
+  template:
+    spec:
+      automountServiceAccountToken: false
+      securityContext:
+        runAsNonRoot: true
+        seccompProfile:
+          type: RuntimeDefault
+      containers:
+      - name: web
+        image: nginx
+        resources:
+          limits:
+            cpu: 500m
+            memory: 256Mi
+        securityContext:
+          allowPrivilegeEscalation: false
+          readOnlyRootFilesystem: true
+          capabilities:
+            drop: ["ALL"]
+            add: ["NET_BIND_SERVICE"]
+`
+
+const insecureCronJob = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          hostNetwork: true
+          volumes:
+          - name: host
+            hostPath:
+              path: /var/lib
+          initContainers:
+          - name: init
+            image: busybox
+            securityContext:
+              allowPrivilegeEscalation: false
+              runAsNonRoot: true
+              seccompProfile:
+                type: RuntimeDefault
+              capabilities:
+                drop: ["ALL"]
+          containers:
+          - name: backup
+            image: busybox
+            ports:
+            - containerPort: 8080
+              hostPort: 8080
+            securityContext:
+              privileged: true
+              runAsUser: 0
+`
+
+func TestCheckConformance(t *testing.T) {
+	custom := []ConformanceProfile{{
+		Name: "acme",
+		Rules: []ConformanceRule{
+			{Name: "team-label", JSONPath: ".metadata.labels.team", Values: []string{"payments", "search"}, Patch: "metadata:\n  labels:\n    team: <team>\n"},
+			{Name: "namespace", Description: "Workloads are namespaced", JSONPath: ".metadata.namespace"},
+		},
+	}}
+
+	tests := []struct {
+		name           string
+		manifest       string
+		profiles       []string
+		expectedPassed bool
+		// expectedFailures are the failed profile/rule/container of the findings.
+		expectedFailures []string
+		expectedPatch    string
+		expectedError    string
+	}{
+		{
+			name:           "hardened deployment",
+			manifest:       hardenedDeployment,
+			profiles:       []string{ProfileNSA, ProfileRestricted, "acme"},
+			expectedPassed: true,
+		},
+		{
+			name:     "insecure cronjob",
+			manifest: insecureCronJob,
+			profiles: []string{ProfileRestricted},
+			expectedFailures: []string{
+				"restricted/host-namespaces/",
+				"restricted/privileged/backup",
+				"restricted/capabilities/backup",
+				"restricted/host-path-volumes/",
+				"restricted/host-ports/backup",
+				"restricted/privilege-escalation/backup",
+				"restricted/run-as-non-root/backup",
+				"restricted/seccomp-profile/backup",
+			},
+			expectedPatch: `spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: backup
+            securityContext:
+              privileged: false
+`,
+		},
+		{
+			name:             "custom profile",
+			manifest:         insecureCronJob,
+			profiles:         []string{"acme"},
+			expectedFailures: []string{"acme/team-label/", "acme/namespace/"},
+			expectedPatch:    "metadata:\n  labels:\n    team: <team>\n",
+		},
+		{
+			name:          "unknown profile",
+			manifest:      hardenedDeployment,
+			profiles:      []string{"baseline"},
+			expectedError: `unknown conformance profile "baseline", must be one of: nsa, restricted, acme`,
+		},
+		{
+			name:          "not a workload",
+			manifest:      "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+			profiles:      []string{ProfileRestricted},
+			expectedError: "ConfigMap/config is not a workload with a pod template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := decodeManifests(tt.manifest)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result, err := checkConformance(objects[0], tt.profiles, custom)
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Passed != tt.expectedPassed {
+				t.Errorf("expected passed %v, got %v", tt.expectedPassed, result.Passed)
+			}
+
+			var failures []string
+			patchFound := tt.expectedPatch == ""
+			for _, finding := range result.Findings {
+				if finding.Passed {
+					continue
+				}
+				failures = append(failures, finding.Profile+"/"+finding.Rule+"/"+finding.Container)
+				patchFound = patchFound || finding.Patch == tt.expectedPatch
+			}
+			if !reflect.DeepEqual(failures, tt.expectedFailures) {
+				t.Errorf("expected failures %v, got %v", tt.expectedFailures, failures)
+			}
+			if !patchFound {
+				t.Errorf("expected a finding with patch:\n%s\ngot %+v", tt.expectedPatch, result.Findings)
+			}
+		})
+	}
+}
+
+func TestLoadConformanceProfiles(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			content: `- name: acme
+  rules:
+  - name: team-label
+    jsonPath: .metadata.labels.team
+`,
+		},
+		{
+			name:          "built-in profile",
+			content:       "- name: restricted\n  rules:\n  - name: team\n    jsonPath: .metadata.labels.team\n",
+			expectedError: "conformance profile restricted is defined more than once",
+		},
+		{
+			name:          "invalid jsonPath",
+			content:       "- name: acme\n  rules:\n  - name: team\n    jsonPath: '{.metadata'\n",
+			expectedError: "invalid jsonPath for rule team of conformance profile acme",
+		},
+		{
+			name:          "no rules",
+			content:       "- name: acme\n",
+			expectedError: "conformance profile acme has no rules",
+		},
+		{
+			name:          "unknown field",
+			content:       "- name: acme\n  rule: []\n",
+			expectedError: "failed to parse conformance profiles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profiles.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConformanceProfiles(path)
+			if tt.expectedError == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.expectedError != "" && (err == nil || !strings.Contains(err.Error(), tt.expectedError)) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
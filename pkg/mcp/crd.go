@@ -0,0 +1,174 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+var crdsGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+type CRDListInput struct {
+	Group         string `json:"group,omitempty" jsonschema:"Only list CustomResourceDefinitions of this API group (e.g. cert-manager.io)"`
+	Name          string `json:"name,omitempty" jsonschema:"Only list CustomResourceDefinitions whose name, kind or plural contains this value"`
+	IncludeSchema bool   `json:"includeSchema,omitempty" jsonschema:"Include the OpenAPI validation schema of every version. Schemas are large, so combine with group or name filters"`
+}
+
+type CRDListResult struct {
+	CRDs []CRDInfo `json:"crds"`
+}
+
+// CRDInfo is the catalog entry of a CustomResourceDefinition.
+type CRDInfo struct {
+	Name        string           `json:"name"`
+	Group       string           `json:"group"`
+	Kind        string           `json:"kind"`
+	Plural      string           `json:"plural"`
+	ShortNames  []string         `json:"shortNames,omitempty"`
+	Categories  []string         `json:"categories,omitempty"`
+	Scope       string           `json:"scope"`
+	Established bool             `json:"established"`
+	Versions    []CRDVersionInfo `json:"versions"`
+}
+
+type CRDVersionInfo struct {
+	Name       string                 `json:"name"`
+	Served     bool                   `json:"served"`
+	Storage    bool                   `json:"storage"`
+	Deprecated bool                   `json:"deprecated,omitempty"`
+	Schema     map[string]interface{} `json:"schema,omitempty"`
+}
+
+func (s *Server) addCRDListTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "crd_list",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "List CustomResourceDefinitions",
+		},
+		Description: "List the CustomResourceDefinitions installed in the cluster with their group, kind, scope and versions. Optionally includes the OpenAPI validation schema of each version to learn how to write custom resources",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input CRDListInput) (*mcp.CallToolResult, *CRDListResult, error) {
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		list, err := dynamicClient.Resource(crdsGVR).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list custom resource definitions: %w", err)
+		}
+
+		crds := make([]CRDInfo, 0, len(list.Items))
+		for _, item := range list.Items {
+			info := crdInfo(&item, input.IncludeSchema)
+			if input.Group != "" && !strings.EqualFold(info.Group, input.Group) {
+				continue
+			}
+			if input.Name != "" && !matchesCRDName(info, input.Name) {
+				continue
+			}
+			crds = append(crds, info)
+		}
+		sort.Slice(crds, func(i, j int) bool {
+			return crds[i].Name < crds[j].Name
+		})
+
+		message := fmt.Sprintf("Found %d custom resource definitions", len(crds))
+		if len(crds) > 0 {
+			table := newTextTable("NAME", "KIND", "SCOPE", "VERSIONS")
+			for _, crd := range crds {
+				versions := make([]string, 0, len(crd.Versions))
+				for _, version := range crd.Versions {
+					versions = append(versions, version.Name)
+				}
+				table.addRow(crd.Name, crd.Kind, crd.Scope, strings.Join(versions, ","))
+			}
+			message = textSummary(message, table.String())
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, &CRDListResult{CRDs: crds}, nil
+	})
+}
+
+// crdInfo extracts the catalog entry from an unstructured CustomResourceDefinition.
+func crdInfo(crd *unstructured.Unstructured, includeSchema bool) CRDInfo {
+	info := CRDInfo{
+		Name:     crd.GetName(),
+		Versions: []CRDVersionInfo{},
+	}
+	info.Group, _, _ = unstructured.NestedString(crd.Object, "spec", "group")
+	info.Kind, _, _ = unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	info.Plural, _, _ = unstructured.NestedString(crd.Object, "spec", "names", "plural")
+	info.ShortNames, _, _ = unstructured.NestedStringSlice(crd.Object, "spec", "names", "shortNames")
+	info.Categories, _, _ = unstructured.NestedStringSlice(crd.Object, "spec", "names", "categories")
+	info.Scope, _, _ = unstructured.NestedString(crd.Object, "spec", "scope")
+
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cond["type"] == "Established" && cond["status"] == "True" {
+			info.Established = true
+		}
+	}
+
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		versionInfo := CRDVersionInfo{}
+		versionInfo.Name, _, _ = unstructured.NestedString(version, "name")
+		versionInfo.Served, _, _ = unstructured.NestedBool(version, "served")
+		versionInfo.Storage, _, _ = unstructured.NestedBool(version, "storage")
+		versionInfo.Deprecated, _, _ = unstructured.NestedBool(version, "deprecated")
+		if includeSchema {
+			versionInfo.Schema, _, _ = unstructured.NestedMap(version, "schema", "openAPIV3Schema")
+		}
+		info.Versions = append(info.Versions, versionInfo)
+	}
+
+	return info
+}
+
+func matchesCRDName(info CRDInfo, name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(strings.ToLower(info.Name), name) ||
+		strings.Contains(strings.ToLower(info.Kind), name) ||
+		strings.Contains(strings.ToLower(info.Plural), name)
+}
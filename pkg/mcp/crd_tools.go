@@ -0,0 +1,367 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+// maxDescribedFields bounds the number of spec fields listed in the descriptions of the CRD tools.
+const maxDescribedFields = 15
+
+var crdToolNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// CRDTool configures the dedicated list and get tools of a CustomResourceDefinition,
+// named <tool>_list and <tool>_get.
+type CRDTool struct {
+	// CRD is the name of the CustomResourceDefinition, e.g. rollouts.argoproj.io.
+	CRD  string `json:"crd"`
+	Tool string `json:"tool"`
+	// Description is prepended to the descriptions of the tools.
+	Description string `json:"description,omitempty"`
+}
+
+type CRDToolListInput struct {
+	Namespace     string `json:"namespace,omitempty" jsonschema:"The namespace to list resources from (optional defaults to all namespaces)"`
+	LabelSelector string `json:"labelSelector,omitempty" jsonschema:"Label selector to filter resources (e.g. app=myapp)"`
+	Limit         int64  `json:"limit,omitempty" jsonschema:"The maximum number of resources to return. A continue token is returned when more resources are available (optional defaults to all resources)"`
+	Continue      string `json:"continue,omitempty" jsonschema:"The continue token returned by the previous call to get the next page"`
+}
+
+type CRDToolGetInput struct {
+	Name      string `json:"name,required" jsonschema:"The name of the resource"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"The namespace of the resource (required for namespaced resources)"`
+}
+
+// LoadCRDTools reads the CustomResourceDefinitions to generate tools for from the given YAML file.
+func LoadCRDTools(path string) ([]CRDTool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CRD tools from %s: %w", path, err)
+	}
+
+	var tools []CRDTool
+	if err := yaml.UnmarshalStrict(data, &tools); err != nil {
+		return nil, fmt.Errorf("failed to parse CRD tools from %s: %w", path, err)
+	}
+
+	builtin := map[string]bool{}
+	for _, names := range toolsets {
+		for _, name := range names {
+			builtin[name] = true
+		}
+	}
+	seen := map[string]bool{}
+	for _, tool := range tools {
+		if !crdToolNameRegexp.MatchString(tool.Tool) {
+			return nil, fmt.Errorf("invalid tool name %q of CRD %s, must be lowercase letters, digits and underscores", tool.Tool, tool.CRD)
+		}
+		if plural, group, ok := strings.Cut(tool.CRD, "."); !ok || plural == "" || group == "" {
+			return nil, fmt.Errorf("invalid CRD name %q of tool %s, must be <plural>.<group>", tool.CRD, tool.Tool)
+		}
+		for _, name := range []string{tool.Tool + "_list", tool.Tool + "_get"} {
+			if builtin[name] || seen[name] {
+				return nil, fmt.Errorf("tool %s is defined more than once", name)
+			}
+			seen[name] = true
+		}
+	}
+	return tools, nil
+}
+
+// crdTarget is the API resource of a CustomResourceDefinition, with the summary of its schema.
+type crdTarget struct {
+	gvr        schema.GroupVersionResource
+	kind       string
+	namespaced bool
+	schema     map[string]interface{}
+}
+
+// getCRDTarget reads a CustomResourceDefinition and returns the resource of its storage version,
+// or of its first served version when the storage version is not served anymore.
+func getCRDTarget(ctx context.Context, dynamicClient dynamic.Interface, name string) (*crdTarget, error) {
+	crd, err := dynamicClient.Resource(crdsGVR).Get(ctx, name, v1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom resource definition %s: %w", name, err)
+	}
+	info := crdInfo(crd, true)
+
+	var version *CRDVersionInfo
+	for i := range info.Versions {
+		if !info.Versions[i].Served {
+			continue
+		}
+		if version == nil || info.Versions[i].Storage {
+			version = &info.Versions[i]
+		}
+	}
+	if version == nil {
+		return nil, fmt.Errorf("custom resource definition %s serves no version", name)
+	}
+	return &crdTarget{
+		gvr:        schema.GroupVersionResource{Group: info.Group, Version: version.Name, Resource: info.Plural},
+		kind:       info.Kind,
+		namespaced: info.Scope == "Namespaced",
+		schema:     version.Schema,
+	}, nil
+}
+
+// describe returns the description of the resources of the CRD built from its schema: its version,
+// scope, description and the fields of its spec.
+func (t *crdTarget) describe() string {
+	scope := "cluster scoped"
+	if t.namespaced {
+		scope = "namespaced"
+	}
+	description := fmt.Sprintf("%s is %s, served as %s.", t.kind, scope, t.gvr.GroupVersion())
+	if text, _, _ := unstructured.NestedString(t.schema, "description"); text != "" {
+		description += " " + firstSentence(text)
+	}
+
+	properties, _, _ := unstructured.NestedMap(t.schema, "properties", "spec", "properties")
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var fields []string
+	for i, name := range names {
+		if i == maxDescribedFields {
+			fields = append(fields, fmt.Sprintf("and %d more", len(names)-maxDescribedFields))
+			break
+		}
+		property, _ := properties[name].(map[string]interface{})
+		field := name
+		if fieldType, _, _ := unstructured.NestedString(property, "type"); fieldType != "" {
+			field += " (" + fieldType + ")"
+		}
+		if text, _, _ := unstructured.NestedString(property, "description"); text != "" {
+			field += ": " + firstSentence(text)
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) > 0 {
+		description += " Spec fields: " + strings.Join(fields, "; ")
+	}
+	return description
+}
+
+// firstSentence returns the first sentence of a schema description, on a single line.
+func firstSentence(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if i := strings.Index(text, ". "); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
+
+// crdTools registers the tools of the configured CustomResourceDefinitions. The CRDs are only
+// known once a session connects with its token, so the tools are first registered with generic
+// descriptions, then registered again with descriptions built from the CRD schemas of the cluster
+// of the first session, which notifies the clients that the tools changed.
+type crdTools struct {
+	s             *Server
+	server        *mcp.Server
+	dynamicConfig *DynamicConfig
+
+	mu        sync.Mutex
+	described map[string]bool
+}
+
+func (s *Server) addCRDTools(server *mcp.Server, dynamicConfig *DynamicConfig) *crdTools {
+	t := &crdTools{s: s, server: server, dynamicConfig: dynamicConfig, described: map[string]bool{}}
+	for _, tool := range s.CRDTools {
+		t.register(tool, "")
+	}
+	return t
+}
+
+// register adds the list and get tools of a CRD, replacing the previous ones.
+func (t *crdTools) register(tool CRDTool, schemaDescription string) {
+	description := tool.Description
+	if description != "" && !strings.HasSuffix(description, ".") {
+		description += "."
+	}
+	if schemaDescription != "" {
+		description = strings.TrimSpace(description + " " + schemaDescription)
+	}
+	dynamicConfig := t.dynamicConfig
+
+	addTool(t.server, &mcp.Tool{
+		Name: tool.Tool + "_list",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           fmt.Sprintf("List %s", tool.CRD),
+		},
+		Description:  strings.TrimSpace(fmt.Sprintf("List the %s custom resources. %s", tool.CRD, description)),
+		OutputSchema: outputSchema[ResourceListResult]("resources"),
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input CRDToolListInput) (*mcp.CallToolResult, *ResourceListResult, error) {
+		if input.Limit < 0 {
+			return nil, nil, fmt.Errorf("limit must not be negative")
+		}
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+		target, err := getCRDTarget(ctx, dynamicClient, tool.CRD)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resources, err := dynamicClient.Resource(target.gvr).Namespace(input.Namespace).List(ctx, v1.ListOptions{
+			LabelSelector: input.LabelSelector,
+			Limit:         input.Limit,
+			Continue:      input.Continue,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list resources: %w", err)
+		}
+
+		message := fmt.Sprintf("Found %d %s resources", len(resources.Items), tool.CRD)
+		if input.Namespace != "" {
+			message += fmt.Sprintf(" in namespace '%s'", input.Namespace)
+		}
+		message += clusterSuffix(request)
+		if resources.GetContinue() != "" {
+			message += ". More resources are available, call again with the continue token to get the next page"
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, &ResourceListResult{
+			Resources:          shapeObjects(resources.Items, OutputModeFull, nil),
+			Continue:           resources.GetContinue(),
+			RemainingItemCount: resources.GetRemainingItemCount(),
+			APIServerURL:       requestAPIServerURL(request),
+			Cluster:            requestCluster(request),
+		}, nil
+	})
+
+	addTool(t.server, &mcp.Tool{
+		Name: tool.Tool + "_get",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           fmt.Sprintf("Get a %s resource", tool.CRD),
+		},
+		Description:  strings.TrimSpace(fmt.Sprintf("Get a %s custom resource by name. %s", tool.CRD, description)),
+		OutputSchema: outputSchema[ResourceGetResult]("resource"),
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input CRDToolGetInput) (*mcp.CallToolResult, *ResourceGetResult, error) {
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+		target, err := getCRDTarget(ctx, dynamicClient, tool.CRD)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !target.namespaced {
+			input.Namespace = ""
+		} else if input.Namespace == "" {
+			input.Namespace, err = t.s.sessionNamespace(ctx, request, tool.CRD)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		resource, err := dynamicClient.Resource(target.gvr).Namespace(input.Namespace).Get(ctx, input.Name, v1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get resource: %w", err)
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Retrieved %s/%s%s", target.kind, input.Name, clusterSuffix(request)),
+				},
+			},
+		}, &ResourceGetResult{Resource: resource.Object, APIServerURL: requestAPIServerURL(request), Cluster: requestCluster(request)}, nil
+	})
+}
+
+// describe registers again the tools of the CRDs not described yet with the descriptions
+// built from their schemas in the cluster of the token.
+func (t *crdTools) describe(ctx context.Context, tokenInfo *auth.TokenInfo) {
+	dynamicClient, _, err := t.dynamicConfig.LoadRestConfigForTokenInfo(tokenInfo)
+	if err != nil {
+		return
+	}
+	for _, tool := range t.s.CRDTools {
+		t.mu.Lock()
+		described := t.described[tool.Tool]
+		t.mu.Unlock()
+		if described {
+			continue
+		}
+
+		target, err := getCRDTarget(ctx, dynamicClient, tool.CRD)
+		if err != nil {
+			slog.Warn("Failed to describe the tools of a CRD", "crd", tool.CRD, "err", err)
+			continue
+		}
+		t.mu.Lock()
+		if !t.described[tool.Tool] {
+			t.described[tool.Tool] = true
+			t.register(tool, target.describe())
+		}
+		t.mu.Unlock()
+	}
+}
+
+// middleware describes the CRD tools with the token of the first initialized sessions.
+func (t *crdTools) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if method != methodInitialized || err != nil || len(t.s.CRDTools) == 0 {
+				return result, err
+			}
+			t.mu.Lock()
+			pending := len(t.described) < len(t.s.CRDTools)
+			t.mu.Unlock()
+			if extra := req.GetExtra(); pending && extra != nil && extra.TokenInfo != nil {
+				// Don't hold the notification while the API server is contacted.
+				go t.describe(context.Background(), extra.TokenInfo)
+			}
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func rolloutsCRD() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "rollouts.argoproj.io"},
+		"spec": map[string]interface{}{
+			"group": "argoproj.io",
+			"scope": "Namespaced",
+			"names": map[string]interface{}{"kind": "Rollout", "plural": "rollouts"},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1alpha0", "served": false, "storage": false},
+				map[string]interface{}{
+					"name": "v1alpha1", "served": true, "storage": true,
+					"schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{
+						"description": "Rollout is a progressively delivered Deployment. It replaces Deployments.",
+						"properties": map[string]interface{}{"spec": map[string]interface{}{
+							"properties": map[string]interface{}{
+								"replicas": map[string]interface{}{"type": "integer", "description": "Number of desired pods.\nDefaults to 1."},
+								"strategy": map[string]interface{}{"type": "object"},
+							},
+						}},
+					}},
+				},
+			},
+		},
+	}}
+}
+
+func TestGetCRDTarget(t *testing.T) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), rolloutsCRD())
+
+	target, err := getCRDTarget(context.Background(), dynamicClient, "rollouts.argoproj.io")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedGVR := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+	if target.gvr != expectedGVR || target.kind != "Rollout" || !target.namespaced {
+		t.Errorf("unexpected target %+v", target)
+	}
+
+	expected := "Rollout is namespaced, served as argoproj.io/v1alpha1. Rollout is a progressively delivered Deployment. " +
+		"Spec fields: replicas (integer): Number of desired pods.; strategy (object)"
+	if description := target.describe(); description != expected {
+		t.Errorf("expected description %q, got %q", expected, description)
+	}
+
+	if _, err := getCRDTarget(context.Background(), dynamicClient, "certificates.cert-manager.io"); err == nil {
+		t.Errorf("expected error for a missing CRD")
+	}
+}
+
+func TestCRDToolsRegister(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer("8080", "k-mcp")
+	s.CRDTools = []CRDTool{{CRD: "rollouts.argoproj.io", Tool: "argo_rollouts", Description: "Argo Rollouts of the platform team"}}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	tools := s.addCRDTools(server, nil)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	descriptions := func() map[string]string {
+		result, err := clientSession.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		descriptions := map[string]string{}
+		for _, tool := range result.Tools {
+			descriptions[tool.Name] = tool.Description
+		}
+		return descriptions
+	}
+
+	listed := descriptions()
+	if listed["argo_rollouts_list"] != "List the rollouts.argoproj.io custom resources. Argo Rollouts of the platform team." || listed["argo_rollouts_get"] == "" {
+		t.Errorf("unexpected tools %v", listed)
+	}
+
+	tools.register(s.CRDTools[0], "Rollout is namespaced, served as argoproj.io/v1alpha1.")
+	if description := descriptions()["argo_rollouts_get"]; !strings.HasSuffix(description, "Argo Rollouts of the platform team. Rollout is namespaced, served as argoproj.io/v1alpha1.") {
+		t.Errorf("expected the description built from the schema, got %q", description)
+	}
+}
+
+func TestLoadCRDTools(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:    "valid",
+			content: "- crd: rollouts.argoproj.io\n  tool: argo_rollouts\n- crd: certificates.cert-manager.io\n  tool: certmanager_certificates\n",
+		},
+		{
+			name:          "invalid tool name",
+			content:       "- crd: rollouts.argoproj.io\n  tool: Argo-Rollouts\n",
+			expectedError: `invalid tool name "Argo-Rollouts"`,
+		},
+		{
+			name:          "invalid CRD name",
+			content:       "- crd: rollouts\n  tool: argo_rollouts\n",
+			expectedError: `invalid CRD name "rollouts"`,
+		},
+		{
+			name:          "duplicate tool",
+			content:       "- crd: rollouts.argoproj.io\n  tool: argo\n- crd: applications.argoproj.io\n  tool: argo\n",
+			expectedError: "tool argo_list is defined more than once",
+		},
+		{
+			name:          "built-in tool",
+			content:       "- crd: resources.example.com\n  tool: resource\n",
+			expectedError: "tool resource_list is defined more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "crd-tools.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadCRDTools(path)
+			if tt.expectedError == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.expectedError != "" && (err == nil || !strings.Contains(err.Error(), tt.expectedError)) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+const (
+	// maxExchangedCredentialCacheSize bounds the number of cached credentials.
+	maxExchangedCredentialCacheSize = 1024
+	// exchangedCredentialExpiryMargin is how long before their expiration the credentials are exchanged
+	// again, so that they do not expire during a tool call.
+	exchangedCredentialExpiryMargin = 30 * time.Second
+	// maxTokenExchangeResponseSize bounds the responses read from the token exchange service.
+	maxTokenExchangeResponseSize = 1 << 20
+)
+
+// Token types of OAuth 2.0 Token Exchange (RFC 8693).
+const (
+	tokenExchangeGrantType   = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeJWT             = "urn:ietf:params:oauth:token-type:jwt"
+	tokenTypeAccessToken     = "urn:ietf:params:oauth:token-type:access_token"
+	tokenExchangeContentType = "application/x-www-form-urlencoded"
+)
+
+// ClusterCredential is the bearer token sent to an API server on behalf of a caller.
+type ClusterCredential struct {
+	Token string
+	// Expiration is when the token expires. Zero means it expires with the inbound token.
+	Expiration time.Time
+}
+
+// CredentialExchanger exchanges the validated inbound token of a caller for the credential of the API
+// server it targets, so that k-mcp can be used with clusters not trusting the issuer of the inbound
+// tokens. The exchange must fail with an error wrapping auth.ErrInvalidToken when the inbound token
+// is refused.
+type CredentialExchanger interface {
+	Exchange(ctx context.Context, apiServerURL, token string) (*ClusterCredential, error)
+}
+
+// CredentialExchangerFunc adapts a function to a CredentialExchanger.
+type CredentialExchangerFunc func(ctx context.Context, apiServerURL, token string) (*ClusterCredential, error)
+
+func (f CredentialExchangerFunc) Exchange(ctx context.Context, apiServerURL, token string) (*ClusterCredential, error) {
+	return f(ctx, apiServerURL, token)
+}
+
+// credentialCache caches the exchanged credentials until shortly before they expire, as the inbound
+// token is verified on every request of a session.
+type credentialCache struct {
+	exchanger CredentialExchanger
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]ClusterCredential
+}
+
+func newCredentialCache(exchanger CredentialExchanger) *credentialCache {
+	return &credentialCache{
+		exchanger: exchanger,
+		cache:     make(map[[sha256.Size]byte]ClusterCredential),
+	}
+}
+
+// exchange returns the credential of the API server for the inbound token, which expires at expiration.
+func (c *credentialCache) exchange(ctx context.Context, apiServerURL, token string, expiration time.Time) (ClusterCredential, error) {
+	key := sha256.Sum256([]byte(apiServerURL + "\x00" + token))
+
+	c.mu.Lock()
+	cached, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && time.Now().Add(exchangedCredentialExpiryMargin).Before(cached.Expiration) {
+		return cached, nil
+	}
+
+	credential, err := c.exchanger.Exchange(ctx, apiServerURL, token)
+	if err != nil {
+		return ClusterCredential{}, fmt.Errorf("failed to exchange the token for a credential of %s: %w", apiServerURL, err)
+	}
+	if credential == nil || credential.Token == "" {
+		return ClusterCredential{}, fmt.Errorf("failed to exchange the token for a credential of %s: no token returned", apiServerURL)
+	}
+	exchanged := *credential
+	if exchanged.Expiration.IsZero() || expiration.Before(exchanged.Expiration) {
+		exchanged.Expiration = expiration
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cache) >= maxExchangedCredentialCacheSize {
+		c.evictExpired()
+	}
+	if len(c.cache) < maxExchangedCredentialCacheSize {
+		c.cache[key] = exchanged
+	}
+	return exchanged, nil
+}
+
+// evictExpired drops the expired credentials. The caller must hold the lock.
+func (c *credentialCache) evictExpired() {
+	now := time.Now()
+	for key, cached := range c.cache {
+		if !now.Before(cached.Expiration) {
+			delete(c.cache, key)
+		}
+	}
+}
+
+// tokenExchanger exchanges the inbound tokens at a security token service with OAuth 2.0 Token Exchange
+// (RFC 8693), requesting an access token whose audience is the API server.
+type tokenExchanger struct {
+	url          string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+}
+
+// NewTokenExchanger returns the CredentialExchanger of a security token service implementing OAuth 2.0
+// Token Exchange at the URL, authenticating with the client credentials when set.
+func NewTokenExchanger(url, clientID, clientSecret string) CredentialExchanger {
+	return &tokenExchanger{url: url, clientID: clientID, clientSecret: clientSecret, client: http.DefaultClient}
+}
+
+// tokenExchangeResponse is the successful or error response of the token exchange service.
+type tokenExchangeResponse struct {
+	AccessToken      string `json:"access_token"`
+	IssuedTokenType  string `json:"issued_token_type"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (e *tokenExchanger) Exchange(ctx context.Context, apiServerURL, token string) (*ClusterCredential, error) {
+	form := url.Values{
+		"grant_type":           {tokenExchangeGrantType},
+		"subject_token":        {token},
+		"subject_token_type":   {tokenTypeJWT},
+		"requested_token_type": {tokenTypeAccessToken},
+		"audience":             {apiServerURL},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", tokenExchangeContentType)
+	req.Header.Set("Accept", "application/json")
+	if e.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(e.clientID), url.QueryEscape(e.clientSecret))
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenExchangeResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var response tokenExchangeResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("token exchange returned %s with an invalid response: %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if response.Error == "" {
+			return nil, fmt.Errorf("token exchange returned %s", resp.Status)
+		}
+		err := fmt.Errorf("token exchange refused the token: %s %s", response.Error, response.ErrorDescription)
+		// Errors about the subject token refuse the inbound token, the others are failures of the service.
+		if response.Error == "invalid_grant" || response.Error == "invalid_request" {
+			return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(response.TokenType, "bearer") && !strings.EqualFold(response.TokenType, "N_A") {
+		return nil, fmt.Errorf("token exchange returned an unsupported token type %q", response.TokenType)
+	}
+
+	credential := &ClusterCredential{Token: response.AccessToken}
+	if response.ExpiresIn > 0 {
+		credential.Expiration = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	return credential, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+func TestTokenExchanger(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		response     string
+		expected     string
+		expiring     bool
+		wantErr      bool
+		invalidToken bool
+	}{
+		{
+			name:     "exchanged",
+			status:   http.StatusOK,
+			response: `{"access_token": "cluster-token", "issued_token_type": "urn:ietf:params:oauth:token-type:access_token", "token_type": "Bearer", "expires_in": 600}`,
+			expected: "cluster-token",
+			expiring: true,
+		},
+		{
+			name:     "without expiration",
+			status:   http.StatusOK,
+			response: `{"access_token": "cluster-token", "token_type": "N_A"}`,
+			expected: "cluster-token",
+		},
+		{
+			name:     "unsupported token type",
+			status:   http.StatusOK,
+			response: `{"access_token": "cluster-token", "token_type": "DPoP"}`,
+			wantErr:  true,
+		},
+		{
+			name:         "refused token",
+			status:       http.StatusBadRequest,
+			response:     `{"error": "invalid_grant", "error_description": "subject token expired"}`,
+			wantErr:      true,
+			invalidToken: true,
+		},
+		{
+			name:     "unauthorized client",
+			status:   http.StatusUnauthorized,
+			response: `{"error": "invalid_client"}`,
+			wantErr:  true,
+		},
+		{
+			name:     "unavailable",
+			status:   http.StatusServiceUnavailable,
+			response: `upstream unavailable`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Error(err)
+				}
+				clientID, clientSecret, _ := r.BasicAuth()
+				if r.Form.Get("grant_type") != tokenExchangeGrantType || r.Form.Get("subject_token") != "inbound-token" ||
+					r.Form.Get("subject_token_type") != tokenTypeJWT || r.Form.Get("audience") != "https://cluster.example.com" ||
+					clientID != "k-mcp" || clientSecret != "s3cr3t" {
+					t.Errorf("unexpected token exchange request %v", r.Form)
+				}
+				w.WriteHeader(tt.status)
+				//nolint:errcheck
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			credential, err := NewTokenExchanger(server.URL, "k-mcp", "s3cr3t").Exchange(context.Background(), "https://cluster.example.com", "inbound-token")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if errors.Is(err, auth.ErrInvalidToken) != tt.invalidToken {
+				t.Errorf("expected the token to be refused %v, got %v", tt.invalidToken, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if credential.Token != tt.expected || credential.Expiration.IsZero() == tt.expiring {
+				t.Errorf("unexpected credential %+v", credential)
+			}
+		})
+	}
+}
+
+func TestCredentialCache(t *testing.T) {
+	exchanges := 0
+	expiration := time.Now().Add(time.Hour)
+	cache := newCredentialCache(CredentialExchangerFunc(func(ctx context.Context, apiServerURL, token string) (*ClusterCredential, error) {
+		exchanges++
+		if token == "refused" {
+			return nil, auth.ErrInvalidToken
+		}
+		return &ClusterCredential{Token: apiServerURL + "/" + token, Expiration: expiration}, nil
+	}))
+
+	steps := []struct {
+		apiServerURL    string
+		token           string
+		tokenExpiration time.Time
+		expected        ClusterCredential
+		wantErr         bool
+		exchanges       int
+	}{
+		// The credential expires with the inbound token when it expires first.
+		{apiServerURL: "https://a", token: "t1", tokenExpiration: expiration.Add(-time.Minute), expected: ClusterCredential{Token: "https://a/t1", Expiration: expiration.Add(-time.Minute)}, exchanges: 1},
+		{apiServerURL: "https://a", token: "t1", tokenExpiration: expiration.Add(-time.Minute), expected: ClusterCredential{Token: "https://a/t1", Expiration: expiration.Add(-time.Minute)}, exchanges: 1},
+		{apiServerURL: "https://b", token: "t1", tokenExpiration: expiration.Add(time.Hour), expected: ClusterCredential{Token: "https://b/t1", Expiration: expiration}, exchanges: 2},
+		// Credentials about to expire are exchanged again.
+		{apiServerURL: "https://c", token: "t1", tokenExpiration: time.Now().Add(time.Second), expected: ClusterCredential{Token: "https://c/t1"}, exchanges: 3},
+		{apiServerURL: "https://c", token: "t1", tokenExpiration: time.Now().Add(time.Second), expected: ClusterCredential{Token: "https://c/t1"}, exchanges: 4},
+		{apiServerURL: "https://a", token: "refused", tokenExpiration: expiration, wantErr: true, exchanges: 5},
+	}
+	for i, step := range steps {
+		credential, err := cache.exchange(context.Background(), step.apiServerURL, step.token, step.tokenExpiration)
+		if (err != nil) != step.wantErr {
+			t.Fatalf("step %d: expected error %v, got %v", i, step.wantErr, err)
+		}
+		if step.wantErr && !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("step %d: expected the token to be refused, got %v", i, err)
+		}
+		if credential.Token != step.expected.Token || (!step.expected.Expiration.IsZero() && !credential.Expiration.Equal(step.expected.Expiration)) {
+			t.Errorf("step %d: expected %+v, got %+v", i, step.expected, credential)
+		}
+		if exchanges != step.exchanges {
+			t.Errorf("step %d: expected %d exchanges, got %d", i, step.exchanges, exchanges)
+		}
+	}
+}
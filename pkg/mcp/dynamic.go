@@ -17,20 +17,77 @@ limitations under the License.
 package mcp
 
 import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/discovery/cached/disk"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/yaml"
+
+	"github.com/ardaguclu/k-mcp/pkg/version"
 )
 
+// DefaultUserAgent is the UserAgent of the requests sent to the API servers.
+const DefaultUserAgent = "k-mcp"
+
+// DefaultDiscoveryCacheTTL is how long the discovery of the API servers is cached on disk.
+const DefaultDiscoveryCacheTTL = 6 * time.Hour
+
+// inClusterNamespaceFile is the namespace of the service account mounted in the pods.
+const inClusterNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 type DynamicConfig struct {
 	CertificateAuthority string
 	InsecureSkipVerify   bool
 	TLSServerName        string
+	// UserAgent overrides the UserAgent of the requests sent to the API servers.
+	UserAgent string
+	// Headers are added to every request sent to the API servers,
+	// e.g. for corporate gateways fronting them.
+	Headers map[string]string
+	// QPS is the maximum rate of the requests sent to an API server with a token, after Burst
+	// requests. Zero means the defaults of client-go, 5 QPS with a burst of 10.
+	QPS   float32
+	Burst int
+	// FailureInjection degrades the requests sent to the API servers for resilience testing.
+	FailureInjection *FailureInjection
+	// Cluster is the configuration of the single cluster managed by k-mcp, the cluster it runs in or
+	// the cluster of a kubeconfig context, whose credentials and CA are used for every request instead
+	// of the tokens of the callers and the TLS settings. Nil means the tokens are sent to the API
+	// servers of their audience.
+	Cluster *rest.Config
+	// APIServerTLS overrides the TLS settings above for the API servers of some hosts, keyed by
+	// host and port, so that the clusters of the tokens can use different PKIs.
+	APIServerTLS map[string]APIServerTLS
+	// DiscoveryCacheDir is the directory caching the discovery of the API servers. Empty means
+	// k-mcp-discovery-cache in the home directory.
+	DiscoveryCacheDir string
+	// DiscoveryCacheTTL is how long the cached discovery is used. Zero means DefaultDiscoveryCacheTTL.
+	DiscoveryCacheTTL time.Duration
+	// DisableDiscoveryCache keeps the discovery in memory only, for read-only filesystems.
+	DisableDiscoveryCache bool
+
+	// mu guards APIServerTLS, which is replaced on reload.
+	mu sync.RWMutex
+	// clients caches the clients of the tokens, nil means they are built on every call.
+	clients *clientCache
 }
 
 func NewDynamicConfig(certificateAuthority string, insecure bool, tlsServerName string) *DynamicConfig {
@@ -38,31 +95,314 @@ func NewDynamicConfig(certificateAuthority string, insecure bool, tlsServerName
 		CertificateAuthority: certificateAuthority,
 		InsecureSkipVerify:   insecure,
 		TLSServerName:        tlsServerName,
+		clients:              newClientCache(),
 	}
 }
 
 func (d *DynamicConfig) LoadRestConfig(bearerToken, apiServerUrl string) (*dynamic.DynamicClient, discovery.CachedDiscoveryInterface, error) {
-	r := &rest.Config{
-		Host:        apiServerUrl,
-		BearerToken: bearerToken,
-		Impersonate: rest.ImpersonationConfig{},
-		TLSClientConfig: rest.TLSClientConfig{
-			Insecure:   d.InsecureSkipVerify,
-			ServerName: d.TLSServerName,
-			CAFile:     d.CertificateAuthority,
-		},
-		UserAgent: "k-mcp",
-	}
+	r := d.restConfig(bearerToken, apiServerUrl)
 	dynamicClient, err := dynamic.NewForConfig(r)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	cacheDir := filepath.Join(homedir.HomeDir(), "k-mcp-discovery-cache", apiServerUrl)
-	cachedDiscoveryClient, err := disk.NewCachedDiscoveryClientForConfig(r, cacheDir, "", time.Hour*6)
+	cachedDiscoveryClient, err := d.discoveryClient(r, apiServerUrl)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	return dynamicClient, cachedDiscoveryClient, nil
 }
+
+// discoveryClient returns the discovery client of an API server, cached on disk unless disabled, and
+// keeping the index of its resources.
+func (d *DynamicConfig) discoveryClient(r *rest.Config, apiServerUrl string) (discovery.CachedDiscoveryInterface, error) {
+	ttl := d.DiscoveryCacheTTL
+	if ttl == 0 {
+		ttl = DefaultDiscoveryCacheTTL
+	}
+	if d.DisableDiscoveryCache {
+		client, err := discovery.NewDiscoveryClientForConfig(r)
+		if err != nil {
+			return nil, err
+		}
+		return newIndexedDiscoveryClient(memory.NewMemCacheClient(client), ttl), nil
+	}
+
+	client, err := disk.NewCachedDiscoveryClientForConfig(r, filepath.Join(d.discoveryCacheDir(), apiServerUrl), "", ttl)
+	if err != nil {
+		return nil, err
+	}
+	return newIndexedDiscoveryClient(client, ttl), nil
+}
+
+// discoveryCacheDir returns the directory caching the discovery of the API servers.
+func (d *DynamicConfig) discoveryCacheDir() string {
+	if d.DiscoveryCacheDir != "" {
+		return d.DiscoveryCacheDir
+	}
+	return filepath.Join(homedir.HomeDir(), "k-mcp-discovery-cache")
+}
+
+// restConfig returns the configuration of the clients of an API server, with the TLS settings,
+// user agent, headers and failure injection of the server.
+func (d *DynamicConfig) restConfig(bearerToken, apiServerUrl string) *rest.Config {
+	r := &rest.Config{
+		Host:            apiServerUrl,
+		BearerToken:     bearerToken,
+		Impersonate:     rest.ImpersonationConfig{},
+		TLSClientConfig: d.tlsFor(apiServerUrl),
+	}
+	if d.Cluster != nil {
+		// The token file is read again when rotated, and exec plugins are run again when their
+		// credentials expire.
+		r = rest.CopyConfig(d.Cluster)
+	}
+	if d.QPS > 0 {
+		r.QPS = d.QPS
+	}
+	if d.Burst > 0 {
+		r.Burst = d.Burst
+	}
+	r.WarningHandlerWithContext = warningHandler{}
+	r.UserAgent = DefaultUserAgent
+	if d.UserAgent != "" {
+		r.UserAgent = d.UserAgent
+	}
+	if len(d.Headers) > 0 {
+		r.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &headerRoundTripper{headers: d.Headers, delegate: rt}
+		})
+	}
+	if d.FailureInjection.Enabled() {
+		r.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return newFailureRoundTripper(d.FailureInjection, rt)
+		})
+	}
+	r.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &requestIDRoundTripper{delegate: rt}
+	})
+	r.Wrap(tracingRoundTripper(r.Host))
+	return r
+}
+
+// APIServerTLS configures the TLS connections to the API servers of a host.
+type APIServerTLS struct {
+	// Host is the host of the API servers, with the port unless it is 443
+	// (e.g. cluster-a.example.com:6443), or their URL.
+	Host string `json:"host"`
+	// CertificateAuthority is the path of the CA certificates verifying the API servers.
+	CertificateAuthority string `json:"certificateAuthority,omitempty"`
+	// TLSServerName is the name of the API servers verified instead of the host.
+	TLSServerName string `json:"tlsServerName,omitempty"`
+	// InsecureSkipVerify skips the verification of the certificates of the API servers.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// LoadAPIServerTLS reads the TLS settings of the API servers from the given YAML file, keyed by host and port.
+func LoadAPIServerTLS(path string) (map[string]APIServerTLS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API server TLS settings from %s: %w", path, err)
+	}
+
+	var settings []APIServerTLS
+	if err := yaml.UnmarshalStrict(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse API server TLS settings from %s: %w", path, err)
+	}
+
+	byHost := make(map[string]APIServerTLS, len(settings))
+	for _, tls := range settings {
+		host, err := apiServerHost(tls.Host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host %q of the API server TLS settings: %w", tls.Host, err)
+		}
+		if _, ok := byHost[host]; ok {
+			return nil, fmt.Errorf("TLS settings of API server %s are defined more than once", host)
+		}
+		if tls.InsecureSkipVerify && (tls.CertificateAuthority != "" || tls.TLSServerName != "") {
+			return nil, fmt.Errorf("TLS settings of API server %s can not skip the verification and set a CA or server name", host)
+		}
+		if tls.CertificateAuthority != "" {
+			if _, err := os.ReadFile(tls.CertificateAuthority); err != nil {
+				return nil, fmt.Errorf("failed to read CA certificate of API server %s: %w", host, err)
+			}
+		}
+		byHost[host] = tls
+	}
+	return byHost, nil
+}
+
+// apiServerHost returns the lowercase host and port of an API server given as a URL or a host,
+// the port defaulting to 443.
+func apiServerHost(apiServer string) (string, error) {
+	host := apiServer
+	if strings.Contains(apiServer, "://") {
+		u, err := url.Parse(apiServer)
+		if err != nil {
+			return "", err
+		}
+		host = u.Host
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@") {
+		return "", fmt.Errorf("expected a host or a URL")
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), "443")
+	}
+	return strings.ToLower(host), nil
+}
+
+// tlsFor returns the TLS settings of an API server.
+func (d *DynamicConfig) tlsFor(apiServerUrl string) rest.TLSClientConfig {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if host, err := apiServerHost(apiServerUrl); err == nil {
+		if tls, ok := d.APIServerTLS[host]; ok {
+			return rest.TLSClientConfig{
+				Insecure:   tls.InsecureSkipVerify,
+				ServerName: tls.TLSServerName,
+				CAFile:     tls.CertificateAuthority,
+			}
+		}
+	}
+	return rest.TLSClientConfig{
+		Insecure:   d.InsecureSkipVerify,
+		ServerName: d.TLSServerName,
+		CAFile:     d.CertificateAuthority,
+	}
+}
+
+// setAPIServerTLS replaces the TLS settings of the API servers.
+func (d *DynamicConfig) setAPIServerTLS(settings map[string]APIServerTLS) {
+	d.mu.Lock()
+	d.APIServerTLS = settings
+	d.mu.Unlock()
+	// The cached clients were built with the previous settings.
+	if d.clients != nil {
+		d.clients.purge()
+	}
+}
+
+// LoadRestConfigForRequest loads the clients for the API server and the bearer token
+// extracted from the token of the tool call request.
+func (d *DynamicConfig) LoadRestConfigForRequest(request *mcp.CallToolRequest) (*dynamic.DynamicClient, discovery.CachedDiscoveryInterface, error) {
+	return d.LoadRestConfigForTokenInfo(request.Extra.TokenInfo)
+}
+
+// LoadRestConfigForTokenInfo loads the clients for the API server and the bearer token
+// stored in the token info by the token verifier, or for the cluster k-mcp runs in.
+func (d *DynamicConfig) LoadRestConfigForTokenInfo(tokenInfo *auth.TokenInfo) (*dynamic.DynamicClient, discovery.CachedDiscoveryInterface, error) {
+	apiServerUrl := tokenInfo.Extra["audience"].(string)
+	bearerToken := tokenInfo.Extra["bearer_token"].(string)
+	if d.clients == nil {
+		return d.LoadRestConfig(bearerToken, apiServerUrl)
+	}
+
+	key := clientCacheKey(bearerToken, apiServerUrl)
+	if clients, ok := d.clients.get(key); ok {
+		return clients.dynamicClient, clients.discoveryClient, nil
+	}
+	dynamicClient, discoveryClient, err := d.LoadRestConfig(bearerToken, apiServerUrl)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The clients expire with the token, whose credential may have been exchanged for one expiring with it.
+	d.clients.add(&cachedClients{
+		key:             key,
+		dynamicClient:   dynamicClient,
+		discoveryClient: discoveryClient,
+		expiration:      tokenInfo.Expiration,
+	})
+	return dynamicClient, discoveryClient, nil
+}
+
+// headerRoundTripper adds the configured headers to every request.
+type headerRoundTripper struct {
+	headers  map[string]string
+	delegate http.RoundTripper
+}
+
+func (rt *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range rt.headers {
+		req.Header.Set(key, value)
+	}
+	return rt.delegate.RoundTrip(req)
+}
+
+// ValidateHeaders rejects the headers that would interfere with the authentication
+// of the requests sent to the API servers.
+func ValidateHeaders(headers map[string]string) error {
+	for key := range headers {
+		canonical := http.CanonicalHeaderKey(key)
+		if canonical == "Authorization" || strings.HasPrefix(canonical, "Impersonate-") {
+			return fmt.Errorf("header %s can not be configured", key)
+		}
+		if canonical == "User-Agent" {
+			return fmt.Errorf("header %s can not be configured, use the user agent template instead", key)
+		}
+	}
+	return nil
+}
+
+// RenderUserAgent renders the UserAgent template. The template can refer to
+// the version of k-mcp ({{.Version}}, {{.GitCommit}}) and the platform ({{.OS}}, {{.Arch}}).
+func RenderUserAgent(userAgentTemplate string) (string, error) {
+	tmpl, err := template.New("user-agent").Option("missingkey=error").Parse(userAgentTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid user agent template: %w", err)
+	}
+
+	info := version.Get()
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]string{
+		"Version":   info.Version,
+		"GitCommit": info.GitCommit,
+		"OS":        runtime.GOOS,
+		"Arch":      runtime.GOARCH,
+	}); err != nil {
+		return "", fmt.Errorf("invalid user agent template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// LoadKubeconfig returns the configuration of the cluster of a kubeconfig context, the current context
+// when empty, with the namespace of the context, empty if it sets none. Without path, the kubeconfig is
+// loaded like kubectl does, from $KUBECONFIG or else ~/.kube/config. The credentials of the user can
+// come from an exec plugin (e.g. aws eks get-token, gke-gcloud-auth-plugin or kubelogin), which is run
+// without a terminal and run again when its credentials expire.
+func LoadKubeconfig(path, context string) (*rest.Config, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = path
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: context})
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig %s: %w", loadingRules.GetDefaultFilename(), err)
+	}
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig %s: %w", loadingRules.GetDefaultFilename(), err)
+	}
+	if context == "" {
+		context = rawConfig.CurrentContext
+	}
+	var namespace string
+	if kubeContext, ok := rawConfig.Contexts[context]; ok {
+		namespace = kubeContext.Namespace
+	}
+	return config, namespace, nil
+}
+
+// InClusterNamespace returns the namespace of the pod k-mcp runs in, from the POD_NAMESPACE environment
+// variable, usually set with the downward API, or else from the namespace of its service account.
+func InClusterNamespace() (string, error) {
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace, nil
+	}
+	data, err := os.ReadFile(inClusterNamespaceFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the namespace of the pod: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
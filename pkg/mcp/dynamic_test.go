@@ -0,0 +1,310 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestRenderUserAgent(t *testing.T) {
+	userAgent, err := RenderUserAgent("corp-k-mcp/{{.Version}} ({{.OS}}/{{.Arch}})")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "corp-k-mcp/v0.0.0-main (" + runtime.GOOS + "/" + runtime.GOARCH + ")"
+	if userAgent != expected {
+		t.Errorf("expected %q, got %q", expected, userAgent)
+	}
+
+	if _, err := RenderUserAgent("k-mcp/{{.Unknown}}"); err == nil {
+		t.Errorf("expected error for unknown field")
+	}
+	if _, err := RenderUserAgent("k-mcp/{{.Version"); err == nil {
+		t.Errorf("expected error for invalid template")
+	}
+}
+
+func TestValidateHeaders(t *testing.T) {
+	if err := ValidateHeaders(map[string]string{"X-Client-Id": "k-mcp"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, header := range []string{"authorization", "Impersonate-User", "user-agent"} {
+		if err := ValidateHeaders(map[string]string{header: "value"}); err == nil {
+			t.Errorf("expected header %s to be rejected", header)
+		}
+	}
+}
+
+func TestHeaderRoundTripper(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &headerRoundTripper{
+		headers:  map[string]string{"X-Client-Id": "k-mcp"},
+		delegate: http.DefaultTransport,
+	}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if received.Get("X-Client-Id") != "k-mcp" {
+		t.Errorf("expected X-Client-Id header, got %v", received)
+	}
+}
+
+func TestRestConfigInCluster(t *testing.T) {
+	d := NewDynamicConfig("/etc/k-mcp/ca.crt", false, "")
+	d.UserAgent = "corp-k-mcp"
+	if r := d.restConfig("caller-token", "https://cluster.example.com"); r.Host != "https://cluster.example.com" || r.BearerToken != "caller-token" || r.CAFile != "/etc/k-mcp/ca.crt" {
+		t.Errorf("expected the token and the API server of the caller, got %+v", r)
+	}
+
+	d.Cluster = &rest.Config{
+		Host:            "https://10.96.0.1:443",
+		BearerTokenFile: "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		TLSClientConfig: rest.TLSClientConfig{CAFile: "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"},
+	}
+	r := d.restConfig("caller-token", "https://10.96.0.1:443")
+	if r.Host != d.Cluster.Host || r.BearerToken != "" || r.BearerTokenFile != d.Cluster.BearerTokenFile || r.CAFile != d.Cluster.CAFile {
+		t.Errorf("expected the service account of the pod, got %+v", r)
+	}
+	if r.UserAgent != "corp-k-mcp" {
+		t.Errorf("expected the user agent to be kept, got %q", r.UserAgent)
+	}
+	if d.Cluster.UserAgent != "" {
+		t.Errorf("expected the in-cluster configuration not to be modified")
+	}
+}
+
+func TestRestConfigRateLimits(t *testing.T) {
+	d := NewDynamicConfig("", false, "")
+	if r := d.restConfig("token", "https://cluster.example.com"); r.QPS != 0 || r.Burst != 0 {
+		t.Errorf("expected the defaults of client-go, got QPS %v and burst %d", r.QPS, r.Burst)
+	}
+
+	d.QPS, d.Burst = 20, 40
+	d.Cluster = &rest.Config{Host: "https://10.96.0.1:443", QPS: 5, Burst: 10}
+	if r := d.restConfig("", "https://10.96.0.1:443"); r.QPS != 20 || r.Burst != 40 {
+		t.Errorf("expected QPS 20 and burst 40, got QPS %v and burst %d", r.QPS, r.Burst)
+	}
+}
+
+func TestDiscoveryCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			//nolint:errcheck
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/apis":
+			//nolint:errcheck
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	for _, disabled := range []bool{false, true} {
+		cacheDir := t.TempDir()
+		d := NewDynamicConfig("", false, "")
+		d.DiscoveryCacheDir = cacheDir
+		d.DisableDiscoveryCache = disabled
+		_, discoveryClient, err := d.LoadRestConfig("token", server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := discoveryClient.ServerGroups(); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, err := os.ReadDir(cacheDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cached := len(entries) > 0; cached == disabled {
+			t.Errorf("expected the discovery to be cached on disk %v, got %v", !disabled, cached)
+		}
+	}
+}
+
+func TestInClusterNamespace(t *testing.T) {
+	t.Setenv("POD_NAMESPACE", "agents")
+	namespace, err := InClusterNamespace()
+	if err != nil || namespace != "agents" {
+		t.Errorf("expected namespace agents, got %q (%v)", namespace, err)
+	}
+}
+
+func TestLoadAPIServerTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(ca, []byte("ca"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name: "hosts and URLs",
+			content: "- host: Cluster-A.example.com:6443\n  certificateAuthority: " + ca + "\n" +
+				"- host: https://cluster-b.example.com\n  tlsServerName: kubernetes\n" +
+				"- host: 10.0.0.1\n  insecureSkipVerify: true\n",
+			expected: []string{"cluster-a.example.com:6443", "cluster-b.example.com:443", "10.0.0.1:443"},
+		},
+		{name: "duplicate host", content: "- host: cluster-a.example.com\n- host: https://cluster-a.example.com:443\n", wantErr: true},
+		{name: "insecure with CA", content: "- host: cluster-a.example.com\n  insecureSkipVerify: true\n  certificateAuthority: " + ca + "\n", wantErr: true},
+		{name: "missing CA", content: "- host: cluster-a.example.com\n  certificateAuthority: " + filepath.Join(dir, "missing.crt") + "\n", wantErr: true},
+		{name: "missing host", content: "- tlsServerName: kubernetes\n", wantErr: true},
+		{name: "unknown field", content: "- host: cluster-a.example.com\n  caData: abc\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "tls.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			settings, err := LoadAPIServerTLS(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if len(settings) != len(tt.expected) {
+				t.Fatalf("expected %d hosts, got %v", len(tt.expected), settings)
+			}
+			for _, host := range tt.expected {
+				if _, ok := settings[host]; !ok {
+					t.Errorf("expected settings of %s, got %v", host, settings)
+				}
+			}
+		})
+	}
+}
+
+func TestRestConfigAPIServerTLS(t *testing.T) {
+	d := NewDynamicConfig("/etc/k-mcp/ca.crt", false, "")
+	d.APIServerTLS = map[string]APIServerTLS{
+		"cluster-b.example.com:443":  {CertificateAuthority: "/etc/k-mcp/b.crt", TLSServerName: "kubernetes"},
+		"cluster-c.example.com:6443": {InsecureSkipVerify: true},
+	}
+
+	tests := []struct {
+		apiServer string
+		expected  rest.TLSClientConfig
+	}{
+		{apiServer: "https://cluster-a.example.com:6443", expected: rest.TLSClientConfig{CAFile: "/etc/k-mcp/ca.crt"}},
+		{apiServer: "https://Cluster-B.example.com", expected: rest.TLSClientConfig{CAFile: "/etc/k-mcp/b.crt", ServerName: "kubernetes"}},
+		{apiServer: "https://cluster-c.example.com:6443", expected: rest.TLSClientConfig{Insecure: true}},
+		// Another port of the host is another API server.
+		{apiServer: "https://cluster-c.example.com", expected: rest.TLSClientConfig{CAFile: "/etc/k-mcp/ca.crt"}},
+	}
+	for _, tt := range tests {
+		if got := d.restConfig("token", tt.apiServer).TLSClientConfig; got.CAFile != tt.expected.CAFile || got.ServerName != tt.expected.ServerName || got.Insecure != tt.expected.Insecure {
+			t.Errorf("%s: expected %+v, got %+v", tt.apiServer, tt.expected, got)
+		}
+	}
+}
+
+func TestLoadKubeconfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	kubeconfig := `apiVersion: v1
+kind: Config
+current-context: eks
+clusters:
+- name: eks
+  cluster:
+    server: https://eks.example.com
+- name: kind
+  cluster:
+    server: https://127.0.0.1:6443
+    insecure-skip-tls-verify: true
+users:
+- name: eks
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      args: [eks, get-token, --cluster-name, shop]
+- name: kind
+  user:
+    token: kind-token
+contexts:
+- name: eks
+  context:
+    cluster: eks
+    user: eks
+- name: kind
+  context:
+    cluster: kind
+    user: kind
+    namespace: shop
+`
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, namespace, err := LoadKubeconfig(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Host != "https://eks.example.com" || config.ExecProvider == nil || config.ExecProvider.Command != "aws" || namespace != "" {
+		t.Errorf("expected the exec plugin of the current context, got %+v in namespace %q", config, namespace)
+	}
+	// The exec plugin is kept for the requests to the cluster.
+	d := NewDynamicConfig("", false, "")
+	d.Cluster = config
+	if r := d.restConfig("caller-token", config.Host); r.ExecProvider == nil || r.BearerToken != "" {
+		t.Errorf("expected the exec plugin instead of the token of the caller, got %+v", r)
+	}
+
+	config, namespace, err = LoadKubeconfig(path, "kind")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Host != "https://127.0.0.1:6443" || config.BearerToken != "kind-token" || namespace != "shop" {
+		t.Errorf("expected the kind context, got %+v in namespace %q", config, namespace)
+	}
+
+	if _, _, err := LoadKubeconfig(path, "unknown"); err == nil {
+		t.Errorf("expected an unknown context to fail")
+	}
+
+	t.Setenv("KUBECONFIG", path)
+	config, namespace, err = LoadKubeconfig("", "kind")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Host != "https://127.0.0.1:6443" || namespace != "shop" {
+		t.Errorf("expected the kind context of $KUBECONFIG, got %+v in namespace %q", config, namespace)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// methodElicit is the MCP method used to send elicitation requests to the client.
+const methodElicit = "elicitation/create"
+
+// ErrElicitationTimeout is returned when the user does not answer an elicitation in time.
+var ErrElicitationTimeout = errors.New("confirmation timed out")
+
+// ErrElicitationUnsupported is returned when a question can not be asked to the user, because
+// the client does not support elicitation, and it has no default answer.
+var ErrElicitationUnsupported = errors.New("the client does not support elicitation")
+
+// elicitationConfirmField is the boolean field of the elicitations confirming an operation.
+const elicitationConfirmField = "confirm"
+
+// headlessElicitationMiddleware answers the elicitations of the sessions whose client does not
+// support elicitation, or of every session in headless mode, instead of sending them. Fields are
+// answered with their default value. Confirmations are only accepted in headless mode, where the
+// operator chose to run without them, and fail with ErrElicitationUnsupported otherwise.
+func headlessElicitationMiddleware(headless bool) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != methodElicit {
+				return next(ctx, method, req)
+			}
+			session, ok := req.GetSession().(*mcp.ServerSession)
+			if !headless && ok && supportsElicitation(session.InitializeParams()) {
+				return next(ctx, method, req)
+			}
+
+			params, _ := req.GetParams().(*mcp.ElicitParams)
+			result, err := defaultElicitResult(params, headless)
+			if err != nil {
+				return nil, err
+			}
+			slog.DebugContext(ctx, "Answered elicitation with defaults", "session_id", req.GetSession().ID(), "content", result.Content)
+			if ok {
+				notifyClient(ctx, session, "notice", fmt.Sprintf("The user was not asked for the %s, answered with the defaults %v", elicitationPrompt(params), result.Content))
+			}
+			return result, nil
+		}
+	}
+}
+
+// supportsElicitation returns whether the client declared the elicitation capability.
+func supportsElicitation(params *mcp.InitializeParams) bool {
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
+
+// defaultElicitResult accepts an elicitation with the default values of its fields.
+func defaultElicitResult(params *mcp.ElicitParams, headless bool) (*mcp.ElicitResult, error) {
+	if params == nil || params.RequestedSchema == nil || len(params.RequestedSchema.Properties) == 0 {
+		return nil, ErrElicitationUnsupported
+	}
+
+	content := make(map[string]any, len(params.RequestedSchema.Properties))
+	for name, property := range params.RequestedSchema.Properties {
+		switch {
+		case name == elicitationConfirmField && headless:
+			content[name] = true
+		case name == elicitationConfirmField:
+			return nil, fmt.Errorf("%w, operations can only be run without confirmation in headless mode", ErrElicitationUnsupported)
+		case name == elicitationPhraseField:
+			return nil, fmt.Errorf("%w, changes above the impact threshold must be confirmed by typing a phrase", ErrElicitationUnsupported)
+		case property.Default != nil:
+			var value any
+			if err := json.Unmarshal(property.Default, &value); err != nil {
+				return nil, fmt.Errorf("invalid default value of %s: %w", name, err)
+			}
+			content[name] = value
+		default:
+			return nil, fmt.Errorf("%w, %s can not be answered", ErrElicitationUnsupported, name)
+		}
+	}
+	return &mcp.ElicitResult{Action: "accept", Content: content}, nil
+}
+
+// elicitationTimeoutMiddleware bounds the time spent waiting for the answer of elicitation
+// requests, so that clients which never answer don't hold the pending tool call indefinitely.
+func elicitationTimeoutMiddleware(timeout time.Duration) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != methodElicit || timeout <= 0 {
+				return next(ctx, method, req)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			result, err := next(ctx, method, req)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				slog.WarnContext(ctx, "Elicitation timed out", "session_id", req.GetSession().ID(), "timeout", timeout)
+				return nil, fmt.Errorf("%w after %s", ErrElicitationTimeout, timeout)
+			}
+			return result, err
+		}
+	}
+}
+
+// elicitNamespace asks the user for the namespace of a namespaced resource.
+// It falls back to the default namespace when the user accepts without a value.
+func elicitNamespace(ctx context.Context, session *mcp.ServerSession, resource string) (string, error) {
+	defaultValue := json.RawMessage(`"default"`)
+	elicitResult, err := session.Elicit(ctx, &mcp.ElicitParams{
+		Message: fmt.Sprintf("Namespace is required for namespaced resource %s. Please specify a namespace:", resource),
+		RequestedSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"namespace": {
+					Type:        "string",
+					Description: "The namespace for the resource",
+					Default:     defaultValue,
+				},
+			},
+			Required: []string{"namespace"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to elicit namespace: %w", err)
+	}
+
+	if elicitResult.Action != "accept" {
+		return "", fmt.Errorf("user cancelled namespace selection")
+	}
+
+	namespace, ok := elicitResult.Content["namespace"].(string)
+	if !ok || namespace == "" {
+		namespace = "default"
+		notifyClient(ctx, session, "notice", fmt.Sprintf("No namespace was given for %s, using the default namespace", resource))
+	}
+	return namespace, nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Outcomes of an elicitation as recorded by elicitationMetrics.
+const (
+	elicitationOutcomeAccept  = "accept"
+	elicitationOutcomeDecline = "decline"
+	elicitationOutcomeCancel  = "cancel"
+	elicitationOutcomeTimeout = "timeout"
+	elicitationOutcomeError   = "error"
+)
+
+// elicitationResponseBuckets are the upper bounds, in seconds, of the response time histogram.
+var elicitationResponseBuckets = []float64{1, 5, 15, 30, 60, 120, 300}
+
+type elicitationKey struct {
+	prompt  string
+	outcome string
+}
+
+type elicitationHistogram struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// elicitationMetrics records how users answer elicitations, so that confirmation flows
+// that are often declined, cancelled or left unanswered can be identified.
+// It is exposed in the Prometheus text format.
+type elicitationMetrics struct {
+	mu            sync.Mutex
+	outcomes      map[elicitationKey]uint64
+	responseTimes map[string]*elicitationHistogram
+}
+
+func newElicitationMetrics() *elicitationMetrics {
+	return &elicitationMetrics{
+		outcomes:      map[elicitationKey]uint64{},
+		responseTimes: map[string]*elicitationHistogram{},
+	}
+}
+
+// middleware records the outcome and the response time of every elicitation sent to clients.
+// It must wrap elicitationTimeoutMiddleware to observe timeouts.
+func (m *elicitationMetrics) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != methodElicit {
+				return next(ctx, method, req)
+			}
+
+			prompt := "unknown"
+			if params, ok := req.GetParams().(*mcp.ElicitParams); ok {
+				prompt = elicitationPrompt(params)
+			}
+
+			start := time.Now()
+			result, err := next(ctx, method, req)
+			m.observe(prompt, elicitationOutcome(result, err), time.Since(start))
+			return result, err
+		}
+	}
+}
+
+func (m *elicitationMetrics) observe(prompt, outcome string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.outcomes[elicitationKey{prompt: prompt, outcome: outcome}]++
+
+	// Timeouts and transport errors are not answers of the user.
+	if outcome == elicitationOutcomeTimeout || outcome == elicitationOutcomeError {
+		return
+	}
+	h, ok := m.responseTimes[prompt]
+	if !ok {
+		h = &elicitationHistogram{buckets: make([]uint64, len(elicitationResponseBuckets))}
+		m.responseTimes[prompt] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range elicitationResponseBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *elicitationMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.write(w)
+}
+
+func (m *elicitationMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]elicitationKey, 0, len(m.outcomes))
+	for key := range m.outcomes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].prompt != keys[j].prompt {
+			return keys[i].prompt < keys[j].prompt
+		}
+		return keys[i].outcome < keys[j].outcome
+	})
+
+	fmt.Fprintln(w, "# HELP k_mcp_elicitations_total Number of elicitations sent to clients by prompt and outcome.")
+	fmt.Fprintln(w, "# TYPE k_mcp_elicitations_total counter")
+	for _, key := range keys {
+		fmt.Fprintf(w, "k_mcp_elicitations_total{prompt=%q,outcome=%q} %d\n", key.prompt, key.outcome, m.outcomes[key])
+	}
+
+	prompts := make([]string, 0, len(m.responseTimes))
+	for prompt := range m.responseTimes {
+		prompts = append(prompts, prompt)
+	}
+	sort.Strings(prompts)
+
+	fmt.Fprintln(w, "# HELP k_mcp_elicitation_response_seconds Time taken by users to answer elicitations.")
+	fmt.Fprintln(w, "# TYPE k_mcp_elicitation_response_seconds histogram")
+	for _, prompt := range prompts {
+		h := m.responseTimes[prompt]
+		for i, bound := range elicitationResponseBuckets {
+			fmt.Fprintf(w, "k_mcp_elicitation_response_seconds_bucket{prompt=%q,le=\"%g\"} %d\n", prompt, bound, h.buckets[i])
+		}
+		fmt.Fprintf(w, "k_mcp_elicitation_response_seconds_bucket{prompt=%q,le=\"+Inf\"} %d\n", prompt, h.count)
+		fmt.Fprintf(w, "k_mcp_elicitation_response_seconds_sum{prompt=%q} %g\n", prompt, h.sum)
+		fmt.Fprintf(w, "k_mcp_elicitation_response_seconds_count{prompt=%q} %d\n", prompt, h.count)
+	}
+}
+
+// elicitationPrompt identifies the kind of an elicitation by the fields it requests.
+// Messages are not used since they contain resource names.
+func elicitationPrompt(params *mcp.ElicitParams) string {
+	if params.RequestedSchema == nil || len(params.RequestedSchema.Properties) == 0 {
+		return "unknown"
+	}
+	fields := make([]string, 0, len(params.RequestedSchema.Properties))
+	for field := range params.RequestedSchema.Properties {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ",")
+}
+
+func elicitationOutcome(result mcp.Result, err error) string {
+	if err != nil {
+		if errors.Is(err, ErrElicitationTimeout) {
+			return elicitationOutcomeTimeout
+		}
+		return elicitationOutcomeError
+	}
+	elicitResult, ok := result.(*mcp.ElicitResult)
+	if !ok {
+		return elicitationOutcomeError
+	}
+	switch elicitResult.Action {
+	case "accept":
+		return elicitationOutcomeAccept
+	case "decline":
+		return elicitationOutcomeDecline
+	default:
+		return elicitationOutcomeCancel
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestElicitationOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   mcp.Result
+		err      error
+		expected string
+	}{
+		{name: "accept", result: &mcp.ElicitResult{Action: "accept"}, expected: elicitationOutcomeAccept},
+		{name: "decline", result: &mcp.ElicitResult{Action: "decline"}, expected: elicitationOutcomeDecline},
+		{name: "cancel", result: &mcp.ElicitResult{Action: "cancel"}, expected: elicitationOutcomeCancel},
+		{name: "timeout", err: fmt.Errorf("%w after 1s", ErrElicitationTimeout), expected: elicitationOutcomeTimeout},
+		{name: "error", err: errors.New("connection closed"), expected: elicitationOutcomeError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if outcome := elicitationOutcome(tt.result, tt.err); outcome != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, outcome)
+			}
+		})
+	}
+}
+
+func TestElicitationPrompt(t *testing.T) {
+	params := &mcp.ElicitParams{
+		Message: "Apply deployment web?",
+		RequestedSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"reason":  {Type: "string"},
+				"confirm": {Type: "boolean"},
+			},
+		},
+	}
+	if prompt := elicitationPrompt(params); prompt != "confirm,reason" {
+		t.Errorf("expected confirm,reason, got %s", prompt)
+	}
+	if prompt := elicitationPrompt(&mcp.ElicitParams{Message: "continue?"}); prompt != "unknown" {
+		t.Errorf("expected unknown, got %s", prompt)
+	}
+}
+
+func TestElicitationMetricsWrite(t *testing.T) {
+	m := newElicitationMetrics()
+	m.observe("namespace", elicitationOutcomeAccept, 2*time.Second)
+	m.observe("namespace", elicitationOutcomeCancel, 20*time.Second)
+	m.observe("confirm", elicitationOutcomeTimeout, 5*time.Minute)
+
+	var buf bytes.Buffer
+	m.write(&buf)
+	out := buf.String()
+
+	for _, line := range []string{
+		`k_mcp_elicitations_total{prompt="confirm",outcome="timeout"} 1`,
+		`k_mcp_elicitations_total{prompt="namespace",outcome="accept"} 1`,
+		`k_mcp_elicitations_total{prompt="namespace",outcome="cancel"} 1`,
+		`k_mcp_elicitation_response_seconds_bucket{prompt="namespace",le="5"} 1`,
+		`k_mcp_elicitation_response_seconds_bucket{prompt="namespace",le="30"} 2`,
+		`k_mcp_elicitation_response_seconds_bucket{prompt="namespace",le="+Inf"} 2`,
+		`k_mcp_elicitation_response_seconds_sum{prompt="namespace"} 22`,
+		`k_mcp_elicitation_response_seconds_count{prompt="namespace"} 2`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("expected output to contain %q, got:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, `k_mcp_elicitation_response_seconds_count{prompt="confirm"}`) {
+		t.Errorf("timeouts must not be recorded as response times, got:\n%s", out)
+	}
+}
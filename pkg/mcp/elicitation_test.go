@@ -0,0 +1,159 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestElicitationTimeoutMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddSendingMiddleware(elicitationTimeoutMiddleware(50 * time.Millisecond))
+
+	elicitErr := make(chan error, 1)
+	mcp.AddTool(server, &mcp.Tool{Name: "ask"}, func(ctx context.Context, request *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		_, err := request.Session.Elicit(ctx, &mcp.ElicitParams{Message: "continue?"})
+		elicitErr <- err
+		return nil, nil, err
+	})
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		ElicitationHandler: func(ctx context.Context, _ *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			// Simulate a client that never answers.
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	callCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	result, err := clientSession.CallTool(callCtx, &mcp.CallToolParams{Name: "ask"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected an error result")
+	}
+
+	if err := <-elicitErr; !errors.Is(err, ErrElicitationTimeout) {
+		t.Errorf("expected elicitation timeout error, got %v", err)
+	}
+}
+
+func TestDefaultElicitResult(t *testing.T) {
+	namespace := &mcp.ElicitParams{RequestedSchema: &jsonschema.Schema{
+		Type:       "object",
+		Properties: map[string]*jsonschema.Schema{"namespace": {Type: "string", Default: json.RawMessage(`"default"`)}},
+	}}
+	confirmation := &mcp.ElicitParams{RequestedSchema: &jsonschema.Schema{
+		Type:       "object",
+		Properties: map[string]*jsonschema.Schema{"confirm": {Type: "boolean"}},
+	}}
+	choice := resourceChoice("po", []string{"pods.v1.", "podtemplates.v1."})
+
+	tests := []struct {
+		name     string
+		params   *mcp.ElicitParams
+		headless bool
+		expected map[string]any
+	}{
+		{name: "default namespace", params: namespace, expected: map[string]any{"namespace": "default"}},
+		{name: "confirmation", params: confirmation},
+		{name: "headless confirmation", params: confirmation, headless: true, expected: map[string]any{"confirm": true}},
+		{name: "resource choice", params: choice, headless: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := defaultElicitResult(tt.params, tt.headless)
+			if tt.expected == nil {
+				if !errors.Is(err, ErrElicitationUnsupported) {
+					t.Errorf("expected unsupported elicitation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Action != "accept" || !reflect.DeepEqual(result.Content, tt.expected) {
+				t.Errorf("expected accepted %v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHeadlessElicitationMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddSendingMiddleware(headlessElicitationMiddleware(false))
+
+	mcp.AddTool(server, &mcp.Tool{Name: "namespace"}, func(ctx context.Context, request *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		namespace, err := elicitNamespace(ctx, request.Session, "pods")
+		if err != nil {
+			return nil, nil, err
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: namespace}}}, nil, nil
+	})
+
+	// The client does not declare the elicitation capability.
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "namespace"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %v", result.Content)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "default" {
+		t.Errorf("expected the default namespace, got %s", text)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/runtime/serializer/cbor/direct"
+)
+
+const (
+	// structuredContentEncodingCapability is the experimental capability negotiating the encoding of the
+	// structured content of tool results. Clients list the encodings they decode, e.g.
+	// {"experimental": {"k-mcp/structuredContentEncoding": {"encodings": ["cbor"]}}}, and the server
+	// answers with the encodings it supports.
+	structuredContentEncodingCapability = "k-mcp/structuredContentEncoding"
+	encodingCBOR                        = "cbor"
+	// structuredContentURI is the URI of the embedded resource carrying the encoded structured content.
+	structuredContentURI = "k-mcp://structured-content"
+
+	methodInitialize = "initialize"
+	methodCallTool   = "tools/call"
+)
+
+// structuredContentEncodingMiddleware negotiates the encoding of the structured content during the
+// initialization, and moves the structured content of the tool results of the sessions that opted in
+// into an embedded CBOR resource, which is smaller than JSON for large results.
+func structuredContentEncodingMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+		if err != nil {
+			return result, err
+		}
+
+		switch method {
+		case methodInitialize:
+			if initializeResult, ok := result.(*mcp.InitializeResult); ok && initializeResult.Capabilities != nil {
+				if initializeResult.Capabilities.Experimental == nil {
+					initializeResult.Capabilities.Experimental = make(map[string]any)
+				}
+				initializeResult.Capabilities.Experimental[structuredContentEncodingCapability] = map[string]any{
+					"encodings": []string{encodingCBOR},
+				}
+			}
+		case methodCallTool:
+			session, ok := req.GetSession().(*mcp.ServerSession)
+			toolResult, isToolResult := result.(*mcp.CallToolResult)
+			if !ok || !isToolResult || !acceptsEncoding(session.InitializeParams(), encodingCBOR) {
+				return result, err
+			}
+			if err := encodeStructuredContent(toolResult); err != nil {
+				// The result is still valid as JSON, fall back to it.
+				slog.WarnContext(ctx, "Failed to encode structured content as CBOR", "session_id", session.ID(), "err", err)
+			}
+		}
+		return result, err
+	}
+}
+
+// acceptsEncoding returns whether the client declared it decodes the encoding in its capabilities.
+func acceptsEncoding(params *mcp.InitializeParams, encoding string) bool {
+	if params == nil || params.Capabilities == nil {
+		return false
+	}
+	capability, ok := params.Capabilities.Experimental[structuredContentEncodingCapability].(map[string]any)
+	if !ok {
+		return false
+	}
+	encodings, _ := capability["encodings"].([]any)
+	return slices.Contains(encodings, any(encoding))
+}
+
+// encodeStructuredContent replaces the structured content of a tool result with an embedded resource
+// holding its CBOR encoding.
+func encodeStructuredContent(result *mcp.CallToolResult) error {
+	if result.StructuredContent == nil {
+		return nil
+	}
+	data, err := direct.Marshal(result.StructuredContent)
+	if err != nil {
+		return err
+	}
+	result.StructuredContent = nil
+	result.Content = append(result.Content, &mcp.EmbeddedResource{
+		Resource: &mcp.ResourceContents{
+			URI:      structuredContentURI,
+			MIMEType: "application/cbor",
+			Blob:     data,
+		},
+	})
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/runtime/serializer/cbor/direct"
+)
+
+func TestAcceptsEncoding(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities string
+		expected     bool
+	}{
+		{name: "cbor", capabilities: `{"experimental":{"k-mcp/structuredContentEncoding":{"encodings":["msgpack","cbor"]}}}`, expected: true},
+		{name: "other encodings", capabilities: `{"experimental":{"k-mcp/structuredContentEncoding":{"encodings":["msgpack"]}}}`},
+		{name: "no experimental capabilities", capabilities: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &mcp.InitializeParams{}
+			if err := json.Unmarshal([]byte(tt.capabilities), &params.Capabilities); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if accepts := acceptsEncoding(params, encodingCBOR); accepts != tt.expected {
+				t.Errorf("expected %t, got %t", tt.expected, accepts)
+			}
+		})
+	}
+}
+
+func TestEncodeStructuredContent(t *testing.T) {
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: "Found 1 resources"}},
+		StructuredContent: &ResourceListResult{Resources: []map[string]interface{}{
+			{"metadata": map[string]interface{}{"name": "web"}, "spec": map[string]interface{}{"replicas": int64(3)}},
+		}},
+	}
+	if err := encodeStructuredContent(result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.StructuredContent != nil {
+		t.Errorf("expected structured content to be moved, got %v", result.StructuredContent)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("expected 2 contents, got %d", len(result.Content))
+	}
+	resource, ok := result.Content[1].(*mcp.EmbeddedResource)
+	if !ok || resource.Resource.MIMEType != "application/cbor" {
+		t.Fatalf("expected an embedded CBOR resource, got %#v", result.Content[1])
+	}
+
+	var decoded map[string]interface{}
+	if err := direct.Unmarshal(resource.Resource.Blob, &decoded); err != nil {
+		t.Fatalf("failed to decode CBOR: %v", err)
+	}
+	expected := map[string]interface{}{"resources": []interface{}{
+		map[string]interface{}{"metadata": map[string]interface{}{"name": "web"}, "spec": map[string]interface{}{"replicas": int64(3)}},
+	}}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %v, got %v", expected, decoded)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FailureInjection degrades the requests sent to the API servers, to test how agents and
+// clients behave against a slow or failing cluster. It must never be enabled in production.
+type FailureInjection struct {
+	// Latency is added to every request.
+	Latency time.Duration
+	// ErrorRate is the fraction of requests, between 0 and 1, failing with 503 Service Unavailable.
+	ErrorRate float64
+}
+
+// Enabled returns whether any failure is injected.
+func (f *FailureInjection) Enabled() bool {
+	return f != nil && (f.Latency > 0 || f.ErrorRate > 0)
+}
+
+// Validate rejects negative latencies and error rates out of range.
+func (f *FailureInjection) Validate() error {
+	if f.Latency < 0 {
+		return fmt.Errorf("injected latency must not be negative")
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return fmt.Errorf("injected error rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// failureRoundTripper delays the requests and fails a share of them without sending them.
+type failureRoundTripper struct {
+	injection *FailureInjection
+	delegate  http.RoundTripper
+	// fail decides whether a request fails, defaults to the configured error rate.
+	fail func() bool
+}
+
+func newFailureRoundTripper(injection *FailureInjection, delegate http.RoundTripper) *failureRoundTripper {
+	return &failureRoundTripper{
+		injection: injection,
+		delegate:  delegate,
+		fail: func() bool {
+			return rand.Float64() < injection.ErrorRate
+		},
+	}
+}
+
+func (rt *failureRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.injection.Latency > 0 {
+		timer := time.NewTimer(rt.injection.Latency)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	if !rt.fail() {
+		return rt.delegate.RoundTrip(req)
+	}
+
+	// Respond like an overloaded API server, so that the error surfaces as a regular API error.
+	status := v1.Status{
+		TypeMeta: v1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   v1.StatusFailure,
+		Message:  "injected failure",
+		Reason:   v1.StatusReasonServiceUnavailable,
+		Code:     http.StatusServiceUnavailable,
+	}
+	body, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable)),
+		StatusCode:    http.StatusServiceUnavailable,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
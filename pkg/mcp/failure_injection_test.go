@@ -0,0 +1,119 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFailureRoundTripper(t *testing.T) {
+	var received int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		injection      *FailureInjection
+		fail           bool
+		expectedStatus int
+		expectedSent   bool
+	}{
+		{name: "latency", injection: &FailureInjection{Latency: 50 * time.Millisecond}, expectedStatus: http.StatusOK, expectedSent: true},
+		{name: "injected error", injection: &FailureInjection{ErrorRate: 1}, fail: true, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = 0
+			rt := newFailureRoundTripper(tt.injection, http.DefaultTransport)
+			rt.fail = func() bool { return tt.fail }
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			start := time.Now()
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close() //nolint:errcheck
+
+			if elapsed := time.Since(start); elapsed < tt.injection.Latency {
+				t.Errorf("expected a latency of at least %s, got %s", tt.injection.Latency, elapsed)
+			}
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if sent := received > 0; sent != tt.expectedSent {
+				t.Errorf("expected request sent %t, got %t", tt.expectedSent, sent)
+			}
+			if tt.fail {
+				var status v1.Status
+				if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+					t.Fatalf("failed to decode status: %v", err)
+				}
+				if status.Reason != v1.StatusReasonServiceUnavailable {
+					t.Errorf("expected reason %s, got %s", v1.StatusReasonServiceUnavailable, status.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestFailureRoundTripperCancelled(t *testing.T) {
+	rt := newFailureRoundTripper(&FailureInjection{Latency: time.Hour}, http.DefaultTransport)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+}
+
+func TestFailureInjectionValidate(t *testing.T) {
+	tests := []struct {
+		injection FailureInjection
+		valid     bool
+	}{
+		{injection: FailureInjection{Latency: time.Second, ErrorRate: 0.5}, valid: true},
+		{injection: FailureInjection{Latency: -time.Second}},
+		{injection: FailureInjection{ErrorRate: 1.5}},
+		{injection: FailureInjection{ErrorRate: -0.1}},
+	}
+
+	for _, tt := range tests {
+		if err := tt.injection.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%+v: expected valid %t, got error %v", tt.injection, tt.valid, err)
+		}
+	}
+}
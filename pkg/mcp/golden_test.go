@@ -0,0 +1,116 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the output shaping tests")
+
+// goldenOptions are the options of a golden test case, read from its options.yaml.
+type goldenOptions struct {
+	OutputMode     string         `json:"outputMode,omitempty"`
+	SummaryColumns SummaryColumns `json:"summaryColumns,omitempty"`
+}
+
+// TestOutputShapingGolden runs every case under testdata/shaping. A case is a directory with
+// an input.yaml holding the listed objects, an optional options.yaml holding goldenOptions
+// and the expected.json output. Run `go test ./pkg/mcp -run Golden -update` to regenerate
+// the expected outputs after changing the shaping rules, and review the diff.
+func TestOutputShapingGolden(t *testing.T) {
+	cases, err := filepath.Glob(filepath.Join("testdata", "shaping", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("no golden test cases found")
+	}
+
+	for _, dir := range cases {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			items := readGoldenInput(t, filepath.Join(dir, "input.yaml"))
+
+			var options goldenOptions
+			data, err := os.ReadFile(filepath.Join(dir, "options.yaml"))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				t.Fatal(err)
+			}
+			if err := sigsyaml.UnmarshalStrict(data, &options); err != nil {
+				t.Fatalf("failed to parse options: %v", err)
+			}
+
+			actual, err := json.MarshalIndent(shapeObjects(items, options.OutputMode, options.SummaryColumns), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual = append(actual, '\n')
+
+			expectedPath := filepath.Join(dir, "expected.json")
+			if *updateGolden {
+				if err := os.WriteFile(expectedPath, actual, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(expectedPath)
+			if err != nil {
+				t.Fatalf("failed to read golden file, run with -update to create it: %v", err)
+			}
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("output of %s does not match the golden file, run with -update to accept it:\n%s", dir, actual)
+			}
+		})
+	}
+}
+
+// readGoldenInput reads the objects of the YAML documents in the file.
+func readGoldenInput(t *testing.T, path string) []unstructured.Unstructured {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var items []unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var obj unstructured.Unstructured
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return items
+			}
+			t.Fatalf("failed to decode %s: %v", path, err)
+		}
+		if obj.Object != nil {
+			items = append(items, obj)
+		}
+	}
+}
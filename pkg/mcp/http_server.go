@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"net/http"
+	"time"
+)
+
+// HTTPLimits bound the resources used by the clients of the HTTP server, so that huge requests
+// or slow clients can not exhaust its memory or connections. Zero disables a limit.
+type HTTPLimits struct {
+	// MaxRequestBodySize is the maximum size in bytes of the bodies of the MCP requests.
+	MaxRequestBodySize int64
+	// MaxHeaderBytes is the maximum size of the request headers.
+	MaxHeaderBytes int
+	// ReadHeaderTimeout is the maximum duration to read the request headers.
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout is the maximum duration to read a request, including its body.
+	ReadTimeout time.Duration
+	// WriteTimeout is the maximum duration to write a response. The responses of the tool calls
+	// are streamed until the calls end, so it must be longer than the tool timeout.
+	WriteTimeout time.Duration
+	// IdleTimeout is the maximum duration a keep-alive connection waits for the next request.
+	IdleTimeout time.Duration
+}
+
+// DefaultHTTPLimits are the limits of the HTTP server by default. The write timeout is disabled, as
+// the event streams of the sessions stay open.
+var DefaultHTTPLimits = HTTPLimits{
+	MaxRequestBodySize: 10 << 20,
+	MaxHeaderBytes:     64 << 10,
+	ReadHeaderTimeout:  10 * time.Second,
+	ReadTimeout:        time.Minute,
+	IdleTimeout:        2 * time.Minute,
+}
+
+// httpServer returns the HTTP server of the handler listening on the address, with the limits.
+func (l HTTPLimits) httpServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		MaxHeaderBytes:    l.MaxHeaderBytes,
+		ReadHeaderTimeout: l.ReadHeaderTimeout,
+		ReadTimeout:       l.ReadTimeout,
+		WriteTimeout:      l.WriteTimeout,
+		IdleTimeout:       l.IdleTimeout,
+	}
+}
+
+// requestBodyLimitHandler refuses the requests whose body is larger than the limit. The bodies of
+// unknown length fail to be read past the limit.
+func requestBodyLimitHandler(limit int64, handler http.Handler) http.Handler {
+	if limit <= 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// DefaultImpactThreshold is the impact score from which applies must be confirmed with a typed phrase.
+	DefaultImpactThreshold = 20
+
+	// The weights of the impact score: every applied object counts, restarted workloads and workloads
+	// whose PodDisruptionBudgets allow no disruption count more, and production namespaces the most.
+	impactObjectWeight     = 1
+	impactRestartWeight    = 5
+	impactPDBRiskWeight    = 10
+	impactProductionWeight = 20
+
+	// elicitationPhraseField is the string field of the confirmations of high impact operations,
+	// in which the user types the requested phrase.
+	elicitationPhraseField = "phrase"
+)
+
+// DefaultProductionNamespaces are the patterns of the namespaces considered production by default.
+var DefaultProductionNamespaces = []string{"prod", "production", "prod-*", "*-prod"}
+
+// ApplyImpact is the impact of an apply, computed from its dry-run before it is confirmed.
+type ApplyImpact struct {
+	Score   int `json:"score"`
+	Objects int `json:"objects"`
+	// RestartedWorkloads are the workloads whose pod template changes, which restarts their pods.
+	RestartedWorkloads []string `json:"restartedWorkloads,omitempty"`
+	// PDBRisks are the restarted workloads whose PodDisruptionBudgets currently allow no disruption.
+	PDBRisks []string `json:"pdbRisks,omitempty"`
+	// ProductionNamespaces are the production namespaces changed by the apply.
+	ProductionNamespaces []string `json:"productionNamespaces,omitempty"`
+}
+
+// addObject counts an applied object, and flags its namespace when it matches the production patterns.
+func (i *ApplyImpact) addObject(obj *unstructured.Unstructured, productionNamespaces []string) {
+	i.Objects++
+	namespace := obj.GetNamespace()
+	if obj.GetKind() == "Namespace" {
+		namespace = obj.GetName()
+	}
+	if namespace != "" && isProductionNamespace(namespace, productionNamespaces) && !slices.Contains(i.ProductionNamespaces, namespace) {
+		i.ProductionNamespaces = append(i.ProductionNamespaces, namespace)
+	}
+	i.score()
+}
+
+// addChange compares the dry-run of an object with its live state, and records the restart of its
+// pods along with the PodDisruptionBudgets allowing no disruption of them.
+func (i *ApplyImpact) addChange(ctx context.Context, dynamicClient dynamic.Interface, dynamicResource dynamic.ResourceInterface, dryRun *unstructured.Unstructured) error {
+	if !restartsPods(dryRun.GetKind()) {
+		return nil
+	}
+	live, err := dynamicResource.Get(ctx, dryRun.GetName(), v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s/%s to assess the impact: %w", dryRun.GetKind(), dryRun.GetName(), err)
+	}
+	if !podTemplateChanged(live, dryRun) {
+		return nil
+	}
+
+	workload := fmt.Sprintf("%s %s/%s", dryRun.GetKind(), dryRun.GetNamespace(), dryRun.GetName())
+	i.RestartedWorkloads = append(i.RestartedWorkloads, workload)
+	i.PDBRisks = append(i.PDBRisks, blockingPDBs(ctx, dynamicClient, dryRun, workload)...)
+	i.score()
+	return nil
+}
+
+func (i *ApplyImpact) score() {
+	i.Score = i.Objects*impactObjectWeight + len(i.RestartedWorkloads)*impactRestartWeight + len(i.PDBRisks)*impactPDBRiskWeight
+	if len(i.ProductionNamespaces) > 0 {
+		i.Score += impactProductionWeight
+	}
+}
+
+// summary describes the impact in the confirmation of the apply.
+func (i *ApplyImpact) summary() string {
+	parts := []string{fmt.Sprintf("%d object(s)", i.Objects)}
+	if len(i.RestartedWorkloads) > 0 {
+		parts = append(parts, fmt.Sprintf("%d workload(s) restarted (%s)", len(i.RestartedWorkloads), strings.Join(i.RestartedWorkloads, ", ")))
+	}
+	if len(i.PDBRisks) > 0 {
+		parts = append(parts, fmt.Sprintf("PodDisruptionBudget risks (%s)", strings.Join(i.PDBRisks, "; ")))
+	}
+	if len(i.ProductionNamespaces) > 0 {
+		parts = append(parts, fmt.Sprintf("production namespace(s) %s", strings.Join(i.ProductionNamespaces, ", ")))
+	}
+	return fmt.Sprintf("Impact score %d: %s", i.Score, strings.Join(parts, ", "))
+}
+
+// restartsPods returns whether changing the pod template of the kind rolls out new pods.
+func restartsPods(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet":
+		return true
+	}
+	return false
+}
+
+func podTemplateChanged(live, applied *unstructured.Unstructured) bool {
+	liveTemplate, _, _ := unstructured.NestedMap(live.Object, "spec", "template")
+	appliedTemplate, _, _ := unstructured.NestedMap(applied.Object, "spec", "template")
+	return !equality.Semantic.DeepEqual(liveTemplate, appliedTemplate)
+}
+
+// blockingPDBs returns the PodDisruptionBudgets of the pods of a workload which currently allow no
+// disruption. PodDisruptionBudgets that can not be listed are reported as a risk too.
+func blockingPDBs(ctx context.Context, dynamicClient dynamic.Interface, workload *unstructured.Unstructured, name string) []string {
+	list, err := dynamicClient.Resource(pdbsGVR).Namespace(workload.GetNamespace()).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return []string{fmt.Sprintf("%s: failed to list PodDisruptionBudgets: %v", name, err)}
+	}
+	podLabels, _, _ := unstructured.NestedStringMap(workload.Object, "spec", "template", "metadata", "labels")
+
+	var risks []string
+	for _, item := range list.Items {
+		var pdb policyv1.PodDisruptionBudget
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pdb); err != nil || pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := v1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || !selector.Matches(labels.Set(podLabels)) {
+			continue
+		}
+		if pdb.Status.DisruptionsAllowed == 0 {
+			risks = append(risks, fmt.Sprintf("%s: PodDisruptionBudget %s allows no disruption", name, pdb.Name))
+		}
+	}
+	return risks
+}
+
+// isProductionNamespace returns whether the namespace matches one of the patterns (e.g. prod-*).
+func isProductionNamespace(namespace string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// requiresPhrase returns whether the impact is high enough to be confirmed with a typed phrase.
+func (s *Server) requiresPhrase(impact *ApplyImpact) bool {
+	threshold := s.reloadable().ImpactThreshold
+	return threshold > 0 && impact.Score >= threshold
+}
+
+// impactConfirmation returns the confirmation of an operation of the given impact. Above the impact
+// threshold, the user must also type the phrase, the name of the cluster.
+func (s *Server) impactConfirmation(message string, impact *ApplyImpact, phrase string) *mcp.ElicitParams {
+	message = fmt.Sprintf("%s\n\n%s", message, impact.summary())
+	params := &mcp.ElicitParams{
+		Message: message,
+		RequestedSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				elicitationConfirmField: {
+					Type:        "boolean",
+					Description: "Confirm whether to proceed with creating/updating the resources",
+				},
+			},
+			Required: []string{elicitationConfirmField},
+		},
+	}
+	if s.requiresPhrase(impact) {
+		params.Message = fmt.Sprintf("%s\n\nThis change is above the impact threshold of %d. Type %q to confirm it.", message, s.reloadable().ImpactThreshold, phrase)
+		params.RequestedSchema.Properties[elicitationPhraseField] = &jsonschema.Schema{
+			Type:        "string",
+			Description: fmt.Sprintf("Type %q to confirm the change", phrase),
+		}
+		params.RequestedSchema.Required = append(params.RequestedSchema.Required, elicitationPhraseField)
+	}
+	return params
+}
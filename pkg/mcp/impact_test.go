@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func impactDeployment(namespace, image string) *unstructured.Unstructured {
+	deployment := bundleObject("apps/v1", "Deployment", namespace, "web")
+	deployment.Object["spec"] = map[string]interface{}{
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "web"}},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"name": "web", "image": image}},
+			},
+		},
+	}
+	return deployment
+}
+
+func impactPDB(namespace, name string, disruptionsAllowed int64) *unstructured.Unstructured {
+	pdb := bundleObject("policy/v1", "PodDisruptionBudget", namespace, name)
+	pdb.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+	}
+	pdb.Object["status"] = map[string]interface{}{"disruptionsAllowed": disruptionsAllowed}
+	return pdb
+}
+
+func TestApplyImpact(t *testing.T) {
+	deploymentsGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		applied  []*unstructured.Unstructured
+		expected ApplyImpact
+	}{
+		{
+			name:     "new objects",
+			applied:  []*unstructured.Unstructured{bundleObject("v1", "ConfigMap", "shop", "settings"), impactDeployment("shop", "web:v2")},
+			expected: ApplyImpact{Score: 2, Objects: 2},
+		},
+		{
+			name:     "unchanged pod template",
+			objects:  []runtime.Object{impactDeployment("shop", "web:v1")},
+			applied:  []*unstructured.Unstructured{impactDeployment("shop", "web:v1")},
+			expected: ApplyImpact{Score: 1, Objects: 1},
+		},
+		{
+			name:     "restarted workload",
+			objects:  []runtime.Object{impactDeployment("shop", "web:v1"), impactPDB("shop", "web", 1)},
+			applied:  []*unstructured.Unstructured{impactDeployment("shop", "web:v2")},
+			expected: ApplyImpact{Score: 6, Objects: 1, RestartedWorkloads: []string{"Deployment shop/web"}},
+		},
+		{
+			name:    "restarted workload without disruption allowed",
+			objects: []runtime.Object{impactDeployment("shop", "web:v1"), impactPDB("shop", "web", 0), impactPDB("other", "web", 0)},
+			applied: []*unstructured.Unstructured{impactDeployment("shop", "web:v2")},
+			expected: ApplyImpact{Score: 16, Objects: 1, RestartedWorkloads: []string{"Deployment shop/web"},
+				PDBRisks: []string{"Deployment shop/web: PodDisruptionBudget web allows no disruption"}},
+		},
+		{
+			name:     "production namespaces",
+			applied:  []*unstructured.Unstructured{bundleObject("v1", "Namespace", "", "prod-eu"), bundleObject("v1", "ConfigMap", "prod-eu", "settings"), bundleObject("v1", "ConfigMap", "shop-prod", "settings")},
+			expected: ApplyImpact{Score: 23, Objects: 3, ProductionNamespaces: []string{"prod-eu", "shop-prod"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), append(tt.objects, impactPDB("kube-system", "dns", 0))...)
+			impact := &ApplyImpact{}
+			for _, obj := range tt.applied {
+				impact.addObject(obj, DefaultProductionNamespaces)
+				dynamicResource := dynamicClient.Resource(deploymentsGVR).Namespace(obj.GetNamespace())
+				if err := impact.addChange(context.TODO(), dynamicClient, dynamicResource, obj); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if !reflect.DeepEqual(*impact, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, *impact)
+			}
+		})
+	}
+}
+
+func TestImpactConfirmation(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		impact    ApplyImpact
+		phrase    bool
+	}{
+		{name: "below threshold", threshold: 20, impact: ApplyImpact{Score: 19}},
+		{name: "at threshold", threshold: 20, impact: ApplyImpact{Score: 20}, phrase: true},
+		{name: "disabled", impact: ApplyImpact{Score: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("8080", "k-mcp")
+			s.ImpactThreshold = tt.threshold
+			params := s.impactConfirmation("Do you want to proceed?", &tt.impact, "api.example.com:6443")
+			_, hasPhrase := params.RequestedSchema.Properties[elicitationPhraseField]
+			if hasPhrase != tt.phrase || slices.Contains(params.RequestedSchema.Required, elicitationPhraseField) != tt.phrase {
+				t.Errorf("expected phrase %v, got schema %+v", tt.phrase, params.RequestedSchema)
+			}
+
+			// Typed phrases can not be answered without a user, even in headless mode.
+			if _, err := defaultElicitResult(params, true); (err != nil) != tt.phrase {
+				t.Errorf("expected headless error %v, got %v", tt.phrase, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/ptr"
+)
+
+// inventoryConcurrency is the number of resource types counted in parallel.
+const inventoryConcurrency = 8
+
+// Well-known labels used to report the installed charts and application versions.
+const (
+	helmChartLabel  = "helm.sh/chart"
+	appNameLabel    = "app.kubernetes.io/name"
+	appVersionLabel = "app.kubernetes.io/version"
+	instanceLabel   = "app.kubernetes.io/instance"
+)
+
+type InventoryExportInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Restrict the inventory to this namespace (optional defaults to the whole cluster)"`
+}
+
+type InventoryExportResult struct {
+	Inventory Inventory `json:"inventory"`
+}
+
+// Inventory is the normalized inventory of a cluster.
+type Inventory struct {
+	GeneratedAt   string          `json:"generatedAt"`
+	ServerVersion string          `json:"serverVersion,omitempty"`
+	Namespace     string          `json:"namespace,omitempty"`
+	Kinds         []KindCount     `json:"kinds"`
+	Images        []ImageUsage    `json:"images"`
+	Charts        []ChartRelease  `json:"charts"`
+	Applications  []AppVersion    `json:"applications"`
+	Nodes         []NodeInventory `json:"nodes"`
+	Warnings      []string        `json:"warnings,omitempty"`
+}
+
+type KindCount struct {
+	Group      string `json:"group,omitempty"`
+	Version    string `json:"version"`
+	Kind       string `json:"kind"`
+	Resource   string `json:"resource"`
+	Namespaced bool   `json:"namespaced"`
+	Count      int64  `json:"count"`
+}
+
+type ImageUsage struct {
+	Image   string   `json:"image"`
+	Digests []string `json:"digests,omitempty"`
+	Pods    int      `json:"pods"`
+}
+
+type ChartRelease struct {
+	Chart     string `json:"chart"`
+	Release   string `json:"release,omitempty"`
+	Namespace string `json:"namespace"`
+}
+
+type AppVersion struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Namespace string `json:"namespace"`
+	Workload  string `json:"workload"`
+}
+
+type NodeInventory struct {
+	Name                    string `json:"name"`
+	OSImage                 string `json:"osImage"`
+	KernelVersion           string `json:"kernelVersion"`
+	OperatingSystem         string `json:"operatingSystem"`
+	Architecture            string `json:"architecture"`
+	ContainerRuntimeVersion string `json:"containerRuntimeVersion"`
+	KubeletVersion          string `json:"kubeletVersion"`
+}
+
+func (s *Server) addInventoryExportTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "inventory_export",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Export the cluster inventory",
+		},
+		Description: "Export a normalized inventory of the cluster as a single JSON document: object counts per kind, container images with their digests, Helm charts, application versions and node OS, kernel, runtime and kubelet versions. Useful for compliance snapshots and as context for later questions",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input InventoryExportInput) (*mcp.CallToolResult, *InventoryExportResult, error) {
+		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		inventory := Inventory{
+			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+			Namespace:   input.Namespace,
+		}
+
+		if version, err := discoveryClient.ServerVersion(); err != nil {
+			inventory.Warnings = append(inventory.Warnings, fmt.Sprintf("failed to get server version: %v", err))
+		} else {
+			inventory.ServerVersion = version.GitVersion
+		}
+
+		resources, failedGroups, err := serverPreferredResources(discoveryClient)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(failedGroups) > 0 {
+			inventory.Warnings = append(inventory.Warnings, fmt.Sprintf("discovery failed for groups: %s", strings.Join(failedGroups, ", ")))
+		}
+
+		var warnings []string
+		inventory.Kinds, warnings = countKinds(ctx, dynamicClient, resources, input.Namespace)
+		inventory.Warnings = append(inventory.Warnings, warnings...)
+
+		podList, err := dynamicClient.Resource(podsGVR).Namespace(input.Namespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		pods := make([]corev1.Pod, 0, len(podList.Items))
+		for _, item := range podList.Items {
+			var pod corev1.Pod
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pod); err != nil {
+				continue
+			}
+			pods = append(pods, pod)
+		}
+		inventory.Images = imageInventory(pods)
+
+		var workloads []unstructured.Unstructured
+		for _, gvr := range []schema.GroupVersionResource{deploymentsGVR, statefulSetsGVR, daemonSetsGVR} {
+			list, err := dynamicClient.Resource(gvr).Namespace(input.Namespace).List(ctx, v1.ListOptions{})
+			if err != nil {
+				inventory.Warnings = append(inventory.Warnings, fmt.Sprintf("failed to list %s: %v", gvr.Resource, err))
+				continue
+			}
+			workloads = append(workloads, list.Items...)
+		}
+		inventory.Charts, inventory.Applications = releaseInventory(workloads)
+
+		inventory.Nodes = []NodeInventory{}
+		if input.Namespace == "" {
+			nodeList, err := dynamicClient.Resource(nodesGVR).List(ctx, v1.ListOptions{})
+			if err != nil {
+				inventory.Warnings = append(inventory.Warnings, fmt.Sprintf("failed to list nodes: %v", err))
+			} else {
+				inventory.Nodes = nodeInventory(nodeList.Items)
+			}
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Exported inventory of %d kinds, %d images, %d charts, %d applications and %d nodes",
+						len(inventory.Kinds), len(inventory.Images), len(inventory.Charts), len(inventory.Applications), len(inventory.Nodes)),
+				},
+			},
+		}, &InventoryExportResult{Inventory: inventory}, nil
+	})
+}
+
+// countKinds counts the objects of every listable resource. Only a single object of every
+// resource is fetched, the remaining item count reported by the server gives the total.
+func countKinds(ctx context.Context, dynamicClient dynamic.Interface, resources []*v1.APIResourceList, namespace string) ([]KindCount, []string) {
+	var (
+		mu       sync.Mutex
+		kinds    = []KindCount{}
+		warnings []string
+	)
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(inventoryConcurrency)
+	for _, resourceList := range resources {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			gvr := gv.WithResource(resource.Name)
+			if strings.Contains(resource.Name, "/") || !slices.Contains(resource.Verbs, "list") || isRestrictedResource(gvr) {
+				continue
+			}
+			if namespace != "" && !resource.Namespaced {
+				continue
+			}
+
+			g.Go(func() error {
+				list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, v1.ListOptions{Limit: 1})
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("failed to count %s: %v", gvr.GroupResource(), err))
+					return nil
+				}
+				count := int64(len(list.Items))
+				if remaining := list.GetRemainingItemCount(); remaining != nil {
+					count += *remaining
+				}
+				kinds = append(kinds, KindCount{
+					Group:      gv.Group,
+					Version:    gv.Version,
+					Kind:       resource.Kind,
+					Resource:   resource.Name,
+					Namespaced: resource.Namespaced,
+					Count:      count,
+				})
+				return nil
+			})
+		}
+	}
+	//nolint:errcheck
+	g.Wait()
+
+	sort.Slice(kinds, func(i, j int) bool {
+		if kinds[i].Group != kinds[j].Group {
+			return kinds[i].Group < kinds[j].Group
+		}
+		return kinds[i].Resource < kinds[j].Resource
+	})
+	sort.Strings(warnings)
+	return kinds, warnings
+}
+
+// imageInventory returns the images used by the containers of the pods with the digests they resolved to.
+func imageInventory(pods []corev1.Pod) []ImageUsage {
+	usages := map[string]*ImageUsage{}
+	digests := map[string]map[string]bool{}
+	for _, pod := range pods {
+		statuses := make(map[string]corev1.ContainerStatus, len(pod.Status.ContainerStatuses)+len(pod.Status.InitContainerStatuses))
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			statuses[status.Name] = status
+		}
+
+		seen := map[string]bool{}
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			usage, ok := usages[container.Image]
+			if !ok {
+				usage = &ImageUsage{Image: container.Image}
+				usages[container.Image] = usage
+				digests[container.Image] = map[string]bool{}
+			}
+			if !seen[container.Image] {
+				usage.Pods++
+				seen[container.Image] = true
+			}
+			if digest := imageDigest(statuses[container.Name].ImageID); digest != "" {
+				digests[container.Image][digest] = true
+			}
+		}
+	}
+
+	images := make([]ImageUsage, 0, len(usages))
+	for image, usage := range usages {
+		for digest := range digests[image] {
+			usage.Digests = append(usage.Digests, digest)
+		}
+		sort.Strings(usage.Digests)
+		images = append(images, *usage)
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Image < images[j].Image
+	})
+	return images
+}
+
+// imageDigest extracts the digest from the image ID reported by the container runtime
+// (e.g. docker.io/library/nginx@sha256:abc or docker-pullable://nginx@sha256:abc).
+func imageDigest(imageID string) string {
+	if i := strings.LastIndex(imageID, "@"); i >= 0 {
+		return imageID[i+1:]
+	}
+	if strings.HasPrefix(imageID, "sha256:") {
+		return imageID
+	}
+	return ""
+}
+
+// releaseInventory returns the Helm charts and the application versions declared by the
+// well-known labels of the workloads.
+func releaseInventory(workloads []unstructured.Unstructured) ([]ChartRelease, []AppVersion) {
+	charts := []ChartRelease{}
+	apps := []AppVersion{}
+	seenCharts := map[ChartRelease]bool{}
+	for _, workload := range workloads {
+		labels := workload.GetLabels()
+		if chart := labels[helmChartLabel]; chart != "" {
+			release := ChartRelease{Chart: chart, Release: labels[instanceLabel], Namespace: workload.GetNamespace()}
+			if !seenCharts[release] {
+				seenCharts[release] = true
+				charts = append(charts, release)
+			}
+		}
+		if version := labels[appVersionLabel]; version != "" {
+			name := labels[appNameLabel]
+			if name == "" {
+				name = workload.GetName()
+			}
+			apps = append(apps, AppVersion{
+				Name:      name,
+				Version:   version,
+				Namespace: workload.GetNamespace(),
+				Workload:  fmt.Sprintf("%s/%s", strings.ToLower(workload.GetKind()), workload.GetName()),
+			})
+		}
+	}
+
+	sort.Slice(charts, func(i, j int) bool {
+		if charts[i].Namespace != charts[j].Namespace {
+			return charts[i].Namespace < charts[j].Namespace
+		}
+		return charts[i].Chart < charts[j].Chart
+	})
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].Namespace != apps[j].Namespace {
+			return apps[i].Namespace < apps[j].Namespace
+		}
+		return apps[i].Workload < apps[j].Workload
+	})
+	return charts, apps
+}
+
+func nodeInventory(items []unstructured.Unstructured) []NodeInventory {
+	nodes := make([]NodeInventory, 0, len(items))
+	for _, item := range items {
+		var node corev1.Node
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &node); err != nil {
+			continue
+		}
+		info := node.Status.NodeInfo
+		nodes = append(nodes, NodeInventory{
+			Name:                    node.Name,
+			OSImage:                 info.OSImage,
+			KernelVersion:           info.KernelVersion,
+			OperatingSystem:         info.OperatingSystem,
+			Architecture:            info.Architecture,
+			ContainerRuntimeVersion: info.ContainerRuntimeVersion,
+			KubeletVersion:          info.KubeletVersion,
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
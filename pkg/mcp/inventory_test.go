@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestImageInventory(t *testing.T) {
+	pod := func(name, imageID string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: name},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.36"}},
+				Containers:     []corev1.Container{{Name: "app", Image: "nginx:1.27"}},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", ImageID: imageID}},
+			},
+		}
+	}
+
+	images := imageInventory([]corev1.Pod{
+		pod("web-0", "docker.io/library/nginx@sha256:aaa"),
+		pod("web-1", "docker-pullable://nginx@sha256:bbb"),
+		pod("web-2", "docker.io/library/nginx@sha256:aaa"),
+	})
+	expected := []ImageUsage{
+		{Image: "busybox:1.36", Pods: 3},
+		{Image: "nginx:1.27", Digests: []string{"sha256:aaa", "sha256:bbb"}, Pods: 3},
+	}
+	if !reflect.DeepEqual(images, expected) {
+		t.Errorf("expected %+v, got %+v", expected, images)
+	}
+}
+
+func TestReleaseInventory(t *testing.T) {
+	workload := func(kind, name string, labels map[string]interface{}) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"kind": kind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "monitoring",
+				"labels":    labels,
+			},
+		}}
+	}
+
+	charts, apps := releaseInventory([]unstructured.Unstructured{
+		workload("Deployment", "grafana", map[string]interface{}{
+			helmChartLabel:  "grafana-8.5.1",
+			instanceLabel:   "grafana",
+			appNameLabel:    "grafana",
+			appVersionLabel: "11.2.0",
+		}),
+		workload("StatefulSet", "grafana-cache", map[string]interface{}{
+			helmChartLabel: "grafana-8.5.1",
+			instanceLabel:  "grafana",
+		}),
+		workload("DaemonSet", "node-exporter", map[string]interface{}{
+			appVersionLabel: "1.8.2",
+		}),
+	})
+
+	expectedCharts := []ChartRelease{{Chart: "grafana-8.5.1", Release: "grafana", Namespace: "monitoring"}}
+	if !reflect.DeepEqual(charts, expectedCharts) {
+		t.Errorf("expected charts %+v, got %+v", expectedCharts, charts)
+	}
+	expectedApps := []AppVersion{
+		{Name: "node-exporter", Version: "1.8.2", Namespace: "monitoring", Workload: "daemonset/node-exporter"},
+		{Name: "grafana", Version: "11.2.0", Namespace: "monitoring", Workload: "deployment/grafana"},
+	}
+	if !reflect.DeepEqual(apps, expectedApps) {
+		t.Errorf("expected applications %+v, got %+v", expectedApps, apps)
+	}
+}
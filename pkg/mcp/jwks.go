@@ -0,0 +1,299 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// jwksRefreshInterval is the age after which the keys are fetched again.
+	jwksRefreshInterval = time.Hour
+	// jwksMinRefreshInterval bounds how often tokens signed with unknown keys can make the keys be fetched again.
+	jwksMinRefreshInterval = time.Minute
+	// jwksFetchTimeout bounds the fetches of the keys.
+	jwksFetchTimeout = 10 * time.Second
+	// maxJWKSSize bounds the size of the key sets.
+	maxJWKSSize = 1 << 20
+)
+
+// jwksSigningMethods are the asymmetric algorithms of the tokens verified with a key set. Symmetric
+// algorithms are refused, since their keys can not be published.
+var jwksSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// ValidateSigningAlgorithms rejects the signing algorithms that can not be accepted, such as none
+// and the symmetric algorithms.
+func ValidateSigningAlgorithms(algorithms []string) error {
+	for _, algorithm := range algorithms {
+		if !slices.Contains(jwksSigningMethods, algorithm) {
+			return fmt.Errorf("unsupported signing algorithm %q, must be one of %s", algorithm, strings.Join(jwksSigningMethods, ", "))
+		}
+	}
+	return nil
+}
+
+// jsonWebKey is a public key of a JSON Web Key Set (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC and OKP keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwksKeySet verifies the signatures of the tokens with the public keys published at a JWKS URL,
+// e.g. the /openid/v1/jwks endpoint of the service account issuer of a cluster. The keys are cached,
+// and fetched again once old or when a token is signed with an unknown key, after a key rotation.
+type jwksKeySet struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]any
+	fetched time.Time
+	// refreshing is closed once the running fetch of the keys completes, nil when none is running.
+	refreshing chan struct{}
+	// refreshErr is the error of the last fetch of the keys.
+	refreshErr error
+}
+
+func newJWKSKeySet(url string) *jwksKeySet {
+	return &jwksKeySet{
+		url:    url,
+		client: &http.Client{},
+	}
+}
+
+// keyfunc returns the function returning the key verifying the signature of a token, from its key ID.
+// Tokens without key ID are verified with the only key of the set. The keys are fetched outside of
+// the lock: the tokens signed with a known key are verified with the cached keys while they are
+// fetched again, and the other ones wait for the fetch until the context of their request is done.
+func (k *jwksKeySet) keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		k.mu.Lock()
+		key, ok := k.lookup(kid)
+		var refreshed <-chan struct{}
+		stale := time.Since(k.fetched) > jwksRefreshInterval
+		if (!ok && (time.Since(k.fetched) > jwksMinRefreshInterval || k.refreshing != nil)) || stale {
+			refreshed = k.startRefresh(ctx)
+		}
+		k.mu.Unlock()
+		if ok {
+			return key, nil
+		}
+		if refreshed == nil {
+			return nil, fmt.Errorf("no key %q in the JWKS of %s", kid, k.url)
+		}
+
+		select {
+		case <-refreshed:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to fetch the JWKS: %w", ctx.Err())
+		}
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		if key, ok := k.lookup(kid); ok {
+			return key, nil
+		}
+		if k.refreshErr != nil {
+			return nil, k.refreshErr
+		}
+		return nil, fmt.Errorf("no key %q in the JWKS of %s", kid, k.url)
+	}
+}
+
+func (k *jwksKeySet) lookup(kid string) (any, bool) {
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// startRefresh fetches the keys in the background unless they are already being fetched, and returns
+// the channel closed once they are. The caller must hold the lock.
+func (k *jwksKeySet) startRefresh(ctx context.Context) <-chan struct{} {
+	if k.refreshing != nil {
+		return k.refreshing
+	}
+	refreshing := make(chan struct{})
+	k.refreshing = refreshing
+	// Failed fetches count too, so that an unavailable endpoint is not hit for every token.
+	k.fetched = time.Now()
+
+	// Other requests may wait for the fetch, which outlives the request starting it but is bounded.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksFetchTimeout)
+	go func() {
+		defer cancel()
+		keys, err := k.fetch(ctx)
+
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		if err == nil {
+			k.keys = keys
+		} else if len(k.keys) > 0 {
+			// Keep verifying with the cached keys while the key set can not be fetched.
+			slog.Warn("Failed to refresh the JWKS keys, using the cached keys", "url", k.url, "err", err)
+		}
+		k.refreshErr = err
+		k.refreshing = nil
+		close(refreshing)
+	}()
+	return refreshing
+}
+
+// fetch returns the keys published at the URL.
+func (k *jwksKeySet) fetch(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the JWKS: %w", err)
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the JWKS: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the JWKS: %s returned %s", k.url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the JWKS: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("Fetched the JWKS keys", "url", k.url, "keys", len(keys))
+	return keys, nil
+}
+
+// parseJWKS returns the signature keys of a JSON Web Key Set by key ID. Keys of unsupported
+// types are skipped.
+func parseJWKS(data []byte) (map[string]any, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in JWKS: %w", jwk.Kid, err)
+		}
+		if key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signature key in JWKS")
+	}
+	return keys, nil
+}
+
+// publicKey returns the public key, nil if its type is not supported.
+func (jwk *jsonWebKey) publicKey() (any, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+		x, err := decodeJWKInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point not on curve %s", jwk.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("invalid key parameter %q", value)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]any {
+	return map[string]any{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]any {
+	return map[string]any{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func TestJWKSKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	keys := []map[string]any{rsaJWK("rsa", &rsaKey.PublicKey)}
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer server.Close()
+
+	keySet := newJWKSKeySet(server.URL)
+	parse := func(method jwt.SigningMethod, kid string, key any) error {
+		token := jwt.NewWithClaims(method, &JWTClaims{RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parser := jwt.NewParser(jwt.WithValidMethods(jwksSigningMethods), jwt.WithExpirationRequired())
+		_, err = parser.ParseWithClaims(signed, &JWTClaims{}, keySet.keyfunc(context.Background()))
+		return err
+	}
+
+	if err := parse(jwt.SigningMethodRS256, "rsa", rsaKey); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := parse(jwt.SigningMethodRS256, "", rsaKey); err != nil {
+		t.Errorf("expected the only key to verify tokens without key ID, got %v", err)
+	}
+	if err := parse(jwt.SigningMethodRS256, "rsa", otherKey); err == nil {
+		t.Errorf("expected error for a token signed with another key")
+	}
+	if err := parse(jwt.SigningMethodHS256, "rsa", []byte("secret")); err == nil {
+		t.Errorf("expected error for a symmetric signature")
+	}
+
+	// Unknown keys are only fetched again after the minimum refresh interval.
+	mu.Lock()
+	keys = append(keys, ecJWK("ec", &ecKey.PublicKey))
+	mu.Unlock()
+	if err := parse(jwt.SigningMethodES256, "ec", ecKey); err == nil {
+		t.Errorf("expected error before the keys are fetched again")
+	}
+	keySet.mu.Lock()
+	keySet.fetched = time.Now().Add(-2 * jwksMinRefreshInterval)
+	keySet.mu.Unlock()
+	if err := parse(jwt.SigningMethodES256, "ec", ecKey); err != nil {
+		t.Errorf("expected the rotated key to be fetched, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fetches != 2 {
+		t.Errorf("expected 2 fetches, got %d", fetches)
+	}
+}
+
+func TestJWKSKeySetSlowFetch(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan struct{})
+	// requested receives a value for every fetch of the keys reaching the server.
+	requested := make(chan struct{}, 2)
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		requested <- struct{}{}
+		<-release
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]any{rsaJWK("rsa", &rsaKey.PublicKey)}})
+	}))
+	defer server.Close()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &JWTClaims{RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}})
+	token.Header["kid"] = "rsa"
+	signed, err := token.SignedString(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keySet := newJWKSKeySet(server.URL)
+	parse := func(ctx context.Context) error {
+		parser := jwt.NewParser(jwt.WithValidMethods(jwksSigningMethods), jwt.WithExpirationRequired())
+		_, err := parser.ParseWithClaims(signed, &JWTClaims{}, keySet.keyfunc(ctx))
+		return err
+	}
+
+	// A verification waiting for the keys stops with its request.
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- parse(ctx)
+	}()
+	<-requested
+	cancel()
+	if err := <-stopped; err == nil {
+		t.Errorf("expected the verification to stop with its request")
+	}
+
+	// The verifications waiting for the keys share the running fetch, still blocked by the server.
+	waiting := make(chan struct{}, 3)
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			errs <- parse(&waitingContext{Context: context.Background(), waiting: waiting})
+		}()
+	}
+	for range 3 {
+		<-waiting
+	}
+	release <- struct{}{}
+	for range 3 {
+		if err := <-errs; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected the verifications to share 1 fetch, got %d fetches", n)
+	}
+
+	// Stale keys keep verifying the tokens while they are fetched again.
+	keySet.mu.Lock()
+	keySet.fetched = time.Now().Add(-2 * jwksRefreshInterval)
+	keySet.mu.Unlock()
+	if err := parse(context.Background()); err != nil {
+		t.Errorf("expected the cached key to verify the token, got %v", err)
+	}
+	keySet.mu.Lock()
+	refreshing := keySet.refreshing
+	keySet.mu.Unlock()
+	if refreshing == nil {
+		t.Fatalf("expected the stale keys to be fetched again in the background")
+	}
+	<-requested
+	release <- struct{}{}
+	<-refreshing
+
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("expected 2 fetches, got %d", n)
+	}
+}
+
+// waitingContext tells when a verification waits for the keys, by sending on waiting the first time
+// its Done channel is asked for.
+type waitingContext struct {
+	context.Context
+	waiting chan<- struct{}
+	once    sync.Once
+}
+
+func (c *waitingContext) Done() <-chan struct{} {
+	c.once.Do(func() { c.waiting <- struct{}{} })
+	return c.Context.Done()
+}
+
+func TestParseJWKS(t *testing.T) {
+	tests := []struct {
+		name     string
+		jwks     string
+		expected int
+		wantErr  bool
+	}{
+		{name: "invalid json", jwks: "{", wantErr: true},
+		{name: "no keys", jwks: `{"keys": []}`, wantErr: true},
+		{name: "encryption key skipped", jwks: `{"keys": [{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`, wantErr: true},
+		{name: "unsupported type skipped", jwks: `{"keys": [{"kty": "oct", "kid": "a"}, {"kty": "RSA", "kid": "b", "n": "AQAB", "e": "AQAB"}]}`, expected: 1},
+		{name: "invalid parameter", jwks: `{"keys": [{"kty": "RSA", "kid": "b", "n": "!", "e": "AQAB"}]}`, wantErr: true},
+		{name: "point not on curve", jwks: `{"keys": [{"kty": "EC", "kid": "c", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseJWKS([]byte(tt.jwks))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if len(keys) != tt.expected {
+				t.Errorf("expected %d keys, got %d", tt.expected, len(keys))
+			}
+		})
+	}
+}
+
+func TestValidateSigningAlgorithms(t *testing.T) {
+	tests := []struct {
+		name       string
+		algorithms []string
+		wantErr    bool
+	}{
+		{name: "default"},
+		{name: "asymmetric", algorithms: []string{"RS256", "ES256", "EdDSA"}},
+		{name: "none", algorithms: []string{"RS256", "none"}, wantErr: true},
+		{name: "symmetric", algorithms: []string{"HS256"}, wantErr: true},
+		{name: "case sensitive", algorithms: []string{"rs256"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSigningAlgorithms(tt.algorithms); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+// kubectlShortNames are the short names of the built-in resources accepted by kubectl.
+var kubectlShortNames = map[string]string{
+	"cm":     "configmaps",
+	"cj":     "cronjobs",
+	"crd":    "customresourcedefinitions",
+	"crds":   "customresourcedefinitions",
+	"deploy": "deployments",
+	"ds":     "daemonsets",
+	"ep":     "endpoints",
+	"ev":     "events",
+	"hpa":    "horizontalpodautoscalers",
+	"ing":    "ingresses",
+	"limits": "limitranges",
+	"netpol": "networkpolicies",
+	"no":     "nodes",
+	"ns":     "namespaces",
+	"pdb":    "poddisruptionbudgets",
+	"po":     "pods",
+	"pv":     "persistentvolumes",
+	"pvc":    "persistentvolumeclaims",
+	"quota":  "resourcequotas",
+	"rs":     "replicasets",
+	"sc":     "storageclasses",
+	"sts":    "statefulsets",
+	"svc":    "services",
+}
+
+// kubectlValueFlags are the flags taking a value, given as the next word or after =.
+var kubectlValueFlags = map[string]bool{"-n": true, "--namespace": true, "-l": true, "--selector": true, "-o": true, "--output": true, "--replicas": true}
+
+type KubectlTranslateInput struct {
+	Command  string `json:"command,required" jsonschema:"The kubectl command, e.g. kubectl get pods -n shop -l app=web. Supported: get, describe and scale, with the -n, -A, -l, -o and --replicas flags"`
+	PlanOnly bool   `json:"planOnly,omitempty" jsonschema:"Return the k-mcp tool call the command translates to without running it"`
+}
+
+// KubectlPlan is the k-mcp tool call a kubectl command translates to.
+type KubectlPlan struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+type KubectlTranslateResult struct {
+	Plan KubectlPlan `json:"plan"`
+	// Executed is whether the tool call was run, reads only: changes are left to the client.
+	Executed bool `json:"executed"`
+	// Result is the structured result of the tool call when executed.
+	Result any `json:"result,omitempty"`
+}
+
+// kubectlCommand is a parsed kubectl command.
+type kubectlCommand struct {
+	verb          string
+	args          []string
+	namespace     string
+	allNamespaces bool
+	selector      string
+	output        string
+	replicas      *int64
+}
+
+// splitKubectlCommand splits a command into words like a shell would, with single quotes, double
+// quotes and backslash escapes. Pipes, redirections, substitutions and command separators are
+// refused, since the command is never run by a shell.
+func splitKubectlCommand(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case strings.ContainsRune("|&;<>`$()", r):
+			return nil, fmt.Errorf("shell syntax %q is not supported, only a single kubectl command", string(r))
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// parseKubectlCommand parses the supported subset of the kubectl commands.
+func parseKubectlCommand(command string) (*kubectlCommand, error) {
+	words, err := splitKubectlCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) > 0 && words[0] == "kubectl" {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no kubectl command")
+	}
+
+	cmd := &kubectlCommand{verb: words[0]}
+	switch cmd.verb {
+	case "get", "describe", "scale":
+	case "logs":
+		return nil, fmt.Errorf("kubectl logs is not supported, k-mcp has no tool reading container logs: use pod_diagnose for the container states, last termination reasons and events")
+	default:
+		return nil, fmt.Errorf("kubectl %s is not supported, only get, describe and scale are", cmd.verb)
+	}
+
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "-") {
+			cmd.args = append(cmd.args, word)
+			continue
+		}
+
+		flag, value, hasValue := strings.Cut(word, "=")
+		if kubectlValueFlags[flag] && !hasValue {
+			if i+1 >= len(words) {
+				return nil, fmt.Errorf("flag %s needs a value", flag)
+			}
+			i++
+			value = words[i]
+		} else if !kubectlValueFlags[flag] && hasValue {
+			return nil, fmt.Errorf("flag %s does not take a value", flag)
+		}
+
+		switch flag {
+		case "-n", "--namespace":
+			cmd.namespace = value
+		case "-A", "--all-namespaces":
+			cmd.allNamespaces = true
+		case "-l", "--selector":
+			cmd.selector = value
+		case "-o", "--output":
+			cmd.output = value
+		case "--replicas":
+			replicas, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || replicas < 0 {
+				return nil, fmt.Errorf("invalid replicas %q", value)
+			}
+			cmd.replicas = ptr.To(replicas)
+		default:
+			return nil, fmt.Errorf("flag %s is not supported", flag)
+		}
+	}
+	if cmd.namespace != "" && cmd.allNamespaces {
+		return nil, fmt.Errorf("-n and -A can not be combined")
+	}
+	return cmd, nil
+}
+
+// target returns the resource type and the name of the object of the command, from the
+// "type name" or "type/name" arguments. The name is empty if the command has none.
+func (c *kubectlCommand) target() (string, string, error) {
+	var resource, name string
+	switch {
+	case len(c.args) == 0:
+		return "", "", fmt.Errorf("kubectl %s needs a resource type", c.verb)
+	case len(c.args) == 1:
+		resource, name, _ = strings.Cut(c.args[0], "/")
+	case len(c.args) == 2 && !strings.Contains(c.args[0], "/"):
+		resource, name = c.args[0], c.args[1]
+	default:
+		return "", "", fmt.Errorf("only one resource type and name are supported, got %s", strings.Join(c.args, " "))
+	}
+	if strings.Contains(resource, ",") {
+		return "", "", fmt.Errorf("only one resource type is supported, got %s", resource)
+	}
+	if short, ok := kubectlShortNames[strings.ToLower(resource)]; ok {
+		resource = short
+	}
+	return resource, name, nil
+}
+
+// resourceArgument returns the resource type of the tool arguments for the resource.
+func resourceArgument(gvr schema.GroupVersionResource) string {
+	if gvr.Group == "" {
+		return gvr.Resource
+	}
+	return gvr.Resource + "." + gvr.Version + "." + gvr.Group
+}
+
+// translateKubectlCommand translates the command to the tool call doing the same. Commands without namespace
+// use the namespace of the session or the preferred one, then default, like kubectl does with
+// the namespace of its context.
+func (s *Server) translateKubectlCommand(ctx context.Context, request *mcp.CallToolRequest, cmd *kubectlCommand, discoveryClient discovery.CachedDiscoveryInterface) (KubectlPlan, error) {
+	resource, name, err := cmd.target()
+	if err != nil {
+		return KubectlPlan{}, err
+	}
+	gvr, namespaced, err := FindResource(ctx, resource, discoveryClient, request.Session)
+	if err != nil {
+		return KubectlPlan{}, fmt.Errorf("failed to find resource: %w", err)
+	}
+
+	namespace := ""
+	if namespaced && !cmd.allNamespaces {
+		namespace = cmd.namespace
+		if namespace == "" {
+			namespace = s.defaultNamespace(request)
+		}
+		if namespace == "" {
+			namespace = "default"
+		}
+	}
+
+	var input any
+	var tool string
+	switch {
+	case cmd.verb == "scale":
+		if cmd.replicas == nil {
+			return KubectlPlan{}, fmt.Errorf("kubectl scale needs --replicas")
+		}
+		if name == "" || cmd.allNamespaces || cmd.selector != "" {
+			return KubectlPlan{}, fmt.Errorf("kubectl scale needs the name of a single object")
+		}
+		manifest, err := scaleManifest(discoveryClient, gvr, name, namespace, *cmd.replicas)
+		if err != nil {
+			return KubectlPlan{}, err
+		}
+		tool, input = "resource_apply", ResourceCreateOrUpdateInput{ResourceYAML: manifest}
+	case cmd.replicas != nil:
+		return KubectlPlan{}, fmt.Errorf("--replicas is only supported by kubectl scale")
+	case name != "":
+		if cmd.selector != "" || cmd.allNamespaces {
+			return KubectlPlan{}, fmt.Errorf("a name can not be combined with -l or -A")
+		}
+		tool, input = "resource_get", ResourceGetInput{Resource: resourceArgument(gvr), Name: name, Namespace: namespace}
+	case cmd.verb == "describe":
+		return KubectlPlan{}, fmt.Errorf("kubectl describe needs the name of an object")
+	default:
+		outputMode := OutputModeSummary
+		switch cmd.output {
+		case "", "wide", "name":
+		case "yaml", "json":
+			outputMode = OutputModeFull
+		default:
+			return KubectlPlan{}, fmt.Errorf("output %q is not supported, only wide, name, yaml and json are", cmd.output)
+		}
+		tool, input = "resource_list", ResourceListInput{Resource: resourceArgument(gvr), Namespace: namespace, LabelSelector: cmd.selector, OutputMode: outputMode}
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return KubectlPlan{}, err
+	}
+	plan := KubectlPlan{Tool: tool}
+	if err := json.Unmarshal(data, &plan.Arguments); err != nil {
+		return KubectlPlan{}, err
+	}
+	return plan, nil
+}
+
+// scaleManifest returns the manifest setting the replicas of the object, when its resource can be scaled.
+func scaleManifest(discoveryClient discovery.CachedDiscoveryInterface, gvr schema.GroupVersionResource, name, namespace string, replicas int64) (string, error) {
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return "", fmt.Errorf("failed to discover %s: %w", gvr.GroupVersion(), err)
+	}
+	var kind string
+	scalable := false
+	for _, resource := range resources.APIResources {
+		switch resource.Name {
+		case gvr.Resource:
+			kind = resource.Kind
+		case gvr.Resource + "/scale":
+			scalable = true
+		}
+	}
+	if kind == "" || !scalable {
+		return "", fmt.Errorf("%s can not be scaled", gvr.Resource)
+	}
+
+	metadata := map[string]any{"name": name}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	data, err := yaml.Marshal(map[string]any{
+		"apiVersion": gvr.GroupVersion().String(),
+		"kind":       kind,
+		"metadata":   metadata,
+		"spec":       map[string]any{"replicas": replicas},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (s *Server) addKubectlTranslateTool(server *mcp.Server, dynamicConfig *DynamicConfig,
+	listResources mcp.ToolHandlerFor[ResourceListInput, *ResourceListResult],
+	getResource mcp.ToolHandlerFor[ResourceGetInput, *ResourceGetResult]) {
+	addTool(server, &mcp.Tool{
+		Name: "kubectl_translate",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Translate a kubectl command",
+		},
+		Description: "Translate a kubectl command (get, describe or scale) to the k-mcp tool call doing the same, and run it for reads. " +
+			"Changes like scale are never run: the resource_apply call is returned as a plan to call after confirmation. " +
+			"The command is parsed, never run by a shell, so pipes and substitutions are refused",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input KubectlTranslateInput) (*mcp.CallToolResult, *KubectlTranslateResult, error) {
+		cmd, err := parseKubectlCommand(input.Command)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid kubectl command: %w", err)
+		}
+
+		_, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+		plan, err := s.translateKubectlCommand(ctx, request, cmd, discoveryClient)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		arguments, err := json.Marshal(plan.Arguments)
+		if err != nil {
+			return nil, nil, err
+		}
+		summary := fmt.Sprintf("kubectl %s translates to %s %s", cmd.verb, plan.Tool, arguments)
+		result := &KubectlTranslateResult{Plan: plan}
+		if input.PlanOnly || plan.Tool == "resource_apply" {
+			if plan.Tool == "resource_apply" {
+				summary += ". Call resource_apply with these arguments to apply the change after confirmation"
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: summary}},
+			}, result, nil
+		}
+
+		var toolResult *mcp.CallToolResult
+		switch plan.Tool {
+		case "resource_get":
+			var getInput ResourceGetInput
+			if err := json.Unmarshal(arguments, &getInput); err != nil {
+				return nil, nil, err
+			}
+			var got *ResourceGetResult
+			toolResult, got, err = getResource(ctx, request, getInput)
+			result.Result = got
+		case "resource_list":
+			var listInput ResourceListInput
+			if err := json.Unmarshal(arguments, &listInput); err != nil {
+				return nil, nil, err
+			}
+			var listed *ResourceListResult
+			toolResult, listed, err = listResources(ctx, request, listInput)
+			result.Result = listed
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		result.Executed = true
+
+		var texts []string
+		for _, content := range toolResult.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				texts = append(texts, text.Text)
+			}
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: textSummary(summary, texts...)}},
+		}, result, nil
+	})
+}
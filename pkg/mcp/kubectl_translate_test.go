@@ -0,0 +1,165 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestSplitKubectlCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "words", command: "kubectl get  pods\t-n shop", expected: []string{"kubectl", "get", "pods", "-n", "shop"}},
+		{name: "quotes", command: `get pods -l 'app in (web, api)' -n "my shop"`, expected: []string{"get", "pods", "-l", "app in (web, api)", "-n", "my shop"}},
+		{name: "escapes", command: `get pods -l app=web\ api "a\"b"`, expected: []string{"get", "pods", "-l", "app=web api", `a"b`}},
+		{name: "pipe", command: "get pods | grep web", wantErr: true},
+		{name: "substitution", command: "get pods -n $(whoami)", wantErr: true},
+		{name: "separator", command: "get pods; rm -rf /", wantErr: true},
+		{name: "unterminated quote", command: "get pods -l 'app=web", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := splitKubectlCommand(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(words, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, words)
+			}
+		})
+	}
+}
+
+// scaleDiscoveryClient serves the resources of a group version from the resources of the fake client.
+type scaleDiscoveryClient struct {
+	*cmdtesting.FakeCachedDiscoveryClient
+}
+
+func (d *scaleDiscoveryClient) ServerResourcesForGroupVersion(groupVersion string) (*v1.APIResourceList, error) {
+	for _, resources := range d.Resources {
+		if resources.GroupVersion == groupVersion {
+			return resources, nil
+		}
+	}
+	return nil, fmt.Errorf("group version %s not found", groupVersion)
+}
+
+func TestTranslateKubectlCommand(t *testing.T) {
+	resources := []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{
+				{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true},
+				{Name: "services", SingularName: "service", Kind: "Service", Namespaced: true},
+				{Name: "nodes", SingularName: "node", Kind: "Node"},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []v1.APIResource{
+				{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true},
+				{Name: "deployments/scale", Kind: "Scale", Namespaced: true},
+			},
+		},
+	}
+	dc := &scaleDiscoveryClient{FakeCachedDiscoveryClient: cmdtesting.NewFakeCachedDiscoveryClient()}
+	dc.PreferredResources = resources
+	dc.Resources = resources
+
+	tests := []struct {
+		name     string
+		command  string
+		expected KubectlPlan
+		wantErr  bool
+	}{
+		{
+			name:     "list in the default namespace",
+			command:  "kubectl get po",
+			expected: KubectlPlan{Tool: "resource_list", Arguments: map[string]any{"resource": "pods", "namespace": "default", "outputMode": "summary"}},
+		},
+		{
+			name:    "list in all namespaces with selector",
+			command: "kubectl get deploy -A -l app=web -o yaml",
+			expected: KubectlPlan{Tool: "resource_list", Arguments: map[string]any{
+				"resource": "deployments.v1.apps", "labelSelector": "app=web", "outputMode": "full",
+			}},
+		},
+		{
+			name:     "cluster-scoped list",
+			command:  "get nodes -n shop",
+			expected: KubectlPlan{Tool: "resource_list", Arguments: map[string]any{"resource": "nodes", "outputMode": "summary"}},
+		},
+		{
+			name:     "get by type/name",
+			command:  "get svc/web --namespace=shop",
+			expected: KubectlPlan{Tool: "resource_get", Arguments: map[string]any{"resource": "services", "name": "web", "namespace": "shop"}},
+		},
+		{
+			name:     "describe",
+			command:  "describe deployment web -n shop",
+			expected: KubectlPlan{Tool: "resource_get", Arguments: map[string]any{"resource": "deployments.v1.apps", "name": "web", "namespace": "shop"}},
+		},
+		{
+			name:    "scale",
+			command: "kubectl scale deploy/web --replicas 3 -n shop",
+			expected: KubectlPlan{Tool: "resource_apply", Arguments: map[string]any{
+				"resourceYAML": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: shop\nspec:\n  replicas: 3\n",
+			}},
+		},
+		{name: "scale without replicas", command: "scale deploy/web", wantErr: true},
+		{name: "scale of a resource without scale", command: "scale svc/web --replicas 2", wantErr: true},
+		{name: "describe without name", command: "describe pods", wantErr: true},
+		{name: "name with selector", command: "get pods web -l app=web", wantErr: true},
+		{name: "several resource types", command: "get pods,services", wantErr: true},
+		{name: "unsupported output", command: "get pods -o jsonpath={.items}", wantErr: true},
+		{name: "unknown resource", command: "get widgets", wantErr: true},
+		{name: "logs", command: "logs web", wantErr: true},
+		{name: "delete", command: "delete pod web", wantErr: true},
+		{name: "unsupported flag", command: "get pods --watch", wantErr: true},
+	}
+
+	s := NewServer("8080", "k-mcp")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := func() (KubectlPlan, error) {
+				cmd, err := parseKubectlCommand(tt.command)
+				if err != nil {
+					return KubectlPlan{}, err
+				}
+				return s.translateKubectlCommand(context.TODO(), &mcp.CallToolRequest{}, cmd, dc)
+			}()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(plan, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, plan)
+			}
+		})
+	}
+}
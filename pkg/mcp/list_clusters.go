@@ -0,0 +1,132 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/utils/ptr"
+)
+
+type ListClustersInput struct{}
+
+type ListClustersResult struct {
+	Clusters []ClusterStatus `json:"clusters"`
+}
+
+// ClusterStatus is a cluster available to the session, with the last probe and the version of its API server.
+type ClusterStatus struct {
+	// Name is the name of the cluster in the registry, or the host of its API server.
+	Name         string `json:"name"`
+	APIServerURL string `json:"apiServerUrl"`
+	// Default is set on the cluster of the calls without a cluster input.
+	Default       bool          `json:"default"`
+	ServerVersion string        `json:"serverVersion,omitempty"`
+	Reachability  *Reachability `json:"reachability,omitempty"`
+	// Error tells why the version of the cluster could not be read.
+	Error string `json:"error,omitempty"`
+}
+
+func (s *Server) addListClustersTool(server *mcp.Server, dynamicConfig *DynamicConfig, prober *reachabilityProber, clusters *clusterAccess) {
+	addTool(server, s.clusterlessTool(&mcp.Tool{
+		Name: "list_clusters",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "List the clusters available to the session",
+		},
+		Description: "List the clusters the token of the session is granted, by the name of the cluster or the URL of its API server, with whether the API server can be reached and its Kubernetes version. " +
+			"Use it to ask the user which cluster to operate on, then pass its name in the cluster input of the other tools",
+	}), func(ctx context.Context, request *mcp.CallToolRequest, input ListClustersInput) (*mcp.CallToolResult, *ListClustersResult, error) {
+		tokenInfo := request.Extra.TokenInfo
+		apiServerURLs := tokenClusters(tokenInfo)
+		// The default cluster of the session, set with set_context or chosen when asked, is the one of
+		// the calls without cluster as long as the token grants it.
+		defaultURL := clusters.sessions.cluster(request.Session)
+		if !slices.Contains(apiServerURLs, defaultURL) {
+			defaultURL, _ = tokenInfo.Extra["audience"].(string)
+		}
+
+		result := &ListClustersResult{Clusters: make([]ClusterStatus, len(apiServerURLs))}
+		var wg sync.WaitGroup
+		for i, apiServerURL := range apiServerURLs {
+			result.Clusters[i] = ClusterStatus{
+				Name:         clusters.displayName(apiServerURL),
+				APIServerURL: apiServerURL,
+				Default:      apiServerURL == defaultURL,
+			}
+			wg.Add(1)
+			go func(status *ClusterStatus) {
+				defer wg.Done()
+				status.ServerVersion, status.Reachability, status.Error = clusterVersion(ctx, dynamicConfig, prober, clusters, tokenInfo, status.APIServerURL)
+			}(&result.Clusters[i])
+		}
+		wg.Wait()
+
+		table := newTextTable("NAME", "API SERVER", "DEFAULT", "REACHABLE", "VERSION")
+		for _, cluster := range result.Clusters {
+			reachable := ""
+			if cluster.Reachability != nil {
+				reachable = strconv.FormatBool(cluster.Reachability.Reachable)
+			}
+			version := cluster.ServerVersion
+			if cluster.Error != "" {
+				version = cluster.Error
+			}
+			table.addRow(cluster.Name, cluster.APIServerURL, strconv.FormatBool(cluster.Default), reachable, version)
+		}
+		message := fmt.Sprintf("Found %d clusters available to the session. Pass the name of one of them in the cluster input of the tools to operate on it", len(result.Clusters))
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: textSummary(message, table.String()),
+				},
+			},
+		}, result, nil
+	})
+}
+
+// clusterVersion probes the API server of a cluster and returns its probe and its version, or why the
+// version could not be read.
+func clusterVersion(ctx context.Context, dynamicConfig *DynamicConfig, prober *reachabilityProber, clusters *clusterAccess, tokenInfo *auth.TokenInfo, apiServerURL string) (string, *Reachability, string) {
+	reachability := prober.check(ctx, apiServerURL)
+	if !reachability.Reachable {
+		return "", reachability, reachabilityError(reachability)
+	}
+	clusterInfo, err := clusters.tokenInfoFor(ctx, tokenInfo, apiServerURL)
+	if err != nil {
+		return "", reachability, fmt.Sprintf("failed to authenticate: %v", err)
+	}
+	_, discoveryClient, err := dynamicConfig.LoadRestConfigForTokenInfo(clusterInfo)
+	if err != nil {
+		return "", reachability, fmt.Sprintf("failed to load discovery client: %v", err)
+	}
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return "", reachability, fmt.Sprintf("failed to get server version: %v", err)
+	}
+	return serverVersion.GitVersion, reachability, ""
+}
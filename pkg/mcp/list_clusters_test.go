@@ -0,0 +1,155 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestClusterVersion(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer inbound" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major": "1", "minor": "31", "gitVersion": "v1.31.2"}`))
+	}))
+	defer server.Close()
+
+	dynamicConfig := NewDynamicConfig("", true, "")
+	dynamicConfig.DiscoveryCacheDir = t.TempDir()
+	prober := newReachabilityProber(dynamicConfig, nil)
+	prober.probe = func(_ context.Context, apiServerURL string) error {
+		if apiServerURL == "https://down.example.com" {
+			return errors.New("no such host")
+		}
+		return nil
+	}
+	clusters := &clusterAccess{clusters: []Cluster{{Name: "prod-eu", Server: server.URL, Auth: ClusterAuthToken}}}
+	tokenInfo := &auth.TokenInfo{
+		Expiration: time.Now().Add(time.Hour),
+		Extra: map[string]any{
+			"audience":     "https://down.example.com",
+			"bearer_token": "inbound",
+			"subject":      "alice",
+			"clusters":     []string{"https://down.example.com", server.URL},
+			"token":        "inbound",
+		},
+	}
+
+	tests := []struct {
+		name          string
+		apiServerURL  string
+		expected      string
+		reachable     bool
+		expectedError string
+	}{
+		{name: "reachable cluster", apiServerURL: server.URL, expected: "v1.31.2", reachable: true},
+		{name: "unreachable cluster", apiServerURL: "https://down.example.com", expectedError: "no such host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverVersion, reachability, errMessage := clusterVersion(context.Background(), dynamicConfig, prober, clusters, tokenInfo, tt.apiServerURL)
+			if serverVersion != tt.expected {
+				t.Errorf("expected version %q, got %q (%s)", tt.expected, serverVersion, errMessage)
+			}
+			if reachability == nil || reachability.Reachable != tt.reachable {
+				t.Errorf("expected reachable %v, got %+v", tt.reachable, reachability)
+			}
+			if tt.expectedError != "" && !strings.Contains(errMessage, tt.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tt.expectedError, errMessage)
+			}
+		})
+	}
+}
+
+func TestListClustersDefault(t *testing.T) {
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	prober := newReachabilityProber(nil, nil)
+	prober.probe = func(context.Context, string) error {
+		return errors.New("no such host")
+	}
+	clusters := &clusterAccess{
+		clusters: []Cluster{{Name: "prod-eu", Server: "https://prod-eu.example.com"}},
+		sessions: newSessionContexts(),
+	}
+	s := NewServer("8080", "k-mcp")
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	s.addListClustersTool(server, nil, prober, clusters)
+	tokenInfo := &auth.TokenInfo{
+		Expiration: time.Now().Add(time.Hour),
+		Extra: map[string]any{
+			"audience": "https://staging.example.com",
+			"clusters": []string{"https://staging.example.com", "https://prod-eu.example.com"},
+		},
+	}
+	transport := &tokenInfoTransport{Transport: serverTransport, tokenInfo: func() *auth.TokenInfo { return tokenInfo }}
+	serverSession, err := server.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	tests := []struct {
+		name           string
+		sessionCluster string
+		expected       string
+	}{
+		{name: "cluster of the token", expected: "staging.example.com"},
+		{name: "cluster of the session", sessionCluster: "https://prod-eu.example.com", expected: "prod-eu"},
+		{name: "cluster of the session not granted", sessionCluster: "https://dev.example.com", expected: "staging.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters.sessions.setCluster(serverSession, tt.sessionCluster, "")
+			result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "list_clusters", Arguments: map[string]any{}})
+			if err != nil || result.IsError {
+				t.Fatalf("unexpected error: %v %v", err, result)
+			}
+			var defaults []string
+			for _, cluster := range result.StructuredContent.(map[string]any)["clusters"].([]any) {
+				if cluster := cluster.(map[string]any); cluster["default"] == true {
+					defaults = append(defaults, cluster["name"].(string))
+				}
+			}
+			if len(defaults) != 1 || defaults[0] != tt.expected {
+				t.Errorf("expected the default cluster %s, got %v", tt.expected, defaults)
+			}
+		})
+	}
+}
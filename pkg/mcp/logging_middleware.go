@@ -20,6 +20,8 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // responseWriter wraps http.ResponseWriter to capture the status code.
@@ -33,14 +35,31 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush sends the buffered events of the streamed responses.
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 func loggingHandler(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
+		// The request ID is passed to the MCP methods in the headers of the request.
+		requestID := r.Header.Get(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+			r.Header.Set(requestIDHeader, requestID)
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		ctx := withRequestID(r.Context(), requestID)
+		r = r.WithContext(ctx)
+
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
 		// Log request details.
-		slog.Debug("[REQUEST]",
+		slog.DebugContext(ctx, "[REQUEST]",
 			"timestamp", start.Format(time.RFC3339),
 			"remote_addr", r.RemoteAddr,
 			"method", r.Method,
@@ -49,7 +68,7 @@ func loggingHandler(handler http.Handler) http.Handler {
 		handler.ServeHTTP(wrapped, r)
 
 		duration := time.Since(start)
-		slog.Debug("[RESPONSE]",
+		slog.DebugContext(ctx, "[RESPONSE]",
 			"timestamp", time.Now().Format(time.RFC3339),
 			"remote_addr", r.RemoteAddr,
 			"method", r.Method,
@@ -0,0 +1,292 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/openapi"
+	"k8s.io/utils/ptr"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+type ManifestCompleteInput struct {
+	ResourceYAML string `json:"resourceYAML,required" jsonschema:"The partial Kubernetes resource(s) in YAML format. Can contain single or multiple resources separated by ---"`
+}
+
+type ManifestCompleteResult struct {
+	// Manifest is the completed manifest, in the same order as the input.
+	Manifest string           `json:"manifest"`
+	Objects  []ManifestReport `json:"objects"`
+}
+
+// ManifestReport lists the fields of a resource of the manifest that were completed or need attention.
+type ManifestReport struct {
+	Kind string `json:"kind"`
+	Name string `json:"name,omitempty"`
+	// Defaulted are the required fields that were set to their default.
+	Defaulted []string `json:"defaulted,omitempty"`
+	// Missing are the required fields without default that still need a value.
+	Missing []string `json:"missing,omitempty"`
+	// Unknown are the fields that are not part of the schema, which the API server drops or rejects.
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+func (s *Server) addManifestCompleteTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "manifest_complete",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Complete a Kubernetes manifest from the cluster OpenAPI schema",
+		},
+		Description: "Check a partial manifest against the OpenAPI schema of the cluster before applying it. Required fields having a default are filled, required fields without default and unknown fields are reported, and the normalized manifest is returned",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ManifestCompleteInput) (*mcp.CallToolResult, *ManifestCompleteResult, error) {
+		objects, err := decodeManifests(input.ResourceYAML)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		_, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		reports, err := completeManifests(objects, discoveryClient.OpenAPIV3())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		docs := make([]string, 0, len(objects))
+		for _, obj := range objects {
+			data, err := sigsyaml.Marshal(obj.Object)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to encode %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			docs = append(docs, string(data))
+		}
+
+		table := newTextTable("KIND", "NAME", "DEFAULTED", "MISSING", "UNKNOWN")
+		for _, report := range reports {
+			table.addRow(report.Kind, report.Name, strings.Join(report.Defaulted, ","), strings.Join(report.Missing, ","), strings.Join(report.Unknown, ","))
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: textSummary(fmt.Sprintf("Checked %d resource(s) against the cluster OpenAPI schema:", len(reports)), table.String()),
+				},
+			},
+		}, &ManifestCompleteResult{Manifest: strings.Join(docs, "---\n"), Objects: reports}, nil
+	})
+}
+
+// openAPISchema is the subset of an OpenAPI v3 schema needed to complete manifests.
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	AllOf                []*openAPISchema          `json:"allOf,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties json.RawMessage           `json:"additionalProperties,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Default              json.RawMessage           `json:"default,omitempty"`
+	PreserveUnknown      bool                      `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	GroupVersionKinds    []schema.GroupVersionKind `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+// openAPIDocument is an OpenAPI v3 document of a group version.
+type openAPIDocument struct {
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// completeManifests fills the missing required fields having a default of the resources, and
+// reports the missing required fields without default and the unknown fields.
+func completeManifests(objects []*unstructured.Unstructured, client openapi.Client) ([]ManifestReport, error) {
+	paths, err := client.Paths()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the OpenAPI schema paths: %w", err)
+	}
+
+	documents := map[string]*openAPIDocument{}
+	reports := make([]ManifestReport, 0, len(objects))
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		if gvk.Kind == "" || gvk.Version == "" {
+			return nil, fmt.Errorf("apiVersion and kind are required")
+		}
+
+		path := "apis/" + gvk.GroupVersion().String()
+		if gvk.Group == "" {
+			path = "api/" + gvk.Version
+		}
+		document, ok := documents[path]
+		if !ok {
+			groupVersion, ok := paths[path]
+			if !ok {
+				return nil, fmt.Errorf("no OpenAPI schema found for %s, the API version is not served by the cluster", gvk.GroupVersion())
+			}
+			data, err := groupVersion.Schema(runtime.ContentTypeJSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the OpenAPI schema of %s: %w", gvk.GroupVersion(), err)
+			}
+			document = &openAPIDocument{}
+			if err := json.Unmarshal(data, document); err != nil {
+				return nil, fmt.Errorf("failed to decode the OpenAPI schema of %s: %w", gvk.GroupVersion(), err)
+			}
+			documents[path] = document
+		}
+
+		root := document.kindSchema(gvk)
+		if root == nil {
+			return nil, fmt.Errorf("no OpenAPI schema found for kind %s in %s", gvk.Kind, gvk.GroupVersion())
+		}
+		report := ManifestReport{Kind: gvk.Kind, Name: obj.GetName()}
+		document.complete(obj.Object, root, "", &report)
+		sort.Strings(report.Defaulted)
+		sort.Strings(report.Missing)
+		sort.Strings(report.Unknown)
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// kindSchema returns the schema of a kind, nil if the document does not define it.
+func (d *openAPIDocument) kindSchema(gvk schema.GroupVersionKind) *openAPISchema {
+	for _, s := range d.Components.Schemas {
+		for _, schemaGVK := range s.GroupVersionKinds {
+			if schemaGVK == gvk {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+// resolve follows the references of a schema, which are either direct or wrapped in allOf to
+// carry a default or a description.
+func (d *openAPIDocument) resolve(s *openAPISchema) *openAPISchema {
+	for i := 0; s != nil && i < 32; i++ {
+		ref := s.Ref
+		if ref == "" && len(s.AllOf) == 1 {
+			ref = s.AllOf[0].Ref
+		}
+		if ref == "" {
+			return s
+		}
+		s = d.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// complete walks an object along its schema, path being the dot separated path of the object.
+func (d *openAPIDocument) complete(obj map[string]interface{}, s *openAPISchema, path string, report *ManifestReport) {
+	s = d.resolve(s)
+	if s == nil {
+		return
+	}
+
+	var additional *openAPISchema
+	freeForm := s.PreserveUnknown || len(s.Properties) == 0
+	if len(s.AdditionalProperties) > 0 {
+		if err := json.Unmarshal(s.AdditionalProperties, &additional); err != nil {
+			// additionalProperties is a boolean.
+			var allowed bool
+			_ = json.Unmarshal(s.AdditionalProperties, &allowed)
+			freeForm = freeForm || allowed
+			additional = nil
+		}
+	}
+
+	for key, value := range obj {
+		property, ok := s.Properties[key]
+		if !ok {
+			property = additional
+		}
+		if property == nil {
+			if !freeForm {
+				report.Unknown = append(report.Unknown, joinFieldPath(path, key))
+			}
+			continue
+		}
+		d.completeValue(value, property, joinFieldPath(path, key), report)
+	}
+
+	for _, key := range s.Required {
+		if _, ok := obj[key]; ok {
+			continue
+		}
+		property := s.Properties[key]
+		defaultValue := schemaDefault(property)
+		if defaultValue == nil {
+			defaultValue = schemaDefault(d.resolve(property))
+		}
+		if defaultValue == nil {
+			report.Missing = append(report.Missing, joinFieldPath(path, key))
+			continue
+		}
+		obj[key] = defaultValue
+		report.Defaulted = append(report.Defaulted, joinFieldPath(path, key))
+	}
+}
+
+// completeValue walks the objects nested in a value.
+func (d *openAPIDocument) completeValue(value interface{}, s *openAPISchema, path string, report *ManifestReport) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		d.complete(value, s, path, report)
+	case []interface{}:
+		s = d.resolve(s)
+		if s == nil || s.Items == nil {
+			return
+		}
+		for i, item := range value {
+			d.completeValue(item, s.Items, fmt.Sprintf("%s[%d]", path, i), report)
+		}
+	}
+}
+
+// schemaDefault returns a new copy of the default of a schema, nil if it has none.
+func schemaDefault(s *openAPISchema) interface{} {
+	if s == nil || len(s.Default) == 0 {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(s.Default, &value); err != nil {
+		return nil
+	}
+	return value
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
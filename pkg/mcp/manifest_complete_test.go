@@ -0,0 +1,207 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/openapi"
+	"k8s.io/client-go/openapi/openapitest"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const appsV1OpenAPI = `{
+  "components": {
+    "schemas": {
+      "io.k8s.api.apps.v1.Deployment": {
+        "type": "object",
+        "properties": {
+          "apiVersion": {"type": "string"},
+          "kind": {"type": "string"},
+          "metadata": {"allOf": [{"$ref": "#/components/schemas/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"}], "default": {}},
+          "spec": {"allOf": [{"$ref": "#/components/schemas/io.k8s.api.apps.v1.DeploymentSpec"}], "default": {}}
+        },
+        "x-kubernetes-group-version-kind": [{"group": "apps", "kind": "Deployment", "version": "v1"}]
+      },
+      "io.k8s.api.apps.v1.DeploymentSpec": {
+        "type": "object",
+        "required": ["selector", "template"],
+        "properties": {
+          "replicas": {"type": "integer"},
+          "selector": {"$ref": "#/components/schemas/io.k8s.apimachinery.pkg.apis.meta.v1.LabelSelector"},
+          "template": {"allOf": [{"$ref": "#/components/schemas/io.k8s.api.core.v1.PodTemplateSpec"}], "default": {}}
+        }
+      },
+      "io.k8s.api.core.v1.PodTemplateSpec": {
+        "type": "object",
+        "properties": {
+          "metadata": {"allOf": [{"$ref": "#/components/schemas/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"}], "default": {}},
+          "spec": {"$ref": "#/components/schemas/io.k8s.api.core.v1.PodSpec"}
+        }
+      },
+      "io.k8s.api.core.v1.PodSpec": {
+        "type": "object",
+        "required": ["containers"],
+        "properties": {
+          "containers": {"type": "array", "items": {"allOf": [{"$ref": "#/components/schemas/io.k8s.api.core.v1.Container"}], "default": {}}}
+        }
+      },
+      "io.k8s.api.core.v1.Container": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"},
+          "image": {"type": "string"},
+          "ports": {"type": "array", "items": {"allOf": [{"$ref": "#/components/schemas/io.k8s.api.core.v1.ContainerPort"}], "default": {}}}
+        }
+      },
+      "io.k8s.api.core.v1.ContainerPort": {
+        "type": "object",
+        "required": ["containerPort", "protocol"],
+        "properties": {
+          "containerPort": {"type": "integer"},
+          "protocol": {"type": "string", "default": "TCP"}
+        }
+      },
+      "io.k8s.apimachinery.pkg.apis.meta.v1.LabelSelector": {
+        "type": "object",
+        "properties": {
+          "matchLabels": {"type": "object", "additionalProperties": {"type": "string", "default": ""}}
+        }
+      },
+      "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "labels": {"type": "object", "additionalProperties": {"type": "string", "default": ""}},
+          "annotations": {"type": "object", "additionalProperties": {"type": "string", "default": ""}}
+        }
+      }
+    }
+  }
+}`
+
+func TestCompleteManifests(t *testing.T) {
+	client := &openapitest.FakeClient{PathsMap: map[string]openapi.GroupVersion{
+		"apis/apps/v1": openapitest.FakeGroupVersion{GVSpec: []byte(appsV1OpenAPI)},
+	}}
+
+	tests := []struct {
+		name          string
+		manifest      string
+		expected      []ManifestReport
+		expectedError string
+		expectedYAML  string
+	}{
+		{
+			name: "defaulted, missing and unknown fields",
+			manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replics: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        imagePullPolcy: Always
+        ports:
+        - containerPort: 80
+`,
+			expected: []ManifestReport{{
+				Kind:      "Deployment",
+				Name:      "web",
+				Defaulted: []string{"spec.template.spec.containers[0].ports[0].protocol"},
+				Missing:   []string{"spec.selector"},
+				Unknown:   []string{"spec.replics", "spec.template.spec.containers[0].imagePullPolcy"},
+			}},
+			expectedYAML: "protocol: TCP",
+		},
+		{
+			name: "complete manifest",
+			manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    spec:
+      containers:
+      - name: web
+`,
+			expected: []ManifestReport{{Kind: "Deployment", Name: "web"}},
+		},
+		{
+			name: "API version not served",
+			manifest: `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+`,
+			expectedError: "no OpenAPI schema found for batch/v1",
+		},
+		{
+			name: "kind not served",
+			manifest: `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+`,
+			expectedError: "no OpenAPI schema found for kind StatefulSet in apps/v1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := decodeManifests(tt.manifest)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			reports, err := completeManifests(objects, client)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(reports, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, reports)
+			}
+			if tt.expectedYAML != "" {
+				data, err := sigsyaml.Marshal(objects[0].Object)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !strings.Contains(string(data), tt.expectedYAML) {
+					t.Errorf("expected the manifest to contain %q, got:\n%s", tt.expectedYAML, data)
+				}
+			}
+		})
+	}
+}
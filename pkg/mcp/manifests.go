@@ -0,0 +1,202 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// yamlErrorLine matches the line of the YAML syntax errors, relative to their document.
+var yamlErrorLine = regexp.MustCompile(`yaml: line (\d+): `)
+
+// manifestDocument is a YAML document of a manifest, with the line it starts at.
+type manifestDocument struct {
+	content string
+	line    int
+}
+
+// decodeManifests decodes the resources of a manifest: YAML documents separated by --- lines, or
+// JSON objects and arrays of objects. The Lists are expanded into their items. Every document that
+// can not be decoded is reported with the line it fails at.
+func decodeManifests(manifest string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	var err error
+	if trimmed := strings.TrimSpace(manifest); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		objects, err = decodeJSONManifest(manifest)
+	} else {
+		objects, err = decodeYAMLManifest(manifest)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no valid resources found in the provided manifest")
+	}
+	return objects, nil
+}
+
+// splitYAMLDocuments splits the manifest into its YAML documents. As with the YAML reader of
+// kubectl, a separator is a line starting with --- followed by nothing but spaces or a comment, so
+// that --- in the values of the documents does not split them.
+func splitYAMLDocuments(manifest string) []manifestDocument {
+	var documents []manifestDocument
+	current := manifestDocument{line: 1}
+	var content strings.Builder
+	for i, line := range strings.SplitAfter(manifest, "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "---"); ok {
+			if rest = strings.TrimSpace(rest); rest == "" || strings.HasPrefix(rest, "#") {
+				current.content = content.String()
+				documents = append(documents, current)
+				current, content = manifestDocument{line: i + 2}, strings.Builder{}
+				continue
+			}
+		}
+		content.WriteString(line)
+	}
+	current.content = content.String()
+	return append(documents, current)
+}
+
+func decodeYAMLManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	var errs []error
+	for index, document := range splitYAMLDocuments(manifest) {
+		if strings.TrimSpace(document.content) == "" {
+			continue
+		}
+
+		var obj unstructured.Unstructured
+		decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(document.content), 4096)
+		if err := decoder.Decode(&obj); err != nil {
+			line, message := document.line, err.Error()
+			if match := yamlErrorLine.FindStringSubmatchIndex(message); match != nil {
+				relative, _ := strconv.Atoi(message[match[2]:match[3]])
+				line, message = document.line+relative-1, message[match[1]:]
+			}
+			errs = append(errs, fmt.Errorf("failed to decode YAML document %d at line %d: %s", index+1, line, message))
+			continue
+		}
+		objects = appendObjects(objects, &obj)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return objects, nil
+}
+
+func decodeJSONManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	var errs []error
+	decoder := json.NewDecoder(strings.NewReader(manifest))
+	// decode decodes the next JSON object of the manifest, returning false when the manifest can
+	// not be read any further.
+	decode := func() bool {
+		start := nextValueOffset(manifest, decoder.InputOffset())
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			offset := decoder.InputOffset()
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				offset = syntaxErr.Offset
+			}
+			errs = append(errs, fmt.Errorf("failed to decode JSON at line %d: %w", lineAt(manifest, offset), err))
+			return false
+		}
+		var obj unstructured.Unstructured
+		if err := obj.UnmarshalJSON(raw); err != nil {
+			errs = append(errs, fmt.Errorf("failed to decode JSON object at line %d: %w", lineAt(manifest, start), err))
+			return true
+		}
+		objects = appendObjects(objects, &obj)
+		return true
+	}
+
+	for {
+		start := nextValueOffset(manifest, decoder.InputOffset())
+		if start >= int64(len(manifest)) {
+			break
+		}
+		if manifest[start] != '[' {
+			if !decode() {
+				break
+			}
+			continue
+		}
+
+		//nolint:errcheck
+		decoder.Token()
+		for decoder.More() {
+			if !decode() {
+				return nil, errors.Join(errs...)
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			errs = append(errs, fmt.Errorf("failed to decode JSON at line %d: %w", lineAt(manifest, decoder.InputOffset()), err))
+			break
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return objects, nil
+}
+
+// appendObjects appends the object to the objects, or its items when it is a List.
+func appendObjects(objects []*unstructured.Unstructured, obj *unstructured.Unstructured) []*unstructured.Unstructured {
+	if obj.Object == nil {
+		return objects
+	}
+	if !strings.HasSuffix(obj.GetKind(), "List") || !obj.IsList() {
+		return append(objects, obj)
+	}
+	//nolint:errcheck
+	obj.EachListItem(func(item runtime.Object) error {
+		if itemObj, ok := item.(*unstructured.Unstructured); ok {
+			objects = append(objects, itemObj)
+		}
+		return nil
+	})
+	return objects
+}
+
+// nextValueOffset returns the offset of the next JSON value of the manifest from the offset,
+// skipping the spaces and the separators of the array values.
+func nextValueOffset(manifest string, offset int64) int64 {
+	for offset < int64(len(manifest)) && strings.ContainsRune(" \t\r\n,", rune(manifest[offset])) {
+		offset++
+	}
+	return offset
+}
+
+// lineAt returns the line of the offset in the manifest.
+func lineAt(manifest string, offset int64) int {
+	return strings.Count(manifest[:min(offset, int64(len(manifest)))], "\n") + 1
+}
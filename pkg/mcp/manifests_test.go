@@ -0,0 +1,158 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeManifests(t *testing.T) {
+	tests := []struct {
+		name           string
+		manifest       string
+		expected       []string
+		expectedErrors []string
+	}{
+		{
+			name:     "YAML and JSON documents",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\n{\"apiVersion\": \"v1\", \"kind\": \"Secret\", \"metadata\": {\"name\": \"b\"}}",
+			expected: []string{"ConfigMap/a", "Secret/b"},
+		},
+		{
+			name: "separator in a value",
+			manifest: `--- # the config
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data:
+  inline: "a---b"
+  block: |
+    ---
+    not a document
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`,
+			expected: []string{"ConfigMap/a", "ConfigMap/b"},
+		},
+		{
+			name:     "JSON object",
+			manifest: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}, "data": {"separator": "---"}}`,
+			expected: []string{"ConfigMap/a"},
+		},
+		{
+			name: "JSON array",
+			manifest: `[
+  {"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "shop"}},
+  {"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "namespace": "shop"}}
+]`,
+			expected: []string{"Namespace/shop", "Deployment/web"},
+		},
+		{
+			name: "JSON list",
+			manifest: `{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}},
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "b"}}
+]}`,
+			expected: []string{"ConfigMap/a", "ConfigMap/b"},
+		},
+		{
+			name: "YAML list",
+			manifest: `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+`,
+			expected: []string{"ConfigMap/a"},
+		},
+		{
+			name:           "no resources",
+			manifest:       "---\n\n---",
+			expectedErrors: []string{"no valid resources found"},
+		},
+		{
+			name: "YAML errors of every document",
+			manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+ labels: {}
+---
+apiVersion: v1
+metadata:
+  name: c
+`,
+			expectedErrors: []string{"YAML document 2 at line 9: did not find expected key", "YAML document 3 at line 12", "Object 'Kind' is missing"},
+		},
+		{
+			name: "JSON syntax error",
+			manifest: `[
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}},
+  {"apiVersion": "v1", "kind": "ConfigMap" "metadata": {"name": "b"}}
+]`,
+			expectedErrors: []string{"JSON at line 3"},
+		},
+		{
+			name: "JSON object error",
+			manifest: `[
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}},
+  {"apiVersion": "v1", "metadata": {"name": "b"}}
+]`,
+			expectedErrors: []string{"JSON object at line 3: Object 'Kind' is missing"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := decodeManifests(tt.manifest)
+			if len(tt.expectedErrors) > 0 {
+				if err == nil {
+					t.Fatalf("expected errors %v, got none", tt.expectedErrors)
+				}
+				for _, expected := range tt.expectedErrors {
+					if !strings.Contains(err.Error(), expected) {
+						t.Errorf("expected error containing %q, got %v", expected, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, obj := range objects {
+				names = append(names, obj.GetKind()+"/"+obj.GetName())
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
@@ -25,18 +25,20 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/auth"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/utils/ptr"
 
@@ -46,12 +48,125 @@ import (
 type Server struct {
 	Port     string
 	Audience string
+	// Transport is the transport serving the MCP server, TransportHTTP when empty. TransportSSE
+	// serves the SSE transport in addition to the streamable HTTP one.
+	Transport string
+	// HTTPLimits bound the requests and the connections of the HTTP server.
+	HTTPLimits HTTPLimits
+
+	// SummaryColumns are the additional columns shown per kind
+	// when resources are listed in summary output mode.
+	SummaryColumns SummaryColumns
+	// ElicitationTimeout is the maximum time to wait for the user to answer
+	// an elicitation. Zero means no timeout.
+	ElicitationTimeout time.Duration
+	// ToolTimeout is the maximum duration of a tool call, including the elicitations
+	// and waits. Zero means no timeout.
+	ToolTimeout time.Duration
+	// ProbeAPIServers are the API servers probed for reachability at startup,
+	// k-mcp is not ready while one of them can not be reached.
+	ProbeAPIServers []string
+	// ProbeInterval is the interval of the reachability probes after startup.
+	// Zero means the API servers are only probed at startup and when first seen.
+	ProbeInterval time.Duration
+	// Toolsets are the names of the toolsets whose tools are registered. Empty means every toolset.
+	Toolsets []string
+	// ReadOnly disables the tools changing the state of the clusters.
+	ReadOnly bool
+	// Headless never sends elicitations: questions are answered with their defaults
+	// and operations are run without confirmation.
+	Headless bool
+	// SavedQueries are the operator configured queries available to every session.
+	SavedQueries []SavedQuery
+	// ConformanceProfiles are the operator configured profiles of conformance_check.
+	ConformanceProfiles []ConformanceProfile
+	// CRDTools are the CustomResourceDefinitions with dedicated list and get tools.
+	CRDTools []CRDTool
+	// JWKSURL is the URL of the JSON Web Key Set verifying the signatures of the tokens.
+	// Empty means the signatures are not verified.
+	JWKSURL string
+	// Issuers are the trusted issuers of the tokens. Empty means any issuer. Without JWKSURL, the
+	// signatures are verified with the keys of the issuers, resolved with OpenID Connect discovery.
+	Issuers []string
+	// SigningAlgorithms are the accepted signing algorithms of the tokens, whether their signatures
+	// are verified or not. Empty means the asymmetric algorithms.
+	SigningAlgorithms []string
+	// ImpactThreshold is the impact score from which applies must be confirmed by typing a phrase.
+	// Zero disables the typed confirmations.
+	ImpactThreshold int
+	// ProductionNamespaces are the patterns of the production namespaces, which raise the impact of applies.
+	ProductionNamespaces []string
+	// PreferencesFile is the file keeping the preferences of the users across restarts.
+	// Empty means the preferences are kept in memory only.
+	PreferencesFile string
+	// MaxResultSize is the maximum size in bytes of the resources returned by resource_list, beyond
+	// which they are truncated. Zero means no limit.
+	MaxResultSize int
+	// MemoryLimit is the memory limit in bytes from which large list and get calls are refused.
+	// Zero disables the load shedding.
+	MemoryLimit uint64
+	// TokenReview validates the tokens with the TokenReview API of the API server of their audience,
+	// in addition to the local checks.
+	TokenReview bool
+	// AuditNamespace is the namespace recording the calls of the mutating tools in the clusters
+	// they change, as Events and ConfigMaps. Empty means the calls are not recorded.
+	AuditNamespace string
+	// AuditLogFile is the file the calls of the mutating tools are appended to as JSON lines.
+	// Empty means they are not written to a file.
+	AuditLogFile string
+	// AuditWebhookURL is the URL the calls of the mutating tools are posted to as JSON objects.
+	// Empty means they are not posted.
+	AuditWebhookURL string
+	// RedactEnvPatterns are the patterns of the names and values of the environment variables
+	// redacted from the tool results and the logs, in addition to the data of the Secrets.
+	RedactEnvPatterns []string
+	// ResourceURL is the public URL of the MCP endpoint, advertised in the OAuth protected resource
+	// metadata. Empty means it is derived from the requests.
+	ResourceURL string
+	// CredentialExchanger exchanges the inbound tokens for the credentials sent to the API servers.
+	// Nil means the inbound tokens are sent as is.
+	CredentialExchanger CredentialExchanger
+	// DefaultNamespace is the namespace of the calls without one, when neither the session nor the
+	// preferences of the user set one. Empty means the namespace is asked for.
+	DefaultNamespace string
+	// Reload returns the configuration applied on SIGHUP. Nil means SIGHUP is ignored.
+	Reload func() (*ReloadableConfig, error)
+	// WatchedFiles are the files whose changes reload the configuration, like SIGHUP.
+	WatchedFiles []string
+	// Clusters is the registry of the named clusters, which the tokens and the tool calls can target
+	// by name. The clusters of the tokens are not restricted to the registry.
+	Clusters []Cluster
+	// ClusterRouting are the routing policies of the tools, selecting the cluster of their calls
+	// without cluster argument when the token grants several clusters. The user is asked for the
+	// cluster of the tools without one.
+	ClusterRouting map[string]string
+	// Tracing configures the export of the spans of the MCP methods and of the requests sent to the
+	// API servers. Tracing is disabled when its endpoint is empty.
+	Tracing Tracing
+
+	// mu guards the reloadable configuration.
+	mu              sync.RWMutex
+	sessionContexts *sessionContexts
+	preferences     *preferenceStore
+	operations      *operations
+	redactor        *redactor
+	clusters        *clusterAccess
+	// clusterlessTools are the tools registered with clusterlessTool.
+	clusterlessTools map[string]bool
+	// toolsChanged notifies the sessions that the tools changed, nil before the tools are registered.
+	toolsChanged func()
 }
 
 func NewServer(port string, audience string) *Server {
 	return &Server{
-		Port:     port,
-		Audience: audience,
+		Port:              port,
+		Audience:          audience,
+		HTTPLimits:        DefaultHTTPLimits,
+		MaxResultSize:     DefaultMaxResultSize,
+		RedactEnvPatterns: DefaultRedactEnvPatterns,
+		sessionContexts:   newSessionContexts(),
+		operations:        newOperations(),
+		clusterlessTools:  map[string]bool{},
 	}
 }
 
@@ -65,9 +180,77 @@ type JWTClaims struct {
 func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 	mux := http.NewServeMux()
 
+	preferences, err := newPreferenceStore(s.PreferencesFile)
+	if err != nil {
+		return err
+	}
+	s.preferences = preferences
+
+	s.redactor, err = newRedactor(s.RedactEnvPatterns)
+	if err != nil {
+		return err
+	}
+
+	shutdownTracing, err := setupTracing(ctx, s.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Warn("Failed to flush the spans", "err", err)
+		}
+	}()
+
+	audit, err := s.newAuditLog(dynamicConfig)
+	if err != nil {
+		return err
+	}
+	defer audit.close()
+	s.operations.completed = audit.recordOperation
+
+	signingMethods := s.SigningAlgorithms
+	if len(signingMethods) == 0 {
+		signingMethods = jwksSigningMethods
+	}
+	var keySet *jwksKeySet
+	var oidc *oidcVerifier
+	switch {
+	case s.JWKSURL != "":
+		keySet = newJWKSKeySet(s.JWKSURL)
+	case len(s.Issuers) > 0:
+		oidc = newOIDCVerifier(s.Issuers, signingMethods)
+	case !s.TokenReview && s.Transport != TransportStdio:
+		slog.Warn("Token signatures are not verified, set --issuer, --jwks-url or --token-review to verify them")
+	}
+	var reviewer *tokenReviewer
+	if s.TokenReview {
+		reviewer = newTokenReviewer(dynamicConfig, s.Audience)
+	}
+	var credentials *credentialCache
+	if s.CredentialExchanger != nil {
+		credentials = newCredentialCache(s.CredentialExchanger)
+	}
+	clusters := &clusterAccess{clusters: s.Clusters, reviewer: reviewer, credentials: credentials, sessions: s.sessionContexts, clusterless: s.clusterlessTools, routing: s.ClusterRouting}
+	s.clusters = clusters
+
 	verifyToken := func(ctx context.Context, tokenString string, _ *http.Request) (*auth.TokenInfo, error) {
-		parser := jwt.NewParser()
-		token, _, err := parser.ParseUnverified(tokenString, &JWTClaims{})
+		var token *jwt.Token
+		var err error
+		switch {
+		case keySet != nil:
+			parser := jwt.NewParser(jwt.WithValidMethods(signingMethods), jwt.WithExpirationRequired())
+			token, err = parser.ParseWithClaims(tokenString, &JWTClaims{}, keySet.keyfunc(ctx))
+		case oidc != nil:
+			token, err = oidc.parse(ctx, tokenString, &JWTClaims{})
+		default:
+			parser := jwt.NewParser()
+			token, _, err = parser.ParseUnverified(tokenString, &JWTClaims{})
+			// The algorithm is still pinned, so that the tokens are refused the same way whether the
+			// signatures are verified here, by the API server or not at all.
+			if err == nil && !slices.Contains(signingMethods, token.Method.Alg()) {
+				err = fmt.Errorf("signing method %s is invalid", token.Method.Alg())
+			}
+		}
 		if err != nil {
 			return nil, fmt.Errorf("%w: failed to parse token: %v", auth.ErrInvalidToken, err)
 		}
@@ -89,35 +272,60 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 			return nil, fmt.Errorf("%w: token not yet valid", auth.ErrInvalidToken)
 		}
 
+		if len(s.Issuers) > 0 && !slices.Contains(s.Issuers, claims.Issuer) {
+			return nil, fmt.Errorf("%w: token issuer %q is not trusted", auth.ErrInvalidToken, claims.Issuer)
+		}
+
 		if claims.Audience == nil {
 			return nil, fmt.Errorf("%w: invalid token audience", auth.ErrInvalidToken)
 		}
 
 		found := false
-		var apiServerUrl string
+		var audiences []string
 		for _, aud := range claims.Audience {
 			if aud == s.Audience {
 				found = true
 			} else {
-				if apiServerUrl == "" {
-					apiServerUrl = aud
-				}
+				audiences = append(audiences, aud)
 			}
 		}
 		if !found {
 			return nil, fmt.Errorf("%w: token audience does not match %s", auth.ErrInvalidToken, s.Audience)
 		}
 
-		if len(apiServerUrl) == 0 {
+		// The other audiences are the clusters the token is granted, by URL or by name in the registry.
+		granted := clusters.granted(audiences)
+		// Every call targets the single managed cluster.
+		if dynamicConfig.Cluster != nil {
+			granted = []string{dynamicConfig.Cluster.Host}
+		}
+
+		if len(granted) == 0 {
 			return nil, fmt.Errorf("%w: apiserver url not found in audience %s", auth.ErrInvalidToken, s.Audience)
 		}
+		// The calls without a cluster argument target the first cluster.
+		apiServerUrl := granted[0]
+
+		subject := claims.Subject
+		username, bearerToken, err := clusters.authenticate(ctx, apiServerUrl, tokenString, claims.ExpiresAt.Time)
+		if err != nil {
+			return nil, err
+		}
+		// The API server knows the user of the token better than its claims, e.g. for prefixed external issuers.
+		if username != "" {
+			subject = username
+		}
 
 		return &auth.TokenInfo{
 			Scopes:     claims.Scopes,
 			Expiration: claims.ExpiresAt.Time,
 			Extra: map[string]any{
 				"audience":     apiServerUrl,
-				"bearer_token": tokenString,
+				"bearer_token": bearerToken,
+				"subject":      subject,
+				"cluster":      clusters.displayName(apiServerUrl),
+				"clusters":     granted,
+				"token":        tokenString,
 			},
 		}, nil
 	}
@@ -128,30 +336,30 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 			method string,
 			req mcp.Request,
 		) (mcp.Result, error) {
-			slog.Debug("MCP method started",
+			slog.DebugContext(ctx, "MCP method started",
 				"method", method,
 				"session_id", req.GetSession().ID(),
 				"has_params", req.GetParams() != nil,
 			)
 			// Log more for tool calls.
 			if ctr, ok := req.(*mcp.CallToolRequest); ok {
-				slog.Debug("Calling tool",
+				slog.DebugContext(ctx, "Calling tool",
 					"name", ctr.Params.Name,
-					"args", ctr.Params.Arguments)
+					"args", s.redactor.redactArguments(ctr.Params.Arguments))
 			}
 
 			start := time.Now()
 			result, err := next(ctx, method, req)
 			duration := time.Since(start)
 			if err != nil {
-				slog.Error("MCP method failed",
+				slog.ErrorContext(ctx, "MCP method failed",
 					"method", method,
 					"session_id", req.GetSession().ID(),
 					"duration_ms", duration.Milliseconds(),
 					"err", err,
 				)
 			} else {
-				slog.Debug("MCP method completed",
+				slog.DebugContext(ctx, "MCP method completed",
 					"method", method,
 					"session_id", req.GetSession().ID(),
 					"duration_ms", duration.Milliseconds(),
@@ -159,7 +367,7 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 				)
 				// Log more for tool results.
 				if ctr, ok := result.(*mcp.CallToolResult); ok {
-					slog.Debug("tool result",
+					slog.DebugContext(ctx, "tool result",
 						"isError", ctr.IsError,
 						"structuredContent", ctr.StructuredContent)
 				}
@@ -168,52 +376,70 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 		}
 	}
 
+	scheduler := newQueryScheduler()
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "k-mcp",
 		Version: version.Get().Version,
-	}, nil)
-	mcp.AddTool(server, &mcp.Tool{
-		Name: "resource_list",
-		Annotations: &mcp.ToolAnnotations{
-			DestructiveHint: ptr.To(false),
-			IdempotentHint:  false,
-			OpenWorldHint:   ptr.To(true),
-			ReadOnlyHint:    true,
-			Title:           "List Kubernetes resources of a specific type",
-		},
-		Description: "List Kubernetes resources of a specific type. This can be pods, deployments.v1.apps, etc. Kind.version.group or Kind format",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input ResourceListInput) (*mcp.CallToolResult, *ResourceListResult, error) {
-		apiServerUrl := request.Extra.TokenInfo.Extra["audience"].(string)
-		bearerToken := request.Extra.TokenInfo.Extra["bearer_token"].(string)
+	}, &mcp.ServerOptions{
+		SubscribeHandler:   scheduler.subscribe,
+		UnsubscribeHandler: scheduler.unsubscribe,
+		CompletionHandler:  s.completionHandler(dynamicConfig),
+	})
+	scheduler.server = server
+	scheduler.redactor = s.redactor
+	listResources := func(ctx context.Context, request *mcp.CallToolRequest, input ResourceListInput) (*mcp.CallToolResult, *ResourceListResult, error) {
+		if len(input.Fields) > 0 {
+			if input.OutputMode != "" && input.OutputMode != OutputModeFull {
+				return nil, nil, fmt.Errorf("fields can not be used with the %s output mode", input.OutputMode)
+			}
+			if _, err := parseFields(input.Fields); err != nil {
+				return nil, nil, err
+			}
+			input.OutputMode = OutputModeFull
+		}
+		if input.OutputMode == "" {
+			input.OutputMode = s.preferences.get(requestSubject(request)).OutputMode
+		}
+		if err := validateOutputMode(input.OutputMode); err != nil {
+			return nil, nil, err
+		}
+		if input.SortBy != "" {
+			if input.OutputMode == OutputModeTable {
+				return nil, nil, fmt.Errorf("sortBy can not be used with the %s output mode", OutputModeTable)
+			}
+			if _, err := parseSortBy(input.SortBy); err != nil {
+				return nil, nil, err
+			}
+		}
+		if input.Limit < 0 {
+			return nil, nil, fmt.Errorf("limit must not be negative")
+		}
 
-		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfig(bearerToken, apiServerUrl)
+		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
 		}
 
+		if members, ok, err := categoryMembers(input.Resource, discoveryClient); err != nil {
+			return nil, nil, err
+		} else if ok {
+			return s.listCategory(ctx, request, dynamicConfig, dynamicClient, members, input)
+		}
+
 		gvr, _, err := FindResource(ctx, input.Resource, discoveryClient, request.Session)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to find resource: %w", err)
 		}
-		var resources *unstructured.UnstructuredList
-		namespace := input.Namespace
-		listOptions := v1.ListOptions{}
-		if input.LabelSelector != "" {
-			listOptions.LabelSelector = input.LabelSelector
-		}
-
-		if namespace != "" {
-			resources, err = dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, listOptions)
-		} else {
-			resources, err = dynamicClient.Resource(gvr).List(ctx, listOptions)
-		}
+		objects, listMeta, err := s.listObjects(ctx, request, dynamicConfig, dynamicClient, gvr, input)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to list resources: %w", err)
+			return nil, nil, err
 		}
 
-		result := make([]map[string]interface{}, 0, len(resources.Items))
-		for _, item := range resources.Items {
-			result = append(result, item.Object)
+		result, truncation := truncateObjects(objects, s.MaxResultSize)
+		continueToken, remainingItemCount := listMeta.Continue, listMeta.RemainingItemCount
+		if truncation != nil && truncation.OmittedResources > 0 {
+			// The continue token would skip the omitted resources.
+			continueToken, remainingItemCount = "", nil
 		}
 
 		message := fmt.Sprintf("Found %d %s resources", len(result), input.Resource)
@@ -223,6 +449,18 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 		if input.Namespace != "" {
 			message += fmt.Sprintf(" in namespace '%s'", input.Namespace)
 		}
+		message += clusterSuffix(request)
+		if continueToken != "" {
+			if remainingItemCount != nil {
+				message += fmt.Sprintf(", about %d more available", *remainingItemCount)
+			}
+			message += ". More resources are available, call again with the continue token to get the next page"
+		}
+		if truncation != nil {
+			message += truncation.message(len(result))
+			notifyClient(ctx, request.Session, "warning", fmt.Sprintf("The %s list exceeded the maximum result size and was truncated: %d resources returned, %d omitted",
+				input.Resource, len(result), truncation.OmittedResources))
+		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -230,23 +468,37 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 					Text: message,
 				},
 			},
-		}, &ResourceListResult{Resources: result}, nil
-	})
-	mcp.AddTool(server, &mcp.Tool{
-		Name: "resource_get",
+		}, &ResourceListResult{
+			Resources:          result,
+			Continue:           continueToken,
+			RemainingItemCount: remainingItemCount,
+			Truncated:          truncation,
+			APIServerURL:       requestAPIServerURL(request),
+			Cluster:            requestCluster(request),
+		}, nil
+	}
+	addTool(server, &mcp.Tool{
+		Name: "resource_list",
 		Annotations: &mcp.ToolAnnotations{
 			DestructiveHint: ptr.To(false),
 			IdempotentHint:  false,
 			OpenWorldHint:   ptr.To(true),
 			ReadOnlyHint:    true,
-			Title:           "Get detailed information about a specific Kubernetes resource",
+			Title:           "List Kubernetes resources of a specific type",
 		},
-		Description: "Get detailed information about a specific Kubernetes resource. This can be pods, deployments.v1.apps, etc. Kind.version.group or Kind format",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input ResourceGetInput) (*mcp.CallToolResult, *ResourceGetResult, error) {
-		apiServerUrl := request.Extra.TokenInfo.Extra["audience"].(string)
-		bearerToken := request.Extra.TokenInfo.Extra["bearer_token"].(string)
+		Description:  "List Kubernetes resources of a specific type. This can be pods, deployments.v1.apps, etc. Kind.version.group or Kind format",
+		OutputSchema: outputSchema[ResourceListResult]("resources"),
+	}, listResources)
+	getResource := func(ctx context.Context, request *mcp.CallToolRequest, input ResourceGetInput) (*mcp.CallToolResult, *ResourceGetResult, error) {
+		fields, err := parseFields(input.Fields)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := validateSubresource(input.Subresource); err != nil {
+			return nil, nil, err
+		}
 
-		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfig(bearerToken, apiServerUrl)
+		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
 		}
@@ -257,56 +509,54 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 		}
 
 		if isNamespaced && input.Namespace == "" {
-			defaultValue := json.RawMessage(`"default"`)
-			elicitResult, err := request.Session.Elicit(ctx, &mcp.ElicitParams{
-				Message: fmt.Sprintf("Namespace is required for namespaced resource %s. Please specify a namespace:", input.Resource),
-				RequestedSchema: &jsonschema.Schema{
-					Type: "object",
-					Properties: map[string]*jsonschema.Schema{
-						"namespace": {
-							Type:        "string",
-							Description: "The namespace for the resource",
-							Default:     defaultValue,
-						},
-					},
-					Required: []string{"namespace"},
-				},
-			})
+			input.Namespace, err = s.sessionNamespace(ctx, request, input.Resource)
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to elicit namespace: %w", err)
+				return nil, nil, err
 			}
-
-			if elicitResult.Action != "accept" {
-				return nil, nil, fmt.Errorf("user cancelled namespace selection")
-			}
-
-			namespace, ok := elicitResult.Content["namespace"].(string)
-			if !ok || namespace == "" {
-				namespace = "default"
-			}
-			input.Namespace = namespace
 		}
 
 		namespace := input.Namespace
+		var getSubresources []string
+		name := input.Name
+		if input.Subresource != "" {
+			getSubresources = []string{input.Subresource}
+			name += "/" + input.Subresource
+		}
 		var resource *unstructured.Unstructured
 		if namespace != "" {
-			resource, err = dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, input.Name, v1.GetOptions{})
+			resource, err = dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, input.Name, v1.GetOptions{}, getSubresources...)
 		} else {
-			resource, err = dynamicClient.Resource(gvr).Get(ctx, input.Name, v1.GetOptions{})
+			resource, err = dynamicClient.Resource(gvr).Get(ctx, input.Name, v1.GetOptions{}, getSubresources...)
 		}
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get resource: %w", err)
 		}
+		object := resource.Object
+		if len(fields) > 0 {
+			object = projectObject(resource, fields)
+		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("Retrieved %s/%s", input.Resource, input.Name),
+					Text: fmt.Sprintf("Retrieved %s/%s%s", input.Resource, name, clusterSuffix(request)),
 				},
 			},
-		}, &ResourceGetResult{Resource: resource.Object}, nil
-	})
-	mcp.AddTool(server, &mcp.Tool{
+		}, &ResourceGetResult{Resource: object, APIServerURL: requestAPIServerURL(request), Cluster: requestCluster(request)}, nil
+	}
+	addTool(server, &mcp.Tool{
+		Name: "resource_get",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Get detailed information about a specific Kubernetes resource",
+		},
+		Description:  "Get detailed information about a specific Kubernetes resource. This can be pods, deployments.v1.apps, etc. Kind.version.group or Kind format",
+		OutputSchema: outputSchema[ResourceGetResult]("resource"),
+	}, getResource)
+	applyTool := &mcp.Tool{
 		Name: "resource_apply",
 		Annotations: &mcp.ToolAnnotations{
 			DestructiveHint: ptr.To(true),
@@ -315,49 +565,113 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 			ReadOnlyHint:    false,
 			Title:           "Apply a specific Kubernetes resource",
 		},
-		Description: "Apply a specific Kubernetes resource. This can be pods, deployments.v1.apps, etc. Kind.version.group or Kind format",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input ResourceCreateOrUpdateInput) (*mcp.CallToolResult, *ResourceApplyResult, error) {
-		apiServerUrl := request.Extra.TokenInfo.Extra["audience"].(string)
-		bearerToken := request.Extra.TokenInfo.Extra["bearer_token"].(string)
-
-		docs := strings.Split(input.ResourceYAML, "---")
-		var unstructuredList []*unstructured.Unstructured
-
-		for _, doc := range docs {
-			doc = strings.TrimSpace(doc)
-			if doc == "" {
-				continue
+		Description:  "Apply a specific Kubernetes resource. This can be pods, deployments.v1.apps, etc. Kind.version.group or Kind format",
+		OutputSchema: outputSchema[ResourceApplyResult]("appliedResources"),
+	}
+	var applyResources mcp.ToolHandlerFor[ResourceCreateOrUpdateInput, *ResourceApplyResult]
+	applyResources = func(ctx context.Context, request *mcp.CallToolRequest, input ResourceCreateOrUpdateInput) (*mcp.CallToolResult, *ResourceApplyResult, error) {
+		if input.FieldManager == "" {
+			input.FieldManager = fieldManager
+		}
+		if err := validateFieldManager(input.FieldManager); err != nil {
+			return nil, nil, err
+		}
+		if input.Namespace != "" {
+			if errs := validation.IsDNS1123Label(input.Namespace); len(errs) > 0 {
+				return nil, nil, fmt.Errorf("invalid namespace %q: %v", input.Namespace, errs)
 			}
-
-			decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(doc), 4096)
-			var obj unstructured.Unstructured
-			if err := decoder.Decode(&obj); err != nil {
-				return nil, nil, fmt.Errorf("failed to decode YAML document: %w", err)
+		}
+		if input.Async {
+			input.Async = false
+			op, err := s.operations.start(ctx, request, s.ToolTimeout, func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, any, error) {
+				result, applied, err := applyResources(ctx, request, input)
+				if err != nil {
+					return nil, nil, err
+				}
+				return result, applied, nil
+			})
+			if err != nil {
+				return nil, nil, err
 			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Started operation %s applying the resources, follow it with operation_status", op.ID),
+					},
+				},
+			}, &ResourceApplyResult{AppliedResources: []map[string]interface{}{}, OperationID: op.ID, APIServerURL: requestAPIServerURL(request)}, nil
+		}
 
-			if obj.Object != nil {
-				unstructuredList = append(unstructuredList, &obj)
+		waitTimeout := defaultApplyWaitTimeout
+		if input.WaitTimeout != "" {
+			var err error
+			waitTimeout, err = time.ParseDuration(input.WaitTimeout)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid waitTimeout %q: %w", input.WaitTimeout, err)
 			}
 		}
 
-		if len(unstructuredList) == 0 {
-			return nil, nil, fmt.Errorf("no valid resources found in the provided YAML")
+		unstructuredList, err := decodeManifests(input.ResourceYAML)
+		if err != nil {
+			return nil, nil, err
 		}
+		// The warnings of the dry-runs are shown in the confirmation, and those of the apply returned with its result.
+		ctx, warnings := withAPIWarnings(ctx)
 
-		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfig(bearerToken, apiServerUrl)
+		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
 		}
 
+		namespace := s.defaultNamespace(request)
+		if input.Namespace != "" {
+			if err := overrideNamespace(ctx, unstructuredList, input.Namespace, input.ForceNamespace, discoveryClient, request.Session); err != nil {
+				return nil, nil, err
+			}
+			namespace = input.Namespace
+		}
+
 		type resourceInfo struct {
 			resource        *unstructured.Unstructured
-			gvr             schema.GroupVersionResource
 			isNamespaced    bool
 			dynamicResource dynamic.ResourceInterface
+			// dependency is the resource of the bundle this resource can only be found and validated after.
+			dependency string
+			// applied is the resource returned by the apply, nil until applied.
+			applied *unstructured.Unstructured
+			// outcome and reason are set once the resource is applied, skipped or failed.
+			outcome, reason string
 		}
 
+		sortForApply(unstructuredList)
 		var resourceInfos []resourceInfo
 		var resourceSummaries []string
+		impact := &ApplyImpact{}
+
+		// notApplied are the resources of the bundle that failed or were skipped, which the
+		// resources depending on them are skipped for.
+		notApplied := map[string]bool{}
+		fail := func(info *resourceInfo, err error) {
+			info.outcome, info.reason = ApplyOutcomeFailed, err.Error()
+			notApplied[bundleKey(info.resource)] = true
+		}
+		skip := func(info *resourceInfo, reason string) {
+			info.outcome, info.reason = ApplyOutcomeSkipped, reason
+			notApplied[bundleKey(info.resource)] = true
+		}
+		documents := func() []ApplyDocumentResult {
+			documents := make([]ApplyDocumentResult, 0, len(resourceInfos))
+			for _, info := range resourceInfos {
+				documents = append(documents, ApplyDocumentResult{
+					Kind:      info.resource.GetKind(),
+					Name:      info.resource.GetName(),
+					Namespace: info.resource.GetNamespace(),
+					Outcome:   info.outcome,
+					Reason:    info.reason,
+				})
+			}
+			return documents
+		}
 
 		for _, resource := range unstructuredList {
 			kind := resource.GetKind()
@@ -365,101 +679,264 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 				return nil, nil, fmt.Errorf("resource kind is required")
 			}
 
-			gvr, isNamespaced, err := FindResource(ctx, strings.ToLower(kind), discoveryClient, request.Session)
-			if err != nil {
-				return nil, nil, fmt.Errorf("failed to find resource: %w", err)
+			if dependency := bundleDependency(resource, unstructuredList); dependency != "" {
+				info := resourceInfo{resource: resource, dependency: dependency}
+				if notApplied[dependency] {
+					skip(&info, fmt.Sprintf("%s is not applied", dependency))
+					resourceSummaries = append(resourceSummaries, fmt.Sprintf("- skip %s/%s, %s", kind, resource.GetName(), info.reason))
+					resourceInfos = append(resourceInfos, info)
+					continue
+				}
+				resourceInfos = append(resourceInfos, info)
+				nsInfo := ""
+				if resource.GetNamespace() != "" {
+					nsInfo = fmt.Sprintf(" (namespace: %s)", resource.GetNamespace())
+				}
+				resourceSummaries = append(resourceSummaries, fmt.Sprintf("- apply %s/%s%s, validated after %s is applied", kind, resource.GetName(), nsInfo, dependency))
+				impact.addObject(resource, s.reloadable().ProductionNamespaces)
+				continue
 			}
 
-			var dynamicResource dynamic.ResourceInterface
-			namespace := resource.GetNamespace()
-
-			if isNamespaced {
-				if namespace == "" {
-					namespace = "default"
-					resource.SetNamespace(namespace)
+			dynamicResource, isNamespaced, err := applyTarget(ctx, resource, dynamicClient, discoveryClient, request.Session, namespace)
+			var dryRunResult *unstructured.Unstructured
+			if err == nil {
+				dryRunResource := resource.DeepCopy()
+				dryRunResult, err = dynamicResource.Apply(ctx, resource.GetName(), dryRunResource, v1.ApplyOptions{DryRun: []string{v1.DryRunAll}, FieldManager: input.FieldManager, Force: input.Force})
+				if err != nil {
+					err = fmt.Errorf("dry-run validation failed for %s/%s: %w", kind, resource.GetName(), err)
 				}
-				dynamicResource = dynamicClient.Resource(gvr).Namespace(namespace)
-			} else {
-				dynamicResource = dynamicClient.Resource(gvr)
 			}
-
-			dryRunResource := resource.DeepCopy()
-			_, err = dynamicResource.Apply(ctx, resource.GetName(), dryRunResource, v1.ApplyOptions{DryRun: []string{v1.DryRunAll}, FieldManager: "k-mcp"})
 			if err != nil {
-				return nil, nil, fmt.Errorf("dry-run validation failed for %s/%s: %w", kind, resource.GetName(), err)
+				if !input.ContinueOnError {
+					return nil, nil, err
+				}
+				info := resourceInfo{resource: resource}
+				fail(&info, err)
+				resourceSummaries = append(resourceSummaries, fmt.Sprintf("- skip %s/%s, %s", kind, resource.GetName(), info.reason))
+				resourceInfos = append(resourceInfos, info)
+				continue
+			}
+			impact.addObject(resource, s.reloadable().ProductionNamespaces)
+			if err := impact.addChange(ctx, dynamicClient, dynamicResource, dryRunResult); err != nil {
+				return nil, nil, err
 			}
 
 			resourceInfos = append(resourceInfos, resourceInfo{
 				resource:        resource,
-				gvr:             gvr,
 				isNamespaced:    isNamespaced,
 				dynamicResource: dynamicResource,
 			})
 
 			nsInfo := ""
 			if isNamespaced {
-				nsInfo = fmt.Sprintf(" (namespace: %s)", namespace)
+				nsInfo = fmt.Sprintf(" (namespace: %s)", resource.GetNamespace())
 			}
 			resourceSummaries = append(resourceSummaries, fmt.Sprintf("- apply %s/%s%s", kind, resource.GetName(), nsInfo))
 		}
 
-		resourcePreview := fmt.Sprintf(`The following resources will be processed:\n\n%s\n\nDo you want to proceed?`, strings.Join(resourceSummaries, "\n"))
-		elicitResult, err := request.Session.Elicit(ctx, &mcp.ElicitParams{
-			Message: resourcePreview,
-			RequestedSchema: &jsonschema.Schema{
-				Type: "object",
-				Properties: map[string]*jsonschema.Schema{
-					"confirm": {
-						Type:        "boolean",
-						Description: "Confirm whether to proceed with creating/updating the resources",
+		steps := 0
+		for _, info := range resourceInfos {
+			if info.outcome == "" {
+				steps++
+			}
+		}
+		if steps == 0 {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: textSummary("None of the resources can be applied:", strings.Join(resourceSummaries, "\n"), warningsSection(warnings.list())),
 					},
 				},
-				Required: []string{"confirm"},
-			},
-		})
+			}, &ResourceApplyResult{AppliedResources: []map[string]interface{}{}, Documents: documents(), Warnings: warnings.list(), APIServerURL: requestAPIServerURL(request)}, nil
+		}
+
+		forceInfo := ""
+		if input.Force {
+			forceInfo = fmt.Sprintf("\n\nThe fields conflicting with other field managers will be taken over by %s.", input.FieldManager)
+		}
+		warningInfo := ""
+		if section := warningsSection(warnings.list()); section != "" {
+			warningInfo = "\n\n" + section
+		}
+		resourcePreview := fmt.Sprintf(`The following resources will be processed:\n\n%s%s%s\n\nDo you want to proceed?`, strings.Join(resourceSummaries, "\n"), forceInfo, warningInfo)
+		phrase := "apply"
+		if request.Extra != nil && request.Extra.TokenInfo != nil {
+			// The name of the cluster the registry shows the user, or else the host of its API server.
+			phrase = s.clusters.displayName(requestAPIServerURL(request))
+		}
+		elicitResult, err := request.Session.Elicit(ctx, s.impactConfirmation(resourcePreview, impact, phrase))
+		if errors.Is(err, ErrElicitationTimeout) || errors.Is(err, ErrElicitationUnsupported) {
+			return cancelledApplyResult(fmt.Sprintf("Operation cancelled - %v", err))
+		}
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to elicit user confirmation: %w", err)
 		}
 
 		if elicitResult.Action != "accept" {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: "Operation cancelled by user",
-					},
-				},
-			}, nil, nil
+			return cancelledApplyResult("Operation cancelled by user")
 		}
 
 		confirm, ok := elicitResult.Content["confirm"].(bool)
 		if !ok || !confirm {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: "Operation cancelled - user did not confirm",
-					},
-				},
-			}, nil, nil
+			return cancelledApplyResult("Operation cancelled - user did not confirm")
+		}
+		if typed, _ := elicitResult.Content[elicitationPhraseField].(string); s.requiresPhrase(impact) && strings.TrimSpace(typed) != phrase {
+			return cancelledApplyResult(fmt.Sprintf("Operation cancelled - the typed phrase does not match %q", phrase))
 		}
 
-		var appliedResources []map[string]interface{}
-		var operationSummaries []string
+		if input.Wait {
+			steps *= 2
+		}
+		progress := newProgressReporter(request, steps)
+
+		waited := 0
+		lastPhase := -1
+		crdsApplied := false
+	apply:
+		for i := range resourceInfos {
+			info := &resourceInfos[i]
+			if info.outcome != "" {
+				continue
+			}
+			if info.dependency != "" && notApplied[info.dependency] {
+				skip(info, fmt.Sprintf("%s is not applied", info.dependency))
+				continue
+			}
 
-		for _, info := range resourceInfos {
-			result, err := info.dynamicResource.Apply(ctx, info.resource.GetName(), info.resource, v1.ApplyOptions{FieldManager: "k-mcp"})
+			if input.WaitBetween && lastPhase >= 0 && applyPhase(info.resource) != lastPhase {
+				progress.notify(ctx, fmt.Sprintf("Waiting for the applied resource(s) to become ready before applying %s/%s",
+					info.resource.GetKind(), info.resource.GetName()))
+				waitCtx, cancel := context.WithTimeout(ctx, waitTimeout)
+				for j := waited; j < i; j++ {
+					if resourceInfos[j].applied == nil {
+						continue
+					}
+					status := waitForReady(waitCtx, resourceInfos[j].dynamicResource, resourceInfos[j].applied)
+					if status.Status == ReadinessReady {
+						continue
+					}
+					cancel()
+					if !input.ContinueOnError {
+						return nil, nil, fmt.Errorf("stopped before applying %s/%s, %s/%s is not ready (%s): %s",
+							info.resource.GetKind(), info.resource.GetName(), status.Kind, status.Name, status.Status, status.Message)
+					}
+					// The resources of the next phases may depend on the resource that is not ready.
+					for k := i; k < len(resourceInfos); k++ {
+						if resourceInfos[k].outcome == "" {
+							skip(&resourceInfos[k], fmt.Sprintf("%s/%s is not ready (%s): %s", status.Kind, status.Name, status.Status, status.Message))
+						}
+					}
+					break apply
+				}
+				cancel()
+				waited = i
+			}
+
+			if info.dependency != "" {
+				if crdsApplied {
+					// The discovery cache does not know about the resources of the CRDs applied so far.
+					discoveryClient.Invalidate()
+					crdsApplied = false
+				}
+				info.dynamicResource, info.isNamespaced, err = applyTarget(ctx, info.resource, dynamicClient, discoveryClient, request.Session, namespace)
+				if err != nil {
+					if !input.ContinueOnError {
+						return nil, nil, err
+					}
+					fail(info, err)
+					continue
+				}
+			}
+
+			result, err := info.dynamicResource.Apply(ctx, info.resource.GetName(), info.resource, v1.ApplyOptions{FieldManager: input.FieldManager, Force: input.Force})
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to apply %s/%s: %w", info.resource.GetKind(), info.resource.GetName(), err)
+				err = fmt.Errorf("failed to apply %s/%s: %w", info.resource.GetKind(), info.resource.GetName(), err)
+				if !input.ContinueOnError {
+					return nil, nil, err
+				}
+				fail(info, err)
+				continue
+			}
+			lastPhase = applyPhase(info.resource)
+
+			if isCRD(result) {
+				// Custom resources can only be created once their CRD is established.
+				progress.notify(ctx, fmt.Sprintf("Waiting for customresourcedefinition %s to be established", result.GetName()))
+				waitCtx, cancel := context.WithTimeout(ctx, waitTimeout)
+				status := waitForReady(waitCtx, info.dynamicResource, result)
+				cancel()
+				if status.Status != ReadinessReady {
+					err := fmt.Errorf("customresourcedefinition %s is not established (%s): %s", result.GetName(), status.Status, status.Message)
+					if !input.ContinueOnError {
+						return nil, nil, err
+					}
+					fail(info, err)
+					continue
+				}
+				crdsApplied = true
 			}
 
-			appliedResources = append(appliedResources, result.Object)
-			nsInfo := ""
-			if info.isNamespaced {
-				nsInfo = fmt.Sprintf(" (namespace: %s)", result.GetNamespace())
+			info.applied, info.outcome = result, ApplyOutcomeApplied
+			progress.step(ctx, "Applied %s/%s", result.GetKind(), result.GetName())
+		}
+
+		var operationSummaries, failureSummaries []string
+		for _, info := range resourceInfos {
+			switch info.outcome {
+			case ApplyOutcomeApplied:
+				nsInfo := ""
+				if info.isNamespaced {
+					nsInfo = fmt.Sprintf(" (namespace: %s)", info.applied.GetNamespace())
+				}
+				operationSummaries = append(operationSummaries, fmt.Sprintf("applied %s/%s%s", info.applied.GetKind(), info.applied.GetName(), nsInfo))
+			case ApplyOutcomeFailed, ApplyOutcomeSkipped:
+				failureSummaries = append(failureSummaries, fmt.Sprintf("%s %s/%s: %s", info.outcome, info.resource.GetKind(), info.resource.GetName(), info.reason))
 			}
-			operationSummaries = append(operationSummaries, fmt.Sprintf("- applied %s/%s%s", result.GetKind(), result.GetName(), nsInfo))
+		}
+		message := textSummary(fmt.Sprintf("Successfully processed %d resource(s):", len(operationSummaries)), textList(operationSummaries))
+		if len(failureSummaries) > 0 {
+			message = textSummary(message, fmt.Sprintf("%d resource(s) were not applied:\n", len(failureSummaries))+textList(failureSummaries))
 		}
 
-		message := fmt.Sprintf("Successfully processed %d resource(s):\n\n%s", len(appliedResources), strings.Join(operationSummaries, "\n"))
+		var statuses []ApplyStatus
+		if input.Wait {
+			waitCtx, cancel := context.WithTimeout(ctx, waitTimeout)
+			defer cancel()
+
+			readiness := newTextTable("KIND", "NAME", "STATUS", "MESSAGE")
+			for _, info := range resourceInfos {
+				if info.outcome != ApplyOutcomeApplied {
+					continue
+				}
+				status := waitForReady(waitCtx, info.dynamicResource, info.applied)
+				statuses = append(statuses, status)
+				progress.step(ctx, "%s/%s: %s", status.Kind, status.Name, status.Status)
+				readiness.addRow(status.Kind, status.Name, status.Status, status.Message)
+			}
+			message = textSummary(message, "Readiness:\n"+readiness.String())
+		}
+
+		appliedResources := []map[string]interface{}{}
+		var readFailures []string
+		for i := range resourceInfos {
+			info := &resourceInfos[i]
+			if info.outcome != ApplyOutcomeApplied {
+				continue
+			}
+			if input.ReadAfterWrite {
+				current, err := readAfterWrite(ctx, info.dynamicResource, info.applied)
+				if err != nil {
+					readFailures = append(readFailures, fmt.Sprintf("%s/%s: %v", info.applied.GetKind(), info.applied.GetName(), err))
+				} else {
+					info.applied = current
+				}
+			}
+			appliedResources = append(appliedResources, info.applied.Object)
+		}
+		if len(readFailures) > 0 {
+			message = textSummary(message, "Failed to re-read, returning the apply response instead:\n"+textList(readFailures))
+		}
+		message = textSummary(message, warningsSection(warnings.list()))
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -467,18 +944,78 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 					Text: message,
 				},
 			},
-		}, &ResourceApplyResult{AppliedResources: appliedResources}, nil
-	})
-	server.AddReceivingMiddleware(loggingMiddleware)
+		}, &ResourceApplyResult{AppliedResources: appliedResources, Documents: documents(), Warnings: warnings.list(), Statuses: statuses, Impact: impact, APIServerURL: requestAPIServerURL(request)}, nil
+	}
+	addTool(server, applyTool, applyResources)
+	s.addPodDiagnoseTool(server, dynamicConfig)
+	s.addWorkloadHealthTool(server, dynamicConfig)
+	s.addCRDListTool(server, dynamicConfig)
+	s.addResourceConditionsTool(server, dynamicConfig)
+	s.addNamespaceQuotasTool(server, dynamicConfig)
+	savedQueries := newSavedQueryStore(s.SavedQueries)
+	s.addSavedQueryTools(server, dynamicConfig, savedQueries)
+	s.addScheduleQueryTool(server, dynamicConfig, savedQueries, scheduler)
+	s.addPDBCheckTool(server, dynamicConfig)
+	s.addPodEvictTool(server, dynamicConfig)
+	s.addResourceUtilizationTool(server, dynamicConfig)
+	s.addInventoryExportTool(server, dynamicConfig)
+	prober := newReachabilityProber(dynamicConfig, s.ProbeAPIServers)
+	clusters.prober = prober
+	s.addClusterInfoTool(server, dynamicConfig, prober)
+	s.addListClustersTool(server, dynamicConfig, prober, clusters)
+	// The SDK notifies the sessions when a tool is registered, registering list_clusters again tells
+	// them to list the tools again, e.g. after the reload of the toolsets or of the cluster registry.
+	s.toolsChanged = func() {
+		s.addListClustersTool(server, dynamicConfig, prober, clusters)
+	}
+	s.addFindOrphansTool(server, dynamicConfig)
+	s.addResourceGraphTool(server, dynamicConfig)
+	s.addTakeOwnershipTool(server, dynamicConfig)
+	s.addManifestCompleteTool(server, dynamicConfig)
+	s.addKubectlTranslateTool(server, dynamicConfig, listResources, getResource)
+	s.addSetContextTool(server)
+	s.addPreferencesTools(server)
+	s.addOperationTools(server)
+	s.addConformanceCheckTool(server, dynamicConfig)
+	crdTools := s.addCRDTools(server, dynamicConfig)
+	s.addObjectResourceTemplate(server, dynamicConfig)
+	s.addPrompts(server)
+	if disabled := disabledTools(s.Toolsets, s.ReadOnly); len(disabled) > 0 {
+		slog.Info("Disabled tools", "tools", disabled)
+	}
+	memory := newMemoryWatchdog(s.MemoryLimit, s.operations.evictResults, scheduler.evictResults)
+	server.AddReceivingMiddleware(requestIDMiddleware, tracingMiddleware, structuredContentEncodingMiddleware, loggingMiddleware, s.toolFilterMiddleware(), clusters.middleware(), sanitizeMiddleware, s.redactor.middleware(), versionSkewMiddleware(dynamicConfig), prober.middleware(), crdTools.middleware(), memory.middleware(), audit.middleware(), apiErrorMiddleware, toolTimeoutMiddleware(s.ToolTimeout))
+	elicitationMetrics := newElicitationMetrics()
+	server.AddSendingMiddleware(headlessElicitationMiddleware(s.Headless), elicitationMetrics.middleware(), elicitationTimeoutMiddleware(s.ElicitationTimeout))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go prober.run(ctx, s.ProbeInterval)
+	go memory.run(ctx)
+	go s.reloadOnHangup(ctx, dynamicConfig)
+
+	if s.Transport == TransportStdio {
+		return s.runStdio(ctx, server, dynamicConfig)
+	}
+
 	handler := mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server {
 		return server
 	}, &mcp.StreamableHTTPOptions{
 		Stateless: false,
 	})
 	handlerWithLogging := loggingHandler(handler)
-	handlerWithJWT := auth.RequireBearerToken(verifyToken, nil)(handlerWithLogging)
+	handlerWithJWT := s.bearerChallengeHandler(auth.RequireBearerToken(verifyToken, nil)(handlerWithLogging))
 
-	mux.Handle("/mcp", handlerWithJWT)
+	mux.Handle(mcpEndpointPath, requestBodyLimitHandler(s.HTTPLimits.MaxRequestBodySize, handlerWithJWT))
+	if s.Transport == TransportSSE {
+		sseHandlerWithJWT := s.bearerChallengeHandler(auth.RequireBearerToken(verifyToken, nil)(loggingHandler(newSSEHandler(server))))
+		mux.Handle(sseEndpointPath, requestBodyLimitHandler(s.HTTPLimits.MaxRequestBodySize, sseHandlerWithJWT))
+	}
+	mux.Handle(protectedResourceMetadataPath, s.protectedResourceMetadataHandler())
+	mux.Handle(protectedResourceMetadataPath+mcpEndpointPath, s.protectedResourceMetadataHandler())
+	mux.Handle("/metrics", elicitationMetrics)
+	mux.Handle("/readyz", prober)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		//nolint:errcheck
@@ -488,16 +1025,10 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 		})
 	})
 
-	httpServer := &http.Server{
-		Addr:    ":" + s.Port,
-		Handler: mux,
-	}
-
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	httpServer := s.HTTPLimits.httpServer(":"+s.Port, mux)
 
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	serverErr := make(chan error, 1)
 	go func() {
@@ -531,31 +1062,190 @@ func (s *Server) Run(ctx context.Context, dynamicConfig *DynamicConfig) error {
 	return nil
 }
 
+// listObjects lists the resources of a type for resource_list, shaped for its output mode.
+func (s *Server) listObjects(ctx context.Context, request *mcp.CallToolRequest, dynamicConfig *DynamicConfig, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, input ResourceListInput) ([]map[string]interface{}, v1.ListMeta, error) {
+	var resources *unstructured.UnstructuredList
+	namespace := input.Namespace
+	listOptions := v1.ListOptions{
+		Limit:    input.Limit,
+		Continue: input.Continue,
+	}
+	if input.LabelSelector != "" {
+		listOptions.LabelSelector = input.LabelSelector
+	}
+	fields, err := parseFields(input.Fields)
+	if err != nil {
+		return nil, v1.ListMeta{}, err
+	}
+
+	var objects []map[string]interface{}
+	var listMeta v1.ListMeta
+	switch {
+	case input.OutputMode == OutputModeTable:
+		var table *v1.Table
+		table, err = dynamicConfig.listTable(ctx, request.Extra.TokenInfo, gvr, namespace, listOptions)
+		if err == nil {
+			objects, listMeta = tableRecords(table), table.ListMeta
+		}
+	case namespace != "":
+		resources, err = dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, listOptions)
+	default:
+		resources, err = dynamicClient.Resource(gvr).List(ctx, listOptions)
+	}
+	if apierrors.IsResourceExpired(err) {
+		return nil, v1.ListMeta{}, fmt.Errorf("the continue token has expired, list again without it: %w", err)
+	}
+	if err != nil {
+		return nil, v1.ListMeta{}, fmt.Errorf("failed to list resources: %w", err)
+	}
+	if resources != nil {
+		if input.SortBy != "" {
+			sortBy, err := parseSortBy(input.SortBy)
+			if err != nil {
+				return nil, v1.ListMeta{}, err
+			}
+			sortObjects(resources.Items, sortBy, input.Descending)
+		}
+		if len(fields) > 0 {
+			objects = projectObjects(resources.Items, fields)
+		} else {
+			objects = shapeObjects(resources.Items, input.OutputMode, s.reloadable().SummaryColumns)
+		}
+		listMeta = v1.ListMeta{Continue: resources.GetContinue(), RemainingItemCount: resources.GetRemainingItemCount()}
+	}
+	return objects, listMeta, nil
+}
+
+const (
+	OutputModeFull    = "full"
+	OutputModeSummary = "summary"
+	// OutputModeTable returns the columns of the server-side printing of the resources, as kubectl get shows them.
+	OutputModeTable = "table"
+)
+
+// validateOutputMode validates an output mode of resource_list, empty meaning the default one.
+func validateOutputMode(outputMode string) error {
+	switch outputMode {
+	case "", OutputModeFull, OutputModeSummary, OutputModeTable:
+		return nil
+	}
+	return fmt.Errorf("invalid output mode %q, must be one of: %s, %s, %s", outputMode, OutputModeFull, OutputModeSummary, OutputModeTable)
+}
+
 type ResourceListInput struct {
-	Resource      string `json:"resource,required" jsonschema:"The Kubernetes resource type (e.g. pods services deployments.v1.apps)"`
-	Namespace     string `json:"namespace,omitempty" jsonschema:"The namespace to list resources from (optional defaults to all namespaces)"`
-	LabelSelector string `json:"labelSelector,omitempty" jsonschema:"Label selector to filter resources (e.g. app=myapp,version=v1.0)"`
+	Resource      string   `json:"resource,required" jsonschema:"The Kubernetes resource type (e.g. pods services deployments.v1.apps), or a category of resource types (e.g. all) whose resources are listed per type"`
+	Namespace     string   `json:"namespace,omitempty" jsonschema:"The namespace to list resources from (optional defaults to all namespaces)"`
+	LabelSelector string   `json:"labelSelector,omitempty" jsonschema:"Label selector to filter resources (e.g. app=myapp,version=v1.0)"`
+	OutputMode    string   `json:"outputMode,omitempty" jsonschema:"Output mode (full, summary or table). Summary returns one compact record per resource with its name, namespace, kind, age and key status fields (e.g. ready, status and restarts for pods), table the columns kubectl get shows, computed by the API server (optional defaults to full)"`
+	Limit         int64    `json:"limit,omitempty" jsonschema:"The maximum number of resources to return. A continue token is returned when more resources are available (optional defaults to all resources)"`
+	Continue      string   `json:"continue,omitempty" jsonschema:"The continue token returned by the previous call to get the next page, with the same resource, namespace and label selector"`
+	FullMetadata  bool     `json:"fullMetadata,omitempty" jsonschema:"Keep the managedFields, the last applied configuration and the resourceVersion of the resources, which are removed by default"`
+	Fields        []string `json:"fields,omitempty" jsonschema:"JSONPath expressions of the fields to return (e.g. .status.conditions or .spec.containers[*].image), instead of the whole resources. Every resource is returned with its name, namespace and kind and the value of every expression, a list when it matches several values"`
+	SortBy        string   `json:"sortBy,omitempty" jsonschema:"Sort the resources by name, creationTimestamp or the value of a JSONPath expression (e.g. .status.startTime), the resources without it coming last. With limit, only the returned page is sorted (optional defaults to the order of the API server)"`
+	Descending    bool     `json:"descending,omitempty" jsonschema:"Sort the resources in descending order, e.g. the newest first with creationTimestamp"`
 }
 
 type ResourceGetInput struct {
-	Resource  string `json:"resource,required" jsonschema:"The Kubernetes resource type (e.g. pods services deployments.v1.apps)"`
-	Name      string `json:"name,required" jsonschema:"The name of the resource"`
-	Namespace string `json:"namespace,omitempty" jsonschema:"The namespace of the resource (required for namespaced resources)"`
+	Resource     string   `json:"resource,required" jsonschema:"The Kubernetes resource type (e.g. pods services deployments.v1.apps)"`
+	Name         string   `json:"name,required" jsonschema:"The name of the resource"`
+	Namespace    string   `json:"namespace,omitempty" jsonschema:"The namespace of the resource (required for namespaced resources)"`
+	FullMetadata bool     `json:"fullMetadata,omitempty" jsonschema:"Keep the managedFields, the last applied configuration and the resourceVersion of the resource, which are removed by default"`
+	Fields       []string `json:"fields,omitempty" jsonschema:"JSONPath expressions of the fields to return (e.g. .status.conditions or .spec.containers[*].image), instead of the whole resource. The resource is returned with its name, namespace and kind and the value of every expression, a list when it matches several values"`
+	Subresource  string   `json:"subresource,omitempty" jsonschema:"The subresource to get instead of the resource: status, or scale to get the Scale of the workloads and of the custom resources with a scale subresource"`
 }
 
 type ResourceCreateOrUpdateInput struct {
-	ResourceYAML string `json:"resourceYAML,required" jsonschema:"The Kubernetes resource(s) in YAML format. Can contain single or multiple resources separated by ---"`
+	ResourceYAML    string `json:"resourceYAML,required" jsonschema:"The Kubernetes resource(s) in YAML or JSON format. Can contain single or multiple YAML documents separated by --- lines, a JSON object, a List or a JSON array of objects"`
+	Wait            bool   `json:"wait,omitempty" jsonschema:"Wait for the applied resources to become ready (deployments rolled out, pods running, CRDs established) before returning"`
+	WaitTimeout     string `json:"waitTimeout,omitempty" jsonschema:"The maximum duration to wait for (e.g. 2m, optional defaults to 5m)"`
+	WaitBetween     bool   `json:"waitBetween,omitempty" jsonschema:"Wait for the resources of every apply phase (namespaces and CRDs, other resources, webhooks) to become ready before applying the next phase"`
+	ReadAfterWrite  bool   `json:"readAfterWrite,omitempty" jsonschema:"Re-read the applied resources once applied (and ready when waiting), and return their fresh state instead of the apply response"`
+	Force           bool   `json:"force,omitempty" jsonschema:"Take over the fields managed by other field managers, e.g. controllers, instead of failing with a conflict"`
+	FieldManager    string `json:"fieldManager,omitempty" jsonschema:"The field manager recorded in the managedFields of the applied resources, to tell the agents apart (optional defaults to k-mcp)"`
+	Namespace       string `json:"namespace,omitempty" jsonschema:"The namespace to apply the namespaced resources in, instead of the default namespace for those without namespace. Resources of another namespace are rejected unless forceNamespace is set"`
+	ForceNamespace  bool   `json:"forceNamespace,omitempty" jsonschema:"Move the resources of another namespace to the namespace given, instead of rejecting them"`
+	ContinueOnError bool   `json:"continueOnError,omitempty" jsonschema:"Apply the resources that can be applied when others fail, instead of stopping at the first failure. The resources depending on a failed one (e.g. on its namespace or CRD) are skipped, and the outcome of every resource is returned in documents"`
+	Async           bool   `json:"async,omitempty" jsonschema:"Apply in a background operation and return its ID immediately, to follow with operation_status and cancel with operation_cancel. Use it with wait when the apply may outlast the tool call timeout of the client"`
 }
 
 // Return types for tool calls
 type ResourceListResult struct {
 	Resources []map[string]interface{} `json:"resources"`
+	// Continue is the token to get the next page, empty on the last page.
+	Continue string `json:"continue,omitempty"`
+	// RemainingItemCount is the estimated number of resources in the next pages, when known.
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty"`
+	// Truncated tells how the resources were truncated to the maximum result size, when they were.
+	Truncated *ListTruncation `json:"truncated,omitempty"`
+	// Groups are the resources of a category listed per resource type, when a category is listed.
+	Groups []ResourceListGroup `json:"groups,omitempty"`
+	// APIServerURL is the API server the resources come from.
+	APIServerURL string `json:"apiServerUrl,omitempty"`
+	// Cluster is the name of the cluster the resources come from, in the cluster registry, or the
+	// host of its API server.
+	Cluster string `json:"cluster,omitempty"`
+	// Clusters are the results of every granted cluster when the call is routed to all of them, the
+	// resources being in their results.
+	Clusters []ClusterResult `json:"clusters,omitempty"`
 }
 
 type ResourceGetResult struct {
 	Resource map[string]interface{} `json:"resource"`
+	// APIServerURL is the API server the resource comes from.
+	APIServerURL string `json:"apiServerUrl,omitempty"`
+	// Cluster is the name of the cluster the resource comes from.
+	Cluster string `json:"cluster,omitempty"`
+	// Clusters are the results of every granted cluster when the call is routed to all of them, the
+	// resource being in their results.
+	Clusters []ClusterResult `json:"clusters,omitempty"`
 }
 
 type ResourceApplyResult struct {
-	AppliedResources []map[string]interface{} `json:"appliedResources"`
+	AppliedResources   []map[string]interface{} `json:"appliedResources"`
+	Statuses           []ApplyStatus            `json:"statuses,omitempty"`
+	CancellationReason string                   `json:"cancellationReason,omitempty"`
+	// Impact is the impact of the apply computed from its dry-run.
+	Impact *ApplyImpact `json:"impact,omitempty"`
+	// Documents are the outcomes of the resources of the manifest, in the order they were applied.
+	Documents []ApplyDocumentResult `json:"documents,omitempty"`
+	// Warnings are the warnings returned by the API server for the dry-runs and the apply, e.g. the
+	// deprecation of the API version of a resource or the warnings of admission webhooks.
+	Warnings []string `json:"warnings,omitempty"`
+	// OperationID is the ID of the background operation applying the resources, when applied with async.
+	OperationID string `json:"operationId,omitempty"`
+	// APIServerURL is the API server the resources were applied to.
+	APIServerURL string `json:"apiServerUrl,omitempty"`
+}
+
+// Outcomes of the resources of resource_apply.
+const (
+	ApplyOutcomeApplied = "applied"
+	// ApplyOutcomeSkipped is the outcome of the resources not applied with continueOnError because
+	// a resource they depend on was not applied.
+	ApplyOutcomeSkipped = "skipped"
+	ApplyOutcomeFailed  = "failed"
+)
+
+// ApplyDocumentResult is the outcome of a resource of the manifest of resource_apply.
+type ApplyDocumentResult struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Outcome   string `json:"outcome"`
+	// Reason tells why the resource failed or was skipped.
+	Reason string `json:"reason,omitempty"`
+}
+
+// cancelledApplyResult returns the result of an apply operation that was not performed.
+func cancelledApplyResult(reason string) (*mcp.CallToolResult, *ResourceApplyResult, error) {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: reason,
+			},
+		},
+	}, &ResourceApplyResult{
+		AppliedResources:   []map[string]interface{}{},
+		CancellationReason: reason,
+	}, nil
 }
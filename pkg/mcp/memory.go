@@ -0,0 +1,175 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"runtime/debug"
+	"runtime/metrics"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// memoryCheckInterval is the interval between two checks of the heap usage.
+	memoryCheckInterval = time.Second
+	// memoryPressureRatio is the ratio of the memory limit from which the server is under pressure.
+	memoryPressureRatio = 0.85
+	// memoryRecoveryRatio is the ratio of the memory limit under which the pressure is relieved, lower
+	// than memoryPressureRatio so that the server does not flap around the limit.
+	memoryRecoveryRatio = 0.7
+	// serverBusyRetryAfter is the delay after which the clients should retry the refused calls.
+	serverBusyRetryAfter = 10 * time.Second
+	// cgroupMemoryMaxFile is the memory limit of the container with cgroup v2.
+	cgroupMemoryMaxFile = "/sys/fs/cgroup/memory.max"
+)
+
+// heapObjectsMetric is the memory occupied by live and not yet swept heap objects.
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// sheddableTools are the tools reading many objects at once, besides the _list and _get tools,
+// refused under memory pressure.
+var sheddableTools = []string{"inventory_export", "run_query", "kubectl_translate"}
+
+// ServerBusy is the structured error of the tool calls refused under memory pressure, in the
+// k-mcp/error metadata of their result.
+type ServerBusy struct {
+	Code              string `json:"code"`
+	Message           string `json:"message"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds"`
+}
+
+// memoryWatchdog monitors the heap usage against the memory limit. Under pressure, it refuses the
+// new calls of the tools returning large results and evicts the caches, so that a spike of large
+// results does not get the server OOM killed.
+type memoryWatchdog struct {
+	limit uint64
+	// heapBytes is replaced in tests.
+	heapBytes func() uint64
+	// evict drops the cached data that can be rebuilt or lost.
+	evict []func()
+
+	pressure atomic.Bool
+}
+
+func newMemoryWatchdog(limit uint64, evict ...func()) *memoryWatchdog {
+	return &memoryWatchdog{
+		limit:     limit,
+		heapBytes: readHeapBytes,
+		evict:     evict,
+	}
+}
+
+func readHeapBytes() uint64 {
+	sample := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// DefaultMemoryLimit returns the memory limit of the process: the soft limit of the Go runtime if set
+// with GOMEMLIMIT, otherwise the limit of the container, zero if there is none.
+func DefaultMemoryLimit() uint64 {
+	if limit := debug.SetMemoryLimit(-1); limit > 0 && limit < math.MaxInt64 {
+		return uint64(limit)
+	}
+	data, err := os.ReadFile(cgroupMemoryMaxFile)
+	if err != nil {
+		return 0
+	}
+	limit, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		// "max" means unlimited.
+		return 0
+	}
+	return limit
+}
+
+func (w *memoryWatchdog) run(ctx context.Context) {
+	if w.limit == 0 {
+		return
+	}
+	ticker := time.NewTicker(memoryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check updates the pressure from the heap usage, evicting the caches when under pressure.
+func (w *memoryWatchdog) check() {
+	heap := w.heapBytes()
+	switch {
+	case heap >= uint64(float64(w.limit)*memoryPressureRatio):
+		if !w.pressure.Swap(true) {
+			slog.Warn("Memory pressure, refusing large list and get calls", "heap_bytes", heap, "limit_bytes", w.limit)
+		}
+		for _, evict := range w.evict {
+			evict()
+		}
+		debug.FreeOSMemory()
+	case heap < uint64(float64(w.limit)*memoryRecoveryRatio):
+		if w.pressure.Swap(false) {
+			slog.Info("Memory pressure relieved", "heap_bytes", heap, "limit_bytes", w.limit)
+		}
+	}
+}
+
+// sheddable returns whether the calls of the tool are refused under memory pressure.
+func sheddable(tool string) bool {
+	return strings.HasSuffix(tool, "_list") || strings.HasSuffix(tool, "_get") || slices.Contains(sheddableTools, tool)
+}
+
+// middleware refuses the calls of the sheddable tools under memory pressure with a server busy error.
+func (w *memoryWatchdog) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			request, ok := req.(*mcp.CallToolRequest)
+			if method != methodCallTool || !ok || !w.pressure.Load() || !sheddable(request.Params.Name) {
+				return next(ctx, method, req)
+			}
+
+			busy := ServerBusy{
+				Code:              "ServerBusy",
+				Message:           fmt.Sprintf("k-mcp is low on memory and refuses %s calls for now, retry later or narrow the call with a namespace, label selector or limit", request.Params.Name),
+				RetryAfterSeconds: int(serverBusyRetryAfter.Seconds()),
+			}
+			slog.WarnContext(ctx, "Refused tool call under memory pressure", "tool", request.Params.Name)
+			return &mcp.CallToolResult{
+				Meta:    mcp.Meta{errorMetaKey: busy},
+				Content: []mcp.Content{&mcp.TextContent{Text: busy.Message}},
+				IsError: true,
+			}, nil
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestMemoryWatchdog(t *testing.T) {
+	evictions := 0
+	watchdog := newMemoryWatchdog(1000, func() { evictions++ })
+
+	// Heap usages in turn, with the expected pressure after each check.
+	steps := []struct {
+		heap      uint64
+		pressure  bool
+		evictions int
+	}{
+		{heap: 500},
+		{heap: 849},
+		{heap: 850, pressure: true, evictions: 1},
+		{heap: 750, pressure: true, evictions: 1},
+		{heap: 900, pressure: true, evictions: 2},
+		{heap: 699, evictions: 2},
+	}
+	for i, step := range steps {
+		watchdog.heapBytes = func() uint64 { return step.heap }
+		watchdog.check()
+		if got := watchdog.pressure.Load(); got != step.pressure {
+			t.Errorf("step %d: expected pressure %v, got %v", i, step.pressure, got)
+		}
+		if evictions != step.evictions {
+			t.Errorf("step %d: expected %d evictions, got %d", i, step.evictions, evictions)
+		}
+	}
+}
+
+func TestMemoryWatchdogMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     string
+		pressure bool
+		refused  bool
+	}{
+		{name: "no pressure", tool: "resource_list"},
+		{name: "list", tool: "resource_list", pressure: true, refused: true},
+		{name: "get", tool: "resource_get", pressure: true, refused: true},
+		{name: "custom resource list", tool: "certificates_list", pressure: true, refused: true},
+		{name: "inventory", tool: "inventory_export", pressure: true, refused: true},
+		{name: "apply", tool: "resource_apply", pressure: true},
+		{name: "preferences", tool: "get_preferences", pressure: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watchdog := newMemoryWatchdog(1000)
+			watchdog.pressure.Store(tt.pressure)
+			called := false
+			handler := watchdog.middleware()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				called = true
+				return &mcp.CallToolResult{}, nil
+			})
+
+			result, err := handler(context.Background(), methodCallTool, &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: tt.tool}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if called == tt.refused {
+				t.Fatalf("expected the call to be refused %v, got called %v", tt.refused, called)
+			}
+			if !tt.refused {
+				return
+			}
+			toolResult := result.(*mcp.CallToolResult)
+			busy, ok := toolResult.Meta[errorMetaKey].(ServerBusy)
+			if !toolResult.IsError || !ok || busy.Code != "ServerBusy" || busy.RetryAfterSeconds <= 0 {
+				t.Errorf("expected a server busy error, got %+v", toolResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	resourceQuotasGVR = schema.GroupVersionResource{Version: "v1", Resource: "resourcequotas"}
+	limitRangesGVR    = schema.GroupVersionResource{Version: "v1", Resource: "limitranges"}
+)
+
+// quotaNearLimitPercent is the usage percentage above which a quota resource is reported as near its limit.
+const quotaNearLimitPercent = 90
+
+type NamespaceQuotasInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"The namespace to inspect"`
+}
+
+type NamespaceQuotasResult struct {
+	Namespace   string           `json:"namespace"`
+	Quotas      []QuotaInfo      `json:"quotas"`
+	LimitRanges []LimitRangeInfo `json:"limitRanges"`
+	Warnings    []string         `json:"warnings,omitempty"`
+}
+
+type QuotaInfo struct {
+	Name      string       `json:"name"`
+	Scopes    []string     `json:"scopes,omitempty"`
+	Resources []QuotaUsage `json:"resources"`
+}
+
+type QuotaUsage struct {
+	Resource    string `json:"resource"`
+	Used        string `json:"used"`
+	Hard        string `json:"hard"`
+	UsedPercent int64  `json:"usedPercent,omitempty"`
+}
+
+type LimitRangeInfo struct {
+	Name   string           `json:"name"`
+	Limits []LimitRangeItem `json:"limits"`
+}
+
+type LimitRangeItem struct {
+	Type                 string            `json:"type"`
+	Default              map[string]string `json:"default,omitempty"`
+	DefaultRequest       map[string]string `json:"defaultRequest,omitempty"`
+	Min                  map[string]string `json:"min,omitempty"`
+	Max                  map[string]string `json:"max,omitempty"`
+	MaxLimitRequestRatio map[string]string `json:"maxLimitRequestRatio,omitempty"`
+}
+
+func (s *Server) addNamespaceQuotasTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "namespace_quotas",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Inspect the resource quotas and limit ranges of a namespace",
+		},
+		Description: "Inspect the ResourceQuota usage against hard limits and the LimitRange defaults of a namespace. Useful to explain why pods can not be created or scheduled",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input NamespaceQuotasInput) (*mcp.CallToolResult, *NamespaceQuotasResult, error) {
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		if input.Namespace == "" {
+			input.Namespace, err = s.sessionNamespace(ctx, request, "resourcequotas")
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		quotaList, err := dynamicClient.Resource(resourceQuotasGVR).Namespace(input.Namespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list resource quotas: %w", err)
+		}
+		limitRangeList, err := dynamicClient.Resource(limitRangesGVR).Namespace(input.Namespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list limit ranges: %w", err)
+		}
+
+		result := &NamespaceQuotasResult{
+			Namespace:   input.Namespace,
+			Quotas:      []QuotaInfo{},
+			LimitRanges: []LimitRangeInfo{},
+		}
+		for _, item := range quotaList.Items {
+			var quota corev1.ResourceQuota
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &quota); err != nil {
+				continue
+			}
+			info, warnings := quotaInfo(&quota)
+			result.Quotas = append(result.Quotas, info)
+			result.Warnings = append(result.Warnings, warnings...)
+		}
+		for _, item := range limitRangeList.Items {
+			var limitRange corev1.LimitRange
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &limitRange); err != nil {
+				continue
+			}
+			result.LimitRanges = append(result.LimitRanges, limitRangeInfo(&limitRange))
+		}
+
+		message := fmt.Sprintf("Namespace %s has %d resource quota(s) and %d limit range(s)", input.Namespace, len(result.Quotas), len(result.LimitRanges))
+		message = textSummary(message, textList(result.Warnings))
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, result, nil
+	})
+}
+
+// quotaInfo returns the usage of the quota, and warnings for the resources that are exhausted or near their limit.
+func quotaInfo(quota *corev1.ResourceQuota) (QuotaInfo, []string) {
+	info := QuotaInfo{
+		Name:      quota.Name,
+		Resources: []QuotaUsage{},
+	}
+	for _, scope := range quota.Spec.Scopes {
+		info.Scopes = append(info.Scopes, string(scope))
+	}
+
+	names := make([]string, 0, len(quota.Status.Hard))
+	for name := range quota.Status.Hard {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, name := range names {
+		hard := quota.Status.Hard[corev1.ResourceName(name)]
+		used := quota.Status.Used[corev1.ResourceName(name)]
+		usage := QuotaUsage{
+			Resource: name,
+			Used:     used.String(),
+			Hard:     hard.String(),
+		}
+		if !hard.IsZero() {
+			usage.UsedPercent = used.MilliValue() * 100 / hard.MilliValue()
+		}
+
+		switch {
+		case hard.Cmp(used) <= 0:
+			warnings = append(warnings, fmt.Sprintf("quota %s: %s is exhausted (%s/%s)", quota.Name, name, usage.Used, usage.Hard))
+		case usage.UsedPercent >= quotaNearLimitPercent:
+			warnings = append(warnings, fmt.Sprintf("quota %s: %s is at %d%% (%s/%s)", quota.Name, name, usage.UsedPercent, usage.Used, usage.Hard))
+		}
+		info.Resources = append(info.Resources, usage)
+	}
+
+	return info, warnings
+}
+
+func limitRangeInfo(limitRange *corev1.LimitRange) LimitRangeInfo {
+	info := LimitRangeInfo{
+		Name:   limitRange.Name,
+		Limits: []LimitRangeItem{},
+	}
+	for _, limit := range limitRange.Spec.Limits {
+		info.Limits = append(info.Limits, LimitRangeItem{
+			Type:                 string(limit.Type),
+			Default:              resourceListToMap(limit.Default),
+			DefaultRequest:       resourceListToMap(limit.DefaultRequest),
+			Min:                  resourceListToMap(limit.Min),
+			Max:                  resourceListToMap(limit.Max),
+			MaxLimitRequestRatio: resourceListToMap(limit.MaxLimitRequestRatio),
+		})
+	}
+	return info
+}
+
+func resourceListToMap(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(list))
+	for name, quantity := range list {
+		m[string(name)] = quantity.String()
+	}
+	return m
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestQuotaInfo(t *testing.T) {
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: v1.ObjectMeta{Name: "compute"},
+		Spec: corev1.ResourceQuotaSpec{
+			Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeNotTerminating},
+		},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{
+				corev1.ResourcePods:           resource.MustParse("10"),
+				corev1.ResourceRequestsCPU:    resource.MustParse("2"),
+				corev1.ResourceRequestsMemory: resource.MustParse("4Gi"),
+			},
+			Used: corev1.ResourceList{
+				corev1.ResourcePods:           resource.MustParse("10"),
+				corev1.ResourceRequestsCPU:    resource.MustParse("1900m"),
+				corev1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}
+
+	info, warnings := quotaInfo(quota)
+	expected := QuotaInfo{
+		Name:   "compute",
+		Scopes: []string{"NotTerminating"},
+		Resources: []QuotaUsage{
+			{Resource: "pods", Used: "10", Hard: "10", UsedPercent: 100},
+			{Resource: "requests.cpu", Used: "1900m", Hard: "2", UsedPercent: 95},
+			{Resource: "requests.memory", Used: "1Gi", Hard: "4Gi", UsedPercent: 25},
+		},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("expected %+v, got %+v", expected, info)
+	}
+
+	expectedWarnings := []string{
+		"quota compute: pods is exhausted (10/10)",
+		"quota compute: requests.cpu is at 95% (1900m/2)",
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("expected warnings %v, got %v", expectedWarnings, warnings)
+	}
+}
+
+func TestLimitRangeInfo(t *testing.T) {
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: v1.ObjectMeta{Name: "defaults"},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type:           corev1.LimitTypeContainer,
+					Default:        corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+					DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+					Max:            corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+			},
+		},
+	}
+
+	info := limitRangeInfo(limitRange)
+	expected := LimitRangeInfo{
+		Name: "defaults",
+		Limits: []LimitRangeItem{
+			{
+				Type:           "Container",
+				Default:        map[string]string{"cpu": "500m"},
+				DefaultRequest: map[string]string{"cpu": "100m"},
+				Max:            map[string]string{"memory": "1Gi"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("expected %+v, got %+v", expected, info)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	objectURIPrefix   = "k8s://"
+	objectURITemplate = objectURIPrefix + "{cluster}/{group}/{version}/{namespace}/{resource}/{name}"
+	// objectURICoreGroup stands for the core API group, which has an empty name.
+	objectURICoreGroup = "core"
+	// objectURIClusterScoped stands for the namespace of cluster scoped objects.
+	objectURIClusterScoped = "-"
+)
+
+// objectURI identifies a Kubernetes object in a cluster.
+type objectURI struct {
+	cluster   string
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+func (u objectURI) String() string {
+	group, namespace := u.gvr.Group, u.namespace
+	if group == "" {
+		group = objectURICoreGroup
+	}
+	if namespace == "" {
+		namespace = objectURIClusterScoped
+	}
+	return objectURIPrefix + strings.Join([]string{u.cluster, group, u.gvr.Version, namespace, u.gvr.Resource, u.name}, "/")
+}
+
+func parseObjectURI(uri string) (objectURI, bool) {
+	rest, ok := strings.CutPrefix(uri, objectURIPrefix)
+	if !ok {
+		return objectURI{}, false
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 6 {
+		return objectURI{}, false
+	}
+	for _, part := range parts {
+		if part == "" {
+			return objectURI{}, false
+		}
+	}
+
+	u := objectURI{
+		cluster:   parts[0],
+		gvr:       schema.GroupVersionResource{Group: parts[1], Version: parts[2], Resource: parts[4]},
+		namespace: parts[3],
+		name:      parts[5],
+	}
+	if u.gvr.Group == objectURICoreGroup {
+		u.gvr.Group = ""
+	}
+	if u.namespace == objectURIClusterScoped {
+		u.namespace = ""
+	}
+	return u, true
+}
+
+// clusterName returns the name of the cluster of the token used in object URIs, the host of its API server.
+func clusterName(tokenInfo *auth.TokenInfo) string {
+	apiServerURL, _ := tokenInfo.Extra["audience"].(string)
+	return clusterHost(apiServerURL)
+}
+
+// clusterHost returns the host of the URL of an API server, naming its cluster.
+func clusterHost(apiServerURL string) string {
+	parsed, err := url.Parse(apiServerURL)
+	if err != nil || parsed.Host == "" {
+		return apiServerURL
+	}
+	return parsed.Host
+}
+
+func (s *Server) addObjectResourceTemplate(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:  "kubernetes-object",
+		Title: "Kubernetes object",
+		Description: "A Kubernetes object of a cluster granted by the token. The cluster is its name or the host of its API server (shown by list_clusters), " +
+			"the group of core resources is `core` and the namespace of cluster scoped objects is `-`, " +
+			"e.g. k8s://api.example.com:6443/apps/v1/default/deployments/web or k8s://api.example.com:6443/core/v1/-/nodes/worker-1",
+		MIMEType:    "application/json",
+		URITemplate: objectURITemplate,
+	}, func(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		uri := request.Params.URI
+		object, ok := parseObjectURI(uri)
+		if !ok || isRestrictedResource(object.gvr) {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		if request.Extra == nil || request.Extra.TokenInfo == nil {
+			return nil, fmt.Errorf("reading Kubernetes objects requires a token")
+		}
+		// The resource reads do not go through the cluster middleware of the tool calls.
+		tokenInfo, err := s.clusters.tokenInfoForCluster(ctx, request.Extra.TokenInfo, object.cluster)
+		if err != nil {
+			return nil, err
+		}
+
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForTokenInfo(tokenInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+		obj, err := dynamicClient.Resource(object.gvr).Namespace(object.namespace).Get(ctx, object.name, v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", uri, err)
+		}
+
+		s.redactor.redactValue(obj.Object)
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", uri, err)
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{
+				{
+					URI:      uri,
+					MIMEType: "application/json",
+					Text:     string(data),
+				},
+			},
+		}, nil
+	})
+}
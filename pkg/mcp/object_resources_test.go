@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestObjectURI(t *testing.T) {
+	tests := []struct {
+		uri      string
+		expected objectURI
+	}{
+		{
+			uri: "k8s://api.example.com:6443/apps/v1/default/deployments/web",
+			expected: objectURI{
+				cluster:   "api.example.com:6443",
+				gvr:       schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				namespace: "default",
+				name:      "web",
+			},
+		},
+		{
+			uri: "k8s://api.example.com:6443/core/v1/-/nodes/worker-1",
+			expected: objectURI{
+				cluster: "api.example.com:6443",
+				gvr:     schema.GroupVersionResource{Version: "v1", Resource: "nodes"},
+				name:    "worker-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			object, ok := parseObjectURI(tt.uri)
+			if !ok {
+				t.Fatalf("failed to parse %s", tt.uri)
+			}
+			if object != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, object)
+			}
+			if uri := object.String(); uri != tt.uri {
+				t.Errorf("expected %s, got %s", tt.uri, uri)
+			}
+		})
+	}
+
+	for _, uri := range []string{
+		"k8s://api.example.com/apps/v1/default/deployments",
+		"k8s://api.example.com/apps/v1/default/deployments/web/scale",
+		"k8s://api.example.com//v1/default/pods/web",
+		"k-mcp://sessions/local/queries/web",
+	} {
+		if _, ok := parseObjectURI(uri); ok {
+			t.Errorf("expected %s to be invalid", uri)
+		}
+	}
+}
+
+func TestClusterName(t *testing.T) {
+	for apiServerURL, expected := range map[string]string{
+		"https://api.example.com:6443": "api.example.com:6443",
+		"https://10.0.0.1":             "10.0.0.1",
+		"api.example.com":              "api.example.com",
+	} {
+		tokenInfo := &auth.TokenInfo{Extra: map[string]any{"audience": apiServerURL}}
+		if cluster := clusterName(tokenInfo); cluster != expected {
+			t.Errorf("%s: expected %s, got %s", apiServerURL, expected, cluster)
+		}
+	}
+}
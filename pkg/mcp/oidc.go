@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// oidcDiscoveryPath is the path of the OpenID Provider configuration under the issuer URL.
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+// oidcConfiguration is the part of the OpenID Provider configuration used to verify the tokens.
+type oidcConfiguration struct {
+	Issuer            string   `json:"issuer"`
+	JWKSURI           string   `json:"jwks_uri"`
+	SigningAlgorithms []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// oidcVerifier verifies the tokens of trusted issuers, resolving the key set and the signing algorithms
+// of every issuer with OpenID Connect discovery, e.g. the service account issuer of a cluster.
+type oidcVerifier struct {
+	issuers map[string]*oidcIssuer
+}
+
+// newOIDCVerifier returns the verifier of the tokens of the issuers signed with one of the algorithms.
+func newOIDCVerifier(issuers, algorithms []string) *oidcVerifier {
+	v := &oidcVerifier{issuers: make(map[string]*oidcIssuer, len(issuers))}
+	for _, issuer := range issuers {
+		v.issuers[issuer] = &oidcIssuer{
+			issuer:  issuer,
+			allowed: algorithms,
+			client:  &http.Client{Timeout: 10 * time.Second},
+		}
+	}
+	return v
+}
+
+// parse verifies the token with the keys of its issuer, which must be trusted.
+func (v *oidcVerifier) parse(ctx context.Context, tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, &jwt.RegisteredClaims{})
+	if err != nil {
+		return nil, err
+	}
+	iss, _ := unverified.Claims.GetIssuer()
+	issuer, ok := v.issuers[iss]
+	if !ok {
+		return nil, fmt.Errorf("issuer %q is not trusted", iss)
+	}
+	keySet, algorithms, err := issuer.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	parser := jwt.NewParser(jwt.WithValidMethods(algorithms), jwt.WithExpirationRequired(), jwt.WithIssuer(iss))
+	return parser.ParseWithClaims(tokenString, claims, keySet.keyfunc(ctx))
+}
+
+// oidcIssuer is a trusted issuer, whose configuration is discovered on first use.
+type oidcIssuer struct {
+	issuer string
+	// allowed are the algorithms accepted whatever the issuer advertises.
+	allowed []string
+	client  *http.Client
+
+	mu         sync.Mutex
+	keySet     *jwksKeySet
+	algorithms []string
+	attempted  time.Time
+}
+
+// resolve returns the key set and the signing algorithms of the issuer, discovering them if needed.
+// Failed discoveries are retried at most once a minute.
+func (i *oidcIssuer) resolve(ctx context.Context) (*jwksKeySet, []string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.keySet != nil {
+		return i.keySet, i.algorithms, nil
+	}
+	if time.Since(i.attempted) < jwksMinRefreshInterval {
+		return nil, nil, fmt.Errorf("OpenID configuration of issuer %s is not available yet", i.issuer)
+	}
+	i.attempted = time.Now()
+
+	config, err := i.discover(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	i.keySet = newJWKSKeySet(config.JWKSURI)
+	i.algorithms = oidcSigningMethods(config.SigningAlgorithms, i.allowed)
+	return i.keySet, i.algorithms, nil
+}
+
+func (i *oidcIssuer) discover(ctx context.Context) (*oidcConfiguration, error) {
+	url := strings.TrimSuffix(i.issuer, "/") + oidcDiscoveryPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the OpenID configuration of issuer %s: %w", i.issuer, err)
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the OpenID configuration of issuer %s: %w", i.issuer, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to discover the OpenID configuration of issuer %s: %s returned %s", i.issuer, url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the OpenID configuration of issuer %s: %w", i.issuer, err)
+	}
+
+	var config oidcConfiguration
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid OpenID configuration of issuer %s: %w", i.issuer, err)
+	}
+	// The issuer of the configuration must be the one it was discovered from (OpenID Connect Discovery 1.0, 4.3).
+	if config.Issuer != i.issuer {
+		return nil, fmt.Errorf("OpenID configuration of issuer %s is for issuer %q", i.issuer, config.Issuer)
+	}
+	if config.JWKSURI == "" {
+		return nil, fmt.Errorf("OpenID configuration of issuer %s has no jwks_uri", i.issuer)
+	}
+	return &config, nil
+}
+
+// oidcSigningMethods returns the allowed algorithms supported by the issuer, all of them when the
+// issuer does not advertise any of them.
+func oidcSigningMethods(advertised, allowed []string) []string {
+	var methods []string
+	for _, method := range advertised {
+		if slices.Contains(allowed, method) {
+			methods = append(methods, method)
+		}
+	}
+	if len(methods) == 0 {
+		return allowed
+	}
+	return methods
+}
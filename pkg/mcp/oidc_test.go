@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newOIDCTestIssuer serves the OpenID configuration and the key set of an issuer.
+func newOIDCTestIssuer(t *testing.T, algorithms []string, keys ...map[string]any) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                server.URL,
+			"jwks_uri":                              server.URL + "/openid/v1/jwks",
+			"id_token_signing_alg_values_supported": algorithms,
+		})
+	})
+	mux.HandleFunc("/openid/v1/jwks", func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOIDCVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := newOIDCTestIssuer(t, []string{"RS256"}, rsaJWK("rsa", &rsaKey.PublicKey))
+	second := newOIDCTestIssuer(t, nil, ecJWK("ec", &ecKey.PublicKey))
+	untrusted := newOIDCTestIssuer(t, []string{"RS256"}, rsaJWK("rsa", &rsaKey.PublicKey))
+	verifier := newOIDCVerifier([]string{first.URL, second.URL}, jwksSigningMethods)
+
+	sign := func(method jwt.SigningMethod, issuer, kid string, key any) string {
+		token := jwt.NewWithClaims(method, &JWTClaims{RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "first issuer", token: sign(jwt.SigningMethodRS256, first.URL, "rsa", rsaKey)},
+		{name: "second issuer", token: sign(jwt.SigningMethodES256, second.URL, "ec", ecKey)},
+		{name: "algorithm not supported by the issuer", token: sign(jwt.SigningMethodPS256, first.URL, "rsa", rsaKey), wantErr: true},
+		{name: "key of another issuer", token: sign(jwt.SigningMethodRS256, second.URL, "rsa", rsaKey), wantErr: true},
+		{name: "untrusted issuer", token: sign(jwt.SigningMethodRS256, untrusted.URL, "rsa", rsaKey), wantErr: true},
+		{name: "malformed token", token: "not-a-token", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.parse(context.Background(), tt.token, &JWTClaims{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOIDCIssuerDiscovery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]any{"issuer": "https://impostor.example.com", "jwks_uri": "https://impostor.example.com/jwks"})
+	}))
+	defer server.Close()
+
+	issuer := newOIDCVerifier([]string{server.URL}, jwksSigningMethods).issuers[server.URL]
+	if _, _, err := issuer.resolve(context.Background()); err == nil {
+		t.Fatalf("expected error for a configuration of another issuer")
+	}
+	// Failed discoveries are not retried for every token.
+	if _, _, err := issuer.resolve(context.Background()); err == nil {
+		t.Fatalf("expected error before the configuration is discovered again")
+	}
+}
+
+func TestOIDCSigningMethods(t *testing.T) {
+	tests := []struct {
+		name       string
+		advertised []string
+		allowed    []string
+		expected   []string
+	}{
+		{name: "not advertised", allowed: jwksSigningMethods, expected: jwksSigningMethods},
+		{name: "symmetric skipped", advertised: []string{"HS256", "RS256", "ES256"}, allowed: jwksSigningMethods, expected: []string{"RS256", "ES256"}},
+		{name: "none supported", advertised: []string{"HS256", "none"}, allowed: jwksSigningMethods, expected: jwksSigningMethods},
+		{name: "pinned", advertised: []string{"RS256", "ES256"}, allowed: []string{"ES256"}, expected: []string{"ES256"}},
+		{name: "pinned not advertised", advertised: []string{"RS256"}, allowed: []string{"ES256"}, expected: []string{"ES256"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := oidcSigningMethods(tt.advertised, tt.allowed); !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	OperationRunning   = "Running"
+	OperationSucceeded = "Succeeded"
+	OperationFailed    = "Failed"
+	OperationCancelled = "Cancelled"
+
+	// operationsLogger is the logger of the notifications sent when operations complete.
+	operationsLogger = "k-mcp/operations"
+	// maxOperationsPerSession bounds the operations, running or completed, kept for a single session.
+	maxOperationsPerSession = 20
+)
+
+type OperationStatusInput struct {
+	ID string `json:"id,omitempty" jsonschema:"The ID of the operation (optional, defaults to every operation of the session)"`
+}
+
+type OperationStatusResult struct {
+	Operations []Operation `json:"operations"`
+}
+
+type OperationCancelInput struct {
+	ID string `json:"id,required" jsonschema:"The ID of the operation to cancel"`
+}
+
+// Operation is a tool call run in the background, whose result is kept once it completes.
+type Operation struct {
+	ID             string     `json:"id"`
+	Tool           string     `json:"tool"`
+	Status         string     `json:"status"`
+	StartTime      time.Time  `json:"startTime"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+	// Message is the text of the result of the tool call, or its error.
+	Message string `json:"message,omitempty"`
+	// Result is the structured result of the tool call.
+	Result any `json:"result,omitempty"`
+}
+
+type operation struct {
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	cancelled bool
+	state     Operation
+}
+
+func (o *operation) snapshot() Operation {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.state
+}
+
+// complete records the result of the tool call, and returns the completed operation.
+func (o *operation) complete(result *mcp.CallToolResult, structured any, err error) Operation {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.state.CompletionTime = ptr.To(time.Now().UTC())
+	switch {
+	case o.cancelled:
+		o.state.Status = OperationCancelled
+	case err != nil:
+		o.state.Status = OperationFailed
+	default:
+		o.state.Status = OperationSucceeded
+	}
+	if err != nil {
+		o.state.Message = err.Error()
+		return o.state
+	}
+	if result != nil {
+		var texts []string
+		for _, content := range result.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				texts = append(texts, text.Text)
+			}
+		}
+		o.state.Message = strings.Join(texts, "\n")
+	}
+	o.state.Result = structured
+	return o.state
+}
+
+// operations are the tool calls run in the background on behalf of the sessions, with the
+// credentials of the session. Operations are cancelled and forgotten once their session ends.
+type operations struct {
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession][]*operation
+	// completed is called with the outcome of the tool calls of the operations once they complete, if set.
+	completed func(ctx context.Context, request *mcp.CallToolRequest, result *mcp.CallToolResult, structured any, err error, completed Operation)
+}
+
+func newOperations() *operations {
+	return &operations{sessions: map[*mcp.ServerSession][]*operation{}}
+}
+
+// start runs a tool call in the background, limited to the timeout if any, and returns the
+// operation following it. The session is notified with a log message once the operation completes.
+func (o *operations) start(ctx context.Context, request *mcp.CallToolRequest, timeout time.Duration,
+	run func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, any, error)) (Operation, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	session := request.Session
+	running, ok := o.sessions[session]
+	if !ok {
+		go func() {
+			//nolint:errcheck
+			session.Wait()
+			o.stopSession(session)
+		}()
+	}
+	if len(running) >= maxOperationsPerSession {
+		// Forget the oldest completed operation to make room for the new one.
+		i := slices.IndexFunc(running, func(op *operation) bool {
+			return op.snapshot().Status != OperationRunning
+		})
+		if i < 0 {
+			return Operation{}, fmt.Errorf("at most %d operations can run per session", maxOperationsPerSession)
+		}
+		running = slices.Delete(running, i, i+1)
+	}
+
+	// The operation outlives the tool call starting it, but keeps the values of its context.
+	ctx = context.WithoutCancel(ctx)
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	op := &operation{
+		cancel: cancel,
+		state: Operation{
+			ID:        uuid.NewString(),
+			Tool:      request.Params.Name,
+			Status:    OperationRunning,
+			StartTime: time.Now().UTC(),
+		},
+	}
+	o.sessions[session] = append(running, op)
+
+	// Progress can not be reported once the tool call starting the operation returned.
+	background := *request
+	params := *request.Params
+	params.Meta = nil
+	background.Params = &params
+
+	go func() {
+		defer cancel()
+		result, structured, err := run(ctx, &background)
+		completed := op.complete(result, structured, err)
+		if o.completed != nil {
+			o.completed(ctx, &background, result, structured, err, completed)
+		}
+		level := mcp.LoggingLevel("info")
+		if completed.Status != OperationSucceeded {
+			level = "warning"
+		}
+		err = session.Log(context.Background(), &mcp.LoggingMessageParams{
+			Level:  level,
+			Logger: operationsLogger,
+			Data: map[string]any{
+				"id":      completed.ID,
+				"tool":    completed.Tool,
+				"status":  completed.Status,
+				"message": completed.Message,
+			},
+		})
+		if err != nil {
+			slog.Debug("Failed to notify the completion of an operation", "session_id", session.ID(), "operation", completed.ID, "err", err)
+		}
+	}()
+	return op.snapshot(), nil
+}
+
+// get returns the operations of the session, or the one with the ID if not empty.
+func (o *operations) get(session *mcp.ServerSession, id string) ([]Operation, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	result := []Operation{}
+	for _, op := range o.sessions[session] {
+		state := op.snapshot()
+		if id == "" || state.ID == id {
+			result = append(result, state)
+		}
+	}
+	if id != "" && len(result) == 0 {
+		return nil, fmt.Errorf("operation %s not found", id)
+	}
+	return result, nil
+}
+
+// cancel cancels a running operation of the session, and returns it. The operation is
+// completed once the tool call stopped.
+func (o *operations) cancel(session *mcp.ServerSession, id string) (Operation, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, op := range o.sessions[session] {
+		op.mu.Lock()
+		if op.state.ID != id {
+			op.mu.Unlock()
+			continue
+		}
+		if op.state.Status == OperationRunning {
+			op.cancelled = true
+		}
+		state := op.state
+		op.mu.Unlock()
+		op.cancel()
+		return state, nil
+	}
+	return Operation{}, fmt.Errorf("operation %s not found", id)
+}
+
+func (o *operations) stopSession(session *mcp.ServerSession) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, op := range o.sessions[session] {
+		op.cancel()
+	}
+	delete(o.sessions, session)
+}
+
+// evictResults drops the structured results of the completed operations, keeping their status and
+// message, to free memory under pressure.
+func (o *operations) evictResults() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, running := range o.sessions {
+		for _, op := range running {
+			op.mu.Lock()
+			if op.state.Status != OperationRunning {
+				op.state.Result = nil
+			}
+			op.mu.Unlock()
+		}
+	}
+}
+
+func (s *Server) addOperationTools(server *mcp.Server) {
+	addTool(server, s.clusterlessTool(&mcp.Tool{
+		Name: "operation_status",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(false),
+			ReadOnlyHint:    true,
+			Title:           "Get the status of background operations",
+		},
+		Description: "Get the status of the operations started by the session with async, and their result once completed. " +
+			"Operations are Running, Succeeded, Failed or Cancelled",
+	}), func(ctx context.Context, request *mcp.CallToolRequest, input OperationStatusInput) (*mcp.CallToolResult, *OperationStatusResult, error) {
+		operations, err := s.operations.get(request.Session, input.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		message := "No operation was started by the session"
+		if len(operations) > 0 {
+			table := newTextTable("ID", "TOOL", "STATUS", "STARTED")
+			var results []string
+			for _, op := range operations {
+				table.addRow(op.ID, op.Tool, op.Status, op.StartTime.Format(time.RFC3339))
+				if op.Message != "" {
+					results = append(results, fmt.Sprintf("Operation %s:\n%s", op.ID, op.Message))
+				}
+			}
+			message = textSummary(fmt.Sprintf("Found %d operation(s)", len(operations)), append([]string{table.String()}, results...)...)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, &OperationStatusResult{Operations: operations}, nil
+	})
+
+	addTool(server, s.clusterlessTool(&mcp.Tool{
+		Name: "operation_cancel",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(false),
+			ReadOnlyHint:    false,
+			Title:           "Cancel a background operation",
+		},
+		Description: "Cancel a running operation started by the session with async. The changes already made by the operation are kept",
+	}), func(ctx context.Context, request *mcp.CallToolRequest, input OperationCancelInput) (*mcp.CallToolResult, *Operation, error) {
+		op, err := s.operations.cancel(request.Session, input.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		message := fmt.Sprintf("Cancelling operation %s, check operation_status for its completion", op.ID)
+		if op.Status != OperationRunning {
+			message = fmt.Sprintf("Operation %s already completed: %s", op.ID, op.Status)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, &op, nil
+	})
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestOperations(t *testing.T) {
+	ctx := context.Background()
+
+	s := NewServer("8080", "k-mcp")
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	s.addOperationTools(server)
+	completedOperations := make(chan Operation, 2)
+	s.operations.completed = func(_ context.Context, _ *mcp.CallToolRequest, _ *mcp.CallToolResult, _ any, _ error, completed Operation) {
+		completedOperations <- completed
+	}
+	release := make(chan struct{})
+	mcp.AddTool(server, &mcp.Tool{Name: "long"}, func(ctx context.Context, request *mcp.CallToolRequest, input struct {
+		Block bool `json:"block"`
+	}) (*mcp.CallToolResult, any, error) {
+		op, err := s.operations.start(ctx, request, 0, func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, any, error) {
+			if !input.Block {
+				<-release
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "applied"}}}, map[string]any{"applied": 1}, nil
+			}
+			<-ctx.Done()
+			return nil, nil, fmt.Errorf("stopped: %w", ctx.Err())
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: op.ID}}}, nil, nil
+	})
+
+	notifications := make(chan *mcp.LoggingMessageParams, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, request *mcp.LoggingMessageRequest) {
+			notifications <- request.Params
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+	if err := clientSession.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := func(block bool) string {
+		result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "long", Arguments: map[string]any{"block": block}})
+		if err != nil || result.IsError {
+			t.Fatalf("unexpected error: %v %v", err, result)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+	status := func(id string) []Operation {
+		result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "operation_status", Arguments: map[string]any{"id": id}})
+		if err != nil || result.IsError {
+			t.Fatalf("unexpected error: %v %v", err, result)
+		}
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var statusResult OperationStatusResult
+		if err := json.Unmarshal(data, &statusResult); err != nil {
+			t.Fatal(err)
+		}
+		return statusResult.Operations
+	}
+	completion := func() *mcp.LoggingMessageParams {
+		select {
+		case n := <-notifications:
+			return n
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected a completion notification")
+			return nil
+		}
+	}
+
+	applying := start(false)
+	if operations := status(applying); len(operations) != 1 || operations[0].Status != OperationRunning || operations[0].Tool != "long" {
+		t.Errorf("expected a running operation, got %+v", operations)
+	}
+	close(release)
+	if n := completion(); n.Logger != operationsLogger || n.Level != "info" || n.Data.(map[string]any)["id"] != applying {
+		t.Errorf("unexpected notification %+v", n)
+	}
+	if completed := <-completedOperations; completed.ID != applying || completed.Status != OperationSucceeded {
+		t.Errorf("expected the completion of the operation to be reported, got %+v", completed)
+	}
+	if operations := status(applying); operations[0].Status != OperationSucceeded || operations[0].Message != "applied" || operations[0].CompletionTime == nil {
+		t.Errorf("expected a succeeded operation, got %+v", operations)
+	}
+
+	blocked := start(true)
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "operation_cancel", Arguments: map[string]any{"id": blocked}})
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected error: %v %v", err, result)
+	}
+	if n := completion(); n.Level != "warning" || n.Data.(map[string]any)["status"] != OperationCancelled {
+		t.Errorf("unexpected notification %+v", n)
+	}
+	if completed := <-completedOperations; completed.ID != blocked || completed.Status != OperationCancelled {
+		t.Errorf("expected the cancellation of the operation to be reported, got %+v", completed)
+	}
+	if operations := status(""); len(operations) != 2 || operations[1].Status != OperationCancelled {
+		t.Errorf("expected the cancelled operation, got %+v", operations)
+	}
+
+	if result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "operation_cancel", Arguments: map[string]any{"id": "unknown"}}); err != nil || !result.IsError {
+		t.Errorf("expected error cancelling an unknown operation")
+	}
+}
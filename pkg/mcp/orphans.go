@@ -0,0 +1,297 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	jobsGVR     = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	cronJobsGVR = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}
+)
+
+// defaultOrphanMinAge is the default age below which finished, evicted or superseded
+// resources are not reported, so that they can still be inspected.
+const defaultOrphanMinAge = time.Hour
+
+// Categories of the cleanup candidates reported by find_orphans.
+const (
+	orphanDanglingOwner        = "DanglingOwner"
+	orphanFinishedJob          = "FinishedJob"
+	orphanSupersededReplicaSet = "SupersededReplicaSet"
+	orphanEvictedPod           = "EvictedPod"
+)
+
+// ownerKinds are the owner kinds whose existence is verified. References to other kinds
+// (e.g. custom resources of operators) are not reported as dangling.
+var ownerKinds = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "Deployment"}:  true,
+	{Group: "apps", Kind: "StatefulSet"}: true,
+	{Group: "apps", Kind: "DaemonSet"}:   true,
+	{Group: "apps", Kind: "ReplicaSet"}:  true,
+	{Group: "batch", Kind: "Job"}:        true,
+	{Group: "batch", Kind: "CronJob"}:    true,
+}
+
+type FindOrphansInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"The namespace to search (optional defaults to all namespaces)"`
+	OlderThan string `json:"olderThan,omitempty" jsonschema:"Only report Jobs finished, and ReplicaSets and evicted pods created, longer ago than this duration (e.g. 24h, optional defaults to 1h)"`
+}
+
+type FindOrphansResult struct {
+	Orphans []OrphanedResource `json:"orphans"`
+}
+
+// OrphanedResource is a resource that is likely safe to clean up.
+type OrphanedResource struct {
+	Category  string `json:"category"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Age       string `json:"age"`
+	Reason    string `json:"reason"`
+}
+
+// orphanInventory holds the objects find_orphans evaluates.
+type orphanInventory struct {
+	pods        []corev1.Pod
+	replicaSets []appsv1.ReplicaSet
+	deployments []appsv1.Deployment
+	jobs        []batchv1.Job
+	// owners are the UIDs of all objects of the ownerKinds.
+	owners map[types.UID]bool
+}
+
+func (s *Server) addFindOrphansTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "find_orphans",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Find orphaned and stale resources",
+		},
+		Description: "Find cleanup candidates: Pods, ReplicaSets and Jobs whose owners no longer exist, finished Jobs that are not cleaned up by a TTL or a CronJob history limit, superseded ReplicaSets scaled to zero and Evicted pods. Nothing is deleted, the candidates are meant to be reviewed by the user",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input FindOrphansInput) (*mcp.CallToolResult, *FindOrphansResult, error) {
+		minAge := defaultOrphanMinAge
+		if input.OlderThan != "" {
+			var err error
+			minAge, err = time.ParseDuration(input.OlderThan)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid olderThan %q: %w", input.OlderThan, err)
+			}
+		}
+
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		lists := make(map[schema.GroupVersionResource][]unstructured.Unstructured)
+		for _, gvr := range []schema.GroupVersionResource{podsGVR, replicaSetsGVR, deploymentsGVR, statefulSetsGVR, daemonSetsGVR, jobsGVR, cronJobsGVR} {
+			list, err := dynamicClient.Resource(gvr).Namespace(input.Namespace).List(ctx, v1.ListOptions{})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+			}
+			lists[gvr] = list.Items
+		}
+
+		inventory := orphanInventory{
+			pods:        fromUnstructuredList[corev1.Pod](lists[podsGVR]),
+			replicaSets: fromUnstructuredList[appsv1.ReplicaSet](lists[replicaSetsGVR]),
+			deployments: fromUnstructuredList[appsv1.Deployment](lists[deploymentsGVR]),
+			jobs:        fromUnstructuredList[batchv1.Job](lists[jobsGVR]),
+			owners:      make(map[types.UID]bool),
+		}
+		for gvr, items := range lists {
+			if gvr == podsGVR {
+				continue
+			}
+			for _, item := range items {
+				inventory.owners[item.GetUID()] = true
+			}
+		}
+
+		orphans := findOrphans(&inventory, time.Now(), minAge)
+
+		var message string
+		if len(orphans) == 0 {
+			message = "No orphaned or stale resources found"
+		} else {
+			counts := make(map[string]int)
+			for _, orphan := range orphans {
+				counts[orphan.Category]++
+			}
+			var summary []string
+			for _, category := range []string{orphanDanglingOwner, orphanFinishedJob, orphanSupersededReplicaSet, orphanEvictedPod} {
+				if counts[category] > 0 {
+					summary = append(summary, fmt.Sprintf("%d %s", counts[category], category))
+				}
+			}
+			table := newTextTable("CATEGORY", "KIND", "NAMESPACE", "NAME", "AGE", "REASON")
+			for _, orphan := range orphans {
+				table.addRow(orphan.Category, orphan.Kind, orphan.Namespace, orphan.Name, orphan.Age, orphan.Reason)
+			}
+			message = textSummary(fmt.Sprintf("Found %d cleanup candidates (%s). Nothing was deleted, review them before removing", len(orphans), strings.Join(summary, ", ")), table.String())
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, &FindOrphansResult{Orphans: orphans}, nil
+	})
+}
+
+// fromUnstructuredList converts the items to their typed objects, skipping the ones that can not be converted.
+func fromUnstructuredList[T any](items []unstructured.Unstructured) []T {
+	objs := make([]T, 0, len(items))
+	for _, item := range items {
+		var obj T
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &obj); err != nil {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	return objs
+}
+
+// findOrphans returns the cleanup candidates of the inventory sorted by category, namespace and name.
+func findOrphans(inventory *orphanInventory, now time.Time, minAge time.Duration) []OrphanedResource {
+	orphans := []OrphanedResource{}
+	add := func(category, kind string, meta *v1.ObjectMeta, since time.Time, reason string) {
+		orphans = append(orphans, OrphanedResource{
+			Category:  category,
+			Kind:      kind,
+			Name:      meta.Name,
+			Namespace: meta.Namespace,
+			Age:       duration.HumanDuration(now.Sub(since)),
+			Reason:    reason,
+		})
+	}
+	danglingOwner := func(meta *v1.ObjectMeta) string {
+		for _, ref := range meta.OwnerReferences {
+			gv, err := schema.ParseGroupVersion(ref.APIVersion)
+			if err != nil || !ownerKinds[gv.WithKind(ref.Kind).GroupKind()] {
+				continue
+			}
+			if !inventory.owners[ref.UID] {
+				return fmt.Sprintf("owner %s/%s no longer exists", strings.ToLower(ref.Kind), ref.Name)
+			}
+		}
+		return ""
+	}
+
+	for i := range inventory.pods {
+		pod := &inventory.pods[i]
+		if reason := danglingOwner(&pod.ObjectMeta); reason != "" {
+			add(orphanDanglingOwner, "Pod", &pod.ObjectMeta, pod.CreationTimestamp.Time, reason)
+			continue
+		}
+		if pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == "Evicted" && now.Sub(pod.CreationTimestamp.Time) >= minAge {
+			add(orphanEvictedPod, "Pod", &pod.ObjectMeta, pod.CreationTimestamp.Time, fmt.Sprintf("evicted: %s", pod.Status.Message))
+		}
+	}
+
+	deploymentRevisions := make(map[types.UID]string)
+	for _, deployment := range inventory.deployments {
+		deploymentRevisions[deployment.UID] = deployment.Annotations["deployment.kubernetes.io/revision"]
+	}
+	for i := range inventory.replicaSets {
+		rs := &inventory.replicaSets[i]
+		if reason := danglingOwner(&rs.ObjectMeta); reason != "" {
+			add(orphanDanglingOwner, "ReplicaSet", &rs.ObjectMeta, rs.CreationTimestamp.Time, reason)
+			continue
+		}
+		owner := v1.GetControllerOf(rs)
+		if owner == nil || owner.Kind != "Deployment" || ptr.Deref(rs.Spec.Replicas, 1) != 0 || rs.Status.Replicas != 0 {
+			continue
+		}
+		revision, current := rs.Annotations["deployment.kubernetes.io/revision"], deploymentRevisions[owner.UID]
+		if current == "" || revision == current || now.Sub(rs.CreationTimestamp.Time) < minAge {
+			continue
+		}
+		add(orphanSupersededReplicaSet, "ReplicaSet", &rs.ObjectMeta, rs.CreationTimestamp.Time,
+			fmt.Sprintf("revision %s of deployment/%s is superseded by revision %s, it is only kept for rollbacks by the revisionHistoryLimit", revision, owner.Name, current))
+	}
+
+	for i := range inventory.jobs {
+		job := &inventory.jobs[i]
+		if reason := danglingOwner(&job.ObjectMeta); reason != "" {
+			add(orphanDanglingOwner, "Job", &job.ObjectMeta, job.CreationTimestamp.Time, reason)
+			continue
+		}
+		// Jobs removed by the TTL controller or the history limits of their CronJob are cleaned up already.
+		if job.Spec.TTLSecondsAfterFinished != nil {
+			continue
+		}
+		if owner := v1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+			continue
+		}
+		condition, finishedAt := jobFinished(job)
+		if condition == "" || now.Sub(finishedAt) < minAge {
+			continue
+		}
+		add(orphanFinishedJob, "Job", &job.ObjectMeta, job.CreationTimestamp.Time,
+			fmt.Sprintf("%s %s ago", strings.ToLower(condition), duration.HumanDuration(now.Sub(finishedAt))))
+	}
+
+	sort.SliceStable(orphans, func(i, j int) bool {
+		if orphans[i].Category != orphans[j].Category {
+			return orphans[i].Category < orphans[j].Category
+		}
+		if orphans[i].Namespace != orphans[j].Namespace {
+			return orphans[i].Namespace < orphans[j].Namespace
+		}
+		return orphans[i].Name < orphans[j].Name
+	})
+	return orphans
+}
+
+// jobFinished returns the terminal condition of the job and when it was reached,
+// or an empty condition when the job is still running.
+func jobFinished(job *batchv1.Job) (string, time.Time) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		if condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed {
+			return string(condition.Type), condition.LastTransitionTime.Time
+		}
+	}
+	return "", time.Time{}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func TestFindOrphans(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	created := v1.NewTime(now.Add(-48 * time.Hour))
+	meta := func(name string, owner *v1.OwnerReference) v1.ObjectMeta {
+		m := v1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: created}
+		if owner != nil {
+			m.OwnerReferences = []v1.OwnerReference{*owner}
+		}
+		return m
+	}
+	owner := func(apiVersion, kind, name, uid string) *v1.OwnerReference {
+		return &v1.OwnerReference{APIVersion: apiVersion, Kind: kind, Name: name, UID: types.UID(uid), Controller: ptr.To(true)}
+	}
+	replicaSet := func(name, revision string, replicas int32) appsv1.ReplicaSet {
+		rs := appsv1.ReplicaSet{
+			ObjectMeta: meta(name, owner("apps/v1", "Deployment", "web", "web-uid")),
+			Spec:       appsv1.ReplicaSetSpec{Replicas: ptr.To(replicas)},
+			Status:     appsv1.ReplicaSetStatus{Replicas: replicas},
+		}
+		rs.UID = types.UID(name + "-uid")
+		rs.Annotations = map[string]string{"deployment.kubernetes.io/revision": revision}
+		return rs
+	}
+	job := func(name string, owner *v1.OwnerReference, conditionType batchv1.JobConditionType, finished time.Duration) batchv1.Job {
+		return batchv1.Job{
+			ObjectMeta: meta(name, owner),
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+				Type:               conditionType,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: v1.NewTime(now.Add(-finished)),
+			}}},
+		}
+	}
+
+	deployment := appsv1.Deployment{ObjectMeta: meta("web", nil)}
+	deployment.UID = "web-uid"
+	deployment.Annotations = map[string]string{"deployment.kubernetes.io/revision": "3"}
+
+	evicted := corev1.Pod{
+		ObjectMeta: meta("evicted", nil),
+		Status:     corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted", Message: "The node was low on resource: memory."},
+	}
+	ttlJob := job("ttl", nil, batchv1.JobComplete, 2*time.Hour)
+	ttlJob.Spec.TTLSecondsAfterFinished = ptr.To[int32](3600)
+
+	inventory := &orphanInventory{
+		pods: []corev1.Pod{
+			evicted,
+			{ObjectMeta: meta("web-3-abcde", owner("apps/v1", "ReplicaSet", "web-3", "web-3-uid"))},
+			{ObjectMeta: meta("gone-abcde", owner("apps/v1", "ReplicaSet", "gone", "gone-uid"))},
+			{ObjectMeta: meta("operator-0", owner("example.com/v1", "Database", "db", "db-uid"))},
+		},
+		replicaSets: []appsv1.ReplicaSet{replicaSet("web-1", "1", 0), replicaSet("web-3", "3", 0), replicaSet("web-2", "2", 1)},
+		deployments: []appsv1.Deployment{deployment},
+		jobs: []batchv1.Job{
+			job("migrate", nil, batchv1.JobFailed, 3*time.Hour),
+			job("recent", nil, batchv1.JobComplete, 10*time.Minute),
+			job("nightly-123", owner("batch/v1", "CronJob", "nightly", "nightly-uid"), batchv1.JobComplete, 5*time.Hour),
+			ttlJob,
+		},
+		owners: map[types.UID]bool{"web-uid": true, "web-1-uid": true, "web-2-uid": true, "web-3-uid": true, "nightly-uid": true},
+	}
+
+	expected := []OrphanedResource{
+		{Category: orphanDanglingOwner, Kind: "Pod", Name: "gone-abcde", Namespace: "default", Age: "2d", Reason: "owner replicaset/gone no longer exists"},
+		{Category: orphanEvictedPod, Kind: "Pod", Name: "evicted", Namespace: "default", Age: "2d", Reason: "evicted: The node was low on resource: memory."},
+		{Category: orphanFinishedJob, Kind: "Job", Name: "migrate", Namespace: "default", Age: "2d", Reason: "failed 3h ago"},
+		{Category: orphanSupersededReplicaSet, Kind: "ReplicaSet", Name: "web-1", Namespace: "default", Age: "2d",
+			Reason: "revision 1 of deployment/web is superseded by revision 3, it is only kept for rollbacks by the revisionHistoryLimit"},
+	}
+	if orphans := findOrphans(inventory, now, time.Hour); !reflect.DeepEqual(orphans, expected) {
+		t.Errorf("expected %+v, got %+v", expected, orphans)
+	}
+
+	// Nothing is old enough with a larger minimum age, but dangling owners are always reported.
+	if orphans := findOrphans(inventory, now, 72*time.Hour); len(orphans) != 1 || orphans[0].Category != orphanDanglingOwner {
+		t.Errorf("expected only the dangling owner, got %+v", orphans)
+	}
+}
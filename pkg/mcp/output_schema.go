@@ -0,0 +1,107 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// kubernetesObjectSchema describes the Kubernetes objects returned by the tools. No field is required,
+// since objects are reduced to a few fields in the summary and table output modes.
+func kubernetesObjectSchema() *jsonschema.Schema {
+	stringMap := func() *jsonschema.Schema {
+		return &jsonschema.Schema{Type: "object", AdditionalProperties: &jsonschema.Schema{Type: "string"}}
+	}
+	return &jsonschema.Schema{
+		Type:        "object",
+		Description: "A Kubernetes object",
+		Properties: map[string]*jsonschema.Schema{
+			"apiVersion": {Type: "string"},
+			"kind":       {Type: "string"},
+			"metadata": {
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name":            {Type: "string"},
+					"namespace":       {Type: "string"},
+					"uid":             {Type: "string"},
+					"resourceVersion": {Type: "string"},
+					"labels":          stringMap(),
+					"annotations":     stringMap(),
+				},
+			},
+		},
+	}
+}
+
+// outputSchema returns the output schema of a tool result, describing the given properties, which
+// hold a Kubernetes object or a list of them, as Kubernetes objects instead of arbitrary objects.
+func outputSchema[T any](objectProperties ...string) *jsonschema.Schema {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		panic(fmt.Sprintf("output schema of %T: %v", *new(T), err))
+	}
+	for _, name := range objectProperties {
+		property, ok := schema.Properties[name]
+		if !ok {
+			panic(fmt.Sprintf("output schema of %T: no property %s", *new(T), name))
+		}
+		if property.Type == "array" {
+			property.Items = kubernetesObjectSchema()
+			continue
+		}
+		description := property.Description
+		*property = *kubernetesObjectSchema()
+		if description != "" {
+			property.Description = description
+		}
+	}
+	return schema
+}
+
+// requestAPIServerURL returns the URL of the API server of the token of a request, which the
+// objects of the result come from.
+func requestAPIServerURL(request *mcp.CallToolRequest) string {
+	if request == nil || request.Extra == nil || request.Extra.TokenInfo == nil {
+		return ""
+	}
+	apiServerURL, _ := request.Extra.TokenInfo.Extra["audience"].(string)
+	return apiServerURL
+}
+
+// requestCluster returns the name of the cluster of the token of a request, which the objects of the
+// result come from: its name in the cluster registry, or the host of its API server.
+func requestCluster(request *mcp.CallToolRequest) string {
+	if request == nil || request.Extra == nil || request.Extra.TokenInfo == nil {
+		return ""
+	}
+	if name, ok := request.Extra.TokenInfo.Extra["cluster"].(string); ok && name != "" {
+		return name
+	}
+	return clusterName(request.Extra.TokenInfo)
+}
+
+// clusterSuffix returns the text telling the cluster of the result of a request, when the token grants
+// several clusters whose results the LLM must tell apart.
+func clusterSuffix(request *mcp.CallToolRequest) string {
+	if request == nil || request.Extra == nil || request.Extra.TokenInfo == nil || len(tokenClusters(request.Extra.TokenInfo)) < 2 {
+		return ""
+	}
+	return fmt.Sprintf(" in cluster %s", requestCluster(request))
+}
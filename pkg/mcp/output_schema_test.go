@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestOutputSchema(t *testing.T) {
+	resolved, err := outputSchema[ResourceListResult]("resources").Resolve(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		result  string
+		isValid bool
+	}{
+		{
+			name:    "full objects",
+			result:  `{"resources": [{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "web", "labels": {"app": "web"}}, "spec": {}}], "apiServerUrl": "https://api.example.com:6443", "cluster": "prod-eu"}`,
+			isValid: true,
+		},
+		{
+			name:    "summary records",
+			result:  `{"resources": [{"name": "web", "kind": "Pod", "namespace": "shop", "status": "Running"}]}`,
+			isValid: true,
+		},
+		{
+			name:   "non-string label",
+			result: `{"resources": [{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "web", "labels": {"replicas": 3}}}]}`,
+		},
+		{
+			name:   "kind is not a string",
+			result: `{"resources": [{"kind": {"name": "Pod"}}]}`,
+		},
+		{
+			name:   "missing resources",
+			result: `{"continue": "abc"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result map[string]any
+			if err := json.Unmarshal([]byte(tt.result), &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := resolved.Validate(result)
+			if tt.isValid && err != nil {
+				t.Errorf("expected valid result, got %v", err)
+			}
+			if !tt.isValid && err == nil {
+				t.Errorf("expected invalid result")
+			}
+		})
+	}
+}
+
+func TestOutputSchemaObjects(t *testing.T) {
+	get := outputSchema[ResourceGetResult]("resource")
+	if resource := get.Properties["resource"]; resource.Properties["metadata"] == nil {
+		t.Errorf("expected the resource to be described as a Kubernetes object, got %+v", resource)
+	}
+	if get.Properties["apiServerUrl"] == nil || get.Properties["cluster"] == nil {
+		t.Errorf("expected the API server URL and the cluster in the result")
+	}
+
+	apply := outputSchema[ResourceApplyResult]("appliedResources")
+	if items := apply.Properties["appliedResources"].Items; items == nil || items.Properties["kind"] == nil {
+		t.Errorf("expected the applied resources to be described as Kubernetes objects")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for an unknown property")
+		}
+	}()
+	outputSchema[ResourceGetResult]("resources")
+}
+
+func TestRequestCluster(t *testing.T) {
+	tests := []struct {
+		name           string
+		extra          map[string]any
+		expected       string
+		expectedSuffix string
+	}{
+		{
+			name:     "single cluster",
+			extra:    map[string]any{"audience": "https://10.0.0.1:6443"},
+			expected: "10.0.0.1:6443",
+		},
+		{
+			name:           "cluster of the registry among several",
+			extra:          map[string]any{"audience": "https://prod-eu.example.com", "cluster": "prod-eu", "clusters": []string{"https://prod-eu.example.com", "https://10.0.0.1:6443"}},
+			expected:       "prod-eu",
+			expectedSuffix: " in cluster prod-eu",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{TokenInfo: &auth.TokenInfo{Extra: tt.extra}}}
+			if cluster := requestCluster(request); cluster != tt.expected {
+				t.Errorf("expected cluster %q, got %q", tt.expected, cluster)
+			}
+			if suffix := clusterSuffix(request); suffix != tt.expectedSuffix {
+				t.Errorf("expected suffix %q, got %q", tt.expectedSuffix, suffix)
+			}
+		})
+	}
+}
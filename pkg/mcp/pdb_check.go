@@ -0,0 +1,293 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+var pdbsGVR = schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"}
+
+type PDBCheckInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"The namespace of the PodDisruptionBudgets (optional defaults to all namespaces)"`
+	Pod       string `json:"pod,omitempty" jsonschema:"Evaluate whether evicting this pod would be blocked. Requires namespace"`
+	Node      string `json:"node,omitempty" jsonschema:"Evaluate whether draining this node would be blocked"`
+}
+
+type PDBCheckResult struct {
+	PDBs      []PDBInfo       `json:"pdbs"`
+	Evictions []EvictionCheck `json:"evictions,omitempty"`
+	Blocked   bool            `json:"blocked"`
+}
+
+type PDBInfo struct {
+	Name                       string `json:"name"`
+	Namespace                  string `json:"namespace"`
+	Selector                   string `json:"selector"`
+	MinAvailable               string `json:"minAvailable,omitempty"`
+	MaxUnavailable             string `json:"maxUnavailable,omitempty"`
+	UnhealthyPodEvictionPolicy string `json:"unhealthyPodEvictionPolicy,omitempty"`
+	ExpectedPods               int32  `json:"expectedPods"`
+	CurrentHealthy             int32  `json:"currentHealthy"`
+	DesiredHealthy             int32  `json:"desiredHealthy"`
+	DisruptionsAllowed         int32  `json:"disruptionsAllowed"`
+}
+
+// EvictionCheck is the evaluation of the eviction of a single pod against the PodDisruptionBudgets.
+type EvictionCheck struct {
+	Pod       string   `json:"pod"`
+	Namespace string   `json:"namespace"`
+	Blocked   bool     `json:"blocked"`
+	PDBs      []string `json:"pdbs,omitempty"`
+	Reason    string   `json:"reason"`
+}
+
+func (s *Server) addPDBCheckTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "pdb_check",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Check PodDisruptionBudgets",
+		},
+		Description: "List PodDisruptionBudgets with their currentHealthy, desiredHealthy and disruptionsAllowed. When a pod or a node is given, evaluates whether evicting the pod or draining the node would be blocked by them, without evicting anything",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input PDBCheckInput) (*mcp.CallToolResult, *PDBCheckResult, error) {
+		if input.Pod != "" && input.Node != "" {
+			return nil, nil, fmt.Errorf("pod and node can not be evaluated together")
+		}
+
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		if input.Pod != "" && input.Namespace == "" {
+			input.Namespace, err = s.sessionNamespace(ctx, request, "pods")
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		var pods []corev1.Pod
+		switch {
+		case input.Pod != "":
+			obj, err := dynamicClient.Resource(podsGVR).Namespace(input.Namespace).Get(ctx, input.Pod, v1.GetOptions{})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get pod: %w", err)
+			}
+			var pod corev1.Pod
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+				return nil, nil, fmt.Errorf("failed to convert pod: %w", err)
+			}
+			pods = append(pods, pod)
+		case input.Node != "":
+			list, err := dynamicClient.Resource(podsGVR).Namespace(input.Namespace).List(ctx, v1.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector("spec.nodeName", input.Node).String(),
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list pods on node %s: %w", input.Node, err)
+			}
+			for _, item := range list.Items {
+				var pod corev1.Pod
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pod); err != nil {
+					continue
+				}
+				if isDrainedPod(&pod) {
+					pods = append(pods, pod)
+				}
+			}
+		}
+
+		list, err := dynamicClient.Resource(pdbsGVR).Namespace(input.Namespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list pod disruption budgets: %w", err)
+		}
+		pdbs := make([]policyv1.PodDisruptionBudget, 0, len(list.Items))
+		result := &PDBCheckResult{PDBs: []PDBInfo{}}
+		for _, item := range list.Items {
+			var pdb policyv1.PodDisruptionBudget
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pdb); err != nil {
+				continue
+			}
+			pdbs = append(pdbs, pdb)
+			result.PDBs = append(result.PDBs, pdbInfo(&pdb))
+		}
+
+		var message string
+		switch {
+		case input.Pod != "" || input.Node != "":
+			result.Evictions = evaluateEvictions(pods, pdbs)
+			blocked := newTextTable("NAMESPACE", "POD", "REASON")
+			for _, eviction := range result.Evictions {
+				if eviction.Blocked {
+					result.Blocked = true
+					blocked.addRow(eviction.Namespace, eviction.Pod, eviction.Reason)
+				}
+			}
+
+			target := fmt.Sprintf("eviction of pod %s/%s", input.Namespace, input.Pod)
+			if input.Node != "" {
+				target = fmt.Sprintf("drain of node %s (%d pods)", input.Node, len(pods))
+			}
+			if result.Blocked {
+				message = textSummary(fmt.Sprintf("The %s would be blocked:", target), blocked.String())
+			} else {
+				message = fmt.Sprintf("The %s would not be blocked by PodDisruptionBudgets", target)
+			}
+		default:
+			message = fmt.Sprintf("Found %d pod disruption budgets", len(result.PDBs))
+			if len(result.PDBs) > 0 {
+				table := newTextTable("NAMESPACE", "NAME", "MIN-AVAILABLE", "MAX-UNAVAILABLE", "ALLOWED-DISRUPTIONS")
+				for _, pdb := range result.PDBs {
+					table.addRow(pdb.Namespace, pdb.Name, pdb.MinAvailable, pdb.MaxUnavailable, strconv.Itoa(int(pdb.DisruptionsAllowed)))
+				}
+				message = textSummary(message, table.String())
+			}
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, result, nil
+	})
+}
+
+func pdbInfo(pdb *policyv1.PodDisruptionBudget) PDBInfo {
+	info := PDBInfo{
+		Name:               pdb.Name,
+		Namespace:          pdb.Namespace,
+		Selector:           "<none>",
+		ExpectedPods:       pdb.Status.ExpectedPods,
+		CurrentHealthy:     pdb.Status.CurrentHealthy,
+		DesiredHealthy:     pdb.Status.DesiredHealthy,
+		DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+	}
+	if pdb.Spec.Selector != nil {
+		info.Selector = v1.FormatLabelSelector(pdb.Spec.Selector)
+	}
+	if pdb.Spec.MinAvailable != nil {
+		info.MinAvailable = pdb.Spec.MinAvailable.String()
+	}
+	if pdb.Spec.MaxUnavailable != nil {
+		info.MaxUnavailable = pdb.Spec.MaxUnavailable.String()
+	}
+	if pdb.Spec.UnhealthyPodEvictionPolicy != nil {
+		info.UnhealthyPodEvictionPolicy = string(*pdb.Spec.UnhealthyPodEvictionPolicy)
+	}
+	return info
+}
+
+// isDrainedPod reports whether kubectl drain would evict the pod.
+// Mirror pods, DaemonSet pods and terminated pods are skipped by drain.
+func isDrainedPod(pod *corev1.Pod) bool {
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return false
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if owner := v1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+	return true
+}
+
+// evaluateEvictions evaluates the eviction of the pods in order, the way the eviction API would.
+// Every allowed eviction of a healthy pod consumes the budget of its PodDisruptionBudget,
+// so the result for a drain reflects the budgets being used up by the previous pods.
+func evaluateEvictions(pods []corev1.Pod, pdbs []policyv1.PodDisruptionBudget) []EvictionCheck {
+	remaining := make([]int32, len(pdbs))
+	for i := range pdbs {
+		remaining[i] = pdbs[i].Status.DisruptionsAllowed
+	}
+
+	checks := make([]EvictionCheck, 0, len(pods))
+	for _, pod := range pods {
+		check := EvictionCheck{
+			Pod:       pod.Name,
+			Namespace: pod.Namespace,
+		}
+
+		var matching []int
+		for i := range pdbs {
+			if pdbMatchesPod(&pdbs[i], &pod) {
+				matching = append(matching, i)
+				check.PDBs = append(check.PDBs, pdbs[i].Name)
+			}
+		}
+
+		switch {
+		case len(matching) == 0:
+			check.Reason = "not covered by any PodDisruptionBudget"
+		case len(matching) > 1:
+			check.Blocked = true
+			check.Reason = "covered by more than one PodDisruptionBudget, the eviction API refuses to evict it"
+		default:
+			pdb := &pdbs[matching[0]]
+			switch {
+			case !isPodReady(&pod) && unhealthyPodEvictable(pdb):
+				check.Reason = fmt.Sprintf("pod is not ready, PodDisruptionBudget %s allows evicting unhealthy pods", pdb.Name)
+			case remaining[matching[0]] > 0:
+				remaining[matching[0]]--
+				check.Reason = fmt.Sprintf("PodDisruptionBudget %s allows the disruption", pdb.Name)
+			default:
+				check.Blocked = true
+				check.Reason = fmt.Sprintf("PodDisruptionBudget %s allows no more disruptions (currentHealthy %d, desiredHealthy %d)",
+					pdb.Name, pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy)
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+func pdbMatchesPod(pdb *policyv1.PodDisruptionBudget, pod *corev1.Pod) bool {
+	if pdb.Namespace != pod.Namespace || pdb.Spec.Selector == nil {
+		return false
+	}
+	selector, err := v1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(pod.Labels))
+}
+
+// unhealthyPodEvictable reports whether the PodDisruptionBudget allows evicting a pod that is not ready.
+func unhealthyPodEvictable(pdb *policyv1.PodDisruptionBudget) bool {
+	if pdb.Spec.UnhealthyPodEvictionPolicy != nil && *pdb.Spec.UnhealthyPodEvictionPolicy == policyv1.AlwaysAllow {
+		return true
+	}
+	return pdb.Status.CurrentHealthy >= pdb.Status.DesiredHealthy
+}
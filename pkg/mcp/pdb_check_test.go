@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func testPod(name string, labels map[string]string, ready bool) corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "web", Labels: labels},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func testPDB(name string, matchLabels map[string]string, currentHealthy, desiredHealthy, disruptionsAllowed int32) policyv1.PodDisruptionBudget {
+	return policyv1.PodDisruptionBudget{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "web"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &v1.LabelSelector{MatchLabels: matchLabels},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{
+			CurrentHealthy:     currentHealthy,
+			DesiredHealthy:     desiredHealthy,
+			DisruptionsAllowed: disruptionsAllowed,
+		},
+	}
+}
+
+func TestEvaluateEvictions(t *testing.T) {
+	tests := []struct {
+		name     string
+		pods     []corev1.Pod
+		pdbs     []policyv1.PodDisruptionBudget
+		expected []bool
+	}{
+		{
+			name:     "not covered",
+			pods:     []corev1.Pod{testPod("api-0", map[string]string{"app": "api"}, true)},
+			pdbs:     []policyv1.PodDisruptionBudget{testPDB("frontend", map[string]string{"app": "frontend"}, 3, 2, 1)},
+			expected: []bool{false},
+		},
+		{
+			name: "budget consumed by previous pods",
+			pods: []corev1.Pod{
+				testPod("frontend-0", map[string]string{"app": "frontend"}, true),
+				testPod("frontend-1", map[string]string{"app": "frontend"}, true),
+			},
+			pdbs:     []policyv1.PodDisruptionBudget{testPDB("frontend", map[string]string{"app": "frontend"}, 3, 2, 1)},
+			expected: []bool{false, true},
+		},
+		{
+			name: "multiple budgets",
+			pods: []corev1.Pod{testPod("frontend-0", map[string]string{"app": "frontend"}, true)},
+			pdbs: []policyv1.PodDisruptionBudget{
+				testPDB("frontend", map[string]string{"app": "frontend"}, 3, 2, 1),
+				testPDB("all", map[string]string{}, 3, 2, 1),
+			},
+			expected: []bool{true},
+		},
+		{
+			name:     "unhealthy pod with satisfied budget",
+			pods:     []corev1.Pod{testPod("frontend-0", map[string]string{"app": "frontend"}, false)},
+			pdbs:     []policyv1.PodDisruptionBudget{testPDB("frontend", map[string]string{"app": "frontend"}, 2, 2, 0)},
+			expected: []bool{false},
+		},
+		{
+			name: "unhealthy pod with always allow policy",
+			pods: []corev1.Pod{testPod("frontend-0", map[string]string{"app": "frontend"}, false)},
+			pdbs: func() []policyv1.PodDisruptionBudget {
+				pdb := testPDB("frontend", map[string]string{"app": "frontend"}, 1, 2, 0)
+				pdb.Spec.UnhealthyPodEvictionPolicy = ptr.To(policyv1.AlwaysAllow)
+				return []policyv1.PodDisruptionBudget{pdb}
+			}(),
+			expected: []bool{false},
+		},
+		{
+			name:     "unhealthy pod with unsatisfied budget",
+			pods:     []corev1.Pod{testPod("frontend-0", map[string]string{"app": "frontend"}, false)},
+			pdbs:     []policyv1.PodDisruptionBudget{testPDB("frontend", map[string]string{"app": "frontend"}, 1, 2, 0)},
+			expected: []bool{true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := evaluateEvictions(tt.pods, tt.pdbs)
+			if len(checks) != len(tt.expected) {
+				t.Fatalf("expected %d checks, got %d", len(tt.expected), len(checks))
+			}
+			for i, check := range checks {
+				if check.Blocked != tt.expected[i] {
+					t.Errorf("pod %s: expected blocked %v, got %v (%s)", check.Pod, tt.expected[i], check.Blocked, check.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestIsDrainedPod(t *testing.T) {
+	daemonSetPod := testPod("agent", nil, true)
+	daemonSetPod.OwnerReferences = []v1.OwnerReference{{Kind: "DaemonSet", Name: "agent", Controller: ptr.To(true)}}
+
+	mirrorPod := testPod("etcd", nil, true)
+	mirrorPod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "hash"}
+
+	completedPod := testPod("job", nil, false)
+	completedPod.Status.Phase = corev1.PodSucceeded
+
+	regularPod := testPod("web", nil, true)
+
+	for _, tt := range []struct {
+		pod      corev1.Pod
+		expected bool
+	}{
+		{pod: daemonSetPod, expected: false},
+		{pod: mirrorPod, expected: false},
+		{pod: completedPod, expected: false},
+		{pod: regularPod, expected: true},
+	} {
+		if drained := isDrainedPod(&tt.pod); drained != tt.expected {
+			t.Errorf("pod %s: expected %v, got %v", tt.pod.Name, tt.expected, drained)
+		}
+	}
+}
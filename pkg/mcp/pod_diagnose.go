@@ -0,0 +1,319 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	podsGVR   = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	eventsGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+)
+
+// maxDiagnosisEvents is the maximum number of the most recent events included in a diagnosis.
+const maxDiagnosisEvents = 20
+
+type PodDiagnoseInput struct {
+	Name      string `json:"name,required" jsonschema:"The name of the pod"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"The namespace of the pod"`
+}
+
+type PodDiagnoseResult struct {
+	Diagnosis PodDiagnosis `json:"diagnosis"`
+}
+
+// PodDiagnosis is the structured overview of a pod and the problems detected on it.
+type PodDiagnosis struct {
+	Name          string               `json:"name"`
+	Namespace     string               `json:"namespace"`
+	Phase         string               `json:"phase"`
+	Reason        string               `json:"reason,omitempty"`
+	Message       string               `json:"message,omitempty"`
+	NodeName      string               `json:"nodeName,omitempty"`
+	QOSClass      string               `json:"qosClass,omitempty"`
+	TotalRestarts int32                `json:"totalRestarts"`
+	Conditions    []ConditionSummary   `json:"conditions,omitempty"`
+	Containers    []ContainerDiagnosis `json:"containers,omitempty"`
+	Events        []EventSummary       `json:"events,omitempty"`
+	Problems      []string             `json:"problems,omitempty"`
+}
+
+type ConditionSummary struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+}
+
+type ContainerDiagnosis struct {
+	Name                string            `json:"name"`
+	Image               string            `json:"image"`
+	Init                bool              `json:"init,omitempty"`
+	Ready               bool              `json:"ready"`
+	RestartCount        int32             `json:"restartCount"`
+	State               string            `json:"state"`
+	Reason              string            `json:"reason,omitempty"`
+	Message             string            `json:"message,omitempty"`
+	LastTerminationInfo string            `json:"lastTermination,omitempty"`
+	Requests            map[string]string `json:"requests,omitempty"`
+	Limits              map[string]string `json:"limits,omitempty"`
+}
+
+type EventSummary struct {
+	Type     string `json:"type"`
+	Reason   string `json:"reason"`
+	Message  string `json:"message"`
+	Count    int32  `json:"count,omitempty"`
+	LastSeen string `json:"lastSeen,omitempty"`
+}
+
+func (s *Server) addPodDiagnoseTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "pod_diagnose",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Diagnose a Kubernetes pod",
+		},
+		Description: "Diagnose a pod in a single call. Returns the pod phase, conditions, container statuses, restart counts, last termination reasons, resource requests/limits, recent events and detected problems",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input PodDiagnoseInput) (*mcp.CallToolResult, *PodDiagnoseResult, error) {
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		if input.Namespace == "" {
+			input.Namespace, err = s.sessionNamespace(ctx, request, "pods")
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		obj, err := dynamicClient.Resource(podsGVR).Namespace(input.Namespace).Get(ctx, input.Name, v1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get pod: %w", err)
+		}
+
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert pod: %w", err)
+		}
+
+		eventList, err := dynamicClient.Resource(eventsGVR).Namespace(input.Namespace).List(ctx, v1.ListOptions{
+			FieldSelector: fields.Set{
+				"involvedObject.kind": "Pod",
+				"involvedObject.name": input.Name,
+			}.String(),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list pod events: %w", err)
+		}
+
+		events := make([]corev1.Event, 0, len(eventList.Items))
+		for _, item := range eventList.Items {
+			var event corev1.Event
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &event); err != nil {
+				continue
+			}
+			if event.InvolvedObject.UID != "" && event.InvolvedObject.UID != pod.UID {
+				// Event belongs to a previous pod with the same name.
+				continue
+			}
+			events = append(events, event)
+		}
+
+		diagnosis := buildPodDiagnosis(&pod, events)
+
+		message := fmt.Sprintf("Pod %s/%s is %s with %d restart(s)", pod.Namespace, pod.Name, diagnosis.Phase, diagnosis.TotalRestarts)
+		if len(diagnosis.Problems) > 0 {
+			message = textSummary(message+". Detected problems:", textList(diagnosis.Problems))
+		} else {
+			message += ". No problems detected"
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, &PodDiagnoseResult{Diagnosis: diagnosis}, nil
+	})
+}
+
+// buildPodDiagnosis builds the diagnosis of the pod from its status and events.
+func buildPodDiagnosis(pod *corev1.Pod, events []corev1.Event) PodDiagnosis {
+	diagnosis := PodDiagnosis{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Phase:     string(pod.Status.Phase),
+		Reason:    pod.Status.Reason,
+		Message:   pod.Status.Message,
+		NodeName:  pod.Spec.NodeName,
+		QOSClass:  string(pod.Status.QOSClass),
+	}
+	if pod.DeletionTimestamp != nil {
+		diagnosis.Problems = append(diagnosis.Problems, fmt.Sprintf("pod is terminating since %s", pod.DeletionTimestamp.UTC().Format("2006-01-02T15:04:05Z")))
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		diagnosis.Conditions = append(diagnosis.Conditions, ConditionSummary{
+			Type:               string(cond.Type),
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: conditionTime(cond.LastTransitionTime),
+		})
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+			diagnosis.Problems = append(diagnosis.Problems, fmt.Sprintf("pod is not scheduled (%s): %s", cond.Reason, cond.Message))
+		}
+	}
+
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, status := range pod.Status.InitContainerStatuses {
+		statuses["init/"+status.Name] = status
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		diagnosis.addContainer(container, statuses["init/"+container.Name], true)
+	}
+	for _, container := range pod.Spec.Containers {
+		diagnosis.addContainer(container, statuses[container.Name], false)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).After(eventTime(events[j]).Time)
+	})
+	if len(events) > maxDiagnosisEvents {
+		events = events[:maxDiagnosisEvents]
+	}
+	for _, event := range events {
+		summary := EventSummary{
+			Type:    event.Type,
+			Reason:  event.Reason,
+			Message: event.Message,
+			Count:   event.Count,
+		}
+		if t := eventTime(event); !t.IsZero() {
+			summary.LastSeen = t.UTC().Format("2006-01-02T15:04:05Z")
+		}
+		diagnosis.Events = append(diagnosis.Events, summary)
+	}
+
+	return diagnosis
+}
+
+func (d *PodDiagnosis) addContainer(container corev1.Container, status corev1.ContainerStatus, init bool) {
+	cd := ContainerDiagnosis{
+		Name:         container.Name,
+		Image:        container.Image,
+		Init:         init,
+		Ready:        status.Ready,
+		RestartCount: status.RestartCount,
+		State:        "unknown",
+	}
+
+	switch {
+	case status.State.Running != nil:
+		cd.State = "running"
+	case status.State.Waiting != nil:
+		cd.State = "waiting"
+		cd.Reason = status.State.Waiting.Reason
+		cd.Message = status.State.Waiting.Message
+	case status.State.Terminated != nil:
+		cd.State = "terminated"
+		cd.Reason = status.State.Terminated.Reason
+		cd.Message = status.State.Terminated.Message
+	}
+
+	if last := status.LastTerminationState.Terminated; last != nil {
+		cd.LastTerminationInfo = fmt.Sprintf("%s (exit code %d)", last.Reason, last.ExitCode)
+		if last.Message != "" {
+			cd.LastTerminationInfo += ": " + last.Message
+		}
+	}
+
+	for name, quantity := range container.Resources.Requests {
+		if cd.Requests == nil {
+			cd.Requests = map[string]string{}
+		}
+		cd.Requests[string(name)] = quantity.String()
+	}
+	for name, quantity := range container.Resources.Limits {
+		if cd.Limits == nil {
+			cd.Limits = map[string]string{}
+		}
+		cd.Limits[string(name)] = quantity.String()
+	}
+
+	d.TotalRestarts += cd.RestartCount
+	d.Containers = append(d.Containers, cd)
+
+	prefix := "container"
+	if init {
+		prefix = "init container"
+	}
+	switch {
+	case cd.State == "waiting" && cd.Reason != "" && cd.Reason != "PodInitializing" && cd.Reason != "ContainerCreating":
+		d.Problems = append(d.Problems, fmt.Sprintf("%s %s is waiting: %s %s", prefix, cd.Name, cd.Reason, cd.Message))
+	case cd.State == "terminated" && status.State.Terminated.ExitCode != 0:
+		d.Problems = append(d.Problems, fmt.Sprintf("%s %s terminated with exit code %d: %s", prefix, cd.Name, status.State.Terminated.ExitCode, cd.Reason))
+	}
+	if last := status.LastTerminationState.Terminated; last != nil && last.Reason == "OOMKilled" {
+		d.Problems = append(d.Problems, fmt.Sprintf("%s %s was OOMKilled, consider raising its memory limit", prefix, cd.Name))
+	}
+	if cd.RestartCount > 0 && cd.LastTerminationInfo != "" {
+		d.Problems = append(d.Problems, fmt.Sprintf("%s %s restarted %d time(s), last termination: %s", prefix, cd.Name, cd.RestartCount, cd.LastTerminationInfo))
+	}
+}
+
+// eventTime returns the most relevant timestamp of the event.
+func eventTime(event corev1.Event) v1.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp
+	}
+	if !event.EventTime.IsZero() {
+		return v1.Time{Time: event.EventTime.Time}
+	}
+	return event.FirstTimestamp
+}
+
+// conditionTime returns the time of the last transition of a condition, empty when it is not set.
+func conditionTime(t v1.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildPodDiagnosis(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{
+				{
+					Name:  "app",
+					Image: "nginx",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+						Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+					},
+				},
+				{Name: "sidecar", Image: "busybox"},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "app",
+					RestartCount: 3,
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 40s"},
+					},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+					},
+				},
+				{
+					Name:  "sidecar",
+					Ready: true,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+
+	now := time.Now()
+	events := []corev1.Event{
+		{Type: "Normal", Reason: "Pulled", LastTimestamp: v1.NewTime(now.Add(-time.Hour))},
+		{Type: "Warning", Reason: "BackOff", LastTimestamp: v1.NewTime(now)},
+	}
+
+	diagnosis := buildPodDiagnosis(pod, events)
+
+	if diagnosis.TotalRestarts != 3 {
+		t.Errorf("expected 3 restarts, got %d", diagnosis.TotalRestarts)
+	}
+	if len(diagnosis.Containers) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(diagnosis.Containers))
+	}
+
+	app := diagnosis.Containers[0]
+	if app.State != "waiting" || app.Reason != "CrashLoopBackOff" {
+		t.Errorf("unexpected app container state %s/%s", app.State, app.Reason)
+	}
+	if app.LastTerminationInfo != "OOMKilled (exit code 137)" {
+		t.Errorf("unexpected last termination %q", app.LastTerminationInfo)
+	}
+	if app.Requests["memory"] != "64Mi" || app.Limits["memory"] != "128Mi" {
+		t.Errorf("unexpected resources requests=%v limits=%v", app.Requests, app.Limits)
+	}
+	if diagnosis.Containers[1].State != "running" {
+		t.Errorf("expected sidecar to be running, got %s", diagnosis.Containers[1].State)
+	}
+
+	if len(diagnosis.Events) != 2 || diagnosis.Events[0].Reason != "BackOff" {
+		t.Errorf("expected events sorted by most recent first, got %+v", diagnosis.Events)
+	}
+
+	problems := strings.Join(diagnosis.Problems, "\n")
+	for _, expected := range []string{"CrashLoopBackOff", "OOMKilled"} {
+		if !strings.Contains(problems, expected) {
+			t.Errorf("expected problems to mention %s, got %q", expected, problems)
+		}
+	}
+}
+
+func TestBuildPodDiagnosis_Unschedulable(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "web", Namespace: "default"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{
+				{
+					Type:               corev1.PodScheduled,
+					Status:             corev1.ConditionFalse,
+					Reason:             "Unschedulable",
+					Message:            "0/3 nodes are available: 3 Insufficient cpu.",
+					LastTransitionTime: v1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+				},
+			},
+		},
+	}
+
+	diagnosis := buildPodDiagnosis(pod, nil)
+	if len(diagnosis.Problems) != 1 || !strings.Contains(diagnosis.Problems[0], "Insufficient cpu") {
+		t.Errorf("expected unschedulable problem, got %v", diagnosis.Problems)
+	}
+	if len(diagnosis.Conditions) != 1 || diagnosis.Conditions[0].LastTransitionTime != "2025-01-01T00:00:00Z" {
+		t.Errorf("expected the last transition time of the condition, got %+v", diagnosis.Conditions)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+type PodEvictInput struct {
+	Name               string `json:"name,required" jsonschema:"The name of the pod"`
+	Namespace          string `json:"namespace,omitempty" jsonschema:"The namespace of the pod"`
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty" jsonschema:"The duration in seconds the pod is given to terminate (optional defaults to the termination grace period of the pod)"`
+}
+
+type PodEvictResult struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	Evicted   bool   `json:"evicted"`
+	// Blocked is set when the eviction was refused to respect the PodDisruptionBudgets of the pod.
+	Blocked bool `json:"blocked"`
+	// PDBs are the PodDisruptionBudgets covering the pod.
+	PDBs               []string `json:"pdbs,omitempty"`
+	Reason             string   `json:"reason,omitempty"`
+	CancellationReason string   `json:"cancellationReason,omitempty"`
+	// APIServerURL is the API server of the pod.
+	APIServerURL string `json:"apiServerUrl,omitempty"`
+}
+
+func (s *Server) addPodEvictTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "pod_evict",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(true),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    false,
+			Title:           "Evict a pod",
+		},
+		Description: "Evict a pod with the Eviction API, which refuses evictions violating the PodDisruptionBudgets of the pod, instead of deleting it. " +
+			"Returns whether the eviction was allowed or blocked and by which PodDisruptionBudget. The eviction is confirmed by the user first",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input PodEvictInput) (*mcp.CallToolResult, *PodEvictResult, error) {
+		if input.GracePeriodSeconds != nil && *input.GracePeriodSeconds < 0 {
+			return nil, nil, fmt.Errorf("gracePeriodSeconds must not be negative")
+		}
+
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+		if input.Namespace == "" {
+			input.Namespace, err = s.sessionNamespace(ctx, request, "pods")
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		recordResource(ctx, podsGVR)
+
+		obj, err := dynamicClient.Resource(podsGVR).Namespace(input.Namespace).Get(ctx, input.Name, v1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get pod: %w", err)
+		}
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert pod: %w", err)
+		}
+
+		list, err := dynamicClient.Resource(pdbsGVR).Namespace(input.Namespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list pod disruption budgets: %w", err)
+		}
+		pdbs := make([]policyv1.PodDisruptionBudget, 0, len(list.Items))
+		for _, item := range list.Items {
+			var pdb policyv1.PodDisruptionBudget
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pdb); err != nil {
+				continue
+			}
+			pdbs = append(pdbs, pdb)
+		}
+		check := evaluateEvictions([]corev1.Pod{pod}, pdbs)[0]
+		result := &PodEvictResult{Pod: pod.Name, Namespace: pod.Namespace, PDBs: check.PDBs, APIServerURL: requestAPIServerURL(request)}
+
+		elicitResult, err := request.Session.Elicit(ctx, &mcp.ElicitParams{
+			Message: fmt.Sprintf("k-mcp will evict pod %s/%s: %s.\n\nDo you want to proceed?", pod.Namespace, pod.Name, check.Reason),
+			RequestedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"confirm": {
+						Type:        "boolean",
+						Description: "Confirm whether to evict the pod",
+					},
+				},
+				Required: []string{"confirm"},
+			},
+		})
+		cancelled := func(reason string) (*mcp.CallToolResult, *PodEvictResult, error) {
+			result.CancellationReason = reason
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: reason,
+					},
+				},
+			}, result, nil
+		}
+		if errors.Is(err, ErrElicitationTimeout) || errors.Is(err, ErrElicitationUnsupported) {
+			return cancelled(fmt.Sprintf("Operation cancelled - %v", err))
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to elicit user confirmation: %w", err)
+		}
+		if elicitResult.Action != "accept" {
+			return cancelled("Operation cancelled by user")
+		}
+		if confirm, ok := elicitResult.Content["confirm"].(bool); !ok || !confirm {
+			return cancelled("Operation cancelled - user did not confirm")
+		}
+
+		_, err = dynamicClient.Resource(podsGVR).Namespace(pod.Namespace).Create(ctx, eviction(&pod, input.GracePeriodSeconds), v1.CreateOptions{}, "eviction")
+		result.Blocked, result.Reason, err = evictionOutcome(err, check)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to evict pod: %w", err)
+		}
+		result.Evicted = !result.Blocked
+
+		message := fmt.Sprintf("Evicted pod %s/%s", pod.Namespace, pod.Name)
+		if result.Blocked {
+			message = fmt.Sprintf("The eviction of pod %s/%s was blocked: %s", pod.Namespace, pod.Name, result.Reason)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, result, nil
+	})
+}
+
+// eviction returns the Eviction of the pod, created as its eviction subresource.
+func eviction(pod *corev1.Pod, gracePeriodSeconds *int64) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "policy/v1",
+		"kind":       "Eviction",
+		"metadata": map[string]interface{}{
+			"name":      pod.Name,
+			"namespace": pod.Namespace,
+		},
+	}}
+	if gracePeriodSeconds != nil {
+		obj.Object["deleteOptions"] = map[string]interface{}{"gracePeriodSeconds": *gracePeriodSeconds}
+	}
+	return obj
+}
+
+// evictionOutcome returns whether the eviction was blocked by the PodDisruptionBudgets of the pod
+// and why, or the error when it failed otherwise. The eviction API refuses the evictions violating a
+// budget with TooManyRequests, and those of the pods covered by several budgets with an internal error.
+func evictionOutcome(err error, check EvictionCheck) (bool, string, error) {
+	switch {
+	case err == nil:
+		return false, check.Reason, nil
+	case apierrors.IsTooManyRequests(err):
+		var reasons []string
+		var status apierrors.APIStatus
+		if errors.As(err, &status) && status.Status().Details != nil {
+			for _, cause := range status.Status().Details.Causes {
+				if cause.Type == policyv1.DisruptionBudgetCause {
+					reasons = append(reasons, cause.Message)
+				}
+			}
+		}
+		if len(reasons) == 0 {
+			return true, err.Error(), nil
+		}
+		return true, strings.Join(reasons, ", "), nil
+	case apierrors.IsInternalError(err) && len(check.PDBs) > 1:
+		return true, check.Reason, nil
+	}
+	return false, "", err
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"errors"
+	"testing"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+func TestEvictionOutcome(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+	budgetViolation := apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+	budgetViolation.ErrStatus.Details.Causes = []v1.StatusCause{{
+		Type:    policyv1.DisruptionBudgetCause,
+		Message: "The disruption budget web needs 2 healthy pods and has 2 currently",
+	}}
+
+	tests := []struct {
+		name            string
+		err             error
+		check           EvictionCheck
+		expectedBlocked bool
+		expectedReason  string
+		expectedError   bool
+	}{
+		{
+			name:           "evicted",
+			check:          EvictionCheck{PDBs: []string{"web"}, Reason: "PodDisruptionBudget web allows the disruption"},
+			expectedReason: "PodDisruptionBudget web allows the disruption",
+		},
+		{
+			name:            "blocked by a budget",
+			err:             budgetViolation,
+			check:           EvictionCheck{PDBs: []string{"web"}},
+			expectedBlocked: true,
+			expectedReason:  "The disruption budget web needs 2 healthy pods and has 2 currently",
+		},
+		{
+			name:            "blocked without cause",
+			err:             apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10),
+			expectedBlocked: true,
+			expectedReason:  "Cannot evict pod as it would violate the pod's disruption budget.",
+		},
+		{
+			name:            "covered by several budgets",
+			err:             apierrors.NewInternalError(errors.New("This pod has more than one PodDisruptionBudget, which the eviction subresource does not support.")),
+			check:           EvictionCheck{PDBs: []string{"web", "all"}, Reason: "covered by more than one PodDisruptionBudget, the eviction API refuses to evict it"},
+			expectedBlocked: true,
+			expectedReason:  "covered by more than one PodDisruptionBudget, the eviction API refuses to evict it",
+		},
+		{
+			name:          "other error",
+			err:           apierrors.NewNotFound(pods, "web-1"),
+			check:         EvictionCheck{PDBs: []string{"web"}},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked, reason, err := evictionOutcome(tt.err, tt.check)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if blocked != tt.expectedBlocked || reason != tt.expectedReason {
+				t.Errorf("expected blocked %v with %q, got %v with %q", tt.expectedBlocked, tt.expectedReason, blocked, reason)
+			}
+		})
+	}
+}
+
+func TestEviction(t *testing.T) {
+	pod := testPod("web-1", nil, true)
+	obj := eviction(&pod, ptr.To[int64](0))
+	if obj.GetKind() != "Eviction" || obj.GetName() != "web-1" || obj.GetNamespace() != "web" {
+		t.Errorf("expected the eviction of web/web-1, got %v", obj.Object)
+	}
+	if seconds, found, _ := unstructured.NestedInt64(obj.Object, "deleteOptions", "gracePeriodSeconds"); !found || seconds != 0 {
+		t.Errorf("expected a grace period of 0 seconds, got %d", seconds)
+	}
+	if _, ok := eviction(&pod, nil).Object["deleteOptions"]; ok {
+		t.Errorf("expected no delete options without grace period")
+	}
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+)
+
+type GetPreferencesInput struct{}
+
+// Preferences are the defaults of a user, identified by the subject of their tokens, kept across sessions.
+type Preferences struct {
+	Namespace        string   `json:"namespace,omitempty" jsonschema:"The namespace used when a namespaced resource is given without namespace, instead of asking for it. The namespace set with set_context takes precedence"`
+	OutputMode       string   `json:"outputMode,omitempty" jsonschema:"The output mode of resource_list when none is given (full, summary or table)"`
+	Timezone         string   `json:"timezone,omitempty" jsonschema:"The IANA timezone the user reads times in (e.g. Europe/Berlin). Times of the results stay in UTC"`
+	FavoriteClusters []string `json:"favoriteClusters,omitempty" jsonschema:"The clusters the user works with the most, as API server URLs or cluster names"`
+}
+
+func (p *Preferences) validate() error {
+	if p.Namespace != "" {
+		if errs := validation.IsDNS1123Label(p.Namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q: %v", p.Namespace, errs)
+		}
+	}
+	if err := validateOutputMode(p.OutputMode); err != nil {
+		return err
+	}
+	if p.Timezone != "" {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", p.Timezone, err)
+		}
+	}
+	return nil
+}
+
+// preferenceStore keeps the preferences of the users by subject, in memory and, when a path is
+// given, in a JSON file so that they survive restarts.
+type preferenceStore struct {
+	path string
+
+	mu          sync.Mutex
+	preferences map[string]Preferences
+}
+
+// newPreferenceStore returns a store loading the preferences saved at the path, if any.
+func newPreferenceStore(path string) (*preferenceStore, error) {
+	store := &preferenceStore{path: path, preferences: map[string]Preferences{}}
+	if path == "" {
+		return store, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preferences from %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &store.preferences); err != nil {
+		return nil, fmt.Errorf("failed to parse preferences from %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// get returns the preferences of a subject, empty if it has none.
+func (p *preferenceStore) get(subject string) Preferences {
+	if p == nil || subject == "" {
+		return Preferences{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.preferences[subject]
+}
+
+// set replaces the preferences of a subject and saves them.
+func (p *preferenceStore) set(subject string, preferences Preferences) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	previous, existed := p.preferences[subject]
+	p.preferences[subject] = preferences
+	if err := p.save(); err != nil {
+		if existed {
+			p.preferences[subject] = previous
+		} else {
+			delete(p.preferences, subject)
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the preferences to a temporary file renamed over the previous one, so that
+// a failed write never loses the saved preferences.
+func (p *preferenceStore) save() error {
+	if p.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(p.preferences, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p.path), filepath.Base(p.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p.path); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return nil
+}
+
+// requestSubject returns the subject of the token of a request, empty if it has none.
+func requestSubject(request *mcp.CallToolRequest) string {
+	if request == nil || request.Extra == nil || request.Extra.TokenInfo == nil {
+		return ""
+	}
+	subject, _ := request.Extra.TokenInfo.Extra["subject"].(string)
+	return subject
+}
+
+// defaultNamespace returns the namespace of a request without namespace: the namespace set for the
+// session, or else the namespace preferred by the user, or else the default namespace of the server,
+// empty if there is none.
+func (s *Server) defaultNamespace(request *mcp.CallToolRequest) string {
+	if namespace := s.sessionContexts.namespace(request.Session); namespace != "" {
+		return namespace
+	}
+	if namespace := s.preferences.get(requestSubject(request)).Namespace; namespace != "" {
+		return namespace
+	}
+	return s.DefaultNamespace
+}
+
+func (s *Server) addPreferencesTools(server *mcp.Server) {
+	addTool(server, s.clusterlessTool(&mcp.Tool{
+		Name: "get_preferences",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(false),
+			ReadOnlyHint:    true,
+			Title:           "Get the preferences of the user",
+		},
+		Description: "Get the preferences of the user kept across sessions: default namespace, output mode of resource_list, timezone and favorite clusters",
+	}), func(ctx context.Context, request *mcp.CallToolRequest, _ GetPreferencesInput) (*mcp.CallToolResult, *Preferences, error) {
+		subject := requestSubject(request)
+		if subject == "" {
+			return nil, nil, fmt.Errorf("the token has no subject to keep preferences for")
+		}
+		preferences := s.preferences.get(subject)
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Preferences of %s: %s", subject, describePreferences(preferences)),
+				},
+			},
+		}, &preferences, nil
+	})
+
+	addTool(server, s.clusterlessTool(&mcp.Tool{
+		Name: "set_preferences",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(false),
+			ReadOnlyHint:    false,
+			Title:           "Set the preferences of the user",
+		},
+		Description: "Replace the preferences of the user kept across sessions: default namespace, output mode of resource_list, timezone and favorite clusters. " +
+			"Omitted preferences are cleared, so get the current preferences first to change only some of them",
+	}), func(ctx context.Context, request *mcp.CallToolRequest, input Preferences) (*mcp.CallToolResult, *Preferences, error) {
+		subject := requestSubject(request)
+		if subject == "" {
+			return nil, nil, fmt.Errorf("the token has no subject to keep preferences for")
+		}
+		if err := input.validate(); err != nil {
+			return nil, nil, err
+		}
+		if err := s.preferences.set(subject, input); err != nil {
+			return nil, nil, err
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Saved the preferences of %s: %s", subject, describePreferences(input)),
+				},
+			},
+		}, &input, nil
+	})
+}
+
+func describePreferences(p Preferences) string {
+	var parts []string
+	if p.Namespace != "" {
+		parts = append(parts, fmt.Sprintf("namespace %s", p.Namespace))
+	}
+	if p.OutputMode != "" {
+		parts = append(parts, fmt.Sprintf("output mode %s", p.OutputMode))
+	}
+	if p.Timezone != "" {
+		parts = append(parts, fmt.Sprintf("timezone %s", p.Timezone))
+	}
+	if len(p.FavoriteClusters) > 0 {
+		parts = append(parts, fmt.Sprintf("favorite clusters %s", strings.Join(p.FavoriteClusters, ", ")))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
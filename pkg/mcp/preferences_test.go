@@ -0,0 +1,118 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestPreferencesValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences Preferences
+		wantErr     bool
+	}{
+		{name: "empty"},
+		{name: "valid", preferences: Preferences{Namespace: "shop", OutputMode: OutputModeSummary, Timezone: "Europe/Berlin", FavoriteClusters: []string{"prod"}}},
+		{name: "invalid namespace", preferences: Preferences{Namespace: "Not_A_Namespace"}, wantErr: true},
+		{name: "invalid output mode", preferences: Preferences{OutputMode: "wide"}, wantErr: true},
+		{name: "invalid timezone", preferences: Preferences{Timezone: "Mars/Olympus"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.preferences.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPreferenceStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+
+	store, err := newPreferenceStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := store.get("alice"); !reflect.DeepEqual(got, Preferences{}) {
+		t.Errorf("expected no preferences, got %v", got)
+	}
+
+	alice := Preferences{Namespace: "shop", OutputMode: OutputModeSummary}
+	if err := store.set("alice", alice); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.set("bob", Preferences{Timezone: "UTC"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded, err := newPreferenceStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := reloaded.get("alice"); !reflect.DeepEqual(got, alice) {
+		t.Errorf("expected %v after reload, got %v", alice, got)
+	}
+	if got := reloaded.get("bob").Timezone; got != "UTC" {
+		t.Errorf("expected timezone UTC after reload, got %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newPreferenceStore(path); err == nil {
+		t.Errorf("expected error for an invalid preferences file")
+	}
+}
+
+func TestDefaultNamespace(t *testing.T) {
+	s := NewServer("8080", "k-mcp")
+	s.preferences, _ = newPreferenceStore("")
+	if err := s.preferences.set("alice", Preferences{Namespace: "shop"}); err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(subject string) *mcp.CallToolRequest {
+		return &mcp.CallToolRequest{Extra: &mcp.RequestExtra{TokenInfo: &auth.TokenInfo{Extra: map[string]any{"subject": subject}}}}
+	}
+
+	if got := s.defaultNamespace(request("alice")); got != "shop" {
+		t.Errorf("expected the preferred namespace shop, got %q", got)
+	}
+	if got := s.defaultNamespace(request("bob")); got != "" {
+		t.Errorf("expected no namespace without preferences, got %q", got)
+	}
+	if got := s.defaultNamespace(&mcp.CallToolRequest{}); got != "" {
+		t.Errorf("expected no namespace without token, got %q", got)
+	}
+
+	// The default namespace of the server, e.g. the namespace of its pod, comes last.
+	s.DefaultNamespace = "k-mcp"
+	if got := s.defaultNamespace(request("alice")); got != "shop" {
+		t.Errorf("expected the preferred namespace shop, got %q", got)
+	}
+	if got := s.defaultNamespace(request("bob")); got != "k-mcp" {
+		t.Errorf("expected the default namespace of the server, got %q", got)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressReporter sends progress notifications for a long-running tool call. It does nothing
+// if the client did not ask for progress with a progress token.
+type progressReporter struct {
+	session  *mcp.ServerSession
+	token    any
+	cluster  string
+	progress int
+	total    int
+}
+
+func newProgressReporter(request *mcp.CallToolRequest, total int) *progressReporter {
+	p := &progressReporter{total: total}
+	if request == nil || request.Params == nil {
+		return p
+	}
+	p.session = request.Session
+	p.token = request.Params.GetProgressToken()
+	if request.Extra != nil && request.Extra.TokenInfo != nil {
+		p.cluster = clusterName(request.Extra.TokenInfo)
+	}
+	return p
+}
+
+// step reports that one more step of the call is done, e.g. "applied Deployment/web (3/7) on cluster X".
+func (p *progressReporter) step(ctx context.Context, format string, args ...any) {
+	p.progress++
+	p.notify(ctx, fmt.Sprintf("%s (%d/%d)", fmt.Sprintf(format, args...), p.progress, p.total))
+}
+
+// notify reports what the call is doing without making progress, e.g. while it waits.
+func (p *progressReporter) notify(ctx context.Context, message string) {
+	if p.token == nil || p.session == nil {
+		return
+	}
+	if p.cluster != "" {
+		message = fmt.Sprintf("%s on cluster %s", message, p.cluster)
+	}
+	err := p.session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Progress:      float64(p.progress),
+		Total:         float64(p.total),
+		Message:       message,
+	})
+	if err != nil {
+		slog.Debug("Failed to send progress notification", "session_id", p.session.ID(), "err", err)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestProgressReporter(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "apply"}, func(ctx context.Context, request *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		progress := newProgressReporter(request, 2)
+		progress.step(ctx, "Applied %s/%s", "Namespace", "shop")
+		progress.notify(ctx, "Waiting for 1 applied resource(s) to become ready")
+		progress.step(ctx, "Applied %s/%s", "Deployment", "web")
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+
+	var mu sync.Mutex
+	var received []*mcp.ProgressNotificationParams
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, request *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, request.Params)
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	// Without a progress token, nothing is reported.
+	if _, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "apply"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	params := &mcp.CallToolParams{Name: "apply", Meta: mcp.Meta{"progressToken": "apply-1"}}
+	if _, err := clientSession.CallTool(ctx, params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		progress float64
+		message  string
+	}{
+		{progress: 1, message: "Applied Namespace/shop (1/2)"},
+		{progress: 1, message: "Waiting for 1 applied resource(s) to become ready"},
+		{progress: 2, message: "Applied Deployment/web (2/2)"},
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		count := len(received)
+		mu.Unlock()
+		if count >= len(expected) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != len(expected) {
+		t.Fatalf("expected %d notifications, got %d", len(expected), len(received))
+	}
+	for i, e := range expected {
+		n := received[i]
+		if n.ProgressToken != "apply-1" || n.Progress != e.progress || n.Total != 2 || n.Message != e.message {
+			t.Errorf("notification %d: expected progress %v message %q, got %+v", i, e.progress, e.message, n)
+		}
+	}
+}
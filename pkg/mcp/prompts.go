@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// workflowPrompt is a curated Kubernetes workflow, exposed to the clients as an MCP prompt
+// guiding the model through the tools of the server.
+type workflowPrompt struct {
+	prompt   *mcp.Prompt
+	template *template.Template
+}
+
+var workflowPrompts = []workflowPrompt{
+	{
+		prompt: &mcp.Prompt{
+			Name:        "diagnose_failing_pod",
+			Title:       "Diagnose a failing pod",
+			Description: "Find out why a pod is not running or keeps restarting and suggest a fix",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "namespace", Description: "The namespace of the pod", Required: true},
+				{Name: "name", Description: "The name of the pod", Required: true},
+			},
+		},
+		template: template.Must(template.New("diagnose_failing_pod").Parse(
+			`The pod {{.name}} in the namespace {{.namespace}} is failing. Find out why.
+
+1. Call pod_diagnose for the pod to get its phase, container states, restart counts, recent events and the logs of the failing containers.
+2. If the pod is owned by a workload, call workload_health for it to check whether other replicas are affected.
+3. Call resource_conditions for the pod and, when the events point at them, for the node, PersistentVolumeClaims or ConfigMaps it uses.
+4. If the pod is pending or OOMKilled, call namespace_quotas and resource_utilization for the namespace to check quotas and limits.
+
+Explain the root cause in a few sentences, quoting the events or log lines proving it, and suggest the smallest fix.
+Do not apply any change without asking me first.`)),
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "review_manifest",
+			Title:       "Review a manifest",
+			Description: "Review Kubernetes manifests for mistakes, security and reliability issues before applying them",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "manifest", Description: "The Kubernetes manifests in YAML format", Required: true},
+				{Name: "namespace", Description: "The namespace the manifests are applied to (optional)"},
+			},
+		},
+		template: template.Must(template.New("review_manifest").Parse(
+			`Review the following Kubernetes manifests{{with .namespace}}, to be applied to the namespace {{.}}{{end}}.
+
+` + "```yaml\n{{.manifest}}\n```" + `
+
+Check that:
+- the API versions and kinds are served by the cluster, calling crd_list for custom resources,
+- containers set resource requests and limits, readiness probes and a non root security context,
+- images are pinned to a tag or digest other than latest,
+- workloads with more than one replica have a PodDisruptionBudget, and existing ones are checked with pdb_check,
+- the referenced ConfigMaps, Secrets, ServiceAccounts and PersistentVolumeClaims exist, using resource_get.
+
+List the issues by severity with the suggested change for each of them. Do not apply the manifests.`)),
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "plan_safe_rollout",
+			Title:       "Plan a safe rollout",
+			Description: "Plan the rollout of a change to a workload, checking that it can be done without downtime",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "namespace", Description: "The namespace of the workload", Required: true},
+				{Name: "name", Description: "The name of the deployment", Required: true},
+				{Name: "change", Description: "The change to roll out, e.g. a new image (optional)"},
+			},
+		},
+		template: template.Must(template.New("plan_safe_rollout").Parse(
+			`Plan a safe rollout of the deployment {{.name}} in the namespace {{.namespace}}{{with .change}}: {{.}}{{end}}.
+
+1. Call workload_health for the deployment and stop if it is not healthy before the change.
+2. Call resource_get for the deployment and check its rollout strategy, maxUnavailable and maxSurge, probes and replicas.
+3. Call pdb_check for the namespace to make sure disruptions stay allowed during the rollout.
+4. Call namespace_quotas for the namespace to make sure the surge pods fit in the quotas.
+
+Write the plan as ordered steps with the manifest changes, what to watch after each of them and how to roll back.
+Apply it with resource_apply, using wait, only after I confirm the plan.`)),
+	},
+}
+
+// renderPrompt renders the messages of a workflow prompt from the arguments of the request.
+func renderPrompt(p workflowPrompt, arguments map[string]string) (*mcp.GetPromptResult, error) {
+	values := make(map[string]string, len(p.prompt.Arguments))
+	for _, argument := range p.prompt.Arguments {
+		value := strings.TrimSpace(arguments[argument.Name])
+		if argument.Required && value == "" {
+			return nil, fmt.Errorf("argument %s is required", argument.Name)
+		}
+		values[argument.Name] = value
+	}
+
+	var text strings.Builder
+	if err := p.template.Execute(&text, values); err != nil {
+		return nil, fmt.Errorf("failed to render prompt %s: %w", p.prompt.Name, err)
+	}
+	return &mcp.GetPromptResult{
+		Description: p.prompt.Description,
+		Messages: []*mcp.PromptMessage{
+			{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: text.String()},
+			},
+		},
+	}, nil
+}
+
+func (s *Server) addPrompts(server *mcp.Server) {
+	for _, p := range workflowPrompts {
+		server.AddPrompt(p.prompt, func(ctx context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return renderPrompt(p, request.Params.Arguments)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	prompts := make(map[string]workflowPrompt)
+	for _, p := range workflowPrompts {
+		prompts[p.prompt.Name] = p
+	}
+
+	tests := []struct {
+		name      string
+		prompt    string
+		arguments map[string]string
+		contains  []string
+		excludes  []string
+		wantErr   bool
+	}{
+		{
+			name:      "diagnose failing pod",
+			prompt:    "diagnose_failing_pod",
+			arguments: map[string]string{"namespace": "shop", "name": "checkout-7d9f"},
+			contains:  []string{"The pod checkout-7d9f in the namespace shop", "pod_diagnose"},
+		},
+		{
+			name:      "missing required argument",
+			prompt:    "diagnose_failing_pod",
+			arguments: map[string]string{"namespace": "shop", "name": "  "},
+			wantErr:   true,
+		},
+		{
+			name:      "review manifest without namespace",
+			prompt:    "review_manifest",
+			arguments: map[string]string{"manifest": "kind: ConfigMap"},
+			contains:  []string{"```yaml\nkind: ConfigMap\n```"},
+			excludes:  []string{"to be applied to the namespace"},
+		},
+		{
+			name:      "plan safe rollout with change",
+			prompt:    "plan_safe_rollout",
+			arguments: map[string]string{"namespace": "shop", "name": "checkout", "change": "image checkout:1.4"},
+			contains:  []string{"deployment checkout in the namespace shop: image checkout:1.4."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderPrompt(prompts[tt.prompt], tt.arguments)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Messages) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(result.Messages))
+			}
+			text := result.Messages[0].Content.(*mcp.TextContent).Text
+			for _, s := range tt.contains {
+				if !strings.Contains(text, s) {
+					t.Errorf("expected prompt to contain %q, got:\n%s", s, text)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(text, s) {
+					t.Errorf("expected prompt not to contain %q, got:\n%s", s, text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// protectedResourceMetadataPath is the well-known path of the OAuth protected resource metadata (RFC 9728).
+	protectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+	// mcpEndpointPath is the path of the MCP endpoint, the protected resource.
+	mcpEndpointPath = "/mcp"
+)
+
+// ProtectedResourceMetadata is the OAuth protected resource metadata of k-mcp (RFC 9728), telling
+// the MCP clients which authorization servers issue the tokens it accepts.
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported"`
+	ResourceName           string   `json:"resource_name,omitempty"`
+}
+
+// resourceURL returns the URL of the MCP endpoint, as configured or as reached by the request.
+func (s *Server) resourceURL(r *http.Request) string {
+	if s.ResourceURL != "" {
+		return s.ResourceURL
+	}
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, mcpEndpointPath)
+}
+
+// resourceMetadataURL returns the URL of the metadata of the resource, the well-known path being
+// inserted between the host and the path of the resource.
+func (s *Server) resourceMetadataURL(r *http.Request) string {
+	resource := s.resourceURL(r)
+	scheme, rest, _ := strings.Cut(resource, "://")
+	host, path, _ := strings.Cut(rest, "/")
+	if path != "" {
+		path = "/" + path
+	}
+	return fmt.Sprintf("%s://%s%s%s", scheme, host, protectedResourceMetadataPath, path)
+}
+
+// protectedResourceMetadataHandler serves the metadata of the MCP endpoint, advertising the trusted
+// issuers as its authorization servers.
+func (s *Server) protectedResourceMetadataHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		// The metadata is public and read by browser based clients.
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(ProtectedResourceMetadata{
+			Resource:               s.resourceURL(r),
+			AuthorizationServers:   s.Issuers,
+			BearerMethodsSupported: []string{"header"},
+			ResourceName:           "k-mcp",
+		})
+	})
+}
+
+// challengeWriter adds the WWW-Authenticate challenge to the responses refusing the token.
+type challengeWriter struct {
+	http.ResponseWriter
+	challenge func(code int) string
+}
+
+func (cw *challengeWriter) WriteHeader(code int) {
+	if challenge := cw.challenge(code); challenge != "" {
+		cw.ResponseWriter.Header().Set("WWW-Authenticate", challenge)
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *challengeWriter) Flush() {
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// bearerChallengeHandler adds a Bearer challenge (RFC 6750) pointing to the metadata of the resource to
+// the responses refusing the token of the request, so that the MCP clients can discover how to get one.
+func (s *Server) bearerChallengeHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cw := &challengeWriter{ResponseWriter: w, challenge: func(code int) string {
+			var errorCode string
+			switch {
+			case code == http.StatusForbidden:
+				errorCode = "insufficient_scope"
+			case code != http.StatusUnauthorized:
+				return ""
+			case r.Header.Get("Authorization") != "":
+				// Requests without credentials get no error code.
+				errorCode = "invalid_token"
+			}
+			challenge := fmt.Sprintf(`Bearer realm="k-mcp", resource_metadata=%q`, s.resourceMetadataURL(r))
+			if errorCode != "" {
+				challenge += fmt.Sprintf(`, error=%q`, errorCode)
+			}
+			return challenge
+		}}
+		handler.ServeHTTP(cw, r)
+	})
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+func TestProtectedResourceMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		resourceURL string
+		issuers     []string
+		header      map[string]string
+		expected    ProtectedResourceMetadata
+	}{
+		{
+			name:     "derived from the request",
+			expected: ProtectedResourceMetadata{Resource: "http://k-mcp.example.com/mcp", BearerMethodsSupported: []string{"header"}, ResourceName: "k-mcp"},
+		},
+		{
+			name:     "behind a TLS terminating proxy",
+			header:   map[string]string{"X-Forwarded-Proto": "https"},
+			issuers:  []string{"https://issuer.example.com"},
+			expected: ProtectedResourceMetadata{Resource: "https://k-mcp.example.com/mcp", AuthorizationServers: []string{"https://issuer.example.com"}, BearerMethodsSupported: []string{"header"}, ResourceName: "k-mcp"},
+		},
+		{
+			name:        "configured",
+			resourceURL: "https://agents.example.com/k-mcp/mcp",
+			expected:    ProtectedResourceMetadata{Resource: "https://agents.example.com/k-mcp/mcp", BearerMethodsSupported: []string{"header"}, ResourceName: "k-mcp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("8080", "k-mcp")
+			s.ResourceURL = tt.resourceURL
+			s.Issuers = tt.issuers
+			req := httptest.NewRequest(http.MethodGet, "http://k-mcp.example.com"+protectedResourceMetadataPath, nil)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			s.protectedResourceMetadataHandler().ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+				t.Fatalf("expected a JSON document, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+			}
+			var metadata ProtectedResourceMetadata
+			if err := json.Unmarshal(rec.Body.Bytes(), &metadata); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(metadata, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, metadata)
+			}
+		})
+	}
+}
+
+func TestBearerChallenge(t *testing.T) {
+	tests := []struct {
+		name          string
+		resourceURL   string
+		authorization string
+		code          int
+		expected      string
+	}{
+		{
+			name:     "no token",
+			code:     http.StatusUnauthorized,
+			expected: `Bearer realm="k-mcp", resource_metadata="http://k-mcp.example.com/.well-known/oauth-protected-resource/mcp"`,
+		},
+		{
+			name:          "invalid token",
+			resourceURL:   "https://agents.example.com/k-mcp/mcp",
+			authorization: "Bearer invalid",
+			code:          http.StatusUnauthorized,
+			expected:      `Bearer realm="k-mcp", resource_metadata="https://agents.example.com/.well-known/oauth-protected-resource/k-mcp/mcp", error="invalid_token"`,
+		},
+		{
+			name:          "valid token",
+			authorization: "Bearer valid",
+			code:          http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("8080", "k-mcp")
+			s.ResourceURL = tt.resourceURL
+			verifier := func(ctx context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+				if token != "valid" {
+					return nil, auth.ErrInvalidToken
+				}
+				return &auth.TokenInfo{Expiration: time.Now().Add(time.Hour)}, nil
+			}
+			handler := s.bearerChallengeHandler(auth.RequireBearerToken(verifier, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+			req := httptest.NewRequest(http.MethodPost, "http://k-mcp.example.com/mcp", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Fatalf("expected status %d, got %d", tt.code, rec.Code)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); got != tt.expected {
+				t.Errorf("expected challenge %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
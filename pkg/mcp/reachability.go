@@ -0,0 +1,333 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// reachabilityProbeTimeout bounds a single reachability probe.
+	reachabilityProbeTimeout = 5 * time.Second
+	// maxObservedAPIServers bounds the number of API servers derived from tokens that are probed,
+	// since token audiences are chosen by the clients.
+	maxObservedAPIServers = 64
+)
+
+// Reachability is the result of the last reachability probe of an API server.
+type Reachability struct {
+	APIServerURL string    `json:"apiServerUrl"`
+	Reachable    bool      `json:"reachable"`
+	Error        string    `json:"error,omitempty"`
+	CheckedAt    time.Time `json:"checkedAt"`
+	// LatencyMilliseconds is how long the API server took to answer the probe.
+	LatencyMilliseconds int64 `json:"latencyMilliseconds,omitempty"`
+}
+
+// LocalCheck is the result of a check of the local resources k-mcp needs to serve the tool calls.
+type LocalCheck struct {
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
+}
+
+// reachabilityProber probes whether the API servers can be reached with the configured TLS settings,
+// so that CA or DNS misconfigurations surface before the first tool call fails. The configured API
+// servers decide the readiness of k-mcp, the API servers derived from the tokens are only reported.
+type reachabilityProber struct {
+	dynamicConfig *DynamicConfig
+	// probe is replaced in tests.
+	probe func(ctx context.Context, apiServerURL string) error
+	// localChecks checks the local resources, nil means there are none to check.
+	localChecks func() []LocalCheck
+
+	mu         sync.Mutex
+	configured []string
+	results    map[string]*Reachability
+}
+
+func newReachabilityProber(dynamicConfig *DynamicConfig, configured []string) *reachabilityProber {
+	p := &reachabilityProber{
+		dynamicConfig: dynamicConfig,
+		configured:    configured,
+		results:       make(map[string]*Reachability),
+	}
+	p.probe = p.probeAPIServer
+	if dynamicConfig != nil {
+		p.localChecks = dynamicConfig.localChecks
+	}
+	for _, apiServerURL := range configured {
+		p.results[apiServerURL] = nil
+	}
+	return p
+}
+
+// probeAPIServer sends an unauthenticated request to the API server. Any HTTP response, even
+// 401 Unauthorized or 403 Forbidden, proves that the API server is reachable and trusted.
+func (p *reachabilityProber) probeAPIServer(ctx context.Context, apiServerURL string) error {
+	client, err := rest.HTTPClientFor(p.dynamicConfig.restConfig("", apiServerURL))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, reachabilityProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(apiServerURL, "/")+"/livez", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// measure probes an API server.
+func (p *reachabilityProber) measure(ctx context.Context, apiServerURL string) *Reachability {
+	result := &Reachability{APIServerURL: apiServerURL, Reachable: true, CheckedAt: time.Now()}
+	if err := p.probe(ctx, apiServerURL); err != nil {
+		result.Reachable = false
+		result.Error = err.Error()
+		slog.Warn("API server is not reachable", "apiServerUrl", apiServerURL, "err", err)
+	} else {
+		result.LatencyMilliseconds = time.Since(result.CheckedAt).Milliseconds()
+	}
+	return result
+}
+
+// check probes an API server and records the result.
+func (p *reachabilityProber) check(ctx context.Context, apiServerURL string) *Reachability {
+	result := p.measure(ctx, apiServerURL)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.results[apiServerURL] = result
+	return result
+}
+
+// latest returns the result of the last probe of an API server, probing it first when it was not
+// probed yet. The result of an API server derived from a token is only recorded within the bound of
+// the observed API servers.
+func (p *reachabilityProber) latest(ctx context.Context, apiServerURL string) *Reachability {
+	p.mu.Lock()
+	result, known := p.results[apiServerURL]
+	p.mu.Unlock()
+	if result != nil {
+		return result
+	}
+
+	result = p.measure(ctx, apiServerURL)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if known || len(p.results) < len(p.configured)+maxObservedAPIServers {
+		p.results[apiServerURL] = result
+	}
+	return result
+}
+
+// checkAll probes every known API server.
+func (p *reachabilityProber) checkAll(ctx context.Context) {
+	p.mu.Lock()
+	apiServerURLs := make([]string, 0, len(p.results))
+	for apiServerURL := range p.results {
+		apiServerURLs = append(apiServerURLs, apiServerURL)
+	}
+	p.mu.Unlock()
+
+	for _, apiServerURL := range apiServerURLs {
+		p.check(ctx, apiServerURL)
+	}
+}
+
+// run probes the known API servers at startup and then at every interval, if positive.
+func (p *reachabilityProber) run(ctx context.Context, interval time.Duration) {
+	p.checkAll(ctx)
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkAll(ctx)
+		}
+	}
+}
+
+// observe starts probing an API server derived from a token, checking it right away the first time.
+func (p *reachabilityProber) observe(apiServerURL string) {
+	p.mu.Lock()
+	_, known := p.results[apiServerURL]
+	if known || len(p.results) >= len(p.configured)+maxObservedAPIServers {
+		p.mu.Unlock()
+		return
+	}
+	p.results[apiServerURL] = nil
+	p.mu.Unlock()
+
+	go p.check(context.Background(), apiServerURL)
+}
+
+// get returns the result of the last probe of an API server, nil if it was not probed yet.
+func (p *reachabilityProber) get(apiServerURL string) *Reachability {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.results[apiServerURL]
+}
+
+// ready returns whether every configured API server was reachable at the last probe,
+// together with their results.
+func (p *reachabilityProber) ready() (bool, []Reachability) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ready := true
+	results := []Reachability{}
+	for _, apiServerURL := range p.configured {
+		result := p.results[apiServerURL]
+		if result == nil {
+			ready = false
+			results = append(results, Reachability{APIServerURL: apiServerURL, Error: "not probed yet"})
+			continue
+		}
+		ready = ready && result.Reachable
+		results = append(results, *result)
+	}
+	slices.SortFunc(results, func(a, b Reachability) int { return strings.Compare(a.APIServerURL, b.APIServerURL) })
+	return ready, results
+}
+
+// ServeHTTP serves the readiness of k-mcp, unready while a configured API server can not be reached
+// or a local resource, like the TLS settings or the discovery cache, can not be used.
+func (p *reachabilityProber) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	ready, results := p.ready()
+	checks := []LocalCheck{}
+	if p.localChecks != nil {
+		checks = p.localChecks()
+	}
+	for _, check := range checks {
+		ready = ready && check.Ready
+	}
+	status := "ready"
+	w.Header().Set("Content-Type", "application/json")
+	if !ready {
+		status = "unready"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	//nolint:errcheck
+	json.NewEncoder(w).Encode(map[string]any{
+		"status":     status,
+		"apiServers": results,
+		"checks":     checks,
+	})
+}
+
+// localChecks checks that the TLS settings of the API servers load, their CA files being readable
+// and valid, and that the discovery cache can be written. They are checked on every readiness
+// request, since the files can change or be removed at any time.
+func (d *DynamicConfig) localChecks() []LocalCheck {
+	var checks []LocalCheck
+	add := func(name string, err error) {
+		check := LocalCheck{Name: name, Ready: err == nil}
+		if err != nil {
+			check.Error = err.Error()
+		}
+		checks = append(checks, check)
+	}
+
+	if d.Cluster != nil {
+		_, err := rest.TLSConfigFor(d.Cluster)
+		add("tls", err)
+	} else {
+		_, err := rest.TLSConfigFor(&rest.Config{TLSClientConfig: d.tlsFor("")})
+		add("tls", err)
+
+		d.mu.RLock()
+		hosts := make([]string, 0, len(d.APIServerTLS))
+		for host := range d.APIServerTLS {
+			hosts = append(hosts, host)
+		}
+		d.mu.RUnlock()
+		slices.Sort(hosts)
+		for _, host := range hosts {
+			_, err := rest.TLSConfigFor(&rest.Config{TLSClientConfig: d.tlsFor("https://" + host)})
+			add("tls "+host, err)
+		}
+	}
+
+	if !d.DisableDiscoveryCache {
+		add("discovery cache", checkWritable(d.discoveryCacheDir()))
+	}
+	return checks
+}
+
+// checkWritable checks that files can be created in the directory, creating it if needed.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".readyz-")
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(f.Name())
+}
+
+// middleware starts probing the API server of the token of every initialized session.
+func (p *reachabilityProber) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if method != methodInitialized || err != nil {
+				return result, err
+			}
+			if extra := req.GetExtra(); extra != nil && extra.TokenInfo != nil {
+				if apiServerURL, ok := extra.TokenInfo.Extra["audience"].(string); ok {
+					p.observe(apiServerURL)
+				}
+			}
+			return result, err
+		}
+	}
+}
+
+// reachabilityError explains why a request to an unreachable API server failed.
+func reachabilityError(result *Reachability) string {
+	if result == nil || result.Reachable {
+		return ""
+	}
+	return fmt.Sprintf("API server %s was not reachable at %s: %s", result.APIServerURL, result.CheckedAt.Format(time.RFC3339), result.Error)
+}
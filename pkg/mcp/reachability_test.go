@@ -0,0 +1,191 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProbeAPIServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/livez" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("expected an unauthenticated probe")
+		}
+		// API servers without anonymous access are reachable too.
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	prober := newReachabilityProber(NewDynamicConfig("", false, ""), nil)
+	if result := prober.check(context.TODO(), server.URL); !result.Reachable {
+		t.Errorf("expected %s to be reachable, got %s", server.URL, result.Error)
+	}
+	// The certificate of the test server is not trusted.
+	result := prober.check(context.TODO(), tlsServer.URL)
+	if result.Reachable || !strings.Contains(result.Error, "certificate") {
+		t.Errorf("expected %s to be unreachable because of its certificate, got %+v", tlsServer.URL, result)
+	}
+}
+
+func TestReachabilityProberReadiness(t *testing.T) {
+	unreachable := map[string]bool{}
+	prober := newReachabilityProber(nil, []string{"https://b.example.com", "https://a.example.com"})
+	prober.probe = func(_ context.Context, apiServerURL string) error {
+		if unreachable[apiServerURL] {
+			return &http.ProtocolError{ErrorString: "no such host"}
+		}
+		return nil
+	}
+
+	// Not ready until the configured API servers are probed.
+	if ready, _ := prober.ready(); ready {
+		t.Errorf("expected not ready before the first probe")
+	}
+
+	prober.checkAll(context.TODO())
+	ready, results := prober.ready()
+	if !ready || len(results) != 2 || results[0].APIServerURL != "https://a.example.com" {
+		t.Errorf("expected ready with sorted results, got %t %+v", ready, results)
+	}
+
+	// API servers derived from tokens do not change the readiness.
+	unreachable["https://c.example.com"] = true
+	prober.check(context.TODO(), "https://c.example.com")
+	if ready, _ := prober.ready(); !ready {
+		t.Errorf("expected derived API servers to be ignored by the readiness")
+	}
+	if reason := reachabilityError(prober.get("https://c.example.com")); !strings.Contains(reason, "no such host") {
+		t.Errorf("expected the probe error to be reported, got %q", reason)
+	}
+
+	unreachable["https://a.example.com"] = true
+	prober.checkAll(context.TODO())
+	recorder := httptest.NewRecorder()
+	prober.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), "no such host") {
+		t.Errorf("expected unready response, got %d %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestLocalChecks(t *testing.T) {
+	dir := t.TempDir()
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+	validCA := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(validCA, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalidCA := filepath.Join(dir, "invalid.crt")
+	if err := os.WriteFile(invalidCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	notADir := filepath.Join(dir, "file")
+	if err := os.WriteFile(notADir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		config   func() *DynamicConfig
+		expected map[string]bool
+	}{
+		{
+			name: "ready",
+			config: func() *DynamicConfig {
+				d := NewDynamicConfig(validCA, false, "")
+				d.DiscoveryCacheDir = filepath.Join(dir, "cache")
+				d.APIServerTLS = map[string]APIServerTLS{"a.example.com:443": {Host: "a.example.com", CertificateAuthority: validCA}}
+				return d
+			},
+			expected: map[string]bool{"tls": true, "tls a.example.com:443": true, "discovery cache": true},
+		},
+		{
+			name: "missing and invalid CA",
+			config: func() *DynamicConfig {
+				d := NewDynamicConfig(filepath.Join(dir, "missing.crt"), false, "")
+				d.DiscoveryCacheDir = filepath.Join(dir, "cache")
+				d.APIServerTLS = map[string]APIServerTLS{"a.example.com:443": {Host: "a.example.com", CertificateAuthority: invalidCA}}
+				return d
+			},
+			expected: map[string]bool{"tls": false, "tls a.example.com:443": false, "discovery cache": true},
+		},
+		{
+			name: "discovery cache not writable",
+			config: func() *DynamicConfig {
+				d := NewDynamicConfig("", false, "")
+				d.DiscoveryCacheDir = filepath.Join(notADir, "cache")
+				return d
+			},
+			expected: map[string]bool{"tls": true, "discovery cache": false},
+		},
+		{
+			name: "discovery cache disabled",
+			config: func() *DynamicConfig {
+				d := NewDynamicConfig("", false, "")
+				d.DiscoveryCacheDir = filepath.Join(notADir, "cache")
+				d.DisableDiscoveryCache = true
+				return d
+			},
+			expected: map[string]bool{"tls": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := tt.config().localChecks()
+			got := map[string]bool{}
+			for _, check := range checks {
+				got[check.Name] = check.Ready
+				if !check.Ready && check.Error == "" {
+					t.Errorf("expected the error of check %s", check.Name)
+				}
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected checks %v, got %+v", tt.expected, checks)
+			}
+			for name, ready := range tt.expected {
+				if got[name] != ready {
+					t.Errorf("expected check %s ready %t, got %+v", name, ready, checks)
+				}
+			}
+		})
+	}
+}
+
+func TestReadinessLocalChecks(t *testing.T) {
+	prober := newReachabilityProber(nil, nil)
+	prober.localChecks = func() []LocalCheck {
+		return []LocalCheck{{Name: "discovery cache", Error: "read-only file system"}}
+	}
+	recorder := httptest.NewRecorder()
+	prober.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), "read-only file system") {
+		t.Errorf("expected unready response, got %d %s", recorder.Code, recorder.Body.String())
+	}
+}
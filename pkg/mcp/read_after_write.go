@@ -0,0 +1,32 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// readAfterWrite re-reads an object written by k-mcp, so that the state returned to the agent includes
+// the changes made since the write by controllers and webhooks. The resource version of the write makes
+// the API server answer with a state not older than the write, even from its watch cache.
+func readAfterWrite(ctx context.Context, resource dynamic.ResourceInterface, written *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return resource.Get(ctx, written.GetName(), v1.GetOptions{ResourceVersion: written.GetResourceVersion()})
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// redactedValue replaces the redacted values.
+const redactedValue = "REDACTED"
+
+// DefaultRedactEnvPatterns are the default patterns of the names and values of the redacted
+// environment variables.
+var DefaultRedactEnvPatterns = []string{`(?i)passw(or)?d|secret|token|api[_-]?key|private[_-]?key|credential`}
+
+// sensitiveAnnotations are the annotations that can carry sensitive values, such as the
+// last applied configuration of a Secret or of a workload with inline credentials.
+var sensitiveAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"openshift.io/token-secret.value",
+}
+
+// redactor scrubs the sensitive values of the objects returned to the clients and logged: the data
+// of the Secrets, the sensitive annotations, and the values of the environment variables whose name
+// or value matches one of its patterns.
+type redactor struct {
+	envPatterns []*regexp.Regexp
+}
+
+func newRedactor(envPatterns []string) (*redactor, error) {
+	r := &redactor{}
+	for _, pattern := range envPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.envPatterns = append(r.envPatterns, re)
+	}
+	return r, nil
+}
+
+// redact returns the value with its sensitive values redacted, as decoded from JSON.
+func (r *redactor) redact(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	r.redactValue(decoded)
+	return decoded, nil
+}
+
+// redactValue redacts the objects found anywhere in the decoded JSON value, in place.
+func (r *redactor) redactValue(value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		r.redactObject(value)
+		for _, field := range value {
+			r.redactValue(field)
+		}
+	case []any:
+		for _, item := range value {
+			r.redactValue(item)
+		}
+	}
+}
+
+// redactObject redacts the sensitive fields of a map, which may be an object, a container or any
+// other part of an object.
+func (r *redactor) redactObject(obj map[string]any) {
+	if obj["kind"] == "Secret" && obj["apiVersion"] == "v1" {
+		for _, field := range []string{"data", "stringData"} {
+			if data, ok := obj[field].(map[string]any); ok {
+				for key := range data {
+					data[key] = redactedValue
+				}
+			}
+		}
+	}
+
+	if metadata, ok := obj["metadata"].(map[string]any); ok {
+		if annotations, ok := metadata["annotations"].(map[string]any); ok {
+			for _, annotation := range sensitiveAnnotations {
+				if _, ok := annotations[annotation]; ok {
+					annotations[annotation] = redactedValue
+				}
+			}
+		}
+	}
+
+	env, _ := obj["env"].([]any)
+	for _, item := range env {
+		variable, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := variable["name"].(string)
+		value, ok := variable["value"].(string)
+		if ok && (r.matches(name) || r.matches(value)) {
+			variable["value"] = redactedValue
+		}
+	}
+}
+
+func (r *redactor) matches(s string) bool {
+	for _, re := range r.envPatterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactArguments returns the arguments of a tool call with their sensitive values redacted, the
+// manifests of resource_apply being decoded to redact their objects.
+func (r *redactor) redactArguments(arguments json.RawMessage) any {
+	var decoded map[string]any
+	if err := json.Unmarshal(arguments, &decoded); err != nil {
+		return redactedValue
+	}
+	if manifest, ok := decoded["resourceYAML"].(string); ok {
+		manifests, err := decodeManifests(manifest)
+		if err != nil {
+			decoded["resourceYAML"] = redactedValue
+		} else {
+			objects := make([]any, 0, len(manifests))
+			for _, manifest := range manifests {
+				objects = append(objects, manifest.Object)
+			}
+			decoded["resourceYAML"] = objects
+		}
+	}
+	r.redactValue(decoded)
+	return decoded
+}
+
+// middleware redacts the structured content of the tool results. The results that can not be
+// redacted are replaced with an error rather than returned as is.
+func (r *redactor) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			toolResult, ok := result.(*mcp.CallToolResult)
+			if err != nil || method != methodCallTool || !ok || toolResult.StructuredContent == nil {
+				return result, err
+			}
+
+			redacted, redactErr := r.redact(toolResult.StructuredContent)
+			if redactErr != nil {
+				slog.ErrorContext(ctx, "Failed to redact the tool result", "err", redactErr)
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{Text: "failed to redact the result"}},
+					IsError: true,
+				}, nil
+			}
+			toolResult.StructuredContent = redacted
+			return toolResult, nil
+		}
+	}
+}
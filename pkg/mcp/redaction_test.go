@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRedactor(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{
+			name:     "secret",
+			value:    `{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "db"}, "data": {"password": "aHVudGVyMg=="}, "stringData": {"user": "admin"}}`,
+			expected: `{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "db"}, "data": {"password": "REDACTED"}, "stringData": {"user": "REDACTED"}}`,
+		},
+		{
+			name:     "secrets in a list result",
+			value:    `{"resources": [{"apiVersion": "v1", "kind": "Secret", "data": {"token": "dG9rZW4="}}], "continue": "abc"}`,
+			expected: `{"resources": [{"apiVersion": "v1", "kind": "Secret", "data": {"token": "REDACTED"}}], "continue": "abc"}`,
+		},
+		{
+			name: "sensitive annotations",
+			value: `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "annotations": {
+				"kubectl.kubernetes.io/last-applied-configuration": "{\"spec\":{}}", "team": "shop"}}}`,
+			expected: `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "annotations": {
+				"kubectl.kubernetes.io/last-applied-configuration": "REDACTED", "team": "shop"}}}`,
+		},
+		{
+			name: "environment variables of a pod template",
+			value: `{"kind": "Deployment", "spec": {"template": {"spec": {"containers": [{"name": "web", "env": [
+				{"name": "DB_PASSWORD", "value": "hunter2"},
+				{"name": "DATABASE_URL", "value": "postgres://web:s3cr3t@db"},
+				{"name": "GITHUB_TOKEN", "valueFrom": {"secretKeyRef": {"name": "github", "key": "token"}}},
+				{"name": "LOG_LEVEL", "value": "debug"},
+				{"name": "CONNECTION", "value": "password=hunter2"}]}]}}}}`,
+			expected: `{"kind": "Deployment", "spec": {"template": {"spec": {"containers": [{"name": "web", "env": [
+				{"name": "DB_PASSWORD", "value": "REDACTED"},
+				{"name": "DATABASE_URL", "value": "postgres://web:s3cr3t@db"},
+				{"name": "GITHUB_TOKEN", "valueFrom": {"secretKeyRef": {"name": "github", "key": "token"}}},
+				{"name": "LOG_LEVEL", "value": "debug"},
+				{"name": "CONNECTION", "value": "REDACTED"}]}]}}}}`,
+		},
+		{
+			name:     "secret of another group",
+			value:    `{"apiVersion": "example.com/v1", "kind": "Secret", "data": {"name": "kept"}}`,
+			expected: `{"apiVersion": "example.com/v1", "kind": "Secret", "data": {"name": "kept"}}`,
+		},
+	}
+
+	r, err := newRedactor(DefaultRedactEnvPatterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value, expected any
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			redacted, err := r.redact(value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(redacted, expected) {
+				t.Errorf("expected %v, got %v", expected, redacted)
+			}
+		})
+	}
+}
+
+func TestRedactorCustomPatterns(t *testing.T) {
+	if _, err := newRedactor([]string{"("}); err == nil {
+		t.Errorf("expected an invalid pattern to fail")
+	}
+
+	r, err := newRedactor([]string{`^INTERNAL_`, `://[^/]+:[^@]+@`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arguments := json.RawMessage(`{"resourceYAML": "apiVersion: v1\nkind: Pod\nmetadata:\n  name: web\nspec:\n  containers:\n  - name: web\n    env:\n    - name: INTERNAL_ENDPOINT\n      value: http://10.0.0.1\n    - name: DATABASE_URL\n      value: postgres://web:s3cr3t@db\n    - name: DB_PASSWORD\n      value: hunter2\n", "wait": true}`)
+	expected := map[string]any{
+		"resourceYAML": []any{map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]any{"name": "web"},
+			"spec": map[string]any{"containers": []any{map[string]any{"name": "web", "env": []any{
+				map[string]any{"name": "INTERNAL_ENDPOINT", "value": redactedValue},
+				map[string]any{"name": "DATABASE_URL", "value": redactedValue},
+				// Not matching the configured patterns, which replace the defaults.
+				map[string]any{"name": "DB_PASSWORD", "value": "hunter2"},
+			}}}},
+		}},
+		"wait": true,
+	}
+	if got := r.redactArguments(arguments); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestRedactorMiddleware(t *testing.T) {
+	r, err := newRedactor(DefaultRedactEnvPatterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := r.middleware()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{StructuredContent: &ResourceListResult{Resources: []map[string]any{
+			{"apiVersion": "v1", "kind": "Secret", "data": map[string]any{"key": "dmFsdWU="}},
+		}}}, nil
+	})
+
+	result, err := handler(context.Background(), methodCallTool, &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "resource_list"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := json.Marshal(result.(*mcp.CallToolResult).StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"resources":[{"apiVersion":"v1","data":{"key":"REDACTED"},"kind":"Secret"}]}`; string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
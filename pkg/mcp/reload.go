@@ -0,0 +1,195 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ReloadableConfig is the part of the configuration of a running server that is applied again on
+// SIGHUP, without dropping the sessions.
+type ReloadableConfig struct {
+	Toolsets             []string
+	ReadOnly             bool
+	SummaryColumns       SummaryColumns
+	ConformanceProfiles  []ConformanceProfile
+	ImpactThreshold      int
+	ProductionNamespaces []string
+	// APIServerTLS replaces the TLS settings of the API servers of the DynamicConfig.
+	APIServerTLS map[string]APIServerTLS
+	// Clusters replaces the cluster registry, whose names the cluster input of the tools offers.
+	Clusters []Cluster
+}
+
+// reloadable returns the current reloadable configuration of the server.
+func (s *Server) reloadable() ReloadableConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return ReloadableConfig{
+		Toolsets:             s.Toolsets,
+		ReadOnly:             s.ReadOnly,
+		SummaryColumns:       s.SummaryColumns,
+		ConformanceProfiles:  s.ConformanceProfiles,
+		ImpactThreshold:      s.ImpactThreshold,
+		ProductionNamespaces: s.ProductionNamespaces,
+		Clusters:             s.Clusters,
+	}
+}
+
+// reload applies the configuration returned by the Reload function of the server. The current
+// configuration is kept when it fails.
+func (s *Server) reload(dynamicConfig *DynamicConfig) error {
+	if s.Reload == nil {
+		return fmt.Errorf("the configuration can not be reloaded")
+	}
+	config, err := s.Reload()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.Toolsets = config.Toolsets
+	s.ReadOnly = config.ReadOnly
+	s.SummaryColumns = config.SummaryColumns
+	s.ConformanceProfiles = config.ConformanceProfiles
+	s.ImpactThreshold = config.ImpactThreshold
+	s.ProductionNamespaces = config.ProductionNamespaces
+	s.Clusters = config.Clusters
+	s.mu.Unlock()
+	dynamicConfig.setAPIServerTLS(config.APIServerTLS)
+	if s.clusters != nil {
+		s.clusters.setRegistry(config.Clusters)
+	}
+	// The enabled tools and the clusters offered by their cluster input may have changed.
+	if s.toolsChanged != nil {
+		s.toolsChanged()
+	}
+
+	slog.Info("Reloaded the configuration", "disabled_tools", disabledTools(config.Toolsets, config.ReadOnly))
+	return nil
+}
+
+// watchInterval is how often the watched files are checked for changes.
+const watchInterval = 5 * time.Second
+
+// reloadOnHangup reloads the configuration on every SIGHUP and on every change of the watched files
+// until the context is cancelled.
+func (s *Server) reloadOnHangup(ctx context.Context, dynamicConfig *DynamicConfig) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	changed := make(chan struct{}, 1)
+	if len(s.WatchedFiles) > 0 && s.Reload != nil {
+		go watchFiles(ctx, s.WatchedFiles, watchInterval, changed)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		case <-changed:
+			slog.Info("The configuration files changed")
+		}
+		if err := s.reload(dynamicConfig); err != nil {
+			slog.Error("Failed to reload the configuration, keeping the current one", "err", err)
+		}
+	}
+}
+
+// fileVersion identifies the content of a file without reading it.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileVersion {
+	info, err := os.Stat(path)
+	if err != nil {
+		// A missing file is a version too, its reload failing until it is written again.
+		return fileVersion{}
+	}
+	return fileVersion{modTime: info.ModTime(), size: info.Size()}
+}
+
+// watchFiles polls the files at every interval, notifying changed when one of them changed, until the
+// context is cancelled. Polling keeps working when the files are replaced, like the ConfigMaps mounted
+// as volumes.
+func watchFiles(ctx context.Context, files []string, interval time.Duration, changed chan<- struct{}) {
+	versions := make([]fileVersion, len(files))
+	for i, file := range files {
+		versions[i] = statFile(file)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		modified := false
+		for i, file := range files {
+			if version := statFile(file); version != versions[i] {
+				versions[i] = version
+				modified = true
+			}
+		}
+		if modified {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// toolFilterMiddleware hides the tools disabled by the current toolsets and read-only mode, which
+// are filtered out of the tools lists and refused, so that they can be enabled again on reload.
+func (s *Server) toolFilterMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			config := s.reloadable()
+			disabled := disabledTools(config.Toolsets, config.ReadOnly)
+			if len(disabled) == 0 {
+				return next(ctx, method, req)
+			}
+
+			if call, ok := req.(*mcp.CallToolRequest); ok && slices.Contains(disabled, call.Params.Name) {
+				return nil, fmt.Errorf("unknown tool %q", call.Params.Name)
+			}
+			result, err := next(ctx, method, req)
+			if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+				list.Tools = slices.DeleteFunc(list.Tools, func(tool *mcp.Tool) bool {
+					return slices.Contains(disabled, tool.Name)
+				})
+			}
+			return result, err
+		}
+	}
+}
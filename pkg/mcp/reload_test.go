@@ -0,0 +1,156 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+
+	s := NewServer("8080", "k-mcp")
+	s.ReadOnly = true
+	s.ImpactThreshold = 20
+	dynamicConfig := NewDynamicConfig("", false, "")
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	for _, name := range []string{"resource_list", "resource_apply"} {
+		mcp.AddTool(server, &mcp.Tool{Name: name}, func(ctx context.Context, request *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name}}}, nil, nil
+		})
+	}
+	server.AddReceivingMiddleware(s.toolFilterMiddleware())
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	toolsChanged := make(chan struct{}, 1)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			select {
+			case toolsChanged <- struct{}{}:
+			default:
+			}
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	toolNames := func() []string {
+		list, err := session.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, tool := range list.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	if names := toolNames(); fmt.Sprint(names) != "[resource_list]" {
+		t.Errorf("expected the mutating tools to be hidden in read-only mode, got %v", names)
+	}
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "resource_apply", Arguments: map[string]any{}}); err == nil {
+		t.Errorf("expected the call of a disabled tool to fail")
+	}
+
+	if err := s.reload(dynamicConfig); err == nil {
+		t.Errorf("expected the reload to fail without reload function")
+	}
+	s.Reload = func() (*ReloadableConfig, error) {
+		return nil, fmt.Errorf("invalid configuration")
+	}
+	if err := s.reload(dynamicConfig); err == nil || !s.reloadable().ReadOnly {
+		t.Errorf("expected a failed reload to keep the configuration")
+	}
+
+	s.Reload = func() (*ReloadableConfig, error) {
+		return &ReloadableConfig{
+			ImpactThreshold: 50,
+			APIServerTLS:    map[string]APIServerTLS{"cluster-a.example.com:443": {Host: "cluster-a.example.com", TLSServerName: "cluster-a"}},
+			Clusters:        []Cluster{{Name: "cluster-a", Server: "https://cluster-a.example.com"}},
+		}, nil
+	}
+	s.clusters = &clusterAccess{}
+	s.toolsChanged = func() {
+		mcp.AddTool(server, &mcp.Tool{Name: "list_clusters"}, func(ctx context.Context, request *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+	}
+	if err := s.reload(dynamicConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-toolsChanged:
+	case <-time.After(5 * time.Second):
+		t.Errorf("expected the sessions to be notified that the tools changed")
+	}
+	if names := toolNames(); fmt.Sprint(names) != "[list_clusters resource_apply resource_list]" {
+		t.Errorf("expected the mutating tools to be enabled again, got %v", names)
+	}
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "resource_apply", Arguments: map[string]any{}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if threshold := s.reloadable().ImpactThreshold; threshold != 50 {
+		t.Errorf("expected the impact threshold to be reloaded, got %d", threshold)
+	}
+	if tls := dynamicConfig.tlsFor("https://cluster-a.example.com"); tls.ServerName != "cluster-a" {
+		t.Errorf("expected the TLS settings of the API servers to be reloaded, got %+v", tls)
+	}
+	if clusters := s.clusters.registry(); len(clusters) != 1 || clusters[0].Name != "cluster-a" {
+		t.Errorf("expected the cluster registry to be reloaded, got %v", clusters)
+	}
+}
+
+func TestWatchFiles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("port: 8080\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan struct{}, 1)
+	go watchFiles(ctx, []string{file}, 10*time.Millisecond, changed)
+
+	select {
+	case <-changed:
+		t.Fatalf("expected no change notification before the file changes")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := os.WriteFile(file, []byte("port: 8081\nread-only: true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a change notification after the file changed")
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// requestIDHeader carries the ID of the HTTP requests, accepted from the clients and returned to them.
+	requestIDHeader = "X-Request-Id"
+	// auditIDHeader sets the audit ID of the requests sent to the API servers, recorded in their audit logs.
+	auditIDHeader = "Audit-ID"
+	// maxRequestIDLength bounds the length of the request IDs accepted from the clients.
+	maxRequestIDLength = 128
+)
+
+type requestIDKey struct{}
+
+func withRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// requestIDFrom returns the request ID of the context, empty when there is none.
+func requestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// validRequestID tells whether a request ID given by a client is short and printable, so that it
+// can be written to the logs and sent to the API servers as is.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range requestID {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDMiddleware stores the request ID of the HTTP request of the MCP method in its context, or a
+// new one when it has none, e.g. with stdio.
+func requestIDMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		var requestID string
+		if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+			requestID = extra.Header.Get(requestIDHeader)
+		}
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		return next(withRequestID(ctx, requestID), method, req)
+	}
+}
+
+// requestIDRoundTripper sends the request ID of the tool call as the audit ID of the requests to
+// the API servers, so that they can be found in their audit logs.
+type requestIDRoundTripper struct {
+	delegate http.RoundTripper
+}
+
+func (rt *requestIDRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestID := requestIDFrom(req.Context())
+	if requestID == "" {
+		return rt.delegate.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(auditIDHeader, requestID)
+	return rt.delegate.RoundTrip(req)
+}
+
+// requestIDHandler adds the request ID of the context to the log records.
+type requestIDHandler struct {
+	slog.Handler
+}
+
+// NewRequestIDHandler returns a handler adding the request ID of the HTTP request or MCP method
+// being served to the records logged with its context.
+func NewRequestIDHandler(handler slog.Handler) slog.Handler {
+	return &requestIDHandler{Handler: handler}
+}
+
+func (h *requestIDHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := requestIDFrom(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &requestIDHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *requestIDHandler) WithGroup(name string) slog.Handler {
+	return &requestIDHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestLoggingHandlerRequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		generated bool
+	}{
+		{name: "accepted", requestID: "abc-123"},
+		{name: "generated", generated: true},
+		{name: "invalid replaced", requestID: "abc\n123", generated: true},
+		{name: "too long replaced", requestID: strings.Repeat("a", maxRequestIDLength+1), generated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header, fromContext string
+			handler := loggingHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Get(requestIDHeader)
+				fromContext = requestIDFrom(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.requestID != "" {
+				req.Header.Set(requestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			returned := rec.Header().Get(requestIDHeader)
+			if tt.generated && (returned == "" || returned == tt.requestID) {
+				t.Errorf("expected a generated request ID, got %q", returned)
+			}
+			if !tt.generated && returned != tt.requestID {
+				t.Errorf("expected request ID %q, got %q", tt.requestID, returned)
+			}
+			if header != returned || fromContext != returned {
+				t.Errorf("expected request ID %q in the header and the context, got %q and %q", returned, header, fromContext)
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var requestID string
+	handler := requestIDMiddleware(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		requestID = requestIDFrom(ctx)
+		return nil, nil
+	})
+
+	//nolint:errcheck
+	handler(context.Background(), methodCallTool, &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "resource_list"},
+		Extra:  &mcp.RequestExtra{Header: http.Header{requestIDHeader: {"abc-123"}}},
+	})
+	if requestID != "abc-123" {
+		t.Errorf("expected the request ID of the HTTP request, got %q", requestID)
+	}
+
+	//nolint:errcheck
+	handler(context.Background(), methodCallTool, &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "resource_list"},
+	})
+	if requestID == "" || requestID == "abc-123" {
+		t.Errorf("expected a generated request ID without HTTP request, got %q", requestID)
+	}
+}
+
+func TestRequestIDRoundTripper(t *testing.T) {
+	var auditID string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auditID = r.Header.Get(auditIDHeader)
+	}))
+	defer apiServer.Close()
+
+	client := &http.Client{Transport: &requestIDRoundTripper{delegate: http.DefaultTransport}}
+	req, err := http.NewRequestWithContext(withRequestID(context.Background(), "abc-123"), http.MethodGet, apiServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if auditID != "abc-123" {
+		t.Errorf("expected the request ID as audit ID, got %q", auditID)
+	}
+}
+
+func TestRequestIDHandler(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(NewRequestIDHandler(slog.NewTextHandler(&out, nil))).With("component", "test")
+
+	logger.InfoContext(withRequestID(context.Background(), "abc-123"), "tool called")
+	logger.Info("started")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", out.String())
+	}
+	if !strings.Contains(lines[0], "component=test request_id=abc-123") {
+		t.Errorf("expected the request ID in %q", lines[0])
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("expected no request ID in %q", lines[1])
+	}
+}
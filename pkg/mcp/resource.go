@@ -18,11 +18,15 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
+	"log/slog"
+	"sort"
 	"strings"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 )
@@ -44,52 +48,49 @@ func isRestrictedResource(gvr schema.GroupVersionResource) bool {
 	return false
 }
 
+type resourceMatch struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+}
+
 func FindResource(ctx context.Context, resourceName string, discoveryClient discovery.CachedDiscoveryInterface, session *mcp.ServerSession) (schema.GroupVersionResource, bool, error) {
+	gvr, namespaced, err := findResource(ctx, resourceName, discoveryClient, session)
+	if err == nil {
+		traceResource(ctx, gvr)
+		recordResource(ctx, gvr)
+	}
+	return gvr, namespaced, err
+}
+
+func findResource(ctx context.Context, resourceName string, discoveryClient discovery.CachedDiscoveryInterface, session *mcp.ServerSession) (schema.GroupVersionResource, bool, error) {
 	_, gk := schema.ParseKindArg(resourceName)
 
-	resources, err := discoveryClient.ServerPreferredResources()
+	index, err := resourceIndexFor(discoveryClient)
 	if err != nil {
-		return schema.GroupVersionResource{}, false, fmt.Errorf("failed to get server resources: %w", err)
-	}
-
-	type resourceMatch struct {
-		gvr        schema.GroupVersionResource
-		namespaced bool
+		return schema.GroupVersionResource{}, false, err
 	}
 
-	var exactMatches []resourceMatch
-	var partialMatches []resourceMatch
-
-	for _, resourceList := range resources {
-		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+	exactMatches := index.lookup(gk.Kind)
+	if len(exactMatches) == 0 && !discoveryClient.Fresh() {
+		// The resource may have been installed after the discovery cache was written.
+		discoveryClient.Invalidate()
+		index, err = resourceIndexFor(discoveryClient)
 		if err != nil {
-			continue
+			return schema.GroupVersionResource{}, false, err
 		}
+		exactMatches = index.lookup(gk.Kind)
+	}
+	var partialMatches []resourceMatch
+	var typo bool
+	if len(exactMatches) == 0 {
+		partialMatches, typo = index.suggestions(gk, resourceName)
+	}
+	failedGroups := index.failedGroups
 
-		for _, resource := range resourceList.APIResources {
-			currentMatch := resourceMatch{
-				gvr: schema.GroupVersionResource{
-					Group:    gv.Group,
-					Version:  gv.Version,
-					Resource: resource.Name,
-				},
-				namespaced: resource.Namespaced,
-			}
-
-			if isRestrictedResource(currentMatch.gvr) {
-				continue
-			}
-
-			if strings.EqualFold(resource.Kind, gk.Kind) || strings.EqualFold(resource.SingularName, gk.Kind) || strings.EqualFold(resource.Name, gk.Kind) {
-				exactMatches = append(exactMatches, currentMatch)
-			}
-
-			if strings.Contains(strings.ToLower(resource.Kind), strings.ToLower(gk.Kind)) ||
-				strings.Contains(strings.ToLower(resource.Name), strings.ToLower(resourceName)) ||
-				strings.Contains(strings.ToLower(resource.SingularName), strings.ToLower(resourceName)) {
-				partialMatches = append(partialMatches, currentMatch)
-			}
-		}
+	var discoveryWarning string
+	if len(failedGroups) > 0 {
+		discoveryWarning = fmt.Sprintf(" (discovery failed for groups: %s)", strings.Join(failedGroups, ", "))
+		notifyClient(ctx, session, "warning", fmt.Sprintf("Discovery failed for groups %s, their resources were skipped", strings.Join(failedGroups, ", ")))
 	}
 
 	if len(exactMatches) == 1 {
@@ -101,31 +102,32 @@ func FindResource(ctx context.Context, resourceName string, discoveryClient disc
 	}
 
 	if len(partialMatches) == 0 {
-		return schema.GroupVersionResource{}, false, fmt.Errorf("resource %q not found", resourceName)
+		return schema.GroupVersionResource{}, false, fmt.Errorf("resource %q not found%s", resourceName, discoveryWarning)
 	}
 
-	if len(partialMatches) == 1 {
+	// A resource only matching through typos is suggested, never used without the user confirming it.
+	if len(partialMatches) == 1 && !typo {
 		return partialMatches[0].gvr, partialMatches[0].namespaced, nil
 	}
 
-	if session == nil {
-		var options []string
-		for _, match := range partialMatches {
-			options = append(options, fmt.Sprintf("%s.%s.%s", match.gvr.Resource, match.gvr.Version, match.gvr.Group))
+	options := make([]string, 0, len(partialMatches))
+	for _, match := range partialMatches {
+		options = append(options, resourceOption(match.gvr))
+	}
+	notFoundErr := func() error {
+		if len(options) == 1 {
+			return fmt.Errorf("resource %q not found, did you mean %s?%s", resourceName, options[0], discoveryWarning)
 		}
-		return schema.GroupVersionResource{}, false, fmt.Errorf("resource %q not found, did you mean one of these: %s", resourceName, strings.Join(options, ", "))
+		return fmt.Errorf("resource %q not found, did you mean one of these: %s%s", resourceName, strings.Join(options, ", "), discoveryWarning)
 	}
-
-	var options []string
-	for i, match := range partialMatches {
-		options = append(options, fmt.Sprintf("%d. %s.%s.%s", i+1, match.gvr.Resource, match.gvr.Version, match.gvr.Group))
+	if session == nil {
+		return schema.GroupVersionResource{}, false, notFoundErr()
 	}
 
-	optionsText := "Did you mean one of these?\n" + strings.Join(options, "\n")
-
-	elicitResult, err := session.Elicit(ctx, &mcp.ElicitParams{
-		Message: fmt.Sprintf("Resource '%s' not found. %s", resourceName, optionsText),
-	})
+	elicitResult, err := session.Elicit(ctx, resourceChoice(resourceName, options))
+	if errors.Is(err, ErrElicitationUnsupported) {
+		return schema.GroupVersionResource{}, false, notFoundErr()
+	}
 	if err != nil {
 		return schema.GroupVersionResource{}, false, fmt.Errorf("failed to elicit user choice: %w", err)
 	}
@@ -134,15 +136,71 @@ func FindResource(ctx context.Context, resourceName string, discoveryClient disc
 		return schema.GroupVersionResource{}, false, fmt.Errorf("user cancelled resource selection")
 	}
 
-	choiceStr, ok := elicitResult.Content["choice"].(string)
-	if !ok {
-		return schema.GroupVersionResource{}, false, fmt.Errorf("invalid choice format")
+	choice, _ := elicitResult.Content[elicitationResourceField].(string)
+	for i, option := range options {
+		if option == choice {
+			return partialMatches[i].gvr, partialMatches[i].namespaced, nil
+		}
+	}
+	return schema.GroupVersionResource{}, false, fmt.Errorf("invalid choice: %q", choice)
+}
+
+// elicitationResourceField is the field of the elicitation choosing between the resources
+// matching a name.
+const elicitationResourceField = "resource"
+
+// resourceOption returns the fully qualified name of the resource offered to the user.
+func resourceOption(gvr schema.GroupVersionResource) string {
+	return fmt.Sprintf("%s.%s.%s", gvr.Resource, gvr.Version, gvr.Group)
+}
+
+// resourceChoice asks the user to choose one of the resources, offered as an enum so that the
+// clients render a picker. A single resource is offered for the user to confirm it.
+func resourceChoice(resourceName string, options []string) *mcp.ElicitParams {
+	enum := make([]any, 0, len(options))
+	for _, option := range options {
+		enum = append(enum, option)
+	}
+	message := fmt.Sprintf("Resource '%s' not found. Did you mean one of these?", resourceName)
+	if len(options) == 1 {
+		message = fmt.Sprintf("Resource '%s' not found. Did you mean '%s'?", resourceName, options[0])
+	}
+	return &mcp.ElicitParams{
+		Message: message,
+		RequestedSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				elicitationResourceField: {
+					Type:        "string",
+					Description: "The resource to use",
+					Enum:        enum,
+				},
+			},
+			Required: []string{elicitationResourceField},
+		},
+	}
+}
+
+// serverPreferredResources returns the preferred resources of the server. When some
+// API groups fail to be discovered (e.g. a broken aggregated API), the resources of the
+// groups that loaded are returned together with the list of failed groups.
+func serverPreferredResources(discoveryClient discovery.CachedDiscoveryInterface) ([]*v1.APIResourceList, []string, error) {
+	resources, err := discoveryClient.ServerPreferredResources()
+	if err == nil {
+		return resources, nil, nil
+	}
+
+	var groupErr *discovery.ErrGroupDiscoveryFailed
+	if !errors.As(err, &groupErr) || len(resources) == 0 {
+		return nil, nil, fmt.Errorf("failed to get server resources: %w", err)
 	}
 
-	choice, err := strconv.Atoi(choiceStr)
-	if err != nil || choice < 1 || choice > len(partialMatches) {
-		return schema.GroupVersionResource{}, false, fmt.Errorf("invalid choice: %s", choiceStr)
+	failedGroups := make([]string, 0, len(groupErr.Groups))
+	for gv := range groupErr.Groups {
+		failedGroups = append(failedGroups, gv.String())
 	}
+	sort.Strings(failedGroups)
+	slog.Warn("Discovery failed for some API groups, continuing with the groups that loaded", "groups", failedGroups, "err", err)
 
-	return partialMatches[choice-1].gvr, partialMatches[choice-1].namespaced, nil
+	return resources, failedGroups, nil
 }
@@ -0,0 +1,254 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	servicesGVR  = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	ingressesGVR = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+)
+
+// graphResources are the resources of the relationship graph of a namespace, in the order of its nodes.
+var graphResources = []schema.GroupVersionResource{ingressesGVR, servicesGVR, deploymentsGVR, statefulSetsGVR, daemonSetsGVR, cronJobsGVR, replicaSetsGVR, jobsGVR, podsGVR}
+
+// Relations of the edges of the relationship graph.
+const (
+	// graphRelationOwns links the owners to the objects they control, e.g. a Deployment to its ReplicaSets.
+	graphRelationOwns = "owns"
+	// graphRelationSelects links the Services to the pods matching their selector.
+	graphRelationSelects = "selects"
+	// graphRelationRoutes links the Ingresses to the Services of their backends.
+	graphRelationRoutes = "routes"
+)
+
+// Formats of the diagram of the relationship graph.
+const (
+	graphFormatMermaid = "mermaid"
+	graphFormatDOT     = "dot"
+)
+
+type ResourceGraphInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"The namespace of the resources"`
+	Format    string `json:"format,omitempty" jsonschema:"Return the graph as a diagram to render: mermaid or dot (optional defaults to a table of the relationships)"`
+}
+
+type ResourceGraphResult struct {
+	Namespace string      `json:"namespace"`
+	Nodes     []GraphNode `json:"nodes"`
+	Edges     []GraphEdge `json:"edges"`
+	// Diagram is the Mermaid or DOT text of the graph, when a format is given.
+	Diagram string `json:"diagram,omitempty"`
+	// Warnings are the resources that could not be listed, and are missing from the graph.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// GraphNode is a resource of the relationship graph, identified by its kind and name.
+type GraphNode struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// GraphEdge is a relationship between two resources of the graph.
+type GraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+}
+
+func (s *Server) addResourceGraphTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "resource_graph",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Graph the relationships of the resources of a namespace",
+		},
+		Description: "Build the graph of the workloads, pods, services and ingresses of a namespace: the owners of the resources, the pods selected by the services and the services routed to by the ingresses. " +
+			"With a format, the graph is also returned as a Mermaid or DOT diagram that chat clients can render",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ResourceGraphInput) (*mcp.CallToolResult, *ResourceGraphResult, error) {
+		if input.Format != "" && input.Format != graphFormatMermaid && input.Format != graphFormatDOT {
+			return nil, nil, fmt.Errorf("invalid format %q, must be %s or %s", input.Format, graphFormatMermaid, graphFormatDOT)
+		}
+
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+		if input.Namespace == "" {
+			input.Namespace, err = s.sessionNamespace(ctx, request, "pods")
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		result := &ResourceGraphResult{Namespace: input.Namespace}
+		var objects []unstructured.Unstructured
+		for _, gvr := range graphResources {
+			list, err := dynamicClient.Resource(gvr).Namespace(input.Namespace).List(ctx, v1.ListOptions{})
+			if err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("failed to list %s: %v", gvr.GroupResource(), err))
+				continue
+			}
+			sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+			objects = append(objects, list.Items...)
+		}
+		result.Nodes, result.Edges = buildResourceGraph(objects)
+
+		message := fmt.Sprintf("Found %d resources and %d relationships in namespace %s", len(result.Nodes), len(result.Edges), input.Namespace)
+		var section string
+		switch input.Format {
+		case graphFormatMermaid:
+			result.Diagram = mermaidGraph(result.Nodes, result.Edges)
+			section = "```mermaid\n" + result.Diagram + "```"
+		case graphFormatDOT:
+			result.Diagram = dotGraph(result.Nodes, result.Edges)
+			section = "```dot\n" + result.Diagram + "```"
+		default:
+			if len(result.Edges) > 0 {
+				table := newTextTable("FROM", "RELATION", "TO")
+				for _, edge := range result.Edges {
+					table.addRow(edge.From, edge.Relation, edge.To)
+				}
+				section = table.String()
+			}
+		}
+		var warnings string
+		if len(result.Warnings) > 0 {
+			warnings = "Missing from the graph:\n" + textList(result.Warnings)
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: textSummary(message, section, warnings),
+				},
+			},
+		}, result, nil
+	})
+}
+
+// buildResourceGraph returns the nodes of the objects, in their order, and the edges between them:
+// their owner references, the selectors of the Services and the backends of the Ingresses.
+func buildResourceGraph(objects []unstructured.Unstructured) ([]GraphNode, []GraphEdge) {
+	nodes := make([]GraphNode, 0, len(objects))
+	edges := []GraphEdge{}
+	ids := make(map[types.UID]string, len(objects))
+	exists := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		id := obj.GetKind() + "/" + obj.GetName()
+		nodes = append(nodes, GraphNode{ID: id, Kind: obj.GetKind(), Name: obj.GetName()})
+		ids[obj.GetUID()] = id
+		exists[id] = true
+	}
+
+	for _, obj := range objects {
+		id := obj.GetKind() + "/" + obj.GetName()
+		for _, owner := range obj.GetOwnerReferences() {
+			if ownerID, ok := ids[owner.UID]; ok {
+				edges = append(edges, GraphEdge{From: ownerID, To: id, Relation: graphRelationOwns})
+			}
+		}
+
+		switch obj.GroupVersionKind().GroupKind() {
+		case schema.GroupKind{Kind: "Service"}:
+			selector, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector")
+			if len(selector) == 0 {
+				continue
+			}
+			for _, pod := range objects {
+				if pod.GetKind() == "Pod" && labels.SelectorFromSet(selector).Matches(labels.Set(pod.GetLabels())) {
+					edges = append(edges, GraphEdge{From: id, To: "Pod/" + pod.GetName(), Relation: graphRelationSelects})
+				}
+			}
+		case schema.GroupKind{Group: "networking.k8s.io", Kind: "Ingress"}:
+			for _, service := range ingressServices(obj.Object) {
+				if serviceID := "Service/" + service; exists[serviceID] {
+					edges = append(edges, GraphEdge{From: id, To: serviceID, Relation: graphRelationRoutes})
+				}
+			}
+		}
+	}
+	return nodes, edges
+}
+
+// ingressServices returns the names of the Services of the backends of the Ingress, sorted.
+func ingressServices(ingress map[string]interface{}) []string {
+	services := map[string]bool{}
+	if name := nestedString(ingress, "spec", "defaultBackend", "service", "name"); name != "" {
+		services[name] = true
+	}
+	for _, rule := range nestedMaps(ingress, "spec", "rules") {
+		for _, path := range nestedMaps(rule, "http", "paths") {
+			if name := nestedString(path, "backend", "service", "name"); name != "" {
+				services[name] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mermaidGraph renders the graph as a Mermaid flowchart. The nodes are given short IDs, since
+// Mermaid IDs can not contain slashes.
+func mermaidGraph(nodes []GraphNode, edges []GraphEdge) string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	mermaidIDs := make(map[string]string, len(nodes))
+	for i, node := range nodes {
+		mermaidIDs[node.ID] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "  n%d[\"%s\"]\n", i, node.ID)
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", mermaidIDs[edge.From], edge.Relation, mermaidIDs[edge.To])
+	}
+	return b.String()
+}
+
+// dotGraph renders the graph in the DOT language of Graphviz.
+func dotGraph(nodes []GraphNode, edges []GraphEdge) string {
+	var b strings.Builder
+	b.WriteString("digraph {\n  rankdir=LR;\n")
+	for _, node := range nodes {
+		fmt.Fprintf(&b, "  %q;\n", node.ID)
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", edge.From, edge.To, edge.Relation)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
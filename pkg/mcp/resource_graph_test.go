@@ -0,0 +1,109 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func graphObject(apiVersion, kind, name string, owner *unstructured.Unstructured, fields map[string]interface{}) unstructured.Unstructured {
+	obj := unstructured.Unstructured{Object: fields}
+	if obj.Object == nil {
+		obj.Object = map[string]interface{}{}
+	}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetUID(types.UID(kind + "-" + name))
+	if owner != nil {
+		obj.SetOwnerReferences([]v1.OwnerReference{{Kind: owner.GetKind(), Name: owner.GetName(), UID: owner.GetUID(), Controller: ptr.To(true)}})
+	}
+	return obj
+}
+
+func testResourceGraph() ([]GraphNode, []GraphEdge) {
+	ingress := graphObject("networking.k8s.io/v1", "Ingress", "shop", nil, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"rules": []interface{}{map[string]interface{}{
+				"http": map[string]interface{}{"paths": []interface{}{
+					map[string]interface{}{"backend": map[string]interface{}{"service": map[string]interface{}{"name": "web"}}},
+					map[string]interface{}{"backend": map[string]interface{}{"service": map[string]interface{}{"name": "missing"}}},
+				}},
+			}},
+		},
+	})
+	service := graphObject("v1", "Service", "web", nil, map[string]interface{}{
+		"spec": map[string]interface{}{"selector": map[string]interface{}{"app": "web"}},
+	})
+	deployment := graphObject("apps/v1", "Deployment", "web", nil, nil)
+	replicaSet := graphObject("apps/v1", "ReplicaSet", "web-5d4f", &deployment, nil)
+	pod := graphObject("v1", "Pod", "web-5d4f-x2k", &replicaSet, nil)
+	pod.SetLabels(map[string]string{"app": "web"})
+	other := graphObject("v1", "Pod", "debug", nil, nil)
+	return buildResourceGraph([]unstructured.Unstructured{ingress, service, deployment, replicaSet, pod, other})
+}
+
+func TestBuildResourceGraph(t *testing.T) {
+	nodes, edges := testResourceGraph()
+	if len(nodes) != 6 || nodes[0] != (GraphNode{ID: "Ingress/shop", Kind: "Ingress", Name: "shop"}) {
+		t.Errorf("expected a node per object, got %v", nodes)
+	}
+	expected := []GraphEdge{
+		{From: "Ingress/shop", To: "Service/web", Relation: graphRelationRoutes},
+		{From: "Service/web", To: "Pod/web-5d4f-x2k", Relation: graphRelationSelects},
+		{From: "Deployment/web", To: "ReplicaSet/web-5d4f", Relation: graphRelationOwns},
+		{From: "ReplicaSet/web-5d4f", To: "Pod/web-5d4f-x2k", Relation: graphRelationOwns},
+	}
+	if !reflect.DeepEqual(edges, expected) {
+		t.Errorf("expected edges %v, got %v", expected, edges)
+	}
+}
+
+func TestGraphDiagrams(t *testing.T) {
+	nodes, edges := testResourceGraph()
+	nodes, edges = nodes[2:5], edges[2:]
+
+	expectedMermaid := `graph LR
+  n0["Deployment/web"]
+  n1["ReplicaSet/web-5d4f"]
+  n2["Pod/web-5d4f-x2k"]
+  n0 -->|owns| n1
+  n1 -->|owns| n2
+`
+	if diagram := mermaidGraph(nodes, edges); diagram != expectedMermaid {
+		t.Errorf("expected Mermaid diagram:\n%s\ngot:\n%s", expectedMermaid, diagram)
+	}
+
+	expectedDOT := `digraph {
+  rankdir=LR;
+  "Deployment/web";
+  "ReplicaSet/web-5d4f";
+  "Pod/web-5d4f-x2k";
+  "Deployment/web" -> "ReplicaSet/web-5d4f" [label="owns"];
+  "ReplicaSet/web-5d4f" -> "Pod/web-5d4f-x2k" [label="owns"];
+}
+`
+	if diagram := dotGraph(nodes, edges); diagram != expectedDOT {
+		t.Errorf("expected DOT diagram:\n%s\ngot:\n%s", expectedDOT, diagram)
+	}
+}
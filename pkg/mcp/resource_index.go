@@ -0,0 +1,239 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// indexedResource is a resource of the index with the names it is matched by.
+type indexedResource struct {
+	match    resourceMatch
+	resource v1.APIResource
+}
+
+// resourceIndex indexes the preferred resources of an API server by their kind, plural, singular
+// and short names, and by their categories, the restricted resources being left out.
+type resourceIndex struct {
+	resources    []indexedResource
+	byName       map[string][]resourceMatch
+	byCategory   map[string][]resourceMatch
+	failedGroups []string
+}
+
+func newResourceIndex(resourceLists []*v1.APIResourceList, failedGroups []string) *resourceIndex {
+	index := &resourceIndex{
+		byName:       make(map[string][]resourceMatch),
+		byCategory:   make(map[string][]resourceMatch),
+		failedGroups: failedGroups,
+	}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+
+		for _, resource := range resourceList.APIResources {
+			match := resourceMatch{
+				gvr:        gv.WithResource(resource.Name),
+				namespaced: resource.Namespaced,
+			}
+			if isRestrictedResource(match.gvr) {
+				continue
+			}
+			index.resources = append(index.resources, indexedResource{match: match, resource: resource})
+
+			names := map[string]bool{}
+			for _, name := range append([]string{resource.Kind, resource.SingularName, resource.Name}, resource.ShortNames...) {
+				if name = strings.ToLower(name); name != "" && !names[name] {
+					names[name] = true
+					index.byName[name] = append(index.byName[name], match)
+				}
+			}
+			for _, category := range resource.Categories {
+				index.byCategory[category] = append(index.byCategory[category], match)
+			}
+		}
+	}
+	return index
+}
+
+// lookup returns the resources whose kind, plural, singular or short name is the name, in any case.
+func (i *resourceIndex) lookup(name string) []resourceMatch {
+	return i.byName[strings.ToLower(name)]
+}
+
+// maxSuggestions bounds the number of resources suggested for a name matching none.
+const maxSuggestions = 10
+
+// scoredMatch is a suggested resource with its score, the lower the closer to the name.
+type scoredMatch struct {
+	match resourceMatch
+	score float64
+	// typo tells that the resource only matches the name through typos.
+	typo bool
+}
+
+// suggestions returns the resources closest to the kind or the resource name, best first: the
+// resources whose names start with it, then the ones a few typos away from it, then the ones whose
+// names contain it. When one resource is closer than every other one, only it is returned. The
+// returned bool tells that the closest resource only matches through typos, and must be confirmed
+// before being used.
+func (i *resourceIndex) suggestions(gk schema.GroupKind, resourceName string) ([]resourceMatch, bool) {
+	queries := []string{strings.ToLower(gk.Kind), strings.ToLower(resourceName)}
+	var scored []scoredMatch
+	for _, indexed := range i.resources {
+		best, typo := -1.0, false
+		for _, name := range append([]string{indexed.resource.Kind, indexed.resource.SingularName, indexed.resource.Name}, indexed.resource.ShortNames...) {
+			if name == "" {
+				continue
+			}
+			for _, query := range queries {
+				if score, typos, ok := nameScore(strings.ToLower(name), query); ok && (best < 0 || score < best) {
+					best, typo = score, typos
+				}
+			}
+		}
+		if best >= 0 {
+			scored = append(scored, scoredMatch{match: indexed.match, score: best, typo: typo})
+		}
+	}
+	sort.SliceStable(scored, func(a, b int) bool {
+		return scored[a].score < scored[b].score
+	})
+
+	// A prefix or a single typo closer than every other resource is a confident suggestion.
+	if len(scored) > 1 && scored[0].score <= 1 && scored[0].score < scored[1].score {
+		scored = scored[:1]
+	}
+	matches := make([]resourceMatch, 0, min(len(scored), maxSuggestions))
+	for _, s := range scored[:min(len(scored), maxSuggestions)] {
+		matches = append(matches, s.match)
+	}
+	return matches, len(scored) > 0 && scored[0].typo
+}
+
+// nameScore scores how close a name is to the query: 0.5 when the name starts with it, the number
+// of typos between them when there are few enough for the length of the query, and one more than
+// the number of typos allowed when the name contains it. The second bool tells that the score is
+// the number of typos.
+func nameScore(name, query string) (float64, bool, bool) {
+	if query == "" {
+		return 0, false, false
+	}
+	maxTypos := 1
+	switch {
+	case len(query) > 8:
+		maxTypos = 3
+	case len(query) > 4:
+		maxTypos = 2
+	}
+
+	if strings.HasPrefix(name, query) {
+		return 0.5, false, true
+	}
+	if typos := editDistance(name, query); typos <= maxTypos {
+		return float64(typos), true, true
+	}
+	if strings.Contains(name, query) {
+		return float64(maxTypos + 1), false, true
+	}
+	return 0, false, false
+}
+
+// editDistance returns the optimal string alignment distance between the strings: the number of
+// inserted, deleted and substituted characters and of swapped adjacent characters between them.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// indexedDiscoveryClient keeps the index of the resources of its discovery, built again when the
+// discovery is invalidated or once it is older than the TTL of the discovery cache.
+type indexedDiscoveryClient struct {
+	discovery.CachedDiscoveryInterface
+	ttl time.Duration
+
+	mu    sync.Mutex
+	index *resourceIndex
+	built time.Time
+}
+
+func newIndexedDiscoveryClient(client discovery.CachedDiscoveryInterface, ttl time.Duration) *indexedDiscoveryClient {
+	return &indexedDiscoveryClient{CachedDiscoveryInterface: client, ttl: ttl}
+}
+
+func (c *indexedDiscoveryClient) Invalidate() {
+	c.mu.Lock()
+	c.index = nil
+	c.mu.Unlock()
+	c.CachedDiscoveryInterface.Invalidate()
+}
+
+func (c *indexedDiscoveryClient) resourceIndex() (*resourceIndex, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.index != nil && time.Since(c.built) < c.ttl {
+		return c.index, nil
+	}
+	resources, failedGroups, err := serverPreferredResources(c.CachedDiscoveryInterface)
+	if err != nil {
+		return nil, err
+	}
+	c.index, c.built = newResourceIndex(resources, failedGroups), time.Now()
+	return c.index, nil
+}
+
+// resourceIndexFor returns the index of the resources of the discovery client, kept by the client
+// when it is indexed and built from its discovery otherwise.
+func resourceIndexFor(discoveryClient discovery.CachedDiscoveryInterface) (*resourceIndex, error) {
+	if indexed, ok := discoveryClient.(*indexedDiscoveryClient); ok {
+		return indexed.resourceIndex()
+	}
+	resources, failedGroups, err := serverPreferredResources(discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+	return newResourceIndex(resources, failedGroups), nil
+}
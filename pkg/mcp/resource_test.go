@@ -18,10 +18,16 @@ package mcp
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 )
 
@@ -100,6 +106,28 @@ func TestFindResource(t *testing.T) {
 				Resource: "deployments",
 			},
 		},
+		{
+			name:         "short name - deploy",
+			resourceName: "deploy",
+			setupDiscovery: func() *cmdtesting.FakeCachedDiscoveryClient {
+				dc := cmdtesting.NewFakeCachedDiscoveryClient()
+				dc.PreferredResources = []*v1.APIResourceList{
+					{
+						GroupVersion: "apps/v1",
+						APIResources: []v1.APIResource{
+							{Name: "deployments", Kind: "Deployment", ShortNames: []string{"deploy"}, Namespaced: true},
+							{Name: "daemonsets", Kind: "DaemonSet", ShortNames: []string{"ds"}, Namespaced: true},
+						},
+					},
+				}
+				return dc
+			},
+			expectedGVR: schema.GroupVersionResource{
+				Group:    "apps",
+				Version:  "v1",
+				Resource: "deployments",
+			},
+		},
 		{
 			name:         "resource by name - pods",
 			resourceName: "pods",
@@ -179,6 +207,51 @@ func TestFindResource(t *testing.T) {
 			},
 			expectedError: "resource \"po\" not found, did you mean one of these: pods.v1., podtemplates.v1.",
 		},
+		{
+			name:         "typo - single close suggestion to confirm",
+			resourceName: "deploymnet",
+			setupDiscovery: func() *cmdtesting.FakeCachedDiscoveryClient {
+				dc := cmdtesting.NewFakeCachedDiscoveryClient()
+				dc.PreferredResources = []*v1.APIResourceList{
+					{
+						GroupVersion: "v1",
+						APIResources: []v1.APIResource{
+							{Name: "pods", Kind: "Pod", Namespaced: true},
+							{Name: "replicationcontrollers", Kind: "ReplicationController", Namespaced: true},
+						},
+					},
+					{
+						GroupVersion: "apps/v1",
+						APIResources: []v1.APIResource{
+							{Name: "daemonsets", Kind: "DaemonSet", Namespaced: true},
+							{Name: "deployments", Kind: "Deployment", Namespaced: true},
+							{Name: "replicasets", Kind: "ReplicaSet", Namespaced: true},
+						},
+					},
+				}
+				return dc
+			},
+			expectedError: "resource \"deploymnet\" not found, did you mean deployments.v1.apps?",
+		},
+		{
+			name:         "typos - ranked suggestions",
+			resourceName: "sevice",
+			setupDiscovery: func() *cmdtesting.FakeCachedDiscoveryClient {
+				dc := cmdtesting.NewFakeCachedDiscoveryClient()
+				dc.PreferredResources = []*v1.APIResourceList{
+					{
+						GroupVersion: "v1",
+						APIResources: []v1.APIResource{
+							{Name: "devices", Kind: "Device", Namespaced: true},
+							{Name: "services", Kind: "Service", Namespaced: true},
+							{Name: "pods", Kind: "Pod", Namespaced: true},
+						},
+					},
+				}
+				return dc
+			},
+			expectedError: "resource \"sevice\" not found, did you mean one of these: devices.v1., services.v1.",
+		},
 		{
 			name:         "exact match - ingress with networking.k8s.io group",
 			resourceName: "Ingress.networking.k8s.io",
@@ -459,6 +532,132 @@ func TestFindResource_MultipleExactMatches(t *testing.T) {
 	}
 }
 
+// partialDiscoveryClient returns the preferred resources together with a group discovery error.
+type partialDiscoveryClient struct {
+	*cmdtesting.FakeCachedDiscoveryClient
+	err error
+}
+
+func (d *partialDiscoveryClient) ServerPreferredResources() ([]*v1.APIResourceList, error) {
+	return d.PreferredResources, d.err
+}
+
+func TestFindResource_PartialDiscoveryFailure(t *testing.T) {
+	dc := &partialDiscoveryClient{
+		FakeCachedDiscoveryClient: cmdtesting.NewFakeCachedDiscoveryClient(),
+		err: &discovery.ErrGroupDiscoveryFailed{
+			Groups: map[schema.GroupVersion]error{
+				{Group: "metrics.k8s.io", Version: "v1beta1"}: errors.New("service unavailable"),
+			},
+		},
+	}
+	dc.PreferredResources = []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true},
+			},
+		},
+	}
+
+	gvr, _, err := FindResource(context.TODO(), "Pod", dc, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvr.Resource != "pods" {
+		t.Errorf("expected pods, got %+v", gvr)
+	}
+
+	_, _, err = FindResource(context.TODO(), "PodMetrics", dc, nil)
+	if err == nil || !strings.Contains(err.Error(), "discovery failed for groups: metrics.k8s.io/v1beta1") {
+		t.Errorf("expected not found error mentioning the failed group, got %v", err)
+	}
+
+	dc.PreferredResources = nil
+	_, _, err = FindResource(context.TODO(), "Pod", dc, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to get server resources") {
+		t.Errorf("expected discovery error when no group loaded, got %v", err)
+	}
+}
+
+// staleDiscoveryClient serves stale resources until it is invalidated.
+type staleDiscoveryClient struct {
+	*cmdtesting.FakeCachedDiscoveryClient
+	fresh []*v1.APIResourceList
+}
+
+func (d *staleDiscoveryClient) Fresh() bool {
+	return d.Invalidations > 0
+}
+
+func (d *staleDiscoveryClient) ServerPreferredResources() ([]*v1.APIResourceList, error) {
+	if d.Invalidations > 0 {
+		return d.fresh, nil
+	}
+	return d.PreferredResources, nil
+}
+
+func TestFindResource_StaleCache(t *testing.T) {
+	dc := &staleDiscoveryClient{
+		FakeCachedDiscoveryClient: cmdtesting.NewFakeCachedDiscoveryClient(),
+		fresh: []*v1.APIResourceList{
+			{
+				GroupVersion: "example.com/v1",
+				APIResources: []v1.APIResource{
+					{Name: "widgets", Kind: "Widget", Namespaced: true},
+				},
+			},
+		},
+	}
+
+	gvr, _, err := FindResource(context.TODO(), "Widget", dc, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvr.Resource != "widgets" {
+		t.Errorf("expected widgets, got %+v", gvr)
+	}
+	if dc.Invalidations != 1 {
+		t.Errorf("expected the cache to be invalidated once, got %d", dc.Invalidations)
+	}
+}
+
+func TestIndexedDiscoveryClient(t *testing.T) {
+	dc := &staleDiscoveryClient{
+		FakeCachedDiscoveryClient: cmdtesting.NewFakeCachedDiscoveryClient(),
+		fresh: []*v1.APIResourceList{
+			{
+				GroupVersion: "v1",
+				APIResources: []v1.APIResource{
+					{Name: "services", Kind: "Service", ShortNames: []string{"svc"}, Categories: []string{"all"}, Namespaced: true},
+					{Name: "secrets", Kind: "Secret", Categories: []string{"all"}, Namespaced: true},
+				},
+			},
+		},
+	}
+	dc.PreferredResources = dc.fresh
+	indexed := newIndexedDiscoveryClient(dc, time.Hour)
+
+	index, err := resourceIndexFor(indexed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches := index.lookup("SVC"); len(matches) != 1 || matches[0].gvr.Resource != "services" {
+		t.Errorf("expected services by short name, got %+v", matches)
+	}
+	if matches := index.byCategory["all"]; len(matches) != 1 || matches[0].gvr.Resource != "services" {
+		t.Errorf("expected the category to hold services but not the restricted secrets, got %+v", matches)
+	}
+	if again, _ := resourceIndexFor(indexed); again != index {
+		t.Errorf("expected the index to be kept until the discovery is invalidated")
+	}
+
+	indexed.Invalidate()
+	if again, _ := resourceIndexFor(indexed); again == index {
+		t.Errorf("expected the index to be built again once the discovery is invalidated")
+	}
+}
+
 func TestIsRestrictedResource(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -530,3 +729,134 @@ func TestIsRestrictedResource(t *testing.T) {
 		})
 	}
 }
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "deployment", b: "deployment", expected: 0},
+		{a: "deployment", b: "deploymnet", expected: 1},
+		{a: "service", b: "sevice", expected: 1},
+		{a: "ingress", b: "ingres", expected: 1},
+		{a: "configmap", b: "confgimpa", expected: 2},
+		{a: "pod", b: "", expected: 3},
+		{a: "pod", b: "node", expected: 2},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("editDistance(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestFindResource_Elicitation(t *testing.T) {
+	ctx := context.Background()
+	dc := cmdtesting.NewFakeCachedDiscoveryClient()
+	dc.PreferredResources = []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true},
+				{Name: "podtemplates", Kind: "PodTemplate", Namespaced: true},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		resourceName  string
+		action        string
+		content       map[string]any
+		expectedEnum  []any
+		expectedGVR   schema.GroupVersionResource
+		expectedError string
+	}{
+		{
+			name:         "chosen resource",
+			resourceName: "po",
+			content:      map[string]any{"resource": "podtemplates.v1."},
+			expectedEnum: []any{"pods.v1.", "podtemplates.v1."},
+			expectedGVR:  schema.GroupVersionResource{Version: "v1", Resource: "podtemplates"},
+		},
+		{
+			name:          "resource not offered",
+			resourceName:  "po",
+			content:       map[string]any{"resource": "2"},
+			expectedEnum:  []any{"pods.v1.", "podtemplates.v1."},
+			expectedError: "failed to elicit user choice",
+		},
+		{
+			name:         "confirmed typo",
+			resourceName: "pdo",
+			content:      map[string]any{"resource": "pods.v1."},
+			expectedEnum: []any{"pods.v1."},
+			expectedGVR:  schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+		},
+		{
+			name:          "declined typo",
+			resourceName:  "pdo",
+			action:        "decline",
+			expectedEnum:  []any{"pods.v1."},
+			expectedError: "user cancelled resource selection",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var elicited *mcp.ElicitParams
+			client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+				ElicitationHandler: func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+					elicited = req.Params
+					if tt.action != "" {
+						return &mcp.ElicitResult{Action: tt.action}, nil
+					}
+					return &mcp.ElicitResult{Action: "accept", Content: tt.content}, nil
+				},
+			})
+			server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+			var gvr schema.GroupVersionResource
+			var findErr error
+			mcp.AddTool(server, &mcp.Tool{Name: "find"}, func(ctx context.Context, request *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+				gvr, _, findErr = FindResource(ctx, tt.resourceName, dc, request.Session)
+				return &mcp.CallToolResult{}, nil, nil
+			})
+
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			serverSession, err := server.Connect(ctx, serverTransport, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer serverSession.Close()
+			clientSession, err := client.Connect(ctx, clientTransport, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer clientSession.Close()
+
+			if _, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "find"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if elicited == nil {
+				t.Fatal("expected the resource to be elicited")
+			}
+			property := elicited.RequestedSchema.Properties["resource"]
+			if property == nil || !reflect.DeepEqual(property.Enum, tt.expectedEnum) {
+				t.Errorf("expected the matching resources as an enum, got %+v", property)
+			}
+			if tt.expectedError != "" {
+				if findErr == nil || !strings.HasPrefix(findErr.Error(), tt.expectedError) {
+					t.Errorf("expected error starting with %q, got %v", tt.expectedError, findErr)
+				}
+				return
+			}
+			if findErr != nil {
+				t.Fatalf("unexpected error: %v", findErr)
+			}
+			if gvr != tt.expectedGVR {
+				t.Errorf("expected GVR %+v, got %+v", tt.expectedGVR, gvr)
+			}
+		})
+	}
+}
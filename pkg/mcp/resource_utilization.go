@@ -0,0 +1,417 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	nodesGVR       = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	podMetricsGVR  = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+	nodeMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+)
+
+const (
+	// appsPodTemplateHashLabel is the label added by the Deployment controller to its ReplicaSets and pods.
+	appsPodTemplateHashLabel = "pod-template-hash"
+	// overProvisionedPercent is the usage, in percent of the request, below which a workload is over-provisioned.
+	overProvisionedPercent = 25
+	// nearLimitPercent is the usage, in percent of the limit, above which a workload is close to its limit.
+	nearLimitPercent = 90
+)
+
+type ResourceUtilizationInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Analyze the workloads of this namespace"`
+	Node      string `json:"node,omitempty" jsonschema:"Analyze the workloads running on this node and the node capacity"`
+}
+
+type ResourceUtilizationResult struct {
+	Node      *NodeUtilization      `json:"node,omitempty"`
+	Workloads []WorkloadUtilization `json:"workloads"`
+	Warnings  []string              `json:"warnings,omitempty"`
+}
+
+// ResourceUtilization compares the requests, limits and the current usage of a single resource.
+type ResourceUtilization struct {
+	Allocatable  string `json:"allocatable,omitempty"`
+	Requests     string `json:"requests"`
+	Limits       string `json:"limits"`
+	Usage        string `json:"usage,omitempty"`
+	UsageRequest int64  `json:"usagePercentOfRequests,omitempty"`
+	UsageLimit   int64  `json:"usagePercentOfLimits,omitempty"`
+}
+
+type NodeUtilization struct {
+	Name   string              `json:"name"`
+	CPU    ResourceUtilization `json:"cpu"`
+	Memory ResourceUtilization `json:"memory"`
+}
+
+type WorkloadUtilization struct {
+	Kind      string              `json:"kind"`
+	Name      string              `json:"name"`
+	Namespace string              `json:"namespace"`
+	Pods      int                 `json:"pods"`
+	CPU       ResourceUtilization `json:"cpu"`
+	Memory    ResourceUtilization `json:"memory"`
+	Findings  []string            `json:"findings,omitempty"`
+}
+
+// resourceTotals accumulates the milli values of a resource.
+type resourceTotals struct {
+	requests, limits, usage int64
+	// unbounded is set when a container has no limit, so the total limit is meaningless.
+	unbounded bool
+	hasUsage  bool
+}
+
+type workloadTotals struct {
+	kind, name, namespace string
+	pods                  int
+	cpu, memory           resourceTotals
+}
+
+func (s *Server) addResourceUtilizationTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "resource_utilization",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Compare resource requests and limits with actual usage",
+		},
+		Description: "Correlate the CPU and memory requests and limits of the pods in a namespace or on a node with their usage reported by metrics-server, aggregated per workload. Returns over-provisioned and under-provisioned workloads and, for a node, its allocatable capacity",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ResourceUtilizationInput) (*mcp.CallToolResult, *ResourceUtilizationResult, error) {
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		if input.Namespace == "" && input.Node == "" {
+			input.Namespace, err = s.sessionNamespace(ctx, request, "pods")
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		listOptions := v1.ListOptions{}
+		if input.Node != "" {
+			listOptions.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", input.Node).String()
+		}
+		podList, err := dynamicClient.Resource(podsGVR).Namespace(input.Namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		pods := make([]corev1.Pod, 0, len(podList.Items))
+		for _, item := range podList.Items {
+			var pod corev1.Pod
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pod); err != nil {
+				continue
+			}
+			if pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			pods = append(pods, pod)
+		}
+
+		result := &ResourceUtilizationResult{}
+
+		var usage map[string]corev1.ResourceList
+		metricsList, err := dynamicClient.Resource(podMetricsGVR).Namespace(input.Namespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("usage is not available, metrics-server may not be installed: %v", err))
+		} else {
+			usage = podUsage(metricsList.Items)
+		}
+
+		workloads := aggregateWorkloads(pods, usage)
+		result.Workloads = make([]WorkloadUtilization, 0, len(workloads))
+		for _, totals := range workloads {
+			result.Workloads = append(result.Workloads, totals.utilization())
+		}
+		sort.Slice(result.Workloads, func(i, j int) bool {
+			if len(result.Workloads[i].Findings) != len(result.Workloads[j].Findings) {
+				return len(result.Workloads[i].Findings) > len(result.Workloads[j].Findings)
+			}
+			if result.Workloads[i].Namespace != result.Workloads[j].Namespace {
+				return result.Workloads[i].Namespace < result.Workloads[j].Namespace
+			}
+			return result.Workloads[i].Name < result.Workloads[j].Name
+		})
+
+		if input.Node != "" {
+			node, warning, err := nodeUtilization(ctx, dynamicClient, input.Node, workloads)
+			if err != nil {
+				return nil, nil, err
+			}
+			if warning != "" {
+				result.Warnings = append(result.Warnings, warning)
+			}
+			result.Node = node
+		}
+
+		var findings []string
+		for _, workload := range result.Workloads {
+			for _, finding := range workload.Findings {
+				findings = append(findings, fmt.Sprintf("%s %s/%s: %s", workload.Kind, workload.Namespace, workload.Name, finding))
+			}
+		}
+		scope := fmt.Sprintf("namespace %s", input.Namespace)
+		if input.Node != "" {
+			scope = fmt.Sprintf("node %s", input.Node)
+		}
+		message := fmt.Sprintf("Analyzed %d workloads on %s", len(result.Workloads), scope)
+		message = textSummary(message, textList(findings))
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, result, nil
+	})
+}
+
+// podUsage returns the usage of each pod, keyed by namespace/name, from PodMetrics objects.
+func podUsage(items []unstructured.Unstructured) map[string]corev1.ResourceList {
+	usage := make(map[string]corev1.ResourceList, len(items))
+	for _, item := range items {
+		containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+		total := corev1.ResourceList{}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			addUsage(total, container)
+		}
+		usage[item.GetNamespace()+"/"+item.GetName()] = total
+	}
+	return usage
+}
+
+// addUsage adds the .usage of a metrics object to the total.
+func addUsage(total corev1.ResourceList, obj map[string]interface{}) {
+	containerUsage, _, _ := unstructured.NestedStringMap(obj, "usage")
+	for name, value := range containerUsage {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			continue
+		}
+		sum := total[corev1.ResourceName(name)]
+		sum.Add(quantity)
+		total[corev1.ResourceName(name)] = sum
+	}
+}
+
+// aggregateWorkloads sums the requests, limits and usage of the pods per owning workload.
+func aggregateWorkloads(pods []corev1.Pod, usage map[string]corev1.ResourceList) map[string]*workloadTotals {
+	workloads := map[string]*workloadTotals{}
+	for i := range pods {
+		pod := &pods[i]
+		kind, name := podWorkload(pod)
+		key := pod.Namespace + "/" + kind + "/" + name
+		totals, ok := workloads[key]
+		if !ok {
+			totals = &workloadTotals{kind: kind, name: name, namespace: pod.Namespace}
+			workloads[key] = totals
+		}
+		totals.pods++
+
+		for _, container := range pod.Spec.Containers {
+			totals.cpu.addContainer(container.Resources, corev1.ResourceCPU)
+			totals.memory.addContainer(container.Resources, corev1.ResourceMemory)
+		}
+		if podUsage, ok := usage[pod.Namespace+"/"+pod.Name]; ok {
+			totals.cpu.addUsage(podUsage, corev1.ResourceCPU)
+			totals.memory.addUsage(podUsage, corev1.ResourceMemory)
+		}
+	}
+	return workloads
+}
+
+// podWorkload returns the kind and the name of the workload owning the pod.
+// Pods of ReplicaSets are attributed to their Deployment.
+func podWorkload(pod *corev1.Pod) (string, string) {
+	owner := v1.GetControllerOf(pod)
+	if owner == nil {
+		return "Pod", pod.Name
+	}
+	if owner.Kind == "ReplicaSet" {
+		if hash, ok := pod.Labels[appsPodTemplateHashLabel]; ok {
+			if deployment, ok := strings.CutSuffix(owner.Name, "-"+hash); ok {
+				return "Deployment", deployment
+			}
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
+func (t *resourceTotals) addContainer(requirements corev1.ResourceRequirements, name corev1.ResourceName) {
+	if request, ok := requirements.Requests[name]; ok {
+		t.requests += request.MilliValue()
+	} else if limit, ok := requirements.Limits[name]; ok {
+		// Requests default to limits when only limits are set.
+		t.requests += limit.MilliValue()
+	}
+	if limit, ok := requirements.Limits[name]; ok {
+		t.limits += limit.MilliValue()
+	} else {
+		t.unbounded = true
+	}
+}
+
+func (t *resourceTotals) addUsage(usage corev1.ResourceList, name corev1.ResourceName) {
+	if quantity, ok := usage[name]; ok {
+		t.usage += quantity.MilliValue()
+		t.hasUsage = true
+	}
+}
+
+// utilization formats the totals and reports the findings for the resource.
+func (t *resourceTotals) utilization(name corev1.ResourceName) (ResourceUtilization, []string) {
+	utilization := ResourceUtilization{
+		Requests: formatMilli(t.requests, name),
+		Limits:   formatMilli(t.limits, name),
+	}
+	if t.unbounded {
+		utilization.Limits = "<none>"
+	}
+
+	var findings []string
+	if t.requests == 0 {
+		findings = append(findings, fmt.Sprintf("no %s requests, the scheduler can not account for it", name))
+	}
+	if !t.hasUsage {
+		return utilization, findings
+	}
+
+	utilization.Usage = formatMilli(t.usage, name)
+	if t.requests > 0 {
+		utilization.UsageRequest = t.usage * 100 / t.requests
+		switch {
+		case utilization.UsageRequest < overProvisionedPercent:
+			findings = append(findings, fmt.Sprintf("over-provisioned %s: using %s of %s requested (%d%%)", name, utilization.Usage, utilization.Requests, utilization.UsageRequest))
+		case utilization.UsageRequest > 100:
+			findings = append(findings, fmt.Sprintf("under-provisioned %s: using %s of %s requested (%d%%)", name, utilization.Usage, utilization.Requests, utilization.UsageRequest))
+		}
+	}
+	if !t.unbounded && t.limits > 0 {
+		utilization.UsageLimit = t.usage * 100 / t.limits
+		if utilization.UsageLimit >= nearLimitPercent {
+			consequence := "risk of OOM kills"
+			if name == corev1.ResourceCPU {
+				consequence = "likely throttled"
+			}
+			findings = append(findings, fmt.Sprintf("%s usage at %d%% of limits, %s", name, utilization.UsageLimit, consequence))
+		}
+	}
+	return utilization, findings
+}
+
+func (w *workloadTotals) utilization() WorkloadUtilization {
+	cpu, cpuFindings := w.cpu.utilization(corev1.ResourceCPU)
+	memory, memoryFindings := w.memory.utilization(corev1.ResourceMemory)
+	return WorkloadUtilization{
+		Kind:      w.kind,
+		Name:      w.name,
+		Namespace: w.namespace,
+		Pods:      w.pods,
+		CPU:       cpu,
+		Memory:    memory,
+		Findings:  append(cpuFindings, memoryFindings...),
+	}
+}
+
+// nodeUtilization compares the allocatable capacity of the node with the totals of its workloads and its usage.
+func nodeUtilization(ctx context.Context, dynamicClient dynamic.Interface, name string, workloads map[string]*workloadTotals) (*NodeUtilization, string, error) {
+	obj, err := dynamicClient.Resource(nodesGVR).Get(ctx, name, v1.GetOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get node: %w", err)
+	}
+	var node corev1.Node
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &node); err != nil {
+		return nil, "", fmt.Errorf("failed to convert node: %w", err)
+	}
+
+	var cpu, memory resourceTotals
+	for _, totals := range workloads {
+		cpu.requests += totals.cpu.requests
+		cpu.limits += totals.cpu.limits
+		memory.requests += totals.memory.requests
+		memory.limits += totals.memory.limits
+	}
+
+	var warning string
+	metrics, err := dynamicClient.Resource(nodeMetricsGVR).Get(ctx, name, v1.GetOptions{})
+	if err != nil {
+		warning = fmt.Sprintf("node usage is not available: %v", err)
+	} else {
+		usage := corev1.ResourceList{}
+		addUsage(usage, metrics.Object)
+		cpu.addUsage(usage, corev1.ResourceCPU)
+		memory.addUsage(usage, corev1.ResourceMemory)
+	}
+
+	return &NodeUtilization{
+		Name:   name,
+		CPU:    cpu.nodeUtilization(node.Status.Allocatable, corev1.ResourceCPU),
+		Memory: memory.nodeUtilization(node.Status.Allocatable, corev1.ResourceMemory),
+	}, warning, nil
+}
+
+// nodeUtilization formats the totals of a node, where usage and limits are relative to the allocatable capacity.
+func (t *resourceTotals) nodeUtilization(allocatable corev1.ResourceList, name corev1.ResourceName) ResourceUtilization {
+	capacity := allocatable[name]
+	utilization := ResourceUtilization{
+		Allocatable: capacity.String(),
+		Requests:    formatMilli(t.requests, name),
+		Limits:      formatMilli(t.limits, name),
+	}
+	if t.hasUsage {
+		utilization.Usage = formatMilli(t.usage, name)
+	}
+	if t.requests > 0 && t.hasUsage {
+		utilization.UsageRequest = t.usage * 100 / t.requests
+	}
+	return utilization
+}
+
+// formatMilli formats a milli value the way kubectl displays the resource.
+func formatMilli(milli int64, name corev1.ResourceName) string {
+	if name == corev1.ResourceCPU {
+		return resource.NewMilliQuantity(milli, resource.DecimalSI).String()
+	}
+	return resource.NewQuantity(milli/1000, resource.BinarySI).String()
+}
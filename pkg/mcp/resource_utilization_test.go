@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+func utilizationPod(name, owner string, requests, limits corev1.ResourceList) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: "web",
+			Labels:    map[string]string{appsPodTemplateHashLabel: "5d9f8"},
+			OwnerReferences: []v1.OwnerReference{
+				{Kind: "ReplicaSet", Name: owner + "-5d9f8", Controller: ptr.To(true)},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "app", Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits}},
+			},
+		},
+	}
+}
+
+func TestAggregateWorkloads(t *testing.T) {
+	pods := []corev1.Pod{
+		utilizationPod("frontend-a", "frontend",
+			corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+			corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}),
+		utilizationPod("frontend-b", "frontend",
+			corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+			corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}),
+		utilizationPod("worker-a", "worker", nil, nil),
+	}
+	usage := podUsage([]unstructured.Unstructured{
+		podMetrics("frontend-a", "50m", "240Mi"),
+		podMetrics("frontend-b", "50m", "240Mi"),
+		podMetrics("worker-a", "1", "1Gi"),
+	})
+
+	workloads := aggregateWorkloads(pods, usage)
+	if len(workloads) != 2 {
+		t.Fatalf("expected 2 workloads, got %d", len(workloads))
+	}
+
+	frontend := workloads["web/Deployment/frontend"].utilization()
+	expectedCPU := ResourceUtilization{Requests: "1", Limits: "<none>", Usage: "100m", UsageRequest: 10}
+	if !reflect.DeepEqual(frontend.CPU, expectedCPU) {
+		t.Errorf("expected cpu %+v, got %+v", expectedCPU, frontend.CPU)
+	}
+	expectedMemory := ResourceUtilization{Requests: "512Mi", Limits: "512Mi", Usage: "480Mi", UsageRequest: 93, UsageLimit: 93}
+	if !reflect.DeepEqual(frontend.Memory, expectedMemory) {
+		t.Errorf("expected memory %+v, got %+v", expectedMemory, frontend.Memory)
+	}
+	expectedFindings := []string{
+		"over-provisioned cpu: using 100m of 1 requested (10%)",
+		"memory usage at 93% of limits, risk of OOM kills",
+	}
+	if !reflect.DeepEqual(frontend.Findings, expectedFindings) {
+		t.Errorf("expected findings %v, got %v", expectedFindings, frontend.Findings)
+	}
+
+	worker := workloads["web/Deployment/worker"].utilization()
+	expectedFindings = []string{
+		"no cpu requests, the scheduler can not account for it",
+		"no memory requests, the scheduler can not account for it",
+	}
+	if !reflect.DeepEqual(worker.Findings, expectedFindings) {
+		t.Errorf("expected findings %v, got %v", expectedFindings, worker.Findings)
+	}
+}
+
+func TestPodWorkload(t *testing.T) {
+	standalone := corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "debug"}}
+	if kind, name := podWorkload(&standalone); kind != "Pod" || name != "debug" {
+		t.Errorf("expected Pod debug, got %s %s", kind, name)
+	}
+
+	statefulSetPod := corev1.Pod{ObjectMeta: v1.ObjectMeta{
+		Name:            "db-0",
+		OwnerReferences: []v1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: ptr.To(true)}},
+	}}
+	if kind, name := podWorkload(&statefulSetPod); kind != "StatefulSet" || name != "db" {
+		t.Errorf("expected StatefulSet db, got %s %s", kind, name)
+	}
+}
+
+func podMetrics(name, cpu, memory string) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "web",
+		},
+		"containers": []interface{}{
+			map[string]interface{}{
+				"name": "app",
+				"usage": map[string]interface{}{
+					"cpu":    cpu,
+					"memory": memory,
+				},
+			},
+		},
+	}}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sanitizeValue strips the bulky metadata of the objects found anywhere in the decoded JSON value, in
+// place: their managed fields, their last applied configuration and their resource version, which
+// changes on every write without telling anything about the objects.
+func sanitizeValue(value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		if metadata, ok := value["metadata"].(map[string]any); ok {
+			delete(metadata, "managedFields")
+			delete(metadata, "resourceVersion")
+			if annotations, ok := metadata["annotations"].(map[string]any); ok {
+				delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+				if len(annotations) == 0 {
+					delete(metadata, "annotations")
+				}
+			}
+		}
+		for _, field := range value {
+			sanitizeValue(field)
+		}
+	case []any:
+		for _, item := range value {
+			sanitizeValue(item)
+		}
+	}
+}
+
+// fullMetadataRequested tells whether the arguments of a tool call ask for the full metadata of the objects.
+func fullMetadataRequested(arguments json.RawMessage) bool {
+	var decoded struct {
+		FullMetadata bool `json:"fullMetadata"`
+	}
+	return json.Unmarshal(arguments, &decoded) == nil && decoded.FullMetadata
+}
+
+// sanitizeMiddleware strips the bulky metadata of the objects of the tool results, unless the call
+// asks for their full metadata.
+func sanitizeMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+		toolResult, ok := result.(*mcp.CallToolResult)
+		if err != nil || method != methodCallTool || !ok || toolResult.StructuredContent == nil {
+			return result, err
+		}
+		if call, ok := req.(*mcp.CallToolRequest); ok && fullMetadataRequested(call.Params.Arguments) {
+			return result, err
+		}
+
+		// The redaction already decoded the structured content from the result of the tool.
+		content := toolResult.StructuredContent
+		if _, ok := content.(map[string]any); !ok {
+			var decoded any
+			data, err := json.Marshal(content)
+			if err == nil {
+				err = json.Unmarshal(data, &decoded)
+			}
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to sanitize the tool result", "err", err)
+				return result, nil
+			}
+			content = decoded
+		}
+		sanitizeValue(content)
+		toolResult.StructuredContent = content
+		return toolResult, nil
+	}
+}
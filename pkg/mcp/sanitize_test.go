@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSanitizeMiddleware(t *testing.T) {
+	handler := sanitizeMiddleware(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{StructuredContent: &ResourceGetResult{Resource: map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]any{
+				"name":            "web",
+				"resourceVersion": "42",
+				"managedFields":   []any{map[string]any{"manager": "kubectl"}},
+				"annotations":     map[string]any{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+				"labels":          map[string]any{"app": "web"},
+			},
+			"spec": map[string]any{"template": map[string]any{"metadata": map[string]any{"labels": map[string]any{"app": "web"}}}},
+		}}}, nil
+	})
+
+	tests := []struct {
+		name      string
+		arguments string
+		expected  string
+	}{
+		{
+			name:      "sanitized by default",
+			arguments: `{"resource":"deployments","name":"web"}`,
+			expected:  `{"resource":{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"labels":{"app":"web"},"name":"web"},"spec":{"template":{"metadata":{"labels":{"app":"web"}}}}}}`,
+		},
+		{
+			name:      "full metadata",
+			arguments: `{"resource":"deployments","name":"web","fullMetadata":true}`,
+			expected: `{"resource":{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}"},` +
+				`"labels":{"app":"web"},"managedFields":[{"manager":"kubectl"}],"name":"web","resourceVersion":"42"},"spec":{"template":{"metadata":{"labels":{"app":"web"}}}}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler(context.Background(), methodCallTool, &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
+				Name:      "resource_get",
+				Arguments: json.RawMessage(tt.arguments),
+			}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			data, err := json.Marshal(result.(*mcp.CallToolResult).StructuredContent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
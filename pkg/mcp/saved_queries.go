@@ -0,0 +1,286 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+// SavedQuery is a named list query that can be re-run by name.
+// Queries are configured by the operator or saved by a session with the save_query tool.
+type SavedQuery struct {
+	Name          string             `json:"name" jsonschema:"The name of the query (e.g. prod-error-pods)"`
+	Description   string             `json:"description,omitempty" jsonschema:"What the query checks"`
+	Resource      string             `json:"resource" jsonschema:"The Kubernetes resource type (e.g. pods services deployments.v1.apps)"`
+	Namespace     string             `json:"namespace,omitempty" jsonschema:"The namespace to list resources from (optional defaults to all namespaces)"`
+	LabelSelector string             `json:"labelSelector,omitempty" jsonschema:"Label selector to filter resources (e.g. app=myapp)"`
+	FieldSelector string             `json:"fieldSelector,omitempty" jsonschema:"Field selector to filter resources (e.g. status.phase=Failed)"`
+	Columns       []ColumnDefinition `json:"columns,omitempty" jsonschema:"Columns to project from each resource as name and JSONPath pairs. Full resources are returned when omitted"`
+}
+
+// LoadSavedQueries reads the operator configured saved queries from the given YAML file.
+func LoadSavedQueries(path string) ([]SavedQuery, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved queries from %s: %w", path, err)
+	}
+
+	var queries []SavedQuery
+	if err := yaml.UnmarshalStrict(data, &queries); err != nil {
+		return nil, fmt.Errorf("failed to parse saved queries from %s: %w", path, err)
+	}
+
+	seen := map[string]bool{}
+	for _, query := range queries {
+		if err := query.validate(); err != nil {
+			return nil, err
+		}
+		if seen[query.Name] {
+			return nil, fmt.Errorf("saved query %s is defined more than once", query.Name)
+		}
+		seen[query.Name] = true
+	}
+
+	return queries, nil
+}
+
+func (q *SavedQuery) validate() error {
+	if q.Name == "" {
+		return fmt.Errorf("saved query for resource %q is missing name", q.Resource)
+	}
+	if q.Resource == "" {
+		return fmt.Errorf("saved query %s is missing resource", q.Name)
+	}
+	for _, col := range q.Columns {
+		if col.Name == "" {
+			return fmt.Errorf("column of saved query %s is missing name", q.Name)
+		}
+		if _, err := parseJSONPath(col.Name, col.JSONPath); err != nil {
+			return fmt.Errorf("invalid jsonPath for column %s of saved query %s: %w", col.Name, q.Name, err)
+		}
+	}
+	return nil
+}
+
+// savedQueryStore holds the operator configured queries, shared by every session,
+// and the queries saved by each session, which are dropped when the session ends.
+type savedQueryStore struct {
+	operator map[string]SavedQuery
+
+	mu       sync.Mutex
+	sessions map[string]map[string]SavedQuery
+}
+
+func newSavedQueryStore(queries []SavedQuery) *savedQueryStore {
+	store := &savedQueryStore{
+		operator: make(map[string]SavedQuery, len(queries)),
+		sessions: map[string]map[string]SavedQuery{},
+	}
+	for _, query := range queries {
+		store.operator[query.Name] = query
+	}
+	return store
+}
+
+func (s *savedQueryStore) save(session *mcp.ServerSession, query SavedQuery) error {
+	if _, ok := s.operator[query.Name]; ok {
+		return fmt.Errorf("saved query %s is configured by the operator and can not be overwritten", query.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queries, ok := s.sessions[session.ID()]
+	if !ok {
+		queries = map[string]SavedQuery{}
+		s.sessions[session.ID()] = queries
+		go func() {
+			//nolint:errcheck
+			session.Wait()
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.sessions, session.ID())
+		}()
+	}
+	queries[query.Name] = query
+	return nil
+}
+
+func (s *savedQueryStore) get(session *mcp.ServerSession, name string) (SavedQuery, bool) {
+	if query, ok := s.operator[name]; ok {
+		return query, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query, ok := s.sessions[session.ID()][name]
+	return query, ok
+}
+
+// lookup is like get, but the error lists the queries visible to the session.
+func (s *savedQueryStore) lookup(session *mcp.ServerSession, name string) (SavedQuery, error) {
+	if query, ok := s.get(session, name); ok {
+		return query, nil
+	}
+	names := s.names(session)
+	if len(names) == 0 {
+		return SavedQuery{}, fmt.Errorf("saved query %s not found, no queries are saved", name)
+	}
+	return SavedQuery{}, fmt.Errorf("saved query %s not found, available queries: %s", name, strings.Join(names, ", "))
+}
+
+// names returns the sorted names of the queries visible to the session.
+func (s *savedQueryStore) names(session *mcp.ServerSession) []string {
+	names := make([]string, 0, len(s.operator))
+	for name := range s.operator {
+		names = append(names, name)
+	}
+
+	s.mu.Lock()
+	for name := range s.sessions[session.ID()] {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+
+	sort.Strings(names)
+	return names
+}
+
+type SaveQueryResult struct {
+	Name string `json:"name"`
+}
+
+type RunQueryInput struct {
+	Name string `json:"name,required" jsonschema:"The name of the saved query to run"`
+}
+
+type RunQueryResult struct {
+	Query     SavedQuery               `json:"query"`
+	Resources []map[string]interface{} `json:"resources"`
+	// APIServerURL is the API server the resources come from.
+	APIServerURL string `json:"apiServerUrl,omitempty"`
+}
+
+func (s *Server) addSavedQueryTools(server *mcp.Server, dynamicConfig *DynamicConfig, store *savedQueryStore) {
+	addTool(server, s.clusterlessTool(&mcp.Tool{
+		Name: "save_query",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(false),
+			ReadOnlyHint:    false,
+			Title:           "Save a named list query",
+		},
+		Description: "Save a list query (resource type, namespace, selectors and projected columns) under a name for the rest of the session, so recurring checks can be re-run with run_query. Saving an existing session query replaces it",
+	}), func(ctx context.Context, request *mcp.CallToolRequest, input SavedQuery) (*mcp.CallToolResult, *SaveQueryResult, error) {
+		if err := input.validate(); err != nil {
+			return nil, nil, err
+		}
+		if err := store.save(request.Session, input); err != nil {
+			return nil, nil, err
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Saved query %s", input.Name),
+				},
+			},
+		}, &SaveQueryResult{Name: input.Name}, nil
+	})
+
+	addTool(server, &mcp.Tool{
+		Name: "run_query",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Run a saved list query",
+		},
+		Description: "Run a list query saved with save_query or configured by the operator, by name",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input RunQueryInput) (*mcp.CallToolResult, *RunQueryResult, error) {
+		query, err := store.lookup(request.Session, input.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		gvr, _, err := FindResource(ctx, query.Resource, discoveryClient, request.Session)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find resource: %w", err)
+		}
+
+		result, err := listSavedQuery(ctx, dynamicClient, gvr, query)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Saved query %s found %d %s resources", query.Name, len(result), query.Resource),
+				},
+			},
+		}, &RunQueryResult{Query: query, Resources: result, APIServerURL: requestAPIServerURL(request)}, nil
+	})
+}
+
+// listSavedQuery lists the resources matching the query and projects the configured columns.
+func listSavedQuery(ctx context.Context, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, query SavedQuery) ([]map[string]interface{}, error) {
+	listOptions := v1.ListOptions{
+		LabelSelector: query.LabelSelector,
+		FieldSelector: query.FieldSelector,
+	}
+	var resources *unstructured.UnstructuredList
+	var err error
+	if query.Namespace != "" {
+		resources, err = dynamicClient.Resource(gvr).Namespace(query.Namespace).List(ctx, listOptions)
+	} else {
+		resources, err = dynamicClient.Resource(gvr).List(ctx, listOptions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to run saved query %s: %w", query.Name, err)
+	}
+
+	result := make([]map[string]interface{}, 0, len(resources.Items))
+	for _, item := range resources.Items {
+		if len(query.Columns) > 0 {
+			result = append(result, columnRecord(&item, query.Columns))
+			continue
+		}
+		result = append(result, item.Object)
+	}
+	return result, nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSavedQueries(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError bool
+	}{
+		{
+			name: "valid",
+			content: `
+- name: prod-error-pods
+  resource: pods
+  namespace: prod
+  fieldSelector: status.phase=Failed
+  columns:
+  - name: node
+    jsonPath: .spec.nodeName
+`,
+		},
+		{
+			name: "missing name",
+			content: `
+- resource: pods
+`,
+			expectedError: true,
+		},
+		{
+			name: "missing resource",
+			content: `
+- name: prod-error-pods
+`,
+			expectedError: true,
+		},
+		{
+			name: "duplicate name",
+			content: `
+- name: prod-error-pods
+  resource: pods
+- name: prod-error-pods
+  resource: deployments
+`,
+			expectedError: true,
+		},
+		{
+			name: "invalid jsonPath",
+			content: `
+- name: prod-error-pods
+  resource: pods
+  columns:
+  - name: node
+    jsonPath: .spec[
+`,
+			expectedError: true,
+		},
+		{
+			name: "unknown field",
+			content: `
+- name: prod-error-pods
+  resource: pods
+  selector: app=web
+`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "queries.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadSavedQueries(path)
+			if tt.expectedError && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectedError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSavedQueryStoreOperatorQueries(t *testing.T) {
+	store := newSavedQueryStore([]SavedQuery{{Name: "prod-error-pods", Resource: "pods"}})
+
+	query, ok := store.get(nil, "prod-error-pods")
+	if !ok || query.Resource != "pods" {
+		t.Errorf("expected operator query to be found, got %+v", query)
+	}
+	if err := store.save(nil, SavedQuery{Name: "prod-error-pods", Resource: "deployments"}); err == nil {
+		t.Errorf("expected error when overwriting an operator query")
+	}
+}
@@ -0,0 +1,409 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// scheduledQueryURIPrefix is the prefix of the MCP resources holding the results of scheduled queries.
+	// The session is part of the URI, so that subscriptions and reads are scoped to the session owning the schedule.
+	scheduledQueryURIPrefix   = "k-mcp://sessions/"
+	scheduledQueryURITemplate = scheduledQueryURIPrefix + "{session}/queries/{name}"
+
+	defaultScheduleInterval = 5 * time.Minute
+	minScheduleInterval     = time.Minute
+	// maxScheduledQueryRuns is the number of runs kept in the history of a scheduled query.
+	maxScheduledQueryRuns = 10
+	// maxScheduledQueriesPerSession bounds the background work a single session can start.
+	maxScheduledQueriesPerSession = 10
+)
+
+type ScheduleQueryInput struct {
+	Name     string `json:"name,required" jsonschema:"The name of the saved query to schedule"`
+	Interval string `json:"interval,omitempty" jsonschema:"How often to run the query (e.g. 10m). Defaults to 5m and must be at least 1m"`
+	Stop     bool   `json:"stop,omitempty" jsonschema:"Stop the schedule of the query instead of starting it"`
+}
+
+type ScheduleQueryResult struct {
+	URI      string `json:"uri"`
+	Interval string `json:"interval,omitempty"`
+}
+
+// QueryRun is the result of a single run of a scheduled query.
+type QueryRun struct {
+	Time      string                   `json:"time"`
+	Count     int                      `json:"count"`
+	Error     string                   `json:"error,omitempty"`
+	Resources []map[string]interface{} `json:"resources,omitempty"`
+}
+
+// QueryReport is the content of the MCP resource of a scheduled query.
+// Runs are ordered from the most recent to the oldest.
+type QueryReport struct {
+	Query    SavedQuery `json:"query"`
+	Interval string     `json:"interval"`
+	Stopped  string     `json:"stopped,omitempty"`
+	Runs     []QueryRun `json:"runs"`
+}
+
+type scheduledQuery struct {
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	report QueryReport
+}
+
+func (q *scheduledQuery) record(run QueryRun) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.report.Runs = append([]QueryRun{run}, q.report.Runs...)
+	if len(q.report.Runs) > maxScheduledQueryRuns {
+		q.report.Runs = q.report.Runs[:maxScheduledQueryRuns]
+	}
+}
+
+func (q *scheduledQuery) stop(reason string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.report.Stopped = reason
+}
+
+func (q *scheduledQuery) marshal() ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return json.Marshal(q.report)
+}
+
+// queryScheduler runs saved queries periodically on behalf of a session, with the credentials
+// of the session. Results are exposed as MCP resources, and sessions subscribed to them are
+// notified after every run. Schedules stop when the session ends or its token expires,
+// since the server has no credentials of its own.
+type queryScheduler struct {
+	server *mcp.Server
+	// redactor redacts the resources of the runs, which do not go through the middlewares of the tool results.
+	redactor *redactor
+
+	mu       sync.Mutex
+	sessions map[string]map[string]*scheduledQuery
+}
+
+// newQueryScheduler returns a scheduler whose server must be set before queries are scheduled,
+// since the subscription handlers are needed to create the server.
+func newQueryScheduler() *queryScheduler {
+	return &queryScheduler{
+		sessions: map[string]map[string]*scheduledQuery{},
+	}
+}
+
+// scheduledQuerySession returns the session part of the URIs of the session.
+// Sessions of transports without session IDs, like stdio, are the only session of the server.
+func scheduledQuerySession(session *mcp.ServerSession) string {
+	if id := session.ID(); id != "" {
+		return id
+	}
+	return "local"
+}
+
+func scheduledQueryURI(sessionID, name string) string {
+	return scheduledQueryURIPrefix + sessionID + "/queries/" + name
+}
+
+// parseScheduledQueryURI returns the session and the query name of a scheduled query URI.
+func parseScheduledQueryURI(uri string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(uri, scheduledQueryURIPrefix)
+	if !ok {
+		return "", "", false
+	}
+	sessionID, name, ok := strings.Cut(rest, "/queries/")
+	if !ok || sessionID == "" || name == "" {
+		return "", "", false
+	}
+	return sessionID, name, true
+}
+
+// start schedules the query for the session, replacing the previous schedule of the same query.
+func (s *queryScheduler) start(session *mcp.ServerSession, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, query SavedQuery, interval time.Duration, expiration time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionID := scheduledQuerySession(session)
+	queries, ok := s.sessions[sessionID]
+	if !ok {
+		queries = map[string]*scheduledQuery{}
+		s.sessions[sessionID] = queries
+		go func() {
+			//nolint:errcheck
+			session.Wait()
+			s.stopSession(sessionID)
+		}()
+	}
+	if previous, ok := queries[query.Name]; ok {
+		previous.cancel()
+	} else if len(queries) >= maxScheduledQueriesPerSession {
+		return "", fmt.Errorf("at most %d queries can be scheduled per session", maxScheduledQueriesPerSession)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduled := &scheduledQuery{
+		cancel: cancel,
+		report: QueryReport{
+			Query:    query,
+			Interval: interval.String(),
+			Runs:     []QueryRun{},
+		},
+	}
+	queries[query.Name] = scheduled
+
+	uri := scheduledQueryURI(sessionID, query.Name)
+	go s.run(ctx, scheduled, uri, dynamicClient, gvr, interval, expiration)
+	return uri, nil
+}
+
+func (s *queryScheduler) run(ctx context.Context, scheduled *scheduledQuery, uri string, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, interval time.Duration, expiration time.Time) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if !expiration.IsZero() && time.Now().After(expiration) {
+			scheduled.stop("token expired, schedule the query again with a new token")
+			s.notify(uri)
+			return
+		}
+
+		run := s.query(ctx, dynamicClient, gvr, scheduled.report.Query)
+		if ctx.Err() != nil {
+			return
+		}
+		if run.Error != "" {
+			slog.Warn("Scheduled query failed", "uri", uri, "err", run.Error)
+		}
+		scheduled.record(run)
+		s.notify(uri)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// query runs the query once. The resources are sanitized and redacted like the results of the tools.
+func (s *queryScheduler) query(ctx context.Context, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, query SavedQuery) QueryRun {
+	run := QueryRun{Time: time.Now().Format(time.RFC3339)}
+	resources, err := listSavedQuery(ctx, dynamicClient, gvr, query)
+	if err != nil {
+		run.Error = err.Error()
+		return run
+	}
+	for _, resource := range resources {
+		sanitizeValue(resource)
+		if s.redactor != nil {
+			s.redactor.redactValue(resource)
+		}
+	}
+	run.Count = len(resources)
+	run.Resources = resources
+	return run
+}
+
+func (s *queryScheduler) notify(uri string) {
+	//nolint:errcheck
+	s.server.ResourceUpdated(context.Background(), &mcp.ResourceUpdatedNotificationParams{URI: uri})
+}
+
+func (s *queryScheduler) stop(sessionID, name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scheduled, ok := s.sessions[sessionID][name]
+	if !ok {
+		return false
+	}
+	scheduled.cancel()
+	delete(s.sessions[sessionID], name)
+	return true
+}
+
+func (s *queryScheduler) stopSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, scheduled := range s.sessions[sessionID] {
+		scheduled.cancel()
+	}
+	delete(s.sessions, sessionID)
+}
+
+// evictResults drops the resources of the runs of the scheduled queries but the latest one, keeping
+// their counts, to free memory under pressure.
+func (s *queryScheduler) evictResults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, queries := range s.sessions {
+		for _, scheduled := range queries {
+			scheduled.mu.Lock()
+			for i := 1; i < len(scheduled.report.Runs); i++ {
+				scheduled.report.Runs[i].Resources = nil
+			}
+			scheduled.mu.Unlock()
+		}
+	}
+}
+
+func (s *queryScheduler) get(sessionID, name string) (*scheduledQuery, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scheduled, ok := s.sessions[sessionID][name]
+	return scheduled, ok
+}
+
+// readResource serves the report of a scheduled query. Sessions can only read their own reports.
+func (s *queryScheduler) readResource(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := request.Params.URI
+	sessionID, name, ok := parseScheduledQueryURI(uri)
+	if !ok || sessionID != scheduledQuerySession(request.Session) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	scheduled, ok := s.get(sessionID, name)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	data, err := scheduled.marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report of scheduled query %s: %w", name, err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		},
+	}, nil
+}
+
+// subscribe only accepts subscriptions to the scheduled queries of the subscribing session.
+func (s *queryScheduler) subscribe(ctx context.Context, request *mcp.SubscribeRequest) error {
+	sessionID, _, ok := parseScheduledQueryURI(request.Params.URI)
+	if !ok || sessionID != scheduledQuerySession(request.Session) {
+		return mcp.ResourceNotFoundError(request.Params.URI)
+	}
+	return nil
+}
+
+func (s *queryScheduler) unsubscribe(ctx context.Context, request *mcp.UnsubscribeRequest) error {
+	return nil
+}
+
+func (s *Server) addScheduleQueryTool(server *mcp.Server, dynamicConfig *DynamicConfig, store *savedQueryStore, scheduler *queryScheduler) {
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "scheduled-query",
+		Title:       "Scheduled query report",
+		Description: "The recent runs of a query scheduled with the schedule_query tool. Subscribe to be notified after every run",
+		MIMEType:    "application/json",
+		URITemplate: scheduledQueryURITemplate,
+	}, scheduler.readResource)
+
+	addTool(server, &mcp.Tool{
+		Name: "schedule_query",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    false,
+			Title:           "Run a saved query periodically",
+		},
+		Description: "Run a saved query periodically in the background for the rest of the session. The recent results are kept in an MCP resource whose URI is returned, and subscribers of the resource are notified after every run",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ScheduleQueryInput) (*mcp.CallToolResult, *ScheduleQueryResult, error) {
+		uri := scheduledQueryURI(scheduledQuerySession(request.Session), input.Name)
+		if input.Stop {
+			if !scheduler.stop(scheduledQuerySession(request.Session), input.Name) {
+				return nil, nil, fmt.Errorf("query %s is not scheduled", input.Name)
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Stopped the schedule of query %s", input.Name),
+					},
+				},
+			}, &ScheduleQueryResult{URI: uri}, nil
+		}
+
+		interval := defaultScheduleInterval
+		if input.Interval != "" {
+			var err error
+			interval, err = time.ParseDuration(input.Interval)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid interval %q: %w", input.Interval, err)
+			}
+			if interval < minScheduleInterval {
+				return nil, nil, fmt.Errorf("interval must be at least %s", minScheduleInterval)
+			}
+		}
+
+		query, err := store.lookup(request.Session, input.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		// Resolve the resource while the user can still be asked to disambiguate it.
+		gvr, _, err := FindResource(ctx, query.Resource, discoveryClient, request.Session)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find resource: %w", err)
+		}
+
+		uri, err = scheduler.start(request.Session, dynamicClient, gvr, query, interval, request.Extra.TokenInfo.Expiration)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Scheduled query %s every %s, results are available in resource %s", query.Name, interval, uri),
+				},
+				&mcp.ResourceLink{
+					URI:      uri,
+					Name:     query.Name,
+					MIMEType: "application/json",
+				},
+			},
+		}, &ScheduleQueryResult{URI: uri, Interval: interval.String()}, nil
+	})
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestParseScheduledQueryURI(t *testing.T) {
+	sessionID, name, ok := parseScheduledQueryURI(scheduledQueryURI("abc", "prod-error-pods"))
+	if !ok || sessionID != "abc" || name != "prod-error-pods" {
+		t.Errorf("unexpected parse result: %q %q %v", sessionID, name, ok)
+	}
+
+	for _, uri := range []string{
+		"k-mcp://sessions/abc",
+		"k-mcp://sessions//queries/prod-error-pods",
+		"k-mcp://sessions/abc/queries/",
+		"file:///queries/prod-error-pods",
+	} {
+		if _, _, ok := parseScheduledQueryURI(uri); ok {
+			t.Errorf("expected %s to be rejected", uri)
+		}
+	}
+}
+
+func TestScheduledQueryHistory(t *testing.T) {
+	scheduled := &scheduledQuery{report: QueryReport{Runs: []QueryRun{}}}
+	for i := 0; i < maxScheduledQueryRuns+2; i++ {
+		scheduled.record(QueryRun{Count: i})
+	}
+	if len(scheduled.report.Runs) != maxScheduledQueryRuns {
+		t.Fatalf("expected %d runs, got %d", maxScheduledQueryRuns, len(scheduled.report.Runs))
+	}
+	if scheduled.report.Runs[0].Count != maxScheduledQueryRuns+1 {
+		t.Errorf("expected the most recent run first, got count %d", scheduled.report.Runs[0].Count)
+	}
+}
+
+func TestQuerySchedulerNotifiesSubscribers(t *testing.T) {
+	ctx := context.Background()
+
+	scheduler := newQueryScheduler()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, &mcp.ServerOptions{
+		SubscribeHandler:   scheduler.subscribe,
+		UnsubscribeHandler: scheduler.unsubscribe,
+	})
+	scheduler.server = server
+	server.AddResourceTemplate(&mcp.ResourceTemplate{Name: "scheduled-query", URITemplate: scheduledQueryURITemplate}, scheduler.readResource)
+
+	updated := make(chan string, 1)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			select {
+			case updated <- req.Params.URI:
+			default:
+			}
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	uri := scheduledQueryURI(scheduledQuerySession(serverSession), "web-pods")
+	if err := clientSession.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	if err := clientSession.Subscribe(ctx, &mcp.SubscribeParams{URI: scheduledQueryURI("other", "web-pods")}); err == nil {
+		t.Errorf("expected subscription to the queries of another session to fail")
+	}
+
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      "web-0",
+			"namespace": "web",
+		},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), pod)
+	query := SavedQuery{Name: "web-pods", Resource: "pods", Namespace: "web"}
+	if _, err := scheduler.start(serverSession, dynamicClient, podsGVR, query, time.Hour, time.Time{}); err != nil {
+		t.Fatalf("failed to schedule query: %v", err)
+	}
+
+	select {
+	case got := <-updated:
+		if got != uri {
+			t.Errorf("expected update of %s, got %s", uri, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the resource update notification")
+	}
+
+	result, err := clientSession.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		t.Fatalf("failed to read resource: %v", err)
+	}
+	var report QueryReport
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Runs) != 1 || report.Runs[0].Count != 1 {
+		t.Errorf("expected one run with one resource, got %+v", report.Runs)
+	}
+
+	if !scheduler.stop(scheduledQuerySession(serverSession), "web-pods") {
+		t.Errorf("expected the schedule to be stopped")
+	}
+}
+
+func TestScheduledQueryRedaction(t *testing.T) {
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":            "web-0",
+			"namespace":       "web",
+			"resourceVersion": "42",
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": `{"spec":{"containers":[{"env":[{"name":"DB_PASSWORD","value":"hunter2"}]}]}}`,
+			},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name": "web",
+					"env": []interface{}{
+						map[string]interface{}{"name": "DB_PASSWORD", "value": "hunter2"},
+						map[string]interface{}{"name": "LOG_LEVEL", "value": "debug"},
+					},
+				},
+			},
+		},
+	}}
+	redactor, err := newRedactor([]string{"PASSWORD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler := newQueryScheduler()
+	scheduler.redactor = redactor
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), pod)
+	run := scheduler.query(context.Background(), dynamicClient, podsGVR, SavedQuery{Name: "web-pods", Resource: "pods", Namespace: "web"})
+	if run.Error != "" || run.Count != 1 {
+		t.Fatalf("expected one resource, got %+v", run)
+	}
+
+	data, err := json.Marshal(run.Resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Errorf("expected the password to be redacted, got %s", data)
+	}
+	if strings.Contains(string(data), "last-applied-configuration") || strings.Contains(string(data), "resourceVersion") {
+		t.Errorf("expected the metadata to be sanitized, got %s", data)
+	}
+	if !strings.Contains(string(data), `"value":"debug"`) {
+		t.Errorf("expected the other variables to be kept, got %s", data)
+	}
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+var secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// SecretResolver resolves a reference to a secret stored outside of the server configuration.
+type SecretResolver interface {
+	Resolve(ctx context.Context, reference string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to a SecretResolver.
+type SecretResolverFunc func(ctx context.Context, reference string) (string, error)
+
+func (f SecretResolverFunc) Resolve(ctx context.Context, reference string) (string, error) {
+	return f(ctx, reference)
+}
+
+// SecretResolvers resolve the configuration values of the form ${source:reference} with the
+// resolver of their source, e.g. ${env:API_KEY}, so that credentials are not written in plain text.
+type SecretResolvers map[string]SecretResolver
+
+// NewSecretResolvers returns the resolvers of the built-in secret sources:
+//   - env: an environment variable, e.g. ${env:API_KEY}
+//   - file: the content of a file without surrounding whitespace, e.g. ${file:/etc/k-mcp/api-key}
+//   - k8s: a key of a Secret, read with the service account when running in a cluster, e.g. ${k8s:k-mcp/gateway/api-key}
+//   - vault: a key of a Vault KV secret, read from VAULT_ADDR with VAULT_TOKEN, e.g. ${vault:secret/data/k-mcp#api-key}
+func NewSecretResolvers() SecretResolvers {
+	return SecretResolvers{
+		"env":   SecretResolverFunc(resolveEnvSecret),
+		"file":  SecretResolverFunc(resolveFileSecret),
+		"k8s":   &kubernetesSecretResolver{client: inClusterDynamicClient},
+		"vault": &vaultSecretResolver{address: os.Getenv("VAULT_ADDR"), token: os.Getenv("VAULT_TOKEN"), client: http.DefaultClient},
+	}
+}
+
+// Resolve returns the secret referenced by a value, or the value itself when it is not a reference.
+func (r SecretResolvers) Resolve(ctx context.Context, value string) (string, error) {
+	reference, ok := strings.CutPrefix(value, "${")
+	if !ok {
+		return value, nil
+	}
+	reference, ok = strings.CutSuffix(reference, "}")
+	if !ok {
+		return value, nil
+	}
+	source, reference, ok := strings.Cut(reference, ":")
+	if !ok {
+		return "", fmt.Errorf("invalid secret reference %q, must be ${source:reference}", value)
+	}
+	resolver, ok := r[source]
+	if !ok {
+		sources := make([]string, 0, len(r))
+		for name := range r {
+			sources = append(sources, name)
+		}
+		sort.Strings(sources)
+		return "", fmt.Errorf("unknown secret source %q, must be one of: %s", source, strings.Join(sources, ", "))
+	}
+
+	secret, err := resolver.Resolve(ctx, reference)
+	if err != nil {
+		// Only the reference is reported, never the value.
+		return "", fmt.Errorf("failed to resolve secret %s: %w", value, err)
+	}
+	return secret, nil
+}
+
+// ResolveMap resolves the secret references of the values of a map, returning a new map.
+func (r SecretResolvers) ResolveMap(ctx context.Context, values map[string]string) (map[string]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	resolved := make(map[string]string, len(values))
+	for key, value := range values {
+		secret, err := r.Resolve(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		resolved[key] = secret
+	}
+	return resolved, nil
+}
+
+func resolveEnvSecret(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+func resolveFileSecret(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func inClusterDynamicClient() (dynamic.Interface, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(config)
+}
+
+// kubernetesSecretResolver reads the keys of Secrets, referenced as namespace/name/key.
+type kubernetesSecretResolver struct {
+	client func() (dynamic.Interface, error)
+}
+
+func (r *kubernetesSecretResolver) Resolve(ctx context.Context, reference string) (string, error) {
+	parts := strings.Split(reference, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", fmt.Errorf("invalid Secret reference %q, must be namespace/name/key", reference)
+	}
+	client, err := r.client()
+	if err != nil {
+		return "", fmt.Errorf("failed to create the client reading Secrets: %w", err)
+	}
+
+	secret, err := client.Resource(secretsGVR).Namespace(parts[0]).Get(ctx, parts[1], v1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	encoded, found, err := unstructured.NestedString(secret.Object, "data", parts[2])
+	if err != nil || !found {
+		return "", fmt.Errorf("key %s not found in Secret %s/%s", parts[2], parts[0], parts[1])
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid data of key %s: %w", parts[2], err)
+	}
+	return string(data), nil
+}
+
+// vaultSecretResolver reads the keys of Vault KV secrets, referenced as path#key. Paths of
+// KV version 2 engines include the data segment, e.g. secret/data/k-mcp#api-key.
+type vaultSecretResolver struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+func (r *vaultSecretResolver) Resolve(ctx context.Context, reference string) (string, error) {
+	path, key, ok := strings.Cut(reference, "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("invalid Vault reference %q, must be path#key", reference)
+	}
+	if r.address == "" || r.token == "" {
+		return "", fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set to read Vault secrets")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(r.address, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", r.token)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for %s", resp.Status, path)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode the Vault response: %w", err)
+	}
+	data := body.Data
+	// KV version 2 nests the secret with its metadata.
+	if nested, ok := data["data"].(map[string]any); ok {
+		data = nested
+	}
+	value, ok := data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("key %s not found in %s", key, path)
+	}
+	return value, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestSecretResolvers(t *testing.T) {
+	t.Setenv("K_MCP_TEST_API_KEY", "from-env")
+	file := filepath.Join(t.TempDir(), "api-key")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := bundleObject("v1", "Secret", "k-mcp", "gateway")
+	secret.Object["data"] = map[string]interface{}{"api-key": base64.StdEncoding.EncodeToString([]byte("from-secret"))}
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), secret)
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/k-mcp":
+			w.Write([]byte(`{"data":{"data":{"api-key":"from-vault-v2"},"metadata":{"version":3}}}`)) //nolint:errcheck
+		case "/v1/kv/k-mcp":
+			w.Write([]byte(`{"data":{"api-key":"from-vault-v1"}}`)) //nolint:errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	resolvers := NewSecretResolvers()
+	resolvers["k8s"] = &kubernetesSecretResolver{client: func() (dynamic.Interface, error) { return dynamicClient, nil }}
+	resolvers["vault"] = &vaultSecretResolver{address: vault.URL, token: "vault-token", client: vault.Client()}
+
+	tests := []struct {
+		value    string
+		expected string
+		err      string
+	}{
+		{value: "plain", expected: "plain"},
+		{value: "${env:K_MCP_TEST_API_KEY}", expected: "from-env"},
+		{value: "${file:" + file + "}", expected: "from-file"},
+		{value: "${k8s:k-mcp/gateway/api-key}", expected: "from-secret"},
+		{value: "${vault:secret/data/k-mcp#api-key}", expected: "from-vault-v2"},
+		{value: "${vault:kv/k-mcp#api-key}", expected: "from-vault-v1"},
+		{value: "${env:K_MCP_TEST_UNSET}", err: "is not set"},
+		{value: "${k8s:k-mcp/gateway/token}", err: "key token not found"},
+		{value: "${k8s:k-mcp/gateway}", err: "must be namespace/name/key"},
+		{value: "${vault:secret/data/other#api-key}", err: "404"},
+		{value: "${aws:k-mcp}", err: "unknown secret source"},
+		{value: "${api-key}", err: "must be ${source:reference}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			value, err := resolvers.Resolve(context.TODO(), tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+)
+
+type SetContextInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"The namespace used by the next tool calls of the session when a namespaced resource is given without namespace, instead of asking for it. Empty clears it"`
+	Cluster   string `json:"cluster,omitempty" jsonschema:"The cluster used by the next tool calls of the session without cluster, by name or by the URL or host of its API server, instead of asking for it when the token grants several clusters. Empty clears it"`
+}
+
+type SetContextResult struct {
+	Namespace string `json:"namespace,omitempty"`
+	// Cluster is the default cluster of the session, or else the cluster of the token.
+	Cluster string `json:"cluster,omitempty"`
+	// APIServerURL is the API server of the default cluster of the session, empty when it has none.
+	APIServerURL string `json:"apiServerUrl,omitempty"`
+}
+
+// sessionContexts are the defaults set by the sessions with set_context, kept until the session ends.
+type sessionContexts struct {
+	mu       sync.Mutex
+	contexts map[*mcp.ServerSession]SetContextResult
+}
+
+func newSessionContexts() *sessionContexts {
+	return &sessionContexts{contexts: map[*mcp.ServerSession]SetContextResult{}}
+}
+
+// set stores the defaults of a session, forgetting them once the session ends.
+func (c *sessionContexts) set(session *mcp.ServerSession, sessionContext SetContextResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(session, sessionContext)
+}
+
+// setCluster stores the default cluster of a session, keeping its other defaults.
+func (c *sessionContexts) setCluster(session *mcp.ServerSession, apiServerURL, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sessionContext := c.contexts[session]
+	sessionContext.APIServerURL, sessionContext.Cluster = apiServerURL, name
+	c.setLocked(session, sessionContext)
+}
+
+// setLocked stores the defaults of a session with the lock held.
+func (c *sessionContexts) setLocked(session *mcp.ServerSession, sessionContext SetContextResult) {
+	if _, ok := c.contexts[session]; !ok {
+		go func() {
+			//nolint:errcheck
+			session.Wait()
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			delete(c.contexts, session)
+		}()
+	}
+	c.contexts[session] = sessionContext
+}
+
+// cluster returns the API server of the default cluster of a session, empty if it has none.
+func (c *sessionContexts) cluster(session *mcp.ServerSession) string {
+	if c == nil || session == nil {
+		return ""
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.contexts[session].APIServerURL
+}
+
+// namespace returns the default namespace of a session, empty if it has none.
+func (c *sessionContexts) namespace(session *mcp.ServerSession) string {
+	if c == nil || session == nil {
+		return ""
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.contexts[session].Namespace
+}
+
+// sessionNamespace returns the default namespace of the session or else of the user, and asks the
+// user for the namespace of the namespaced resource when there is none.
+func (s *Server) sessionNamespace(ctx context.Context, request *mcp.CallToolRequest, resource string) (string, error) {
+	if namespace := s.defaultNamespace(request); namespace != "" {
+		return namespace, nil
+	}
+	return elicitNamespace(ctx, request.Session, resource)
+}
+
+func (s *Server) addSetContextTool(server *mcp.Server) {
+	addTool(server, s.clusterlessTool(&mcp.Tool{
+		Name: "set_context",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(false),
+			ReadOnlyHint:    false,
+			Title:           "Set the defaults of the session",
+		},
+		Description: "Set the namespace used by the next tool calls of the session when a namespaced resource is given without namespace, instead of asking for it every time, " +
+			"and the cluster used by the calls without cluster, among the clusters granted by the token of the session (see list_clusters)",
+	}), func(ctx context.Context, request *mcp.CallToolRequest, input SetContextInput) (*mcp.CallToolResult, *SetContextResult, error) {
+		if input.Namespace != "" {
+			if errs := validation.IsDNS1123Label(input.Namespace); len(errs) > 0 {
+				return nil, nil, fmt.Errorf("invalid namespace %q: %v", input.Namespace, errs)
+			}
+		}
+
+		result := SetContextResult{Namespace: input.Namespace}
+		if input.Cluster != "" {
+			if s.clusters == nil || request.Extra == nil || request.Extra.TokenInfo == nil {
+				return nil, nil, fmt.Errorf("the cluster of the session can not be set without a token")
+			}
+			apiServerURL, err := s.clusters.resolve(input.Cluster, tokenClusters(request.Extra.TokenInfo))
+			if err != nil {
+				return nil, nil, err
+			}
+			result.APIServerURL, result.Cluster = apiServerURL, s.clusters.displayName(apiServerURL)
+		} else if request.Extra != nil && request.Extra.TokenInfo != nil {
+			result.Cluster = requestCluster(request)
+		}
+		s.sessionContexts.set(request.Session, result)
+
+		message := "Cleared the default namespace of the session, the namespace is asked when it is required"
+		if input.Namespace != "" {
+			message = fmt.Sprintf("Namespace %s is used by default for the namespaced resources of the session", input.Namespace)
+		}
+		if result.APIServerURL != "" {
+			message += fmt.Sprintf(". Cluster %s is used by default for the calls of the session", result.Cluster)
+		} else if result.Cluster != "" {
+			message += fmt.Sprintf(" on cluster %s", result.Cluster)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, &result, nil
+	})
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSetContext(t *testing.T) {
+	ctx := context.Background()
+
+	s := NewServer("8080", "k-mcp")
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	s.addSetContextTool(server)
+	mcp.AddTool(server, &mcp.Tool{Name: "namespace"}, func(ctx context.Context, request *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		namespace, err := s.sessionNamespace(ctx, request, "pods")
+		if err != nil {
+			return nil, nil, err
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: namespace}}}, nil, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	namespace := func() (string, bool) {
+		result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "namespace"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+	setContext := func(namespace string) bool {
+		result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "set_context", Arguments: map[string]any{"namespace": namespace}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result.IsError
+	}
+
+	// Without default, the namespace is asked, which this client does not support.
+	if _, isError := namespace(); !isError {
+		t.Errorf("expected the namespace to be asked without default")
+	}
+	if setContext("shop") {
+		t.Fatalf("unexpected error setting the namespace")
+	}
+	if got, isError := namespace(); isError || got != "shop" {
+		t.Errorf("expected the default namespace shop, got %q", got)
+	}
+	if !setContext("Not_A_Namespace") {
+		t.Errorf("expected error for an invalid namespace")
+	}
+	if got, _ := namespace(); got != "shop" {
+		t.Errorf("expected the default namespace to be kept after an invalid one, got %q", got)
+	}
+	if setContext("") {
+		t.Fatalf("unexpected error clearing the namespace")
+	}
+	if _, isError := namespace(); !isError {
+		t.Errorf("expected the namespace to be asked once cleared")
+	}
+
+	serverSession.Close() //nolint:errcheck
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.sessionContexts.mu.Lock()
+		remaining := len(s.sessionContexts.contexts)
+		s.sessionContexts.mu.Unlock()
+		if remaining == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the context to be forgotten once the session ended")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSetContextCluster(t *testing.T) {
+	ctx := context.Background()
+
+	s := NewServer("8080", "k-mcp")
+	s.clusters = &clusterAccess{clusters: []Cluster{{Name: "prod-eu", Server: "https://prod-eu.example.com"}}, sessions: s.sessionContexts}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	s.addSetContextTool(server)
+	tokenInfo := &auth.TokenInfo{Extra: map[string]any{
+		"audience": "https://staging.example.com",
+		"clusters": []string{"https://staging.example.com", "https://prod-eu.example.com"},
+	}}
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	transport := &tokenInfoTransport{Transport: serverTransport, tokenInfo: func() *auth.TokenInfo { return tokenInfo }}
+	serverSession, err := server.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	tests := []struct {
+		cluster  string
+		expected string
+		wantErr  bool
+	}{
+		{cluster: "prod-eu", expected: "https://prod-eu.example.com"},
+		{cluster: "dev", expected: "https://prod-eu.example.com", wantErr: true},
+		{cluster: "", expected: ""},
+	}
+	for _, tt := range tests {
+		result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "set_context", Arguments: map[string]any{"cluster": tt.cluster}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.IsError != tt.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tt.cluster, tt.wantErr, result.Content)
+		}
+		if got := s.sessionContexts.cluster(serverSession); got != tt.expected {
+			t.Errorf("%q: expected the cluster of the session %q, got %q", tt.cluster, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// sortByAliases are the fields resource_list sorts by with a name instead of a JSONPath expression.
+var sortByAliases = map[string]string{
+	"name":              ".metadata.name",
+	"creationTimestamp": ".metadata.creationTimestamp",
+}
+
+// parseSortBy parses the field to sort the resources by, an alias or a JSONPath expression.
+func parseSortBy(sortBy string) (*jsonpath.JSONPath, error) {
+	if alias, ok := sortByAliases[sortBy]; ok {
+		sortBy = alias
+	}
+	path, err := parseJSONPath("sortBy", sortBy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sortBy %q, must be name, creationTimestamp or a JSONPath expression: %w", sortBy, err)
+	}
+	return path, nil
+}
+
+// sortObjects sorts the objects by the first value of the field in place, in ascending order
+// unless descending. The objects without the field come last, in their order.
+func sortObjects(items []unstructured.Unstructured, sortBy *jsonpath.JSONPath, descending bool) {
+	keys := make([]any, len(items))
+	for i := range items {
+		keys[i] = firstValue(sortBy, items[i].Object)
+	}
+
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	slices.SortStableFunc(indexes, func(a, b int) int {
+		switch {
+		case keys[a] == nil && keys[b] == nil:
+			return 0
+		case keys[a] == nil:
+			return 1
+		case keys[b] == nil:
+			return -1
+		}
+		if descending {
+			return compareValues(keys[b], keys[a])
+		}
+		return compareValues(keys[a], keys[b])
+	})
+
+	sorted := make([]unstructured.Unstructured, len(items))
+	for i, index := range indexes {
+		sorted[i] = items[index]
+	}
+	copy(items, sorted)
+}
+
+// firstValue returns the first value the JSONPath expression matches in the object, nil if none.
+func firstValue(path *jsonpath.JSONPath, obj map[string]interface{}) any {
+	results, err := path.FindResults(obj)
+	if err != nil {
+		return nil
+	}
+	for _, result := range results {
+		for _, value := range result {
+			if value.IsValid() && value.CanInterface() && value.Interface() != nil {
+				return value.Interface()
+			}
+		}
+	}
+	return nil
+}
+
+// compareValues compares numbers by their value, quantities (e.g. 512Mi and 1Gi) by their amount
+// and the other values by their text, timestamps being in chronological order.
+func compareValues(a, b any) int {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	x, y := fmt.Sprint(a), fmt.Sprint(b)
+	if qx, err := resource.ParseQuantity(x); err == nil {
+		if qy, err := resource.ParseQuantity(y); err == nil {
+			return qx.Cmp(qy)
+		}
+	}
+	return strings.Compare(x, y)
+}
+
+func number(value any) (float64, bool) {
+	switch value := value.(type) {
+	case int64:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
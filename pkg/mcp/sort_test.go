@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func sortTestPod(name, created string, restarts int64, memory string) unstructured.Unstructured {
+	pod := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":              name,
+			"creationTimestamp": created,
+		},
+		"status": map[string]interface{}{
+			"containerStatuses": []interface{}{
+				map[string]interface{}{"restartCount": restarts},
+			},
+		},
+	}}
+	if memory != "" {
+		//nolint:errcheck
+		unstructured.SetNestedField(pod.Object, memory, "spec", "memory")
+	}
+	return pod
+}
+
+func TestSortObjects(t *testing.T) {
+	pods := []unstructured.Unstructured{
+		sortTestPod("web", "2025-03-01T10:00:00Z", 2, "1Gi"),
+		sortTestPod("api", "2025-03-02T08:00:00Z", 10, "512Mi"),
+		sortTestPod("db", "2025-02-20T12:00:00Z", 0, ""),
+	}
+
+	tests := []struct {
+		name       string
+		sortBy     string
+		descending bool
+		expected   []string
+	}{
+		{
+			name:     "name",
+			sortBy:   "name",
+			expected: []string{"api", "db", "web"},
+		},
+		{
+			name:       "newest first",
+			sortBy:     "creationTimestamp",
+			descending: true,
+			expected:   []string{"api", "web", "db"},
+		},
+		{
+			name:     "numbers",
+			sortBy:   ".status.containerStatuses[*].restartCount",
+			expected: []string{"db", "web", "api"},
+		},
+		{
+			name:     "quantities with missing values last",
+			sortBy:   "{.spec.memory}",
+			expected: []string{"api", "web", "db"},
+		},
+		{
+			name:       "missing values last when descending",
+			sortBy:     ".spec.memory",
+			descending: true,
+			expected:   []string{"web", "api", "db"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := append([]unstructured.Unstructured(nil), pods...)
+			sortBy, err := parseSortBy(tt.sortBy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sortObjects(items, sortBy, tt.descending)
+
+			var names []string
+			for _, item := range items {
+				names = append(names, item.GetName())
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestParseSortBy(t *testing.T) {
+	if _, err := parseSortBy(".status.containerStatuses[*"); err == nil {
+		t.Errorf("expected an invalid JSONPath expression to fail")
+	}
+}
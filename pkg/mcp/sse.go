@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"crypto/rand"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// TransportSSE serves the deprecated HTTP with SSE transport (MCP 2024-11-05) on sseEndpointPath,
+	// in addition to the streamable HTTP transport, for the clients that have not migrated yet.
+	TransportSSE = "sse"
+	// sseEndpointPath is the path of the SSE streams, to which the messages of their sessions are posted.
+	sseEndpointPath = "/sse"
+)
+
+// sseSession is a session of the SSE transport, owned by the caller who opened its stream.
+type sseSession struct {
+	transport *mcp.SSEServerTransport
+	subject   string
+	audience  string
+	// tokenInfo is the token info of the latest message, attached to the requests of the session.
+	tokenInfo atomic.Pointer[auth.TokenInfo]
+}
+
+// owns tells whether the token of a message is the one of a caller of the session, so that its
+// session ID is not enough to post to the session of another caller.
+func (s *sseSession) owns(tokenInfo *auth.TokenInfo) bool {
+	subject, _ := tokenInfo.Extra["subject"].(string)
+	audience, _ := tokenInfo.Extra["audience"].(string)
+	return subject == s.subject && audience == s.audience
+}
+
+// sseHandler serves the SSE transport, whose streams are opened with GET and whose messages are
+// posted to the endpoint of their session. Unlike mcp.SSEHandler, the requests of a session carry
+// the token info of the messages, which must be verified before.
+type sseHandler struct {
+	server *mcp.Server
+
+	mu       sync.Mutex
+	sessions map[string]*sseSession
+}
+
+func newSSEHandler(server *mcp.Server) *sseHandler {
+	return &sseHandler{server: server, sessions: make(map[string]*sseSession)}
+}
+
+func (h *sseHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	tokenInfo := auth.TokenInfoFromContext(req.Context())
+	if tokenInfo == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPost:
+		h.mu.Lock()
+		session := h.sessions[req.URL.Query().Get("sessionid")]
+		h.mu.Unlock()
+		if session == nil || !session.owns(tokenInfo) {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		session.tokenInfo.Store(tokenInfo)
+		session.transport.ServeHTTP(w, req)
+	case http.MethodGet:
+		h.serveStream(w, req, tokenInfo)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// serveStream opens a session, and streams its messages until the client disconnects.
+func (h *sseHandler) serveStream(w http.ResponseWriter, req *http.Request, tokenInfo *auth.TokenInfo) {
+	sessionID := rand.Text()
+	endpoint, err := req.URL.Parse("?sessionid=" + sessionID)
+	if err != nil {
+		http.Error(w, "failed to create the session endpoint", http.StatusInternalServerError)
+		return
+	}
+
+	session := &sseSession{transport: &mcp.SSEServerTransport{Endpoint: endpoint.RequestURI(), Response: w}}
+	session.subject, _ = tokenInfo.Extra["subject"].(string)
+	session.audience, _ = tokenInfo.Extra["audience"].(string)
+	session.tokenInfo.Store(tokenInfo)
+
+	h.mu.Lock()
+	h.sessions[sessionID] = session
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.sessions, sessionID)
+		h.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	transport := &tokenInfoTransport{Transport: session.transport, tokenInfo: session.tokenInfo.Load}
+	ss, err := h.server.Connect(req.Context(), transport, nil)
+	if err != nil {
+		slog.Error("Failed to connect the SSE session", "err", err)
+		http.Error(w, "connection failed", http.StatusInternalServerError)
+		return
+	}
+	defer ss.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		ss.Wait() //nolint:errcheck
+		close(closed)
+	}()
+	select {
+	case <-req.Context().Done():
+	case <-closed:
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// bearerTransport authenticates the requests of a test client with a token.
+type bearerTransport struct {
+	token string
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSSEHandler(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	type whoami struct {
+		Subject string `json:"subject"`
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "whoami"}, func(ctx context.Context, request *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, whoami, error) {
+		if request.Extra == nil || request.Extra.TokenInfo == nil {
+			return nil, whoami{}, fmt.Errorf("no token info")
+		}
+		subject, _ := request.Extra.TokenInfo.Extra["subject"].(string)
+		return nil, whoami{Subject: subject}, nil
+	})
+
+	// The tokens are the subjects of their callers.
+	verifyToken := func(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+		return &auth.TokenInfo{
+			Expiration: time.Now().Add(time.Hour),
+			Extra:      map[string]any{"subject": token, "audience": "https://127.0.0.1:6443", "bearer_token": token},
+		}, nil
+	}
+	httpServer := httptest.NewServer(auth.RequireBearerToken(verifyToken, nil)(newSSEHandler(server)))
+	defer httpServer.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	session, err := client.Connect(ctx, &mcp.SSEClientTransport{
+		Endpoint:   httpServer.URL + sseEndpointPath,
+		HTTPClient: &http.Client{Transport: &bearerTransport{token: "alice"}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "whoami", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("expected the token info of the caller, got %v", result.Content)
+	}
+	if subject := result.StructuredContent.(map[string]any)["subject"]; subject != "alice" {
+		t.Errorf("expected the subject of the token, got %v", subject)
+	}
+
+	// Open a stream of alice, and post to its session as alice and as bob.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+sseEndpointPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: &bearerTransport{token: "alice"}}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var endpoint string
+	scanner := bufio.NewScanner(resp.Body)
+	for endpoint == "" && scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			endpoint = data
+		}
+	}
+
+	ping := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	for token, expected := range map[string]int{"bob": http.StatusNotFound, "alice": http.StatusAccepted} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, httpServer.URL+endpoint, strings.NewReader(ping))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := (&http.Client{Transport: &bearerTransport{token: token}}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("expected status %d for %s, got %d", expected, token, resp.StatusCode)
+		}
+	}
+}
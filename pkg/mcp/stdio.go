@@ -0,0 +1,103 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/signal"
+	"os/user"
+	"syscall"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Transports serving the MCP server.
+const (
+	// TransportHTTP serves the streamable HTTP transport, authenticating the callers with their tokens.
+	TransportHTTP = "http"
+	// TransportStdio serves a single client over stdin and stdout, e.g. a desktop client or an editor
+	// running k-mcp as a subprocess, with the credentials of the managed cluster.
+	TransportStdio = "stdio"
+)
+
+// localTokenInfo returns the token info of the requests of the stdio client, which calls the managed
+// cluster with its own credentials on behalf of the local user.
+func localTokenInfo(cluster string) *auth.TokenInfo {
+	subject := "local"
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		subject = current.Username
+	}
+	return &auth.TokenInfo{
+		Extra: map[string]any{
+			"audience":     cluster,
+			"bearer_token": "",
+			"subject":      subject,
+		},
+	}
+}
+
+// tokenInfoTransport attaches the current token info to every request read from its connection, as
+// the streamable HTTP transport does with the verified tokens, so that the tools find their cluster.
+type tokenInfoTransport struct {
+	mcp.Transport
+	tokenInfo func() *auth.TokenInfo
+}
+
+func (t *tokenInfoTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tokenInfoConnection{Connection: conn, tokenInfo: t.tokenInfo}, nil
+}
+
+type tokenInfoConnection struct {
+	mcp.Connection
+	tokenInfo func() *auth.TokenInfo
+}
+
+func (c *tokenInfoConnection) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.Connection.Read(ctx)
+	if req, ok := msg.(*jsonrpc.Request); ok {
+		req.Extra = &mcp.RequestExtra{TokenInfo: c.tokenInfo()}
+	}
+	return msg, err
+}
+
+// runStdio serves the MCP server over stdin and stdout until the client disconnects or the context
+// is cancelled.
+func (s *Server) runStdio(ctx context.Context, server *mcp.Server, dynamicConfig *DynamicConfig) error {
+	if dynamicConfig.Cluster == nil {
+		return fmt.Errorf("the stdio transport requires the cluster of a kubeconfig context or the cluster k-mcp runs in")
+	}
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	slog.InfoContext(ctx, "Serving MCP over stdio", "cluster", dynamicConfig.Cluster.Host)
+	tokenInfo := localTokenInfo(dynamicConfig.Cluster.Host)
+	transport := &tokenInfoTransport{Transport: &mcp.StdioTransport{}, tokenInfo: func() *auth.TokenInfo { return tokenInfo }}
+	if err := server.Run(ctx, transport); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	slog.InfoContext(ctx, "Stdio server stopped")
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestStdioTokenInfo(t *testing.T) {
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	type whoami struct {
+		Cluster string `json:"cluster"`
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "whoami"}, func(ctx context.Context, request *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, whoami, error) {
+		if request.Extra == nil || request.Extra.TokenInfo == nil {
+			return nil, whoami{}, fmt.Errorf("no token info")
+		}
+		return nil, whoami{Cluster: clusterName(request.Extra.TokenInfo)}, nil
+	})
+	tokenInfo := localTokenInfo("https://127.0.0.1:6443")
+	transport := &tokenInfoTransport{Transport: serverTransport, tokenInfo: func() *auth.TokenInfo { return tokenInfo }}
+	if _, err := server.Connect(ctx, transport, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "whoami", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("expected the token info of the stdio client, got %v", result.Content)
+	}
+	if cluster := result.StructuredContent.(map[string]any)["cluster"]; cluster != "127.0.0.1:6443" {
+		t.Errorf("expected the cluster of the kubeconfig context, got %v", cluster)
+	}
+}
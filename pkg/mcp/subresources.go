@@ -0,0 +1,35 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// subresources are the subresources resource_get can read: the status of the resources, e.g. of
+// the custom resources whose status is only written by their controller, and their scale.
+var subresources = []string{"status", "scale"}
+
+// validateSubresource returns an error when the subresource is not one of the subresources.
+func validateSubresource(subresource string) error {
+	if subresource != "" && !slices.Contains(subresources, subresource) {
+		return fmt.Errorf("unsupported subresource %q, must be one of %s", subresource, strings.Join(subresources, ", "))
+	}
+	return nil
+}
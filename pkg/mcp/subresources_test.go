@@ -0,0 +1,39 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import "testing"
+
+func TestValidateSubresource(t *testing.T) {
+	tests := []struct {
+		subresource string
+		valid       bool
+	}{
+		{subresource: "", valid: true},
+		{subresource: "status", valid: true},
+		{subresource: "scale", valid: true},
+		{subresource: "log"},
+		{subresource: "Status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subresource, func(t *testing.T) {
+			if err := validateSubresource(tt.subresource); (err == nil) != tt.valid {
+				t.Errorf("expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// statusSummaries return the key status fields of the objects of a kind in summary mode, as the
+// default columns of kubectl get show them.
+var statusSummaries = map[schema.GroupKind]func(obj map[string]interface{}) map[string]interface{}{
+	{Kind: "Pod"}:                        podSummary,
+	{Kind: "Node"}:                       nodeSummary,
+	{Kind: "Service"}:                    serviceSummary,
+	{Kind: "Namespace"}:                  phaseSummary,
+	{Kind: "PersistentVolumeClaim"}:      persistentVolumeClaimSummary,
+	{Group: "apps", Kind: "Deployment"}:  deploymentSummary,
+	{Group: "apps", Kind: "StatefulSet"}: statefulSetSummary,
+	{Group: "apps", Kind: "ReplicaSet"}:  replicaSetSummary,
+	{Group: "apps", Kind: "DaemonSet"}:   daemonSetSummary,
+	{Group: "batch", Kind: "Job"}:        jobSummary,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: conditionSummary,
+}
+
+// statusSummary returns the key status fields of the object: those of its kind, or its phase and
+// the status of its Ready condition for the other kinds.
+func statusSummary(obj *unstructured.Unstructured) map[string]interface{} {
+	if summarize, ok := statusSummaries[obj.GroupVersionKind().GroupKind()]; ok {
+		return summarize(obj.Object)
+	}
+	summary := phaseSummary(obj.Object)
+	for key, value := range conditionSummary(obj.Object) {
+		summary[key] = value
+	}
+	return summary
+}
+
+func nestedInt64(obj map[string]interface{}, fields ...string) int64 {
+	value, _, _ := unstructured.NestedInt64(obj, fields...)
+	return value
+}
+
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	value, _, _ := unstructured.NestedString(obj, fields...)
+	return value
+}
+
+func nestedMaps(obj map[string]interface{}, fields ...string) []map[string]interface{} {
+	values, _, _ := unstructured.NestedSlice(obj, fields...)
+	maps := make([]map[string]interface{}, 0, len(values))
+	for _, value := range values {
+		if m, ok := value.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// conditionStatus returns the status of the condition of the object, empty when it has none.
+func conditionStatus(obj map[string]interface{}, conditionType string) string {
+	for _, condition := range nestedMaps(obj, "status", "conditions") {
+		if condition["type"] == conditionType {
+			status, _ := condition["status"].(string)
+			return status
+		}
+	}
+	return ""
+}
+
+func phaseSummary(obj map[string]interface{}) map[string]interface{} {
+	summary := map[string]interface{}{}
+	if phase := nestedString(obj, "status", "phase"); phase != "" {
+		summary["status"] = phase
+	}
+	return summary
+}
+
+func conditionSummary(obj map[string]interface{}) map[string]interface{} {
+	summary := map[string]interface{}{}
+	if ready := conditionStatus(obj, "Ready"); ready != "" {
+		summary["ready"] = ready
+	} else if established := conditionStatus(obj, "Established"); established != "" {
+		summary["established"] = established
+	}
+	return summary
+}
+
+// podSummary returns the ready containers, the status and the restarts of the pod, the status
+// being the reason of its first waiting or terminated container, if any, as kubectl shows it.
+func podSummary(obj map[string]interface{}) map[string]interface{} {
+	status := nestedString(obj, "status", "phase")
+	if reason := nestedString(obj, "status", "reason"); reason != "" {
+		status = reason
+	}
+	var ready, restarts int64
+	containerReason := ""
+	for _, container := range nestedMaps(obj, "status", "containerStatuses") {
+		if isReady, _ := container["ready"].(bool); isReady {
+			ready++
+		}
+		restarts += nestedInt64(container, "restartCount")
+		if containerReason == "" {
+			containerReason = nestedString(container, "state", "waiting", "reason")
+		}
+		if containerReason == "" {
+			containerReason = nestedString(container, "state", "terminated", "reason")
+		}
+	}
+	if containerReason != "" {
+		status = containerReason
+	}
+	if nestedString(obj, "metadata", "deletionTimestamp") != "" {
+		status = "Terminating"
+	}
+	containers, _, _ := unstructured.NestedSlice(obj, "spec", "containers")
+	return map[string]interface{}{
+		"ready":    fmt.Sprintf("%d/%d", ready, len(containers)),
+		"status":   status,
+		"restarts": restarts,
+	}
+}
+
+func nodeSummary(obj map[string]interface{}) map[string]interface{} {
+	status := "Unknown"
+	switch conditionStatus(obj, "Ready") {
+	case "True":
+		status = "Ready"
+	case "False":
+		status = "NotReady"
+	}
+	if unschedulable, _, _ := unstructured.NestedBool(obj, "spec", "unschedulable"); unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	return map[string]interface{}{
+		"status":  status,
+		"version": nestedString(obj, "status", "nodeInfo", "kubeletVersion"),
+	}
+}
+
+func serviceSummary(obj map[string]interface{}) map[string]interface{} {
+	var ports []string
+	for _, port := range nestedMaps(obj, "spec", "ports") {
+		ports = append(ports, fmt.Sprintf("%d/%s", nestedInt64(port, "port"), nestedString(port, "protocol")))
+	}
+	return map[string]interface{}{
+		"type":      nestedString(obj, "spec", "type"),
+		"clusterIP": nestedString(obj, "spec", "clusterIP"),
+		"ports":     strings.Join(ports, ","),
+	}
+}
+
+func persistentVolumeClaimSummary(obj map[string]interface{}) map[string]interface{} {
+	summary := phaseSummary(obj)
+	summary["volume"] = nestedString(obj, "spec", "volumeName")
+	summary["capacity"] = nestedString(obj, "status", "capacity", "storage")
+	return summary
+}
+
+func deploymentSummary(obj map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"ready":     fmt.Sprintf("%d/%d", nestedInt64(obj, "status", "readyReplicas"), nestedInt64(obj, "spec", "replicas")),
+		"upToDate":  nestedInt64(obj, "status", "updatedReplicas"),
+		"available": nestedInt64(obj, "status", "availableReplicas"),
+	}
+}
+
+func statefulSetSummary(obj map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"ready": fmt.Sprintf("%d/%d", nestedInt64(obj, "status", "readyReplicas"), nestedInt64(obj, "spec", "replicas")),
+	}
+}
+
+func replicaSetSummary(obj map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"desired": nestedInt64(obj, "spec", "replicas"),
+		"current": nestedInt64(obj, "status", "replicas"),
+		"ready":   nestedInt64(obj, "status", "readyReplicas"),
+	}
+}
+
+func daemonSetSummary(obj map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"desired":   nestedInt64(obj, "status", "desiredNumberScheduled"),
+		"current":   nestedInt64(obj, "status", "currentNumberScheduled"),
+		"ready":     nestedInt64(obj, "status", "numberReady"),
+		"upToDate":  nestedInt64(obj, "status", "updatedNumberScheduled"),
+		"available": nestedInt64(obj, "status", "numberAvailable"),
+	}
+}
+
+func jobSummary(obj map[string]interface{}) map[string]interface{} {
+	completions, found, _ := unstructured.NestedInt64(obj, "spec", "completions")
+	if !found {
+		completions = 1
+	}
+	status := "Running"
+	switch {
+	case conditionStatus(obj, "Complete") == "True":
+		status = "Complete"
+	case conditionStatus(obj, "Failed") == "True":
+		status = "Failed"
+	case conditionStatus(obj, "Suspended") == "True":
+		status = "Suspended"
+	}
+	return map[string]interface{}{
+		"status":      status,
+		"completions": fmt.Sprintf("%d/%d", nestedInt64(obj, "status", "succeeded"), completions),
+	}
+}
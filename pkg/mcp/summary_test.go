@@ -0,0 +1,138 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestStatusSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name: "crash looping pod",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{"name": "app"}, map[string]interface{}{"name": "sidecar"}},
+				},
+				"status": map[string]interface{}{
+					"phase": "Running",
+					"containerStatuses": []interface{}{
+						map[string]interface{}{"name": "app", "ready": false, "restartCount": int64(7), "state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}}},
+						map[string]interface{}{"name": "sidecar", "ready": true, "restartCount": int64(1), "state": map[string]interface{}{"running": map[string]interface{}{}}},
+					},
+				},
+			},
+			expected: map[string]interface{}{"ready": "1/2", "status": "CrashLoopBackOff", "restarts": int64(8)},
+		},
+		{
+			name: "terminating pod",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata":   map[string]interface{}{"deletionTimestamp": "2025-03-01T10:00:00Z"},
+				"status":     map[string]interface{}{"phase": "Running"},
+			},
+			expected: map[string]interface{}{"ready": "0/0", "status": "Terminating", "restarts": int64(0)},
+		},
+		{
+			name: "deployment",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"spec":       map[string]interface{}{"replicas": int64(3)},
+				"status":     map[string]interface{}{"readyReplicas": int64(2), "updatedReplicas": int64(3), "availableReplicas": int64(2)},
+			},
+			expected: map[string]interface{}{"ready": "2/3", "upToDate": int64(3), "available": int64(2)},
+		},
+		{
+			name: "cordoned node",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Node",
+				"spec":       map[string]interface{}{"unschedulable": true},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+					"nodeInfo":   map[string]interface{}{"kubeletVersion": "v1.34.0"},
+				},
+			},
+			expected: map[string]interface{}{"status": "Ready,SchedulingDisabled", "version": "v1.34.0"},
+		},
+		{
+			name: "failed job",
+			obj: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"spec":       map[string]interface{}{"completions": int64(5)},
+				"status": map[string]interface{}{
+					"succeeded":  int64(3),
+					"conditions": []interface{}{map[string]interface{}{"type": "Failed", "status": "True"}},
+				},
+			},
+			expected: map[string]interface{}{"status": "Failed", "completions": "3/5"},
+		},
+		{
+			name: "service",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"spec": map[string]interface{}{
+					"type":      "ClusterIP",
+					"clusterIP": "10.96.0.10",
+					"ports":     []interface{}{map[string]interface{}{"port": int64(53), "protocol": "UDP"}, map[string]interface{}{"port": int64(53), "protocol": "TCP"}},
+				},
+			},
+			expected: map[string]interface{}{"type": "ClusterIP", "clusterIP": "10.96.0.10", "ports": "53/UDP,53/TCP"},
+		},
+		{
+			name: "custom resource",
+			obj: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Database",
+				"status": map[string]interface{}{
+					"phase":      "Provisioning",
+					"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False"}},
+				},
+			},
+			expected: map[string]interface{}{"status": "Provisioning", "ready": "False"},
+		},
+		{
+			name: "custom resource without status",
+			obj: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+			},
+			expected: map[string]interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := statusSummary(&unstructured.Unstructured{Object: tt.obj})
+			if !reflect.DeepEqual(summary, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, summary)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// tableAcceptHeader asks the API servers for the Table representation of the lists, with the
+// columns of their server-side printing, as kubectl get does.
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// maxTableResponseSize bounds the Table responses read from the API servers.
+const maxTableResponseSize = 64 << 20
+
+// listTable lists the resources of a namespace, or of every namespace when empty, as a Table.
+func (d *DynamicConfig) listTable(ctx context.Context, tokenInfo *auth.TokenInfo, gvr schema.GroupVersionResource, namespace string, options v1.ListOptions) (*v1.Table, error) {
+	apiServerUrl := tokenInfo.Extra["audience"].(string)
+	bearerToken := tokenInfo.Extra["bearer_token"].(string)
+	config := d.restConfig(bearerToken, apiServerUrl)
+	client, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := []string{"/api", gvr.Version}
+	if gvr.Group != "" {
+		segments = []string{"/apis", gvr.Group, gvr.Version}
+	}
+	if namespace != "" {
+		segments = append(segments, "namespaces", namespace)
+	}
+	segments = append(segments, gvr.Resource)
+	query := url.Values{"includeObject": {"Metadata"}}
+	if options.LabelSelector != "" {
+		query.Set("labelSelector", options.LabelSelector)
+	}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.FormatInt(options.Limit, 10))
+	}
+	if options.Continue != "" {
+		query.Set("continue", options.Continue)
+	}
+	tableURL := strings.TrimSuffix(config.Host, "/") + path.Join(segments...) + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tableURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", tableAcceptHeader)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTableResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var status v1.Status
+		if err := json.Unmarshal(body, &status); err == nil && status.Kind == "Status" {
+			return nil, &apierrors.StatusError{ErrStatus: status}
+		}
+		return nil, fmt.Errorf("API server returned %s", resp.Status)
+	}
+	var table v1.Table
+	if err := json.Unmarshal(body, &table); err != nil {
+		return nil, fmt.Errorf("invalid Table returned by the API server: %w", err)
+	}
+	if table.Kind != "Table" {
+		return nil, fmt.Errorf("the API server does not print %s as a Table, use the summary output mode instead", gvr.Resource)
+	}
+	return &table, nil
+}
+
+// tableRecords returns one record per row of the Table, holding the name and namespace of its object
+// and its cells keyed by their column. The columns kubectl only shows with -o wide are left out.
+func tableRecords(table *v1.Table) []map[string]interface{} {
+	records := make([]map[string]interface{}, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		record := map[string]interface{}{}
+		var metadata v1.PartialObjectMetadata
+		if len(row.Object.Raw) > 0 && json.Unmarshal(row.Object.Raw, &metadata) == nil {
+			record["name"] = metadata.Name
+			if metadata.Namespace != "" {
+				record["namespace"] = metadata.Namespace
+			}
+		}
+		for i, column := range table.ColumnDefinitions {
+			if column.Priority > 0 || i >= len(row.Cells) {
+				continue
+			}
+			if strings.EqualFold(column.Name, "name") {
+				if _, ok := record["name"]; !ok {
+					record["name"] = row.Cells[i]
+				}
+				continue
+			}
+			record[column.Name] = row.Cells[i]
+		}
+		records = append(records, record)
+	}
+	return records
+}
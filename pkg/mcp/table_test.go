@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const podTable = `{
+  "kind": "Table",
+  "apiVersion": "meta.k8s.io/v1",
+  "metadata": {"continue": "next"},
+  "columnDefinitions": [
+    {"name": "Name", "type": "string", "priority": 0},
+    {"name": "Ready", "type": "string", "priority": 0},
+    {"name": "Restarts", "type": "integer", "priority": 0},
+    {"name": "Node", "type": "string", "priority": 1}
+  ],
+  "rows": [
+    {
+      "cells": ["web-0", "1/1", 2, "worker-1"],
+      "object": {"kind": "PartialObjectMetadata", "apiVersion": "meta.k8s.io/v1", "metadata": {"name": "web-0", "namespace": "shop"}}
+    }
+  ]
+}`
+
+func TestListTable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Query().Get("continue") == "expired":
+			w.WriteHeader(http.StatusGone)
+			//nolint:errcheck
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Expired","code":410}`))
+		case r.URL.Path != "/api/v1/namespaces/shop/pods" || r.Header.Get("Accept") != tableAcceptHeader ||
+			r.URL.Query().Get("labelSelector") != "app=web" || r.URL.Query().Get("limit") != "10":
+			http.NotFound(w, r)
+		default:
+			//nolint:errcheck
+			w.Write([]byte(podTable))
+		}
+	}))
+	defer server.Close()
+
+	d := NewDynamicConfig("", false, "")
+	tokenInfo := &auth.TokenInfo{Extra: map[string]any{"audience": server.URL, "bearer_token": "token"}}
+	pods := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+	table, err := d.listTable(context.TODO(), tokenInfo, pods, "shop", v1.ListOptions{LabelSelector: "app=web", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Continue != "next" {
+		t.Errorf("expected the continue token of the table, got %q", table.Continue)
+	}
+	expected := []map[string]interface{}{{"name": "web-0", "namespace": "shop", "Ready": "1/1", "Restarts": float64(2)}}
+	if records := tableRecords(table); !reflect.DeepEqual(records, expected) {
+		t.Errorf("expected %v, got %v", expected, records)
+	}
+
+	_, err = d.listTable(context.TODO(), tokenInfo, pods, "shop", v1.ListOptions{Continue: "expired"})
+	if !apierrors.IsResourceExpired(err) {
+		t.Errorf("expected the API error to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,389 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+// fieldManager is the field manager of the changes made by k-mcp.
+const fieldManager = "k-mcp"
+
+// maxFieldManagerLength is the maximum length of the field managers accepted by the API server.
+const maxFieldManagerLength = 128
+
+// validateFieldManager validates a field manager of resource_apply as the API server does.
+func validateFieldManager(manager string) error {
+	if len(manager) > maxFieldManagerLength {
+		return fmt.Errorf("fieldManager must not be longer than %d characters", maxFieldManagerLength)
+	}
+	for _, r := range manager {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("fieldManager %q must only contain printable characters", manager)
+		}
+	}
+	return nil
+}
+
+type TakeOwnershipInput struct {
+	Resource       string   `json:"resource,required" jsonschema:"The Kubernetes resource type (e.g. deployments configmaps)"`
+	Name           string   `json:"name,required" jsonschema:"The name of the resource"`
+	Namespace      string   `json:"namespace,omitempty" jsonschema:"The namespace of the resource (required for namespaced resources)"`
+	FieldPaths     []string `json:"fieldPaths,required" jsonschema:"The dot separated paths of the fields to take ownership of (e.g. spec.replicas). Use brackets for keys containing dots (e.g. metadata.labels['app.kubernetes.io/name']). Lists are taken as a whole"`
+	ReadAfterWrite bool     `json:"readAfterWrite,omitempty" jsonschema:"Re-read the resource after taking the ownership and return its fresh state instead of the apply response"`
+}
+
+type TakeOwnershipResult struct {
+	Fields             []FieldOwnership       `json:"fields"`
+	Resource           map[string]interface{} `json:"resource,omitempty"`
+	CancellationReason string                 `json:"cancellationReason,omitempty"`
+	// APIServerURL is the API server of the resource.
+	APIServerURL string `json:"apiServerUrl,omitempty"`
+}
+
+// FieldOwnership lists the managers of a field before its ownership was taken.
+type FieldOwnership struct {
+	Path             string   `json:"path"`
+	PreviousManagers []string `json:"previousManagers"`
+}
+
+func (s *Server) addTakeOwnershipTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "take_ownership",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(true),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    false,
+			Title:           "Take ownership of fields of a Kubernetes resource",
+		},
+		Description:  "Transfer the ownership of specific fields of a resource from their current field managers (e.g. kubectl-edit) to k-mcp using a forced server-side apply, keeping their values. Resolves recurring apply conflicts. The current managers are shown for confirmation first",
+		OutputSchema: outputSchema[TakeOwnershipResult]("resource"),
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input TakeOwnershipInput) (*mcp.CallToolResult, *TakeOwnershipResult, error) {
+		if len(input.FieldPaths) == 0 {
+			return nil, nil, fmt.Errorf("at least one field path is required")
+		}
+		paths := make([][]string, 0, len(input.FieldPaths))
+		for _, fieldPath := range input.FieldPaths {
+			path, err := parseFieldPath(fieldPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			if path[0] == "apiVersion" || path[0] == "kind" || path[0] == "status" ||
+				(path[0] == "metadata" && (len(path) == 1 || (path[1] != "labels" && path[1] != "annotations"))) {
+				return nil, nil, fmt.Errorf("ownership of %s can not be taken", fieldPath)
+			}
+			paths = append(paths, path)
+		}
+
+		dynamicClient, discoveryClient, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		gvr, isNamespaced, err := FindResource(ctx, input.Resource, discoveryClient, request.Session)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find resource: %w", err)
+		}
+		if isNamespaced && input.Namespace == "" {
+			input.Namespace, err = s.sessionNamespace(ctx, request, input.Resource)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if !isNamespaced {
+			input.Namespace = ""
+		}
+		resource := dynamicClient.Resource(gvr).Namespace(input.Namespace)
+
+		obj, err := resource.Get(ctx, input.Name, v1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get resource: %w", err)
+		}
+
+		patch, err := ownershipPatch(obj, paths)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fields := make([]FieldOwnership, 0, len(paths))
+		var summaries []string
+		for i, path := range paths {
+			managers := fieldManagers(obj.GetManagedFields(), path)
+			fields = append(fields, FieldOwnership{Path: input.FieldPaths[i], PreviousManagers: managers})
+			if len(managers) == 0 {
+				managers = []string{"<none>"}
+			}
+			summaries = append(summaries, fmt.Sprintf("- %s: %s", input.FieldPaths[i], strings.Join(managers, ", ")))
+		}
+
+		elicitResult, err := request.Session.Elicit(ctx, &mcp.ElicitParams{
+			Message: fmt.Sprintf("k-mcp will take the ownership of the following fields of %s/%s from their current managers:\n\n%s\n\nDo you want to proceed?",
+				obj.GetKind(), obj.GetName(), strings.Join(summaries, "\n")),
+			RequestedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"confirm": {
+						Type:        "boolean",
+						Description: "Confirm whether to take the ownership of the fields",
+					},
+				},
+				Required: []string{"confirm"},
+			},
+		})
+		cancelled := func(reason string) (*mcp.CallToolResult, *TakeOwnershipResult, error) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: reason,
+					},
+				},
+			}, &TakeOwnershipResult{Fields: fields, CancellationReason: reason}, nil
+		}
+		if errors.Is(err, ErrElicitationTimeout) || errors.Is(err, ErrElicitationUnsupported) {
+			return cancelled(fmt.Sprintf("Operation cancelled - %v", err))
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to elicit user confirmation: %w", err)
+		}
+		if elicitResult.Action != "accept" {
+			return cancelled("Operation cancelled by user")
+		}
+		if confirm, ok := elicitResult.Content["confirm"].(bool); !ok || !confirm {
+			return cancelled("Operation cancelled - user did not confirm")
+		}
+
+		result, err := resource.Apply(ctx, obj.GetName(), patch, v1.ApplyOptions{FieldManager: fieldManager, Force: true})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to take ownership: %w", err)
+		}
+		message := fmt.Sprintf("k-mcp took the ownership of %s of %s/%s", strings.Join(input.FieldPaths, ", "), obj.GetKind(), obj.GetName())
+		if input.ReadAfterWrite {
+			if current, err := readAfterWrite(ctx, resource, result); err == nil {
+				result = current
+			} else {
+				message += fmt.Sprintf(". Failed to re-read, returning the apply response instead: %v", err)
+			}
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, &TakeOwnershipResult{Fields: fields, Resource: result.Object, APIServerURL: requestAPIServerURL(request)}, nil
+	})
+}
+
+// parseFieldPath splits a dot separated field path. Segments containing dots are written in brackets,
+// e.g. metadata.labels['app.kubernetes.io/name'].
+func parseFieldPath(fieldPath string) ([]string, error) {
+	var path []string
+	rest := fieldPath
+	for rest != "" {
+		var segment string
+		switch {
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid field path %q: unterminated bracket", fieldPath)
+			}
+			segment, rest = rest[2:end], rest[end+2:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segment, rest = rest[:end], rest[end:]
+		}
+		if segment == "" {
+			return nil, fmt.Errorf("invalid field path %q: empty segment", fieldPath)
+		}
+		path = append(path, segment)
+		rest = strings.TrimPrefix(rest, ".")
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("invalid field path %q", fieldPath)
+	}
+	return path, nil
+}
+
+// ownershipPatch returns the apply patch taking the ownership of the fields at the paths while
+// keeping their values. Fields absent from an apply patch are released by the field manager,
+// and removed when nobody else owns them, so the fields owned by k-mcp are kept in the patch.
+func ownershipPatch(obj *unstructured.Unstructured, paths [][]string) (*unstructured.Unstructured, error) {
+	patch := map[string]interface{}{}
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager != fieldManager || entry.Operation != v1.ManagedFieldsOperationApply || entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil, fmt.Errorf("failed to parse the managed fields of %s: %w", fieldManager, err)
+		}
+		if owned, ok := extractOwnedFields(obj.Object, fields).(map[string]interface{}); ok {
+			patch = owned
+		}
+	}
+
+	for _, path := range paths {
+		value, found, err := unstructured.NestedFieldNoCopy(obj.Object, path...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", strings.Join(path, "."), err)
+		}
+		if !found {
+			return nil, fmt.Errorf("field %s is not set", strings.Join(path, "."))
+		}
+		if err := unstructured.SetNestedField(patch, runtime.DeepCopyJSONValue(value), path...); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", strings.Join(path, "."), err)
+		}
+	}
+
+	result := &unstructured.Unstructured{Object: patch}
+	result.SetAPIVersion(obj.GetAPIVersion())
+	result.SetKind(obj.GetKind())
+	result.SetName(obj.GetName())
+	result.SetNamespace(obj.GetNamespace())
+	return result, nil
+}
+
+// extractOwnedFields returns the parts of the value described by the FieldsV1 set, whose keys are
+// fields (f:name), keyed list items (k:{"name":"app"}), set values (v:"value") and list indexes (i:0).
+func extractOwnedFields(value interface{}, fields map[string]interface{}) interface{} {
+	if len(fields) == 0 {
+		return runtime.DeepCopyJSONValue(value)
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, sub := range fields {
+			name, ok := strings.CutPrefix(key, "f:")
+			if !ok {
+				continue
+			}
+			child, ok := typed[name]
+			if !ok {
+				continue
+			}
+			subFields, _ := sub.(map[string]interface{})
+			result[name] = extractOwnedFields(child, subFields)
+		}
+		return result
+	case []interface{}:
+		result := []interface{}{}
+		for i, item := range typed {
+			for key, sub := range fields {
+				subFields, _ := sub.(map[string]interface{})
+				if listItemMatches(key, i, item) {
+					extracted := extractOwnedFields(item, subFields)
+					// The keys of a list item identify it and must always be applied.
+					if keyed, ok := strings.CutPrefix(key, "k:"); ok {
+						var keys map[string]interface{}
+						if err := json.Unmarshal([]byte(keyed), &keys); err == nil {
+							if extractedMap, ok := extracted.(map[string]interface{}); ok {
+								for k, v := range keys {
+									extractedMap[k] = v
+								}
+							}
+						}
+					}
+					result = append(result, extracted)
+					break
+				}
+			}
+		}
+		return result
+	default:
+		return runtime.DeepCopyJSONValue(value)
+	}
+}
+
+func listItemMatches(key string, index int, item interface{}) bool {
+	switch {
+	case strings.HasPrefix(key, "k:"):
+		var keys map[string]interface{}
+		if err := json.Unmarshal([]byte(key[2:]), &keys); err != nil {
+			return false
+		}
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range keys {
+			if fmt.Sprint(itemMap[k]) != fmt.Sprint(v) {
+				return false
+			}
+		}
+		return true
+	case strings.HasPrefix(key, "v:"):
+		var value interface{}
+		if err := json.Unmarshal([]byte(key[2:]), &value); err != nil {
+			return false
+		}
+		return reflect.DeepEqual(runtime.DeepCopyJSONValue(item), runtime.DeepCopyJSONValue(value))
+	case strings.HasPrefix(key, "i:"):
+		return key[2:] == fmt.Sprint(index)
+	}
+	return false
+}
+
+// fieldManagers returns the managers owning the field at the path or fields below it.
+func fieldManagers(entries []v1.ManagedFieldsEntry, path []string) []string {
+	managers := []string{}
+	for _, entry := range entries {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		owned := true
+		for _, segment := range path {
+			sub, ok := fields["f:"+segment].(map[string]interface{})
+			if !ok {
+				owned = false
+				break
+			}
+			fields = sub
+		}
+		if !owned {
+			continue
+		}
+		manager := fmt.Sprintf("%s (%s)", entry.Manager, entry.Operation)
+		if entry.Subresource != "" {
+			manager = fmt.Sprintf("%s (%s, %s subresource)", entry.Manager, entry.Operation, entry.Subresource)
+		}
+		managers = append(managers, manager)
+	}
+	return managers
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "spec.replicas", expected: []string{"spec", "replicas"}},
+		{path: "metadata.labels['app.kubernetes.io/name']", expected: []string{"metadata", "labels", "app.kubernetes.io/name"}},
+		{path: "metadata.annotations['example.com/owner'].x", expected: []string{"metadata", "annotations", "example.com/owner", "x"}},
+		{path: "spec..replicas"},
+		{path: "metadata.labels['app"},
+		{path: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := parseFieldPath(tt.path)
+			if tt.expected == nil {
+				if err == nil {
+					t.Errorf("expected error, got %v", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(path, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, path)
+			}
+		})
+	}
+}
+
+func ownershipTestDeployment() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "web",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app": "web", "team": "payments"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(5),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "web:2", "args": []interface{}{"--port=8080"}},
+						map[string]interface{}{"name": "proxy", "image": "envoy:1.31"},
+					},
+				},
+			},
+		},
+	}}
+	obj.SetManagedFields([]v1.ManagedFieldsEntry{
+		{
+			Manager:   fieldManager,
+			Operation: v1.ManagedFieldsOperationApply,
+			FieldsV1: &v1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:app":{}}},"f:spec":{"f:template":{"f:spec":{"f:containers":{` +
+				`"k:{\"name\":\"app\"}":{".":{},"f:image":{},"f:name":{}}}}}}}`)},
+		},
+		{
+			Manager:   "kubectl-edit",
+			Operation: v1.ManagedFieldsOperationUpdate,
+			FieldsV1:  &v1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:team":{}}},"f:spec":{"f:replicas":{}}}`)},
+		},
+		{
+			Manager:     "kube-controller-manager",
+			Operation:   v1.ManagedFieldsOperationUpdate,
+			Subresource: "status",
+			FieldsV1:    &v1.FieldsV1{Raw: []byte(`{"f:status":{"f:replicas":{}}}`)},
+		},
+	})
+	return obj
+}
+
+func TestOwnershipPatch(t *testing.T) {
+	patch, err := ownershipPatch(ownershipTestDeployment(), [][]string{{"spec", "replicas"}, {"metadata", "labels", "team"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "web",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app": "web", "team": "payments"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(5),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "web:2"},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(patch.Object, expected) {
+		t.Errorf("expected %v, got %v", expected, patch.Object)
+	}
+
+	if _, err := ownershipPatch(ownershipTestDeployment(), [][]string{{"spec", "paused"}}); err == nil {
+		t.Errorf("expected error for a field that is not set")
+	}
+}
+
+func TestFieldManagers(t *testing.T) {
+	entries := ownershipTestDeployment().GetManagedFields()
+	tests := []struct {
+		path     []string
+		expected []string
+	}{
+		{path: []string{"spec", "replicas"}, expected: []string{"kubectl-edit (Update)"}},
+		{path: []string{"metadata", "labels"}, expected: []string{"k-mcp (Apply)", "kubectl-edit (Update)"}},
+		{path: []string{"status"}, expected: []string{"kube-controller-manager (Update, status subresource)"}},
+		{path: []string{"spec", "paused"}, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		if managers := fieldManagers(entries, tt.path); !reflect.DeepEqual(managers, tt.expected) {
+			t.Errorf("%v: expected %v, got %v", tt.path, tt.expected, managers)
+		}
+	}
+}
+
+func TestValidateFieldManager(t *testing.T) {
+	tests := []struct {
+		manager string
+		valid   bool
+	}{
+		{manager: "k-mcp", valid: true},
+		{manager: "k-mcp/release-agent", valid: true},
+		{manager: strings.Repeat("a", maxFieldManagerLength), valid: true},
+		{manager: strings.Repeat("a", maxFieldManagerLength+1)},
+		{manager: "agent\n"},
+	}
+	for _, tt := range tests {
+		if err := validateFieldManager(tt.manager); (err == nil) != tt.valid {
+			t.Errorf("expected %q to be valid %v, got %v", tt.manager, tt.valid, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// textNone is the cell of the tables without value.
+const textNone = "<none>"
+
+// textTable renders rows as a plain-text table for the text content of the tool results: columns
+// are aligned with spaces, without colors or other terminal escapes, and rows are sorted, so that
+// the same result is always rendered the same way whatever the client.
+type textTable struct {
+	headers []string
+	rows    [][]string
+}
+
+func newTextTable(headers ...string) *textTable {
+	return &textTable{headers: headers}
+}
+
+// addRow adds a row, with one cell per header. Empty cells are rendered as <none>, and line breaks
+// and tabs are replaced with spaces to keep one line per row.
+func (t *textTable) addRow(cells ...string) {
+	row := make([]string, len(t.headers))
+	for i := range row {
+		cell := ""
+		if i < len(cells) {
+			cell = strings.Join(strings.Fields(cells[i]), " ")
+		}
+		if cell == "" {
+			cell = textNone
+		}
+		row[i] = cell
+	}
+	t.rows = append(t.rows, row)
+}
+
+func (t *textTable) String() string {
+	rows := slices.Clone(t.rows)
+	slices.SortStableFunc(rows, slices.Compare)
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	//nolint:errcheck
+	w.Write([]byte(strings.Join(t.headers, "\t") + "\n"))
+	for _, row := range rows {
+		//nolint:errcheck
+		w.Write([]byte(strings.Join(row, "\t") + "\n"))
+	}
+	//nolint:errcheck
+	w.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textList renders items as a bulleted list, one item per line, in the given order.
+func textList(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return "- " + strings.Join(items, "\n- ")
+}
+
+// textSummary renders the text content of a tool result: a summary sentence followed by the
+// sections detailing it, separated by blank lines. Empty sections are skipped.
+func textSummary(summary string, sections ...string) string {
+	parts := []string{summary}
+	for _, section := range sections {
+		if section != "" {
+			parts = append(parts, section)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
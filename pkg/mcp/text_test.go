@@ -0,0 +1,72 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import "testing"
+
+func TestTextTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     [][]string
+		expected string
+	}{
+		{
+			name:     "no rows",
+			expected: "NAMESPACE  NAME  STATUS",
+		},
+		{
+			name: "aligned and sorted",
+			rows: [][]string{{"shop", "web", "Ready"}, {"default", "api-server", "Pending"}, {"shop", "cart", "Ready"}},
+			expected: `NAMESPACE  NAME        STATUS
+default    api-server  Pending
+shop       cart        Ready
+shop       web         Ready`,
+		},
+		{
+			name: "empty and multi-line cells",
+			rows: [][]string{{"", "web", "back-off\nrestarting\tfailed container"}, {"shop"}},
+			expected: `NAMESPACE  NAME    STATUS
+<none>     web     back-off restarting failed container
+shop       <none>  <none>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTextTable("NAMESPACE", "NAME", "STATUS")
+			for _, row := range tt.rows {
+				table.addRow(row...)
+			}
+			if got := table.String(); got != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+			// Rendering does not reorder the rows of the table.
+			if got := table.String(); got != tt.expected {
+				t.Errorf("expected the same rendering twice, got:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestTextSummary(t *testing.T) {
+	if got := textSummary("Found 2 problems:", textList([]string{"b", "a"})); got != "Found 2 problems:\n\n- b\n- a" {
+		t.Errorf("unexpected summary %q", got)
+	}
+	if got := textSummary("No problems", textList(nil), ""); got != "No problems" {
+		t.Errorf("expected empty sections to be skipped, got %q", got)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+)
+
+const (
+	// tokenReviewCacheTTL is how long a reviewed token is trusted before being reviewed again,
+	// so that revoked tokens are refused shortly after.
+	tokenReviewCacheTTL = time.Minute
+	// maxTokenReviewCacheSize bounds the number of cached reviews.
+	maxTokenReviewCacheSize = 1024
+)
+
+// tokenReviewer validates the tokens with the TokenReview API of the API server of their audience, so
+// that the API server authoritatively decides whether they are valid, bound objects and external issuers
+// included. The review is created with the token itself, which must be allowed to create TokenReviews.
+type tokenReviewer struct {
+	dynamicConfig *DynamicConfig
+	audience      string
+	// review is replaced in tests.
+	review func(ctx context.Context, apiServerURL, token string, audiences []string) (*authenticationv1.TokenReviewStatus, error)
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]reviewedToken
+}
+
+// reviewedToken is a cached successful review.
+type reviewedToken struct {
+	username string
+	expiry   time.Time
+}
+
+func newTokenReviewer(dynamicConfig *DynamicConfig, audience string) *tokenReviewer {
+	r := &tokenReviewer{
+		dynamicConfig: dynamicConfig,
+		audience:      audience,
+		cache:         make(map[[sha256.Size]byte]reviewedToken),
+	}
+	r.review = r.createTokenReview
+	return r
+}
+
+func (r *tokenReviewer) createTokenReview(ctx context.Context, apiServerURL, token string, audiences []string) (*authenticationv1.TokenReviewStatus, error) {
+	client, err := authenticationv1client.NewForConfig(r.dynamicConfig.restConfig(token, apiServerURL))
+	if err != nil {
+		return nil, err
+	}
+	review, err := client.TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: audiences},
+	}, v1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &review.Status, nil
+}
+
+// verify reviews the token with the API server, and returns the username it authenticates. The token
+// must be valid for the audience of k-mcp.
+func (r *tokenReviewer) verify(ctx context.Context, apiServerURL, token string, expiration time.Time) (string, error) {
+	key := sha256.Sum256([]byte(apiServerURL + "\x00" + token))
+
+	r.mu.Lock()
+	cached, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok && time.Now().Before(cached.expiry) {
+		return cached.username, nil
+	}
+
+	status, err := r.review(ctx, apiServerURL, token, []string{r.audience})
+	if err != nil {
+		return "", fmt.Errorf("token review failed: %w", err)
+	}
+	if !status.Authenticated {
+		if status.Error != "" {
+			return "", fmt.Errorf("token review refused the token: %s", status.Error)
+		}
+		return "", fmt.Errorf("token review refused the token")
+	}
+	// An API server ignoring the audiences of the review, or authenticating the token for its own
+	// audiences only, would accept tokens that are not meant for k-mcp.
+	if !slices.Contains(status.Audiences, r.audience) {
+		return "", fmt.Errorf("token review did not authenticate the token for the audience %s", r.audience)
+	}
+
+	expiry := time.Now().Add(tokenReviewCacheTTL)
+	if expiration.Before(expiry) {
+		expiry = expiration
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.cache) >= maxTokenReviewCacheSize {
+		r.evictExpired()
+	}
+	if len(r.cache) < maxTokenReviewCacheSize {
+		r.cache[key] = reviewedToken{username: status.User.Username, expiry: expiry}
+	}
+	return status.User.Username, nil
+}
+
+// evictExpired drops the expired reviews. The caller must hold the lock.
+func (r *tokenReviewer) evictExpired() {
+	now := time.Now()
+	for key, cached := range r.cache {
+		if !now.Before(cached.expiry) {
+			delete(r.cache, key)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+func TestTokenReviewer(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     *authenticationv1.TokenReviewStatus
+		err        error
+		expiration time.Duration
+		expected   string
+		wantErr    bool
+		reviews    int
+	}{
+		{
+			name:       "authenticated",
+			status:     &authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "oidc:alice"}, Audiences: []string{"k-mcp"}},
+			expiration: time.Hour,
+			expected:   "oidc:alice",
+			reviews:    1,
+		},
+		{
+			name:       "expiring token reviewed again",
+			status:     &authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "oidc:alice"}, Audiences: []string{"k-mcp"}},
+			expiration: -time.Second,
+			expected:   "oidc:alice",
+			reviews:    2,
+		},
+		{
+			name:       "authenticated without audiences",
+			status:     &authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "oidc:alice"}},
+			expiration: time.Hour,
+			wantErr:    true,
+			reviews:    2,
+		},
+		{
+			name:       "authenticated for other audiences",
+			status:     &authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "oidc:alice"}, Audiences: []string{"https://kubernetes.default.svc"}},
+			expiration: time.Hour,
+			wantErr:    true,
+			reviews:    2,
+		},
+		{
+			name:       "refused",
+			status:     &authenticationv1.TokenReviewStatus{Error: "token has been invalidated"},
+			expiration: time.Hour,
+			wantErr:    true,
+			reviews:    2,
+		},
+		{
+			name:       "review failed",
+			err:        fmt.Errorf("tokenreviews.authentication.k8s.io is forbidden"),
+			expiration: time.Hour,
+			wantErr:    true,
+			reviews:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reviewer := newTokenReviewer(nil, "k-mcp")
+			reviews := 0
+			reviewer.review = func(ctx context.Context, apiServerURL, token string, audiences []string) (*authenticationv1.TokenReviewStatus, error) {
+				reviews++
+				if !slices.Equal(audiences, []string{"k-mcp"}) {
+					t.Errorf("expected the audience of k-mcp to be reviewed, got %v", audiences)
+				}
+				return tt.status, tt.err
+			}
+
+			// Successful reviews are cached until the token expires.
+			for range 2 {
+				username, err := reviewer.verify(context.Background(), "https://cluster.example.com", "token", time.Now().Add(tt.expiration))
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				if username != tt.expected {
+					t.Errorf("expected username %q, got %q", tt.expected, username)
+				}
+			}
+			if reviews != tt.reviews {
+				t.Errorf("expected %d reviews, got %d", tt.reviews, reviews)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// toolsets are the named groups of tools operators can enable.
+var toolsets = map[string][]string{
+	"core":        {"resource_list", "resource_get", "resource_apply", "crd_list", "cluster_info", "list_clusters", "manifest_complete", "set_context", "get_preferences", "set_preferences", "operation_status", "operation_cancel", "kubectl_translate"},
+	"diagnostics": {"pod_diagnose", "workload_health", "resource_conditions", "namespace_quotas", "pdb_check", "resource_utilization", "find_orphans", "conformance_check", "resource_graph"},
+	"queries":     {"save_query", "run_query", "schedule_query"},
+	"inventory":   {"inventory_export"},
+	"ownership":   {"take_ownership"},
+	"eviction":    {"pod_evict"},
+}
+
+// mutatingTools are the tools changing the state of the clusters, disabled in read-only mode.
+var mutatingTools = []string{"resource_apply", "take_ownership", "pod_evict"}
+
+// ToolsetNames returns the names of the toolsets, sorted.
+func ToolsetNames() []string {
+	names := make([]string, 0, len(toolsets))
+	for name := range toolsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateToolsets rejects unknown toolset names.
+func ValidateToolsets(names []string) error {
+	for _, name := range names {
+		if _, ok := toolsets[name]; !ok {
+			return fmt.Errorf("unknown toolset %q, must be one of: %s", name, strings.Join(ToolsetNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// disabledTools returns the tools that are not registered, the tools of the toolsets that are
+// not enabled (every toolset is enabled when none is given) and, in read-only mode, the mutating tools.
+func disabledTools(enabledToolsets []string, readOnly bool) []string {
+	var disabled []string
+	for name, tools := range toolsets {
+		if len(enabledToolsets) > 0 && !slices.Contains(enabledToolsets, name) {
+			disabled = append(disabled, tools...)
+		}
+	}
+	if readOnly {
+		disabled = append(disabled, mutatingTools...)
+	}
+	sort.Strings(disabled)
+	return slices.Compact(disabled)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestDisabledTools(t *testing.T) {
+	tests := []struct {
+		name     string
+		toolsets []string
+		readOnly bool
+		expected []string
+	}{
+		{name: "every toolset"},
+		{name: "read-only", readOnly: true, expected: []string{"pod_evict", "resource_apply", "take_ownership"}},
+		{
+			name:     "core and queries",
+			toolsets: []string{"core", "queries"},
+			expected: []string{"conformance_check", "find_orphans", "inventory_export", "namespace_quotas", "pdb_check", "pod_diagnose",
+				"pod_evict", "resource_conditions", "resource_graph", "resource_utilization", "take_ownership", "workload_health"},
+		},
+		{
+			name:     "read-only diagnostics",
+			toolsets: []string{"diagnostics"},
+			readOnly: true,
+			expected: []string{"cluster_info", "crd_list", "get_preferences", "inventory_export", "kubectl_translate", "list_clusters", "manifest_complete", "operation_cancel", "operation_status",
+				"pod_evict", "resource_apply", "resource_get", "resource_list", "run_query", "save_query", "schedule_query", "set_context", "set_preferences", "take_ownership"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if disabled := disabledTools(tt.toolsets, tt.readOnly); !reflect.DeepEqual(disabled, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, disabled)
+			}
+		})
+	}
+}
+
+func TestToolsets(t *testing.T) {
+	if err := ValidateToolsets([]string{"core", "diagnostics"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateToolsets([]string{"core", "admin"}); err == nil {
+		t.Errorf("expected error for unknown toolset")
+	}
+
+	seen := map[string]string{}
+	for name, tools := range toolsets {
+		for _, tool := range tools {
+			if other, ok := seen[tool]; ok {
+				t.Errorf("tool %s is in toolsets %s and %s", tool, other, name)
+			}
+			seen[tool] = name
+		}
+	}
+	for _, tool := range mutatingTools {
+		if _, ok := seen[tool]; !ok {
+			t.Errorf("mutating tool %s is in no toolset", tool)
+		}
+	}
+	if !slices.IsSorted(ToolsetNames()) {
+		t.Errorf("expected sorted toolset names, got %v", ToolsetNames())
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/ardaguclu/k-mcp/pkg/version"
+)
+
+// tracerName is the name of the tracer of the spans of k-mcp.
+const tracerName = "github.com/ardaguclu/k-mcp"
+
+const (
+	attributeMethod    = attribute.Key("mcp.method.name")
+	attributeSessionID = attribute.Key("mcp.session.id")
+	attributeToolName  = attribute.Key("mcp.tool.name")
+	attributeCluster   = attribute.Key("k8s.cluster")
+	attributeResource  = attribute.Key("k8s.resource")
+)
+
+// Tracing configures the export of the spans of the MCP methods and of the requests sent to the API
+// servers, with OTLP over HTTP.
+type Tracing struct {
+	// Endpoint is the URL of the OTLP collector (e.g. http://localhost:4318). Empty disables tracing.
+	Endpoint string
+	// Headers are added to the export requests, e.g. to authenticate to the collector.
+	Headers map[string]string
+	// SamplingRatio is the fraction of the traces sampled, unless the caller of the method already
+	// decided whether its trace is sampled.
+	SamplingRatio float64
+}
+
+// Enabled returns whether the spans are exported.
+func (t Tracing) Enabled() bool {
+	return t.Endpoint != ""
+}
+
+// setupTracing installs the global tracer provider exporting the spans to the collector, and the
+// W3C trace context propagation. It returns the function flushing the pending spans on shutdown.
+func setupTracing(ctx context.Context, t Tracing) (func(context.Context) error, error) {
+	if !t.Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(t.Endpoint)}
+	if len(t.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(t.Headers))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", "k-mcp"),
+		attribute.String("service.version", version.Get().Version),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.SamplingRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// tracingMiddleware starts a span for every MCP method, recording the session, the tool and the
+// cluster of the call. The trace of the caller is continued when it is propagated in the _meta of
+// the request, as traceparent.
+func tracingMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if params := req.GetParams(); params != nil {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, metaCarrier(params.GetMeta()))
+		}
+
+		name := method
+		attributes := []attribute.KeyValue{attributeMethod.String(method)}
+		if session := req.GetSession(); session != nil {
+			attributes = append(attributes, attributeSessionID.String(session.ID()))
+		}
+		if call, ok := req.(*mcp.CallToolRequest); ok {
+			name = method + " " + call.Params.Name
+			attributes = append(attributes, attributeToolName.String(call.Params.Name))
+		}
+		if extra := req.GetExtra(); extra != nil && extra.TokenInfo != nil {
+			attributes = append(attributes, attributeCluster.String(clusterName(extra.TokenInfo)))
+		}
+
+		ctx, span := otel.Tracer(tracerName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attributes...))
+		defer span.End()
+
+		result, err := next(ctx, method, req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if toolResult, ok := result.(*mcp.CallToolResult); ok && toolResult.IsError {
+			span.SetStatus(codes.Error, "tool call failed")
+		}
+		return result, err
+	}
+}
+
+// traceResource records the resource found for the call on its span.
+func traceResource(ctx context.Context, gvr schema.GroupVersionResource) {
+	trace.SpanFromContext(ctx).SetAttributes(attributeResource.String(gvr.String()))
+}
+
+// tracingRoundTripper starts a span for every request sent to the API server, child of the span of
+// the MCP method, and propagates the trace to the API server. It does nothing unless tracing is set up.
+func tracingRoundTripper(apiServerUrl string) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return otelhttp.NewTransport(rt,
+			otelhttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
+				return req.Method + " " + req.URL.Path
+			}),
+			otelhttp.WithSpanOptions(trace.WithAttributes(attributeCluster.String(clusterHost(apiServerUrl)))),
+		)
+	}
+}
+
+// metaCarrier reads the propagated trace context from the _meta of the MCP requests.
+type metaCarrier map[string]any
+
+func (c metaCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+func (c metaCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c metaCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+// recordSpans installs a tracer provider recording the spans until the test finishes.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := recordSpans(t)
+	ctx := context.Background()
+
+	dc := cmdtesting.NewFakeCachedDiscoveryClient()
+	dc.PreferredResources = []*v1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []v1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			},
+		},
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(tracingMiddleware)
+	mcp.AddTool(server, &mcp.Tool{Name: "find"}, func(ctx context.Context, request *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		_, _, err := FindResource(ctx, "deploy", dc, nil)
+		return &mcp.CallToolResult{}, nil, err
+	})
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	traceparent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	if _, err := clientSession.CallTool(ctx, &mcp.CallToolParams{
+		Meta: mcp.Meta{"traceparent": traceparent},
+		Name: "find",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var call sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "tools/call find" {
+			call = span
+		}
+	}
+	if call == nil {
+		t.Fatalf("expected a span of the tool call, got %d spans", len(recorder.Ended()))
+	}
+	if call.SpanContext().TraceID().String() != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("expected the trace of the caller to be continued, got trace %s", call.SpanContext().TraceID())
+	}
+	if tool := spanAttribute(call, attributeToolName); tool != "find" {
+		t.Errorf("expected tool find, got %q", tool)
+	}
+	if sessionID := spanAttribute(call, attributeSessionID); sessionID != serverSession.ID() {
+		t.Errorf("expected session %q, got %q", serverSession.ID(), sessionID)
+	}
+	if resource := spanAttribute(call, attributeResource); resource != "apps/v1, Resource=deployments" {
+		t.Errorf("expected the found resource, got %q", resource)
+	}
+}
+
+func TestTracingRoundTripper(t *testing.T) {
+	recorder := recordSpans(t)
+
+	var traceparent string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer apiServer.Close()
+
+	ctx, parent := otel.Tracer(tracerName).Start(context.Background(), "tools/call resource_list")
+	client := &http.Client{Transport: tracingRoundTripper(apiServer.URL)(http.DefaultTransport)}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiServer.URL+"/api/v1/namespaces/default/pods", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	var request sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "GET /api/v1/namespaces/default/pods" {
+			request = span
+		}
+	}
+	if request == nil {
+		t.Fatalf("expected a span of the request, got %d spans", len(recorder.Ended()))
+	}
+	if request.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected the request span to be a child of the tool call span")
+	}
+	if cluster := spanAttribute(request, attributeCluster); cluster != apiServer.Listener.Addr().String() {
+		t.Errorf("expected cluster %q, got %q", apiServer.Listener.Addr().String(), cluster)
+	}
+	if traceparent == "" {
+		t.Errorf("expected the trace to be propagated to the API server")
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxResultSize is the default maximum size in bytes of the resources returned by resource_list.
+const DefaultMaxResultSize = 1 << 20
+
+const (
+	// strippedManagedFields is the stripped field holding the field managers of the objects.
+	strippedManagedFields = "metadata.managedFields"
+	// strippedLastApplied is the stripped annotation holding the last applied configuration of the objects.
+	strippedLastApplied = "metadata.annotations[kubectl.kubernetes.io/last-applied-configuration]"
+	// strippedStatus are the stripped fields of the statuses.
+	strippedStatus = "status (except its scalar fields and the type, status and reason of its conditions)"
+)
+
+// ListTruncation tells how the resources of a list exceeding the maximum result size were truncated.
+type ListTruncation struct {
+	// StrippedFields are the fields removed from the resources.
+	StrippedFields []string `json:"strippedFields,omitempty"`
+	// OmittedResources is the number of resources dropped from the end of the list.
+	OmittedResources int `json:"omittedResources,omitempty"`
+}
+
+// message returns the notice of the truncation, with hints to get the omitted resources.
+func (t *ListTruncation) message(returned int) string {
+	message := ". The result exceeded the maximum size and was truncated"
+	if len(t.StrippedFields) > 0 {
+		message += ", removing " + strings.Join(t.StrippedFields, ", ")
+	}
+	if t.OmittedResources > 0 {
+		message += fmt.Sprintf(". The last %d resources were omitted, call again with limit %d and follow the continue tokens to get every resource, "+
+			"or narrow the list with a label selector, a namespace or the summary output mode", t.OmittedResources, max(returned, 1))
+	}
+	return message
+}
+
+// truncateObjects fits the objects in maxSize bytes of JSON, stripping their noisiest fields first
+// and dropping the last objects only when that is not enough. The objects are stripped in place.
+// A maxSize of zero means no limit.
+func truncateObjects(objects []map[string]interface{}, maxSize int) ([]map[string]interface{}, *ListTruncation) {
+	if maxSize <= 0 || objectsSize(objects) <= maxSize {
+		return objects, nil
+	}
+
+	truncation := &ListTruncation{}
+	for _, strip := range []struct {
+		field string
+		strip func(obj map[string]interface{}) bool
+	}{
+		{field: strippedManagedFields, strip: stripManagedFields},
+		{field: strippedLastApplied, strip: stripLastApplied},
+		{field: strippedStatus, strip: stripStatusNoise},
+	} {
+		stripped := false
+		for _, obj := range objects {
+			if strip.strip(obj) {
+				stripped = true
+			}
+		}
+		if stripped {
+			truncation.StrippedFields = append(truncation.StrippedFields, strip.field)
+		}
+		if objectsSize(objects) <= maxSize {
+			return objects, truncation
+		}
+	}
+
+	// The size of the empty list, then of every object and its separator.
+	size := len("[]")
+	for i, obj := range objects {
+		size += jsonSize(obj) + 1
+		if size > maxSize {
+			truncation.OmittedResources = len(objects) - i
+			return objects[:i], truncation
+		}
+	}
+	return objects, truncation
+}
+
+// objectsSize returns the size of the objects encoded in JSON.
+func objectsSize(objects []map[string]interface{}) int {
+	size := len("[]")
+	for _, obj := range objects {
+		size += jsonSize(obj) + 1
+	}
+	return size
+}
+
+func jsonSize(value interface{}) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+func stripManagedFields(obj map[string]interface{}) bool {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if _, ok := metadata["managedFields"]; !ok {
+		return false
+	}
+	delete(metadata, "managedFields")
+	return true
+}
+
+func stripLastApplied(obj map[string]interface{}) bool {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	annotations, ok := metadata["annotations"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if _, ok := annotations["kubectl.kubernetes.io/last-applied-configuration"]; !ok {
+		return false
+	}
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	return true
+}
+
+// stripStatusNoise keeps the scalar fields of the status, like the phase and the replica counts, and
+// the type, status and reason of its conditions, dropping the rest like the container statuses and the
+// images of the nodes.
+func stripStatusNoise(obj map[string]interface{}) bool {
+	status, ok := obj["status"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	stripped := false
+	for field, value := range status {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			delete(status, field)
+			stripped = true
+		case []interface{}:
+			if field != "conditions" {
+				delete(status, field)
+				stripped = true
+				continue
+			}
+			for i, item := range value {
+				condition, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				kept := map[string]interface{}{}
+				for _, key := range []string{"type", "status", "reason"} {
+					if v, ok := condition[key]; ok {
+						kept[key] = v
+					}
+				}
+				if len(kept) != len(condition) {
+					value[i] = kept
+					stripped = true
+				}
+			}
+		}
+	}
+	return stripped
+}
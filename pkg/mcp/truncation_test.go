@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTruncationPod returns a pod with the fields stripped by the truncation.
+func newTruncationPod(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":          name,
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl", "fieldsV1": strings.Repeat("f", 200)}},
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": strings.Repeat("a", 200),
+				"team": "web",
+			},
+		},
+		"status": map[string]interface{}{
+			"phase":             "Running",
+			"containerStatuses": []interface{}{map[string]interface{}{"name": "web", "imageID": strings.Repeat("i", 200)}},
+			"conditions":        []interface{}{map[string]interface{}{"type": "Ready", "status": "True", "message": strings.Repeat("m", 200)}},
+		},
+	}
+}
+
+func TestTruncateObjects(t *testing.T) {
+	stripped := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":        "web-0",
+			"annotations": map[string]interface{}{"team": "web"},
+		},
+		"status": map[string]interface{}{
+			"phase":      "Running",
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		},
+	}
+	strippedSize := jsonSize(stripped) + 1
+
+	tests := []struct {
+		name             string
+		maxSize          int
+		expectedCount    int
+		expectedStripped []string
+		expectedOmitted  int
+		unchanged        bool
+	}{
+		{name: "no limit", maxSize: 0, expectedCount: 3, unchanged: true},
+		{name: "under the limit", maxSize: 1 << 20, expectedCount: 3, unchanged: true},
+		{
+			name:             "stripped to fit",
+			maxSize:          len("[]") + 3*strippedSize,
+			expectedCount:    3,
+			expectedStripped: []string{strippedManagedFields, strippedLastApplied, strippedStatus},
+		},
+		{
+			name:             "cut to fit",
+			maxSize:          len("[]") + 2*strippedSize,
+			expectedCount:    2,
+			expectedStripped: []string{strippedManagedFields, strippedLastApplied, strippedStatus},
+			expectedOmitted:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []map[string]interface{}{newTruncationPod("web-0"), newTruncationPod("web-0"), newTruncationPod("web-0")}
+			result, truncation := truncateObjects(objects, tt.maxSize)
+			if len(result) != tt.expectedCount {
+				t.Fatalf("expected %d objects, got %d", tt.expectedCount, len(result))
+			}
+			if tt.unchanged {
+				if truncation != nil || !reflect.DeepEqual(result[0], newTruncationPod("web-0")) {
+					t.Errorf("expected the objects to be unchanged, got %v and %+v", result[0], truncation)
+				}
+				return
+			}
+			if truncation == nil {
+				t.Fatalf("expected the objects to be truncated")
+			}
+			if !reflect.DeepEqual(truncation.StrippedFields, tt.expectedStripped) || truncation.OmittedResources != tt.expectedOmitted {
+				t.Errorf("expected stripped fields %v and %d omitted resources, got %+v", tt.expectedStripped, tt.expectedOmitted, truncation)
+			}
+			if !reflect.DeepEqual(result[0], stripped) {
+				t.Errorf("expected %v, got %v", stripped, result[0])
+			}
+			if message := truncation.message(len(result)); tt.expectedOmitted > 0 && !strings.Contains(message, "limit 2") {
+				t.Errorf("expected a pagination hint, got %q", message)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// apiWarnings collects the warnings the API servers return for the requests of a tool call, e.g.
+// the deprecation of the API version of a resource or the warnings of admission webhooks.
+type apiWarnings struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+type apiWarningsKey struct{}
+
+// withAPIWarnings returns a context collecting the warnings of the requests made with it.
+func withAPIWarnings(ctx context.Context) (context.Context, *apiWarnings) {
+	warnings := &apiWarnings{}
+	return context.WithValue(ctx, apiWarningsKey{}, warnings), warnings
+}
+
+func (w *apiWarnings) add(text string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// The dry-run of a request returns the same warnings as the request.
+	if !slices.Contains(w.warnings, text) {
+		w.warnings = append(w.warnings, text)
+	}
+}
+
+// list returns the warnings in the order they were first returned.
+func (w *apiWarnings) list() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.warnings)
+}
+
+// warningHandler collects the warnings of the API servers in the context of the request, and logs
+// those of the requests without one.
+type warningHandler struct{}
+
+func (warningHandler) HandleWarningHeaderWithContext(ctx context.Context, code int, _ string, text string) {
+	// As with the default handler of client-go, only the warnings with the 299 code are handled.
+	if code != 299 || text == "" {
+		return
+	}
+	if warnings, ok := ctx.Value(apiWarningsKey{}).(*apiWarnings); ok {
+		warnings.add(text)
+		return
+	}
+	slog.WarnContext(ctx, "API server warning", "warning", text)
+}
+
+// warningsSection returns the section of the text result listing the warnings, empty without warnings.
+func warningsSection(warnings []string) string {
+	if len(warnings) == 0 {
+		return ""
+	}
+	return "API server warnings:\n" + textList(warnings)
+}
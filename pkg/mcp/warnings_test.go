@@ -0,0 +1,70 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestAPIWarnings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add("Warning", `299 - "policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+, unavailable in v1.25+; use policy/v1 PodDisruptionBudget"`)
+		w.Header().Add("Warning", `299 - "admission webhook \"policy.example.com\" does not allow latest tags"`)
+		w.Header().Add("Warning", `199 - "miscellaneous warning"`)
+		//nolint:errcheck
+		w.Write([]byte(`{"apiVersion":"policy/v1beta1","kind":"PodDisruptionBudget","metadata":{"name":"web","namespace":"default"}}`))
+	}))
+	defer server.Close()
+
+	d := NewDynamicConfig("", false, "")
+	d.DisableDiscoveryCache = true
+	dynamicClient, _, err := d.LoadRestConfig("token", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdbs := dynamicClient.Resource(schema.GroupVersionResource{Group: "policy", Version: "v1beta1", Resource: "poddisruptionbudgets"}).Namespace("default")
+
+	ctx, warnings := withAPIWarnings(context.Background())
+	for range 2 {
+		if _, err := pdbs.Get(ctx, "web", metav1.GetOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []string{
+		"policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+, unavailable in v1.25+; use policy/v1 PodDisruptionBudget",
+		`admission webhook "policy.example.com" does not allow latest tags`,
+	}
+	if !reflect.DeepEqual(warnings.list(), expected) {
+		t.Errorf("expected warnings %q, got %q", expected, warnings.list())
+	}
+
+	// The warnings of the requests made without collecting them are only logged.
+	if _, err := pdbs.Get(context.Background(), "web", metav1.GetOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings.list()) != len(expected) {
+		t.Errorf("expected the warnings of other requests not to be collected, got %q", warnings.list())
+	}
+}
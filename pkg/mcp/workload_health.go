@@ -0,0 +1,400 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	deploymentsGVR  = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	statefulSetsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}
+	daemonSetsGVR   = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}
+	replicaSetsGVR  = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}
+)
+
+const (
+	WorkloadHealthy     = "Healthy"
+	WorkloadProgressing = "Progressing"
+	WorkloadDegraded    = "Degraded"
+	WorkloadFailed      = "Failed"
+)
+
+const (
+	// maxReplicaSetHistory is the number of the most recent ReplicaSets included in the health report.
+	maxReplicaSetHistory = 5
+	// maxFailingPods is the number of failing pods included in the health report.
+	maxFailingPods = 10
+)
+
+type WorkloadHealthInput struct {
+	Kind      string `json:"kind,omitempty" jsonschema:"The workload kind (deployment statefulset or daemonset). Defaults to deployment"`
+	Name      string `json:"name,required" jsonschema:"The name of the workload"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"The namespace of the workload"`
+}
+
+type WorkloadHealthResult struct {
+	Health WorkloadHealth `json:"health"`
+}
+
+// WorkloadHealth is the health verdict of a workload together with the data supporting it.
+type WorkloadHealth struct {
+	Kind              string             `json:"kind"`
+	Name              string             `json:"name"`
+	Namespace         string             `json:"namespace"`
+	Verdict           string             `json:"verdict"`
+	Reasons           []string           `json:"reasons,omitempty"`
+	DesiredReplicas   int32              `json:"desiredReplicas"`
+	ReadyReplicas     int32              `json:"readyReplicas"`
+	UpdatedReplicas   int32              `json:"updatedReplicas"`
+	AvailableReplicas int32              `json:"availableReplicas"`
+	Conditions        []ConditionSummary `json:"conditions,omitempty"`
+	ReplicaSets       []ReplicaSetInfo   `json:"replicaSets,omitempty"`
+	FailingPods       []FailingPod       `json:"failingPods,omitempty"`
+}
+
+type ReplicaSetInfo struct {
+	Name          string   `json:"name"`
+	Revision      string   `json:"revision,omitempty"`
+	Replicas      int32    `json:"replicas"`
+	ReadyReplicas int32    `json:"readyReplicas"`
+	Images        []string `json:"images,omitempty"`
+	Created       string   `json:"created,omitempty"`
+}
+
+type FailingPod struct {
+	Name     string   `json:"name"`
+	Phase    string   `json:"phase"`
+	Restarts int32    `json:"restarts"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+func (s *Server) addWorkloadHealthTool(server *mcp.Server, dynamicConfig *DynamicConfig) {
+	addTool(server, &mcp.Tool{
+		Name: "workload_health",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: ptr.To(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   ptr.To(true),
+			ReadOnlyHint:    true,
+			Title:           "Evaluate the health of a Kubernetes workload",
+		},
+		Description: "Evaluate the health of a deployment, statefulset or daemonset. Returns a verdict (Healthy, Progressing, Degraded, Failed) with the reasons, replica counts, rollout conditions, recent ReplicaSet history and the failure reasons of unhealthy pods",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input WorkloadHealthInput) (*mcp.CallToolResult, *WorkloadHealthResult, error) {
+		var gvr schema.GroupVersionResource
+		switch strings.ToLower(input.Kind) {
+		case "", "deployment", "deployments", "deploy":
+			gvr = deploymentsGVR
+		case "statefulset", "statefulsets", "sts":
+			gvr = statefulSetsGVR
+		case "daemonset", "daemonsets", "ds":
+			gvr = daemonSetsGVR
+		default:
+			return nil, nil, fmt.Errorf("unsupported workload kind %q, must be one of: deployment, statefulset, daemonset", input.Kind)
+		}
+
+		dynamicClient, _, err := dynamicConfig.LoadRestConfigForRequest(request)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load dynamic client: %w", err)
+		}
+
+		if input.Namespace == "" {
+			input.Namespace, err = s.sessionNamespace(ctx, request, gvr.Resource)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		obj, err := dynamicClient.Resource(gvr).Namespace(input.Namespace).Get(ctx, input.Name, v1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get %s: %w", gvr.Resource, err)
+		}
+
+		var health WorkloadHealth
+		var selector *v1.LabelSelector
+		var uid string
+		switch gvr {
+		case deploymentsGVR:
+			var deployment appsv1.Deployment
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment); err != nil {
+				return nil, nil, fmt.Errorf("failed to convert deployment: %w", err)
+			}
+			health = deploymentHealth(&deployment)
+			selector, uid = deployment.Spec.Selector, string(deployment.UID)
+		case statefulSetsGVR:
+			var statefulSet appsv1.StatefulSet
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &statefulSet); err != nil {
+				return nil, nil, fmt.Errorf("failed to convert statefulset: %w", err)
+			}
+			health = statefulSetHealth(&statefulSet)
+			selector = statefulSet.Spec.Selector
+		case daemonSetsGVR:
+			var daemonSet appsv1.DaemonSet
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &daemonSet); err != nil {
+				return nil, nil, fmt.Errorf("failed to convert daemonset: %w", err)
+			}
+			health = daemonSetHealth(&daemonSet)
+			selector = daemonSet.Spec.Selector
+		}
+
+		labelSelector, err := v1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid workload selector: %w", err)
+		}
+		listOptions := v1.ListOptions{LabelSelector: labelSelector.String()}
+
+		if gvr == deploymentsGVR {
+			health.ReplicaSets, err = replicaSetHistory(ctx, dynamicClient, input.Namespace, uid, listOptions)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		pods, err := dynamicClient.Resource(podsGVR).Namespace(input.Namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list workload pods: %w", err)
+		}
+		for _, item := range pods.Items {
+			var pod corev1.Pod
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pod); err != nil {
+				continue
+			}
+			health.addPod(&pod)
+		}
+
+		message := fmt.Sprintf("%s %s/%s is %s (%d/%d available)", obj.GetKind(), input.Namespace, input.Name, health.Verdict, health.AvailableReplicas, health.DesiredReplicas)
+		message = textSummary(message, textList(health.Reasons))
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: message,
+				},
+			},
+		}, &WorkloadHealthResult{Health: health}, nil
+	})
+}
+
+// replicaSetHistory returns the most recent ReplicaSets owned by the deployment, newest revision first.
+func replicaSetHistory(ctx context.Context, dynamicClient dynamic.Interface, namespace, deploymentUID string, listOptions v1.ListOptions) ([]ReplicaSetInfo, error) {
+	list, err := dynamicClient.Resource(replicaSetsGVR).Namespace(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+
+	var replicaSets []appsv1.ReplicaSet
+	for _, item := range list.Items {
+		var rs appsv1.ReplicaSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &rs); err != nil {
+			continue
+		}
+		owner := v1.GetControllerOf(&rs)
+		if owner == nil || string(owner.UID) != deploymentUID {
+			continue
+		}
+		replicaSets = append(replicaSets, rs)
+	}
+
+	sort.SliceStable(replicaSets, func(i, j int) bool {
+		return replicaSetRevision(&replicaSets[i]) > replicaSetRevision(&replicaSets[j])
+	})
+	if len(replicaSets) > maxReplicaSetHistory {
+		replicaSets = replicaSets[:maxReplicaSetHistory]
+	}
+
+	infos := make([]ReplicaSetInfo, 0, len(replicaSets))
+	for _, rs := range replicaSets {
+		info := ReplicaSetInfo{
+			Name:          rs.Name,
+			Revision:      rs.Annotations["deployment.kubernetes.io/revision"],
+			Replicas:      rs.Status.Replicas,
+			ReadyReplicas: rs.Status.ReadyReplicas,
+			Created:       rs.CreationTimestamp.UTC().Format("2006-01-02T15:04:05Z"),
+		}
+		for _, container := range rs.Spec.Template.Spec.Containers {
+			info.Images = append(info.Images, container.Image)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func replicaSetRevision(rs *appsv1.ReplicaSet) int64 {
+	revision, err := strconv.ParseInt(rs.Annotations["deployment.kubernetes.io/revision"], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+func deploymentHealth(deployment *appsv1.Deployment) WorkloadHealth {
+	health := WorkloadHealth{
+		Kind:              "Deployment",
+		Name:              deployment.Name,
+		Namespace:         deployment.Namespace,
+		DesiredReplicas:   ptr.Deref(deployment.Spec.Replicas, 1),
+		ReadyReplicas:     deployment.Status.ReadyReplicas,
+		UpdatedReplicas:   deployment.Status.UpdatedReplicas,
+		AvailableReplicas: deployment.Status.AvailableReplicas,
+	}
+
+	failed := false
+	for _, cond := range deployment.Status.Conditions {
+		health.Conditions = append(health.Conditions, ConditionSummary{
+			Type:               string(cond.Type),
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: conditionTime(cond.LastTransitionTime),
+		})
+		switch {
+		case cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse:
+			failed = true
+			health.Reasons = append(health.Reasons, fmt.Sprintf("rollout is not progressing (%s): %s", cond.Reason, cond.Message))
+		case cond.Type == appsv1.DeploymentReplicaFailure && cond.Status == corev1.ConditionTrue:
+			failed = true
+			health.Reasons = append(health.Reasons, fmt.Sprintf("replica creation failed (%s): %s", cond.Reason, cond.Message))
+		}
+	}
+
+	rolloutInProgress := deployment.Status.ObservedGeneration < deployment.Generation ||
+		health.UpdatedReplicas < health.DesiredReplicas ||
+		deployment.Status.Replicas > health.UpdatedReplicas
+	health.Verdict = replicaVerdict(&health, failed, rolloutInProgress, deployment.Spec.Paused)
+	return health
+}
+
+func statefulSetHealth(statefulSet *appsv1.StatefulSet) WorkloadHealth {
+	health := WorkloadHealth{
+		Kind:              "StatefulSet",
+		Name:              statefulSet.Name,
+		Namespace:         statefulSet.Namespace,
+		DesiredReplicas:   ptr.Deref(statefulSet.Spec.Replicas, 1),
+		ReadyReplicas:     statefulSet.Status.ReadyReplicas,
+		UpdatedReplicas:   statefulSet.Status.UpdatedReplicas,
+		AvailableReplicas: statefulSet.Status.AvailableReplicas,
+	}
+	for _, cond := range statefulSet.Status.Conditions {
+		health.Conditions = append(health.Conditions, ConditionSummary{
+			Type:               string(cond.Type),
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: conditionTime(cond.LastTransitionTime),
+		})
+	}
+
+	rolloutInProgress := statefulSet.Status.ObservedGeneration < statefulSet.Generation ||
+		(statefulSet.Status.UpdateRevision != "" && statefulSet.Status.CurrentRevision != statefulSet.Status.UpdateRevision)
+	health.Verdict = replicaVerdict(&health, false, rolloutInProgress, false)
+	return health
+}
+
+func daemonSetHealth(daemonSet *appsv1.DaemonSet) WorkloadHealth {
+	health := WorkloadHealth{
+		Kind:              "DaemonSet",
+		Name:              daemonSet.Name,
+		Namespace:         daemonSet.Namespace,
+		DesiredReplicas:   daemonSet.Status.DesiredNumberScheduled,
+		ReadyReplicas:     daemonSet.Status.NumberReady,
+		UpdatedReplicas:   daemonSet.Status.UpdatedNumberScheduled,
+		AvailableReplicas: daemonSet.Status.NumberAvailable,
+	}
+	for _, cond := range daemonSet.Status.Conditions {
+		health.Conditions = append(health.Conditions, ConditionSummary{
+			Type:               string(cond.Type),
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: conditionTime(cond.LastTransitionTime),
+		})
+	}
+	if daemonSet.Status.NumberMisscheduled > 0 {
+		health.Reasons = append(health.Reasons, fmt.Sprintf("%d pod(s) are running on nodes where they should not", daemonSet.Status.NumberMisscheduled))
+	}
+
+	rolloutInProgress := daemonSet.Status.ObservedGeneration < daemonSet.Generation ||
+		health.UpdatedReplicas < health.DesiredReplicas
+	health.Verdict = replicaVerdict(&health, false, rolloutInProgress, false)
+	return health
+}
+
+// replicaVerdict computes the verdict from the replica counts and appends the reasons explaining it.
+func replicaVerdict(health *WorkloadHealth, failed, rolloutInProgress, paused bool) string {
+	if paused {
+		health.Reasons = append(health.Reasons, "rollout is paused")
+	}
+
+	switch {
+	case failed:
+		return WorkloadFailed
+	case health.DesiredReplicas == 0:
+		health.Reasons = append(health.Reasons, "workload is scaled to zero")
+		return WorkloadHealthy
+	case health.AvailableReplicas >= health.DesiredReplicas && !rolloutInProgress:
+		return WorkloadHealthy
+	case rolloutInProgress && !paused:
+		health.Reasons = append(health.Reasons, fmt.Sprintf("rollout in progress: %d/%d replicas updated", health.UpdatedReplicas, health.DesiredReplicas))
+		return WorkloadProgressing
+	default:
+		health.Reasons = append(health.Reasons, fmt.Sprintf("only %d/%d replicas are available", health.AvailableReplicas, health.DesiredReplicas))
+		return WorkloadDegraded
+	}
+}
+
+// addPod records the pod as failing when it is not ready, and adds its problems to the reasons.
+func (h *WorkloadHealth) addPod(pod *corev1.Pod) {
+	if pod.Status.Phase == corev1.PodSucceeded || isPodReady(pod) {
+		return
+	}
+
+	diagnosis := buildPodDiagnosis(pod, nil)
+	if len(h.FailingPods) >= maxFailingPods {
+		return
+	}
+	h.FailingPods = append(h.FailingPods, FailingPod{
+		Name:     pod.Name,
+		Phase:    diagnosis.Phase,
+		Restarts: diagnosis.TotalRestarts,
+		Problems: diagnosis.Problems,
+	})
+	for _, problem := range diagnosis.Problems {
+		h.Reasons = append(h.Reasons, fmt.Sprintf("pod %s: %s", pod.Name, problem))
+	}
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
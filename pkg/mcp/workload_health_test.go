@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDeploymentHealth(t *testing.T) {
+	tests := []struct {
+		name            string
+		deployment      *appsv1.Deployment
+		expectedVerdict string
+	}{
+		{
+			name: "all replicas available",
+			deployment: &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+				Status: appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3},
+			},
+			expectedVerdict: WorkloadHealthy,
+		},
+		{
+			name: "scaled to zero",
+			deployment: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](0)},
+			},
+			expectedVerdict: WorkloadHealthy,
+		},
+		{
+			name: "rollout in progress",
+			deployment: &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+				Status: appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1, ReadyReplicas: 3, AvailableReplicas: 3},
+			},
+			expectedVerdict: WorkloadProgressing,
+		},
+		{
+			name: "replicas unavailable",
+			deployment: &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+				Status: appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 1, AvailableReplicas: 1},
+			},
+			expectedVerdict: WorkloadDegraded,
+		},
+		{
+			name: "progress deadline exceeded",
+			deployment: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+				Status: appsv1.DeploymentStatus{
+					Replicas:        3,
+					UpdatedReplicas: 1,
+					Conditions: []appsv1.DeploymentCondition{
+						{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+					},
+				},
+			},
+			expectedVerdict: WorkloadFailed,
+		},
+		{
+			name: "paused rollout with unavailable replicas",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: v1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2), Paused: true},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1},
+			},
+			expectedVerdict: WorkloadDegraded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := deploymentHealth(tt.deployment)
+			if health.Verdict != tt.expectedVerdict {
+				t.Errorf("expected verdict %s, got %s (reasons: %v)", tt.expectedVerdict, health.Verdict, health.Reasons)
+			}
+		})
+	}
+}
+
+func TestWorkloadHealthAddPod(t *testing.T) {
+	health := WorkloadHealth{}
+	health.addPod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "ready"},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	})
+	health.addPod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "crashing"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			},
+		},
+	})
+
+	if len(health.FailingPods) != 1 || health.FailingPods[0].Name != "crashing" {
+		t.Fatalf("expected only the crashing pod to be reported, got %+v", health.FailingPods)
+	}
+	if len(health.Reasons) != 1 {
+		t.Errorf("expected the pod problem in the reasons, got %v", health.Reasons)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testing provides an MCP client for integration tests against k-mcp deployments.
+// It takes care of the bearer token, answers the elicitations of the server automatically
+// and offers assertion helpers for the tool results.
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Options configures the client connected by Connect.
+type Options struct {
+	// Endpoint is the URL of the MCP endpoint, e.g. http://localhost:8080/mcp.
+	Endpoint string
+	// Token is the bearer token sent with every request. Use NewToken to generate one.
+	Token string
+	// Elicitation answers the elicitations of the server. Defaults to DeclineAll.
+	Elicitation ElicitationResponder
+}
+
+// Client is a connected MCP client recording the elicitations it answered.
+type Client struct {
+	Session *mcp.ClientSession
+
+	mu           sync.Mutex
+	responder    ElicitationResponder
+	elicitations []*mcp.ElicitParams
+}
+
+// Connect connects to the k-mcp server and closes the session when the test finishes.
+func Connect(ctx context.Context, t testing.TB, opts Options) *Client {
+	t.Helper()
+
+	c := &Client{responder: opts.Elicitation}
+	if c.responder == nil {
+		c.responder = DeclineAll()
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "k-mcp-testing", Version: "v0.0.0"}, &mcp.ClientOptions{
+		ElicitationHandler: c.elicit,
+	})
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint: opts.Endpoint,
+		HTTPClient: &http.Client{Transport: &bearerRoundTripper{
+			token:    opts.Token,
+			delegate: http.DefaultTransport,
+		}},
+		MaxRetries: -1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to connect to %s: %v", opts.Endpoint, err)
+	}
+	t.Cleanup(func() {
+		//nolint:errcheck
+		session.Close()
+	})
+	c.Session = session
+	return c
+}
+
+// SetElicitation replaces the responder answering the elicitations of the server.
+func (c *Client) SetElicitation(responder ElicitationResponder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responder = responder
+}
+
+// Elicitations returns the elicitations received so far.
+func (c *Client) Elicitations() []*mcp.ElicitParams {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*mcp.ElicitParams(nil), c.elicitations...)
+}
+
+func (c *Client) elicit(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+	c.mu.Lock()
+	c.elicitations = append(c.elicitations, req.Params)
+	responder := c.responder
+	c.mu.Unlock()
+	return responder(ctx, req.Params)
+}
+
+// CallTool calls the tool and fails the test when the call could not be made.
+// Tool errors are returned as results, use RequireSuccess or RequireError to check them.
+func (c *Client) CallTool(ctx context.Context, t testing.TB, name string, arguments map[string]any) *mcp.CallToolResult {
+	t.Helper()
+
+	result, err := c.Session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      name,
+		Arguments: arguments,
+	})
+	if err != nil {
+		t.Fatalf("failed to call tool %s: %v", name, err)
+	}
+	return result
+}
+
+// RequireSuccess fails the test when the tool returned an error.
+func RequireSuccess(t testing.TB, result *mcp.CallToolResult) {
+	t.Helper()
+
+	if result.IsError {
+		t.Fatalf("expected the tool to succeed, got error: %s", Text(result))
+	}
+}
+
+// RequireError fails the test when the tool succeeded or its error does not contain substr.
+func RequireError(t testing.TB, result *mcp.CallToolResult, substr string) {
+	t.Helper()
+
+	if !result.IsError {
+		t.Fatalf("expected the tool to fail, got: %s", Text(result))
+	}
+	if text := Text(result); !strings.Contains(text, substr) {
+		t.Fatalf("expected the error to contain %q, got: %s", substr, text)
+	}
+}
+
+// RequireText fails the test when the text content of the result does not contain substr.
+func RequireText(t testing.TB, result *mcp.CallToolResult, substr string) {
+	t.Helper()
+
+	if text := Text(result); !strings.Contains(text, substr) {
+		t.Fatalf("expected the result to contain %q, got: %s", substr, text)
+	}
+}
+
+// DecodeStructured decodes the structured content of a successful result into out.
+func DecodeStructured(t testing.TB, result *mcp.CallToolResult, out any) {
+	t.Helper()
+
+	RequireSuccess(t, result)
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("failed to marshal structured content: %v", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("failed to decode structured content: %v", err)
+	}
+}
+
+// Text returns the text content of the result.
+func Text(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// bearerRoundTripper sets the bearer token on every request.
+type bearerRoundTripper struct {
+	token    string
+	delegate http.RoundTripper
+}
+
+func (rt *bearerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.token == "" {
+		return rt.delegate.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+rt.token)
+	return rt.delegate.RoundTrip(req)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type namespaceInput struct{}
+
+type namespaceResult struct {
+	Namespace string `json:"namespace"`
+}
+
+// newTestServer serves an MCP server with a tool eliciting a namespace, requiring a token of the audience.
+func newTestServer(t *testing.T, audience string) string {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "namespace"}, func(ctx context.Context, request *mcp.CallToolRequest, input namespaceInput) (*mcp.CallToolResult, *namespaceResult, error) {
+		result, err := request.Session.Elicit(ctx, &mcp.ElicitParams{
+			Message: "Select a namespace",
+			RequestedSchema: &jsonschema.Schema{
+				Type:       "object",
+				Properties: map[string]*jsonschema.Schema{"namespace": {Type: "string"}},
+				Required:   []string{"namespace"},
+			},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if result.Action != "accept" {
+			return nil, nil, fmt.Errorf("namespace selection %s", result.Action)
+		}
+		namespace := result.Content["namespace"].(string)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Selected " + namespace}}}, &namespaceResult{Namespace: namespace}, nil
+	})
+
+	verifyToken := func(ctx context.Context, tokenString string, _ *http.Request) (*auth.TokenInfo, error) {
+		token, _, err := jwt.NewParser().ParseUnverified(tokenString, &jwt.RegisteredClaims{})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+		}
+		audiences, _ := token.Claims.GetAudience()
+		expiration, _ := token.Claims.GetExpirationTime()
+		if len(audiences) != 3 || audiences[1] != audience || expiration == nil {
+			return nil, fmt.Errorf("%w: unexpected claims", auth.ErrInvalidToken)
+		}
+		return &auth.TokenInfo{Expiration: expiration.Time}, nil
+	}
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	httpServer := httptest.NewServer(auth.RequireBearerToken(verifyToken, nil)(handler))
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	endpoint := newTestServer(t, "k-mcp")
+
+	token, err := NewToken(TokenOptions{APIServerURL: "https://127.0.0.1:6443"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := Connect(ctx, t, Options{
+		Endpoint:    endpoint,
+		Token:       token,
+		Elicitation: AcceptWith(map[string]any{"namespace": "default", "confirm": true}),
+	})
+
+	result := client.CallTool(ctx, t, "namespace", nil)
+	RequireText(t, result, "Selected default")
+	var structured namespaceResult
+	DecodeStructured(t, result, &structured)
+	if structured.Namespace != "default" {
+		t.Errorf("expected namespace default, got %q", structured.Namespace)
+	}
+
+	client.SetElicitation(Sequence(DeclineAll(), CancelAll()))
+	RequireError(t, client.CallTool(ctx, t, "namespace", nil), "namespace selection decline")
+	RequireError(t, client.CallTool(ctx, t, "namespace", nil), "namespace selection cancel")
+	RequireError(t, client.CallTool(ctx, t, "namespace", nil), "unexpected elicitation")
+
+	if elicitations := client.Elicitations(); len(elicitations) != 4 {
+		t.Errorf("expected 4 elicitations, got %d", len(elicitations))
+	}
+}
+
+func TestAcceptWithMissingField(t *testing.T) {
+	result, err := AcceptWith(map[string]any{"confirm": true})(context.Background(), &mcp.ElicitParams{
+		RequestedSchema: &jsonschema.Schema{
+			Properties: map[string]*jsonschema.Schema{"namespace": {Type: "string"}},
+			Required:   []string{"namespace"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Action != "decline" {
+		t.Errorf("expected decline, got %s", result.Action)
+	}
+}
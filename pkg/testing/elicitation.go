@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ElicitationResponder answers an elicitation of the server on behalf of the user.
+type ElicitationResponder func(ctx context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error)
+
+// AcceptWith accepts every elicitation, answering the requested fields from values.
+// Elicitations requiring a field missing from values are declined.
+//
+// For example, AcceptWith(map[string]any{"namespace": "default", "confirm": true})
+// selects the default namespace and confirms every apply.
+func AcceptWith(values map[string]any) ElicitationResponder {
+	return func(ctx context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+		content := make(map[string]any)
+		if params.RequestedSchema != nil {
+			for _, required := range params.RequestedSchema.Required {
+				if _, ok := values[required]; !ok {
+					return &mcp.ElicitResult{Action: "decline"}, nil
+				}
+			}
+			for property := range params.RequestedSchema.Properties {
+				if value, ok := values[property]; ok {
+					content[property] = value
+				}
+			}
+		}
+		return &mcp.ElicitResult{Action: "accept", Content: content}, nil
+	}
+}
+
+// DeclineAll declines every elicitation.
+func DeclineAll() ElicitationResponder {
+	return func(ctx context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+		return &mcp.ElicitResult{Action: "decline"}, nil
+	}
+}
+
+// CancelAll cancels every elicitation.
+func CancelAll() ElicitationResponder {
+	return func(ctx context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+		return &mcp.ElicitResult{Action: "cancel"}, nil
+	}
+}
+
+// Sequence answers the elicitations with the given responders in order.
+// Elicitations received after the last responder was used fail.
+func Sequence(responders ...ElicitationResponder) ElicitationResponder {
+	var mu sync.Mutex
+	next := 0
+	return func(ctx context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+		mu.Lock()
+		if next >= len(responders) {
+			mu.Unlock()
+			return nil, fmt.Errorf("unexpected elicitation %q", params.Message)
+		}
+		responder := responders[next]
+		next++
+		mu.Unlock()
+		return responder(ctx, params)
+	}
+}
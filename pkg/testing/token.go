@@ -0,0 +1,70 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/ardaguclu/k-mcp/pkg/mcp"
+)
+
+// defaultTokenAudience is the default audience of the Kubernetes API server.
+const defaultTokenAudience = "https://kubernetes.default.svc.cluster.local"
+
+// TokenOptions configures the token generated by NewToken.
+type TokenOptions struct {
+	// APIServerURL is the URL of the API server the tools connect to.
+	APIServerURL string
+	// Audience is the audience of the k-mcp server. Defaults to "k-mcp".
+	Audience string
+	// Lifetime is the duration the token is valid for. Defaults to one hour.
+	Lifetime time.Duration
+	// Scopes are the scopes of the token.
+	Scopes []string
+}
+
+// NewToken generates a token carrying the audiences k-mcp expects. k-mcp does not verify
+// the signature of tokens, but the API server does: generated tokens are only accepted
+// by API servers that do not authenticate requests, such as fakes served by the test.
+// Use a service account token created with `kubectl create token` against real clusters.
+func NewToken(opts TokenOptions) (string, error) {
+	if opts.APIServerURL == "" {
+		return "", fmt.Errorf("the API server URL is required")
+	}
+	if opts.Audience == "" {
+		opts.Audience = "k-mcp"
+	}
+	if opts.Lifetime == 0 {
+		opts.Lifetime = time.Hour
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &mcp.JWTClaims{
+		Scopes: opts.Scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "system:serviceaccount:default:k-mcp-testing",
+			Audience:  jwt.ClaimStrings{opts.APIServerURL, opts.Audience, defaultTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(opts.Lifetime)),
+		},
+	})
+	return token.SignedString([]byte("k-mcp-testing"))
+}